@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/repository"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/security"
+	_ "github.com/lib/pq"
+)
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// Reporta quantas contas e API keys ativas ainda dependem de cada key id de API_KEY_SECRETS.
+// Um key id que nao e o ativo e chega a zero pode ser removido com seguranca. Sai com codigo 1
+// quando alguma chave usa um key id fora da configuracao (exceto com --report-only).
+func main() {
+	reportOnly := flag.Bool("report-only", false, "always exit 0, even when keys use unknown key ids")
+	flag.Parse()
+
+	connStr := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		getEnv("DB_HOST", "localhost"),
+		getEnv("DB_PORT", "5432"),
+		getEnv("DB_USER", "postgres"),
+		getEnv("DB_PASSWORD", "postgres"),
+		getEnv("DB_NAME", "gateway"),
+		getEnv("DB_SSL_MODE", "disable"),
+	)
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		log.Fatalf("error connecting to database: %v", err)
+	}
+	defer db.Close()
+
+	keyIDs, err := security.KeyIDs()
+	if err != nil {
+		log.Fatalf("error loading api key secrets: %v", err)
+	}
+
+	usage, err := repository.NewAPIKeyRepository(db).CountByKeyID(context.Background())
+	if err != nil {
+		log.Fatalf("error counting api keys: %v", err)
+	}
+
+	counted := make(map[string]repository.KeyIDUsage, len(usage))
+	for _, item := range usage {
+		counted[item.KeyID] = item
+	}
+
+	// keyIDs vem com o ativo primeiro.
+	for i, keyID := range keyIDs {
+		item := counted[keyID]
+		slog.Info("api key id usage",
+			"key_id", keyID,
+			"active", i == 0,
+			"accounts", item.Accounts,
+			"keys", item.Keys,
+			"removable", i > 0 && item.Keys == 0,
+		)
+		delete(counted, keyID)
+	}
+
+	for _, item := range counted {
+		slog.Warn("api keys on unknown key id",
+			"key_id", item.KeyID,
+			"accounts", item.Accounts,
+			"keys", item.Keys,
+		)
+	}
+
+	if len(counted) > 0 && !*reportOnly {
+		os.Exit(1)
+	}
+}
@@ -13,20 +13,170 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+	// Fusos das contas (limites por dia e mes) sem depender do tzdata da imagem.
+	_ "time/tzdata"
 
 	_ "github.com/GuiCintra27/payment-gateway/go-gateway/docs"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/billing"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/cardbin"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/expiry"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/fraud"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/ledger"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/outbox"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/ratelimit"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/repository"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/security"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/service"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/handlers"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/middleware"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/server"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/webhook"
 	"github.com/joho/godotenv"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/segmentio/kafka-go"
 )
 
+// newFraudEngine monta o motor antifraude com as regras embutidas.
+// FRAUD_ENGINE_ENABLED=false desliga o motor e mantem apenas a decisao local.
+func newFraudEngine(db *sql.DB) fraud.RuleEngine {
+	if getEnv("FRAUD_ENGINE_ENABLED", "true") == "false" {
+		return nil
+	}
+
+	defaults := fraud.DefaultSettings()
+	if value, err := strconv.Atoi(getEnv("FRAUD_REVIEW_SCORE", "")); err == nil && value > 0 {
+		defaults.ReviewScore = value
+	}
+	if value, err := strconv.Atoi(getEnv("FRAUD_REJECT_SCORE", "")); err == nil && value > 0 {
+		defaults.RejectScore = value
+	}
+
+	store := fraud.NewRepository(db)
+	return fraud.NewEngine(store, defaults, fraud.DefaultRules(store)...)
+}
+
+// newDecider escolhe a estrategia de decisao local via APPROVAL_STRATEGY:
+// random (padrao), seeded (APPROVAL_SEED) ou sandbox (cartoes magicos).
+func newDecider() domain.Decider {
+	switch strategy := getEnv("APPROVAL_STRATEGY", "random"); strategy {
+	case "seeded":
+		seed, err := strconv.ParseInt(getEnv("APPROVAL_SEED", "1"), 10, 64)
+		if err != nil {
+			log.Printf("invalid APPROVAL_SEED, using default: %v", err)
+			seed = 1
+		}
+		return domain.NewSeededDecider(domain.DefaultApprovalRate, seed)
+	case "sandbox":
+		return domain.NewSandboxDecider()
+	case "random":
+	default:
+		log.Printf("invalid APPROVAL_STRATEGY %q, using random", strategy)
+	}
+	return domain.NewRandomDecider(domain.DefaultApprovalRate)
+}
+
+// newBoletoConfig le o emissor de boletos (BOLETO_BANK_CODE, BOLETO_AGREEMENT, BOLETO_WALLET)
+// e o prazo padrao de vencimento (BOLETO_DUE_DAYS).
+func newBoletoConfig() service.BoletoConfig {
+	issuer, err := domain.NewBoletoIssuer(
+		getEnv("BOLETO_BANK_CODE", "001"),
+		getEnv("BOLETO_AGREEMENT", "1234567"),
+		getEnv("BOLETO_WALLET", "18"),
+	)
+	if err != nil {
+		log.Printf("invalid boleto issuer, using default: %v", err)
+		issuer, _ = domain.NewBoletoIssuer("001", "1234567", "18")
+	}
+	dueDays, err := strconv.Atoi(getEnv("BOLETO_DUE_DAYS", "3"))
+	if err != nil || dueDays < 0 {
+		log.Printf("invalid BOLETO_DUE_DAYS, using default")
+		dueDays = 3
+	}
+	return service.BoletoConfig{Issuer: issuer, DueDays: dueDays}
+}
+
+// newPixConfig le o recebedor PIX (PIX_KEY, PIX_MERCHANT_NAME, PIX_MERCHANT_CITY) e a expiracao
+// padrao das cobrancas (PIX_EXPIRATION). Sem PIX_KEY, faturas pix sao recusadas.
+func newPixConfig() service.PixConfig {
+	expiration, err := time.ParseDuration(getEnv("PIX_EXPIRATION", "1h"))
+	if err != nil || expiration <= 0 {
+		log.Printf("invalid PIX_EXPIRATION, using default: %v", err)
+		expiration = time.Hour
+	}
+
+	key := getEnv("PIX_KEY", "")
+	if key == "" {
+		return service.PixConfig{Expiration: expiration}
+	}
+	receiver, err := domain.NewPixReceiver(key, getEnv("PIX_MERCHANT_NAME", "Payment Gateway"), getEnv("PIX_MERCHANT_CITY", "SAO PAULO"))
+	if err != nil {
+		log.Printf("invalid pix receiver, pix disabled: %v", err)
+		return service.PixConfig{Expiration: expiration}
+	}
+	return service.PixConfig{Receiver: receiver, Expiration: expiration}
+}
+
+// newBINTable le a tabela de BINs de CARD_BIN_TABLE_PATH (CSV bin,card_type,issuer_country).
+// Sem o arquivo, usa a tabela de exemplo embutida.
+func newBINTable() *cardbin.Table {
+	path := getEnv("CARD_BIN_TABLE_PATH", "")
+	if path == "" {
+		return cardbin.Default()
+	}
+	table, err := cardbin.Load(path)
+	if err != nil {
+		log.Printf("invalid CARD_BIN_TABLE_PATH, using default: %v", err)
+		return cardbin.Default()
+	}
+	return table
+}
+
+// newDunningSchedule le SUBSCRIPTION_DUNNING_SCHEDULE: esperas entre cobrancas falhas de uma
+// assinatura separadas por virgula (ex.: "24h,72h,120h"). Vazio usa o padrao; um item invalido
+// interrompe a inicializacao, como em SETTLEMENT_DELAYS.
+func newDunningSchedule() domain.DunningSchedule {
+	value := getEnv("SUBSCRIPTION_DUNNING_SCHEDULE", "")
+	if value == "" {
+		return domain.DefaultDunningSchedule
+	}
+	schedule := domain.DunningSchedule{}
+	for _, item := range strings.Split(value, ",") {
+		wait, err := time.ParseDuration(strings.TrimSpace(item))
+		if err != nil || wait <= 0 {
+			log.Fatalf("invalid SUBSCRIPTION_DUNNING_SCHEDULE item: %q", item)
+		}
+		schedule = append(schedule, wait)
+	}
+	return schedule
+}
+
+// newSettlementSchedule le SETTLEMENT_DELAYS no formato tipo=duracao separado por virgulas
+// (ex.: credit_card=720h,boleto=24h,pix=0s). Tipos omitidos mantem o padrao; um item invalido
+// interrompe a inicializacao, para que um prazo de liquidacao nao seja trocado em silencio.
+func newSettlementSchedule() domain.SettlementSchedule {
+	schedule := domain.SettlementSchedule{}
+	for paymentType, delay := range domain.DefaultSettlementSchedule {
+		schedule[paymentType] = delay
+	}
+	value := getEnv("SETTLEMENT_DELAYS", "")
+	if value == "" {
+		return schedule
+	}
+	for _, item := range strings.Split(value, ",") {
+		paymentType, rawDelay, ok := strings.Cut(strings.TrimSpace(item), "=")
+		paymentType = strings.TrimSpace(paymentType)
+		delay, err := time.ParseDuration(strings.TrimSpace(rawDelay))
+		if !ok || err != nil || delay < 0 || !domain.ValidPaymentType(paymentType) {
+			log.Fatalf("invalid SETTLEMENT_DELAYS item: %q", item)
+		}
+		schedule[paymentType] = delay
+	}
+	return schedule
+}
+
 // getEnv retorna variável de ambiente ou valor padrão se não definida
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -69,14 +219,47 @@ func main() {
 	// Inicializa camadas da aplicação (repository -> service -> server)
 	accountRepository := repository.NewAccountRepository(db)
 	accountService := service.NewAccountService(accountRepository)
+	apiKeyCacheTTL, err := time.ParseDuration(getEnv("API_KEY_CACHE_TTL", "30s"))
+	if err != nil {
+		log.Printf("invalid API_KEY_CACHE_TTL, using default: %v", err)
+		apiKeyCacheTTL = service.DefaultAPIKeyCacheTTL
+	}
+	apiKeyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(db), apiKeyCacheTTL)
 
-	invoiceRepository := repository.NewInvoiceRepository(db)
+	// Revogacoes feitas em qualquer replica limpam o cache de API keys desta via LISTEN/NOTIFY
+	revocationListener := pq.NewListener(connStr, 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("api key revocation listener: %v", err)
+		}
+	})
+	if err := revocationListener.Listen(domain.APIKeyRevokedChannel); err != nil {
+		log.Printf("api key revocation listener disabled, revoked keys expire with API_KEY_CACHE_TTL: %v", err)
+	}
+	defer revocationListener.Close()
+	go apiKeyService.ListenRevocations(context.Background(), revocationListener.Notify)
+
+	invoiceRepository := repository.NewInvoiceRepository(db).WithSettlementSchedule(newSettlementSchedule())
 	accountLimitRepository := repository.NewAccountLimitRepository(db)
 	accountLimitService := service.NewAccountLimitService(accountLimitRepository, invoiceRepository)
-	invoiceService := service.NewInvoiceService(invoiceRepository, *accountService, kafkaProducer, accountLimitService)
+	// Sem CARD_VAULT_KEYS (fora de dev), POST /tokens e card_token respondem card_vault_disabled
+	if _, err := security.CardVaultActiveKeyID(); err != nil {
+		log.Printf("card vault disabled: %v", err)
+	}
+	cardTokenRepository := repository.NewCardTokenRepository(db)
+	cardTokenService := service.NewCardTokenService(cardTokenRepository)
+	customerService := service.NewCustomerService(repository.NewCustomerRepository(db), cardTokenRepository)
+	invoiceService := service.NewInvoiceService(invoiceRepository, *accountService, kafkaProducer, accountLimitService, cardTokenService, customerService, newFraudEngine(db), newDecider(), newBINTable(), newBoletoConfig(), newPixConfig())
+	subscriptionService := service.NewSubscriptionService(repository.NewSubscriptionRepository(db), *accountService, customerService, cardTokenRepository, invoiceService, newDunningSchedule())
+	payoutService := service.NewPayoutService(repository.NewPayoutRepository(db), *accountService)
+	pricingService := service.NewPricingService(repository.NewPricingPlanRepository(db), *accountService)
 	demoService := service.NewDemoService(accountRepository, invoiceRepository)
 	healthHandler := handlers.NewHealthHandler(db, baseKafkaConfig.Brokers)
 	idempotencyRepository := repository.NewIdempotencyRepository(db)
+	webhookRepository := webhook.NewRepository(db)
+	// WEBHOOK_ALLOW_PRIVATE_TARGETS=true libera endpoints em localhost e redes privadas (apenas dev)
+	webhookTargets := webhook.TargetPolicy{AllowPrivate: getEnv("WEBHOOK_ALLOW_PRIVATE_TARGETS", "false") == "true"}
+	webhookService := service.NewWebhookService(webhookRepository, webhookTargets)
+	ledgerService := service.NewLedgerService(ledger.NewRepository(db))
 
 	ratePerMinute, err := strconv.Atoi(getEnv("API_RATE_LIMIT_PER_MINUTE", "60"))
 	if err != nil {
@@ -88,7 +271,36 @@ func main() {
 		log.Printf("invalid API_RATE_LIMIT_BURST, using default: %v", err)
 		rateBurst = 10
 	}
-	rateLimitMiddleware := middleware.NewRateLimitMiddleware(ratePerMinute, rateBurst)
+	rateLimitPlans := ratelimit.NewPlans(db, ratelimit.Limit{PerMinute: ratePerMinute, Burst: rateBurst})
+
+	// memory: limite por replica; postgres: janela deslizante compartilhada entre replicas
+	var limiter ratelimit.RateLimiter
+	switch backend := getEnv("RATE_LIMIT_BACKEND", "memory"); backend {
+	case "postgres":
+		postgresLimiter := ratelimit.NewPostgresLimiter(db)
+		go postgresLimiter.Start(context.Background())
+		limiter = postgresLimiter
+	default:
+		if backend != "memory" {
+			log.Printf("invalid RATE_LIMIT_BACKEND %q, using memory", backend)
+		}
+		limiter = ratelimit.NewMemoryLimiter()
+	}
+	// TRUSTED_PROXIES lista os proxies cujos X-Forwarded-For/X-Real-IP sao aceitos no limite por IP
+	trustedProxies, err := middleware.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
+	rateLimitMiddleware := middleware.NewRateLimitMiddleware(limiter, rateLimitPlans, trustedProxies)
+
+	adminKeys, err := security.ParseAdminKeys(os.Getenv("ADMIN_API_KEYS"))
+	if err != nil {
+		log.Fatalf("invalid ADMIN_API_KEYS: %v", err)
+	}
+	if len(adminKeys) == 0 {
+		log.Println("ADMIN_API_KEYS not set, admin API disabled")
+	}
+	adminAuthMiddleware := middleware.NewAdminAuthMiddleware(adminKeys)
 
 	// Configura e inicializa o consumidor Kafka
 	consumerTopic := getEnv("KAFKA_CONSUMER_TOPIC", "transactions_result")
@@ -126,12 +338,117 @@ func main() {
 		Balancer: &kafka.LeastBytes{},
 	}
 	defer outboxWriter.Close()
-	outboxWorker := outbox.NewWorker(outboxRepo, outboxWriter, 500*time.Millisecond, 10, 5)
+	// Webhooks saem por um worker proprio para que endpoints lentos nao atrasem os eventos do Kafka
+	outboxWorker := outbox.NewWorker(outboxRepo, outbox.NewKafkaPublisher(outboxWriter), 500*time.Millisecond, 10, 5).Except(webhook.OutboxEventType)
+
+	webhookMaxAttempts, err := strconv.Atoi(getEnv("WEBHOOK_MAX_ATTEMPTS", "8"))
+	if err != nil {
+		log.Printf("invalid WEBHOOK_MAX_ATTEMPTS, using default: %v", err)
+		webhookMaxAttempts = 8
+	}
+	webhookTimeout, err := time.ParseDuration(getEnv("WEBHOOK_TIMEOUT", "10s"))
+	if err != nil {
+		log.Printf("invalid WEBHOOK_TIMEOUT, using default: %v", err)
+		webhookTimeout = 10 * time.Second
+	}
+	webhookMaxBackoff, err := time.ParseDuration(getEnv("WEBHOOK_MAX_BACKOFF", "10m"))
+	if err != nil {
+		log.Printf("invalid WEBHOOK_MAX_BACKOFF, using default: %v", err)
+		webhookMaxBackoff = 10 * time.Minute
+	}
+	webhookConcurrency, err := strconv.Atoi(getEnv("WEBHOOK_CONCURRENCY", "10"))
+	if err != nil || webhookConcurrency <= 0 {
+		log.Printf("invalid WEBHOOK_CONCURRENCY, using default: %v", err)
+		webhookConcurrency = 10
+	}
+	webhookSender := webhook.NewSender(webhookRepository, webhookTimeout, webhookTargets)
+	webhookWorker := outbox.NewWorker(outboxRepo, webhookSender, 500*time.Millisecond, 10, webhookMaxAttempts).
+		Only(webhook.OutboxEventType).
+		WithConcurrency(webhookConcurrency)
+	webhookWorker.Route(webhook.OutboxEventType, webhookSender, webhookMaxAttempts, webhookMaxBackoff)
+	go webhookWorker.Start(context.Background())
+	// Faturas de teste sao resolvidas pelo antifraude simulado, sem passar pelo Kafka.
+	outboxWorker.Route(service.TestPendingTransactionEventType, service.NewTestModeResponder(invoiceService), 5, 0)
+	// Saques vao ao topico do processador de liquidacao. Depois de PAYOUT_MAX_ATTEMPTS falhas o
+	// evento vira dead e o saque aparece em GET /admin/payouts/dead-letters, com o valor ainda
+	// reservado, ate o operador reenviar ou falhar o saque. Saques de teste sao pagos pelo
+	// processador simulado.
+	payoutMaxAttempts, err := strconv.Atoi(getEnv("PAYOUT_MAX_ATTEMPTS", "30"))
+	if err != nil || payoutMaxAttempts <= 0 {
+		log.Printf("invalid PAYOUT_MAX_ATTEMPTS, using default: %v", err)
+		payoutMaxAttempts = 30
+	}
+	payoutWriter := &kafka.Writer{
+		Addr:     kafka.TCP(baseKafkaConfig.Brokers...),
+		Topic:    getEnv("KAFKA_PAYOUTS_TOPIC", "payouts"),
+		Balancer: &kafka.LeastBytes{},
+	}
+	defer payoutWriter.Close()
+	outboxWorker.Route(service.PayoutRequestedEventType, outbox.NewKafkaPublisher(payoutWriter), payoutMaxAttempts, time.Minute)
+	outboxWorker.Route(service.TestPayoutRequestedEventType, service.NewTestPayoutResponder(payoutService), 5, 0)
 	go outboxWorker.Start(context.Background())
 
+	// Inicia o sweeper que expira faturas pendentes alem do TTL (0 desativa)
+	pendingTTL, err := time.ParseDuration(getEnv("INVOICE_PENDING_TTL", "24h"))
+	if err != nil {
+		log.Printf("invalid INVOICE_PENDING_TTL, using default: %v", err)
+		pendingTTL = 24 * time.Hour
+	}
+	expiryPollEvery, err := time.ParseDuration(getEnv("INVOICE_EXPIRY_POLL_INTERVAL", "1m"))
+	if err != nil {
+		log.Printf("invalid INVOICE_EXPIRY_POLL_INTERVAL, using default: %v", err)
+		expiryPollEvery = time.Minute
+	}
+	if pendingTTL > 0 {
+		expirySweeper := expiry.NewSweeper("pending_expiry", invoiceRepository.ExpirePending, pendingTTL, expiryPollEvery, 100)
+		go expirySweeper.Start(context.Background())
+	}
+
+	// Expira boletos nao pagos apos o vencimento mais a carencia para compensacao bancaria
+	boletoGrace, err := time.ParseDuration(getEnv("BOLETO_EXPIRY_GRACE", "72h"))
+	if err != nil || boletoGrace < 0 {
+		log.Printf("invalid BOLETO_EXPIRY_GRACE, using default: %v", err)
+		boletoGrace = 72 * time.Hour
+	}
+	boletoSweeper := expiry.NewSweeper("boleto_expiry", invoiceRepository.ExpireOverdueBoletos, boletoGrace, expiryPollEvery, 100)
+	go boletoSweeper.Start(context.Background())
+
+	// Expira faturas PIX cuja cobranca passou da expiracao sem pagamento
+	pixSweeper := expiry.NewSweeper("pix_expiry", invoiceRepository.ExpireOverduePix, 0, expiryPollEvery, 100)
+	go pixSweeper.Start(context.Background())
+
+	// Libera automaticamente autorizacoes nao capturadas dentro da janela (0 desativa)
+	authorizationTTL, err := time.ParseDuration(getEnv("INVOICE_AUTHORIZATION_TTL", "168h"))
+	if err != nil {
+		log.Printf("invalid INVOICE_AUTHORIZATION_TTL, using default: %v", err)
+		authorizationTTL = 7 * 24 * time.Hour
+	}
+	if authorizationTTL > 0 {
+		voidSweeper := expiry.NewSweeper("authorization_void", invoiceRepository.VoidExpiredAuthorizations, authorizationTTL, expiryPollEvery, 100)
+		go voidSweeper.Start(context.Background())
+	}
+
+	// Libera para saque os valores aprovados cuja liquidacao ja venceu
+	settlementPollEvery, err := time.ParseDuration(getEnv("SETTLEMENT_RELEASE_INTERVAL", "1m"))
+	if err != nil || settlementPollEvery <= 0 {
+		log.Printf("invalid SETTLEMENT_RELEASE_INTERVAL, using default: %v", err)
+		settlementPollEvery = time.Minute
+	}
+	releaseSweeper := expiry.NewSweeper("balance_release", invoiceRepository.ReleaseMaturedFunds, 0, settlementPollEvery, 100)
+	go releaseSweeper.Start(context.Background())
+
+	// Cobra as assinaturas vencidas e aplica o dunning das faturas recusadas
+	subscriptionPollEvery, err := time.ParseDuration(getEnv("SUBSCRIPTION_POLL_INTERVAL", "1m"))
+	if err != nil || subscriptionPollEvery <= 0 {
+		log.Printf("invalid SUBSCRIPTION_POLL_INTERVAL, using default: %v", err)
+		subscriptionPollEvery = time.Minute
+	}
+	subscriptionScheduler := billing.NewScheduler(subscriptionService, subscriptionPollEvery, 50)
+	go subscriptionScheduler.Start(context.Background())
+
 	// Configura e inicia o servidor HTTP
 	port := getEnv("HTTP_PORT", "8080")
-	srv := server.NewServer(accountService, invoiceService, idempotencyRepository, demoService, healthHandler, rateLimitMiddleware, port)
+	srv := server.NewServer(accountService, apiKeyService, invoiceService, webhookService, ledgerService, accountLimitService, cardTokenService, customerService, subscriptionService, payoutService, pricingService, idempotencyRepository, demoService, healthHandler, rateLimitMiddleware, adminAuthMiddleware, getEnv("PIX_WEBHOOK_SECRET", ""), port)
 	srv.ConfigureRoutes()
 
 	if err := srv.Start(); err != nil {
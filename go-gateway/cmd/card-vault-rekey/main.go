@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/repository"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/security"
+	_ "github.com/lib/pq"
+)
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// Recifra com CARD_VAULT_ACTIVE_KEY_ID todos os tokens de cartao ainda cifrados com key ids
+// antigos. Quando termina, os key ids antigos podem ser removidos de CARD_VAULT_KEYS.
+func main() {
+	batchSize := flag.Int("batch-size", 100, "tokens re-encrypted per transaction")
+	flag.Parse()
+
+	connStr := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		getEnv("DB_HOST", "localhost"),
+		getEnv("DB_PORT", "5432"),
+		getEnv("DB_USER", "postgres"),
+		getEnv("DB_PASSWORD", "postgres"),
+		getEnv("DB_NAME", "gateway"),
+		getEnv("DB_SSL_MODE", "disable"),
+	)
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		log.Fatalf("error connecting to database: %v", err)
+	}
+	defer db.Close()
+
+	activeKeyID, err := security.CardVaultActiveKeyID()
+	if err != nil {
+		log.Fatalf("error loading card vault keys: %v", err)
+	}
+
+	repo := repository.NewCardTokenRepository(db)
+	total := 0
+	for {
+		count, err := repo.RekeyStale(context.Background(), *batchSize)
+		if err != nil {
+			log.Fatalf("error re-encrypting card tokens: %v", err)
+		}
+		if count == 0 {
+			break
+		}
+		total += count
+	}
+
+	slog.Info("card tokens re-encrypted", "active_key_id", activeKeyID, "tokens", total)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/ledger"
+	_ "github.com/lib/pq"
+)
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// Recalcula o saldo de cada conta a partir das partidas do ledger e reporta divergencias
+// com accounts.balance_cents. Sai com codigo 1 quando houver drift (exceto com --report-only).
+func main() {
+	reportOnly := flag.Bool("report-only", false, "always exit 0, even when drift is found")
+	flag.Parse()
+
+	connStr := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		getEnv("DB_HOST", "localhost"),
+		getEnv("DB_PORT", "5432"),
+		getEnv("DB_USER", "postgres"),
+		getEnv("DB_PASSWORD", "postgres"),
+		getEnv("DB_NAME", "gateway"),
+		getEnv("DB_SSL_MODE", "disable"),
+	)
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		log.Fatalf("error connecting to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := ledger.NewRepository(db)
+
+	checked, err := repo.CountAccounts(ctx)
+	if err != nil {
+		log.Fatalf("error counting accounts: %v", err)
+	}
+
+	drifts, err := repo.Reconcile(ctx)
+	if err != nil {
+		log.Fatalf("error reconciling ledger: %v", err)
+	}
+
+	for _, drift := range drifts {
+		slog.Warn("ledger drift",
+			"account_id", drift.AccountID,
+			"mode", drift.Mode,
+			"currency", drift.Currency,
+			"projection", drift.Projection,
+			"balance_cents", drift.BalanceCents,
+			"ledger_cents", drift.LedgerCents,
+			"drift_cents", drift.DriftCents,
+		)
+	}
+	slog.Info("ledger reconcile finished", "accounts_checked", checked, "accounts_with_drift", len(drifts))
+
+	if len(drifts) > 0 && !*reportOnly {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,84 @@
+package billing
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Biller executa as etapas de cobranca recorrente. Cada metodo processa ate limit assinaturas
+// e retorna quantas foram concluidas. Implementado por service.SubscriptionService.
+type Biller interface {
+	// ResolveInvoices aplica o resultado das faturas pendentes que ja tem status final.
+	ResolveInvoices(ctx context.Context, limit int) (int, error)
+	// BillDue cria as faturas das assinaturas com cobranca vencida.
+	BillDue(ctx context.Context, limit int) (int, error)
+}
+
+// Scheduler executa periodicamente a cobranca das assinaturas.
+type Scheduler struct {
+	biller    Biller
+	pollEvery time.Duration
+	batchSize int
+}
+
+func NewScheduler(biller Biller, pollEvery time.Duration, batchSize int) *Scheduler {
+	if pollEvery <= 0 {
+		pollEvery = time.Minute
+	}
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+
+	return &Scheduler{
+		biller:    biller,
+		pollEvery: pollEvery,
+		batchSize: batchSize,
+	}
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.pollEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.tick(ctx)
+		}
+	}
+}
+
+// tick resolve primeiro as faturas pendentes para que assinaturas pagas ou em dunning ja
+// entrem na fila de cobranca com a data correta.
+func (s *Scheduler) tick(ctx context.Context) {
+	if resolved, err := s.drain(ctx, s.biller.ResolveInvoices); err != nil {
+		slog.Error("subscription invoice resolution failed", "error", err)
+	} else if resolved > 0 {
+		slog.Info("subscription invoices resolved", "count", resolved)
+	}
+
+	if billed, err := s.drain(ctx, s.biller.BillDue); err != nil {
+		slog.Error("subscription billing failed", "error", err)
+	} else if billed > 0 {
+		slog.Info("subscriptions billed", "count", billed)
+	}
+}
+
+// drain repete a etapa enquanto ela processar lotes cheios.
+func (s *Scheduler) drain(ctx context.Context, step func(context.Context, int) (int, error)) (int, error) {
+	total := 0
+	for ctx.Err() == nil {
+		processed, err := step(ctx, s.batchSize)
+		if err != nil {
+			return total, err
+		}
+		total += processed
+		if processed < s.batchSize {
+			break
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,53 @@
+package billing
+
+import (
+	"context"
+	"testing"
+)
+
+type fakeBiller struct {
+	settled  int
+	due      int
+	calls    []string
+	dueAtRun []int
+}
+
+func (b *fakeBiller) ResolveInvoices(_ context.Context, limit int) (int, error) {
+	b.calls = append(b.calls, "resolve")
+	resolved := min(limit, b.settled)
+	b.settled -= resolved
+	// Cada fatura paga ou recusada devolve a assinatura para a fila de cobranca.
+	b.due += resolved
+	return resolved, nil
+}
+
+func (b *fakeBiller) BillDue(_ context.Context, limit int) (int, error) {
+	b.calls = append(b.calls, "bill")
+	b.dueAtRun = append(b.dueAtRun, b.due)
+	billed := min(limit, b.due)
+	b.due -= billed
+	return billed, nil
+}
+
+func TestTickResolvesBeforeBillingAndDrainsBatches(t *testing.T) {
+	biller := &fakeBiller{settled: 12, due: 3}
+	scheduler := NewScheduler(biller, 0, 10)
+
+	scheduler.tick(context.Background())
+
+	expected := []string{"resolve", "resolve", "bill", "bill"}
+	if len(biller.calls) != len(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, biller.calls)
+	}
+	for i, call := range expected {
+		if biller.calls[i] != call {
+			t.Fatalf("expected calls %v, got %v", expected, biller.calls)
+		}
+	}
+	if biller.dueAtRun[0] != 15 {
+		t.Fatalf("expected billing to see 15 due subscriptions, got %d", biller.dueAtRun[0])
+	}
+	if biller.settled != 0 || biller.due != 0 {
+		t.Fatalf("expected queues drained, got settled=%d due=%d", biller.settled, biller.due)
+	}
+}
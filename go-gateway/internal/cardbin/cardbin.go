@@ -0,0 +1,129 @@
+package cardbin
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+)
+
+// defaultTable e a tabela embutida, usada sem CARD_BIN_TABLE_PATH.
+//
+//go:embed bins.csv
+var defaultTable []byte
+
+// Tamanhos de BIN aceitos na tabela; a busca usa o prefixo mais longo.
+const (
+	minBINLength = 6
+	maxBINLength = 8
+)
+
+// Info sao os metadados de um BIN: tipo do cartao (credit, debit, prepaid) e pais emissor (ISO 3166-1 alfa-2).
+type Info struct {
+	Funding string
+	Country string
+}
+
+// Table indexa os BINs da tabela CSV. Uma Table nil nao encontra nenhum BIN.
+type Table struct {
+	entries map[string]Info
+}
+
+// Default retorna a tabela embutida no binario.
+func Default() *Table {
+	table, err := Parse(bytes.NewReader(defaultTable))
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded bin table: %v", err))
+	}
+	return table
+}
+
+// Load le a tabela de um arquivo CSV local.
+func Load(path string) (*Table, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Parse le o CSV com cabecalho bin,card_type,issuer_country. Linhas iniciadas por # sao ignoradas.
+func Parse(r io.Reader) (*Table, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("bin table header: %w", err)
+	}
+	if strings.Join(header, ",") != "bin,card_type,issuer_country" {
+		return nil, errors.New("bin table header must be bin,card_type,issuer_country")
+	}
+
+	entries := make(map[string]Info)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		bin, funding, country := record[0], strings.ToLower(record[1]), strings.ToUpper(record[2])
+		if len(bin) < minBINLength || len(bin) > maxBINLength || !isDigits(bin) {
+			return nil, fmt.Errorf("bin table line %d: bin must have 6 to 8 digits", line)
+		}
+		if !domain.ValidCardFunding(funding) {
+			return nil, fmt.Errorf("bin table line %d: card_type must be credit, debit or prepaid", line)
+		}
+		if len(country) != 2 {
+			return nil, fmt.Errorf("bin table line %d: issuer_country must be an ISO 3166-1 alpha-2 code", line)
+		}
+		entries[bin] = Info{Funding: funding, Country: country}
+	}
+	return &Table{entries: entries}, nil
+}
+
+// Lookup busca o BIN mais longo (8 a 6 digitos) que prefixa o numero do cartao.
+func (t *Table) Lookup(number string) (Info, bool) {
+	if t == nil {
+		return Info{}, false
+	}
+	for length := maxBINLength; length >= minBINLength; length-- {
+		if len(number) < length {
+			continue
+		}
+		if info, ok := t.entries[number[:length]]; ok {
+			return info, true
+		}
+	}
+	return Info{}, false
+}
+
+// Len retorna quantos BINs a tabela tem.
+func (t *Table) Len() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.entries)
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package cardbin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookupUsesLongestPrefix(t *testing.T) {
+	table, err := Parse(strings.NewReader("bin,card_type,issuer_country\n424242,credit,US\n42424201,prepaid,br\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if info, ok := table.Lookup("4242420100000000"); !ok || info.Funding != "prepaid" || info.Country != "BR" {
+		t.Fatalf("expected 8 digit bin to win, got %+v (found %v)", info, ok)
+	}
+	if info, ok := table.Lookup("4242424242424242"); !ok || info.Funding != "credit" || info.Country != "US" {
+		t.Fatalf("expected 6 digit bin, got %+v (found %v)", info, ok)
+	}
+	if _, ok := table.Lookup("5555555555554444"); ok {
+		t.Fatalf("expected unknown bin")
+	}
+}
+
+func TestParseRejectsInvalidRows(t *testing.T) {
+	rows := []string{
+		"4242,credit,US",
+		"424242,charge,US",
+		"424242,credit,USA",
+	}
+	for _, row := range rows {
+		if _, err := Parse(strings.NewReader("bin,card_type,issuer_country\n" + row + "\n")); err == nil {
+			t.Fatalf("expected error for %q", row)
+		}
+	}
+}
+
+func TestDefaultTableLoads(t *testing.T) {
+	if Default().Len() == 0 {
+		t.Fatalf("expected embedded bin table to have entries")
+	}
+}
@@ -12,19 +12,26 @@ import (
 
 // Account representa uma conta com suas informações e saldo protegido para acessos concorrentes
 type Account struct {
-	ID           string
-	Name         string
-	Email        string
-	APIKey       string
-	APIKeyKeyID  string
+	ID          string
+	Name        string
+	Email       string
+	APIKey      string
+	APIKeyKeyID string
+	// TestAPIKey acessa apenas os dados de teste da conta.
+	TestAPIKey      string
+	TestAPIKeyKeyID string
+	// Currency e a moeda padrao da conta; BalanceCents e o saldo total nessa moeda e
+	// PendingCents a parte aguardando liquidacao.
+	Currency     string
 	BalanceCents int64
+	PendingCents int64
 	mu           sync.RWMutex
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
 
-// generateAPIKey gera uma chave API segura usando crypto/rand
-func generateAPIKey() (string, error) {
+// generateAPIKey gera uma chave API segura usando crypto/rand, com o prefixo do modo
+func generateAPIKey(mode Mode) (string, error) {
 	// Usa crypto/rand para garantir chaves API seguras
 	b := make([]byte, 16)
 	n, err := rand.Read(b)
@@ -34,12 +41,20 @@ func generateAPIKey() (string, error) {
 	if n != len(b) {
 		return "", fmt.Errorf("random bytes insuficientes: %d", n)
 	}
-	return hex.EncodeToString(b), nil
+	prefix := APIKeyPrefixLive
+	if mode == ModeTest {
+		prefix = APIKeyPrefixTest
+	}
+	return prefix + hex.EncodeToString(b), nil
 }
 
-// NewAccount cria uma conta com ID único, API Key segura e timestamps iniciais
+// NewAccount cria uma conta com ID único, API Keys live e test seguras e timestamps iniciais
 func NewAccount(name, email string) (*Account, error) {
-	apiKey, err := generateAPIKey()
+	apiKey, err := generateAPIKey(ModeLive)
+	if err != nil {
+		return nil, err
+	}
+	testAPIKey, err := generateAPIKey(ModeTest)
 	if err != nil {
 		return nil, err
 	}
@@ -48,9 +63,11 @@ func NewAccount(name, email string) (*Account, error) {
 		ID:           uuid.New().String(),
 		Name:         name,
 		Email:        email,
+		Currency:     DefaultCurrency,
 		BalanceCents: 0,
 		APIKey:       apiKey,
 		APIKeyKeyID:  "",
+		TestAPIKey:   testAPIKey,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
@@ -66,3 +83,17 @@ func (a *Account) AddBalance(amountCents int64) {
 	a.BalanceCents += amountCents
 	a.UpdatedAt = time.Now()
 }
+
+// Balance representa o saldo da conta em uma moeda. BalanceCents e o total; PendingCents e a
+// parte ainda nao liquidada, que nao pode ser sacada.
+type Balance struct {
+	Currency     string
+	BalanceCents int64
+	PendingCents int64
+	UpdatedAt    time.Time
+}
+
+// AvailableCents retorna a parte do saldo disponivel para saque.
+func (b Balance) AvailableCents() int64 {
+	return b.BalanceCents - b.PendingCents
+}
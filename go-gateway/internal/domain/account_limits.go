@@ -1,27 +1,230 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
-// AccountLimit define politicas de limite por conta.
+// LimitWindow e o periodo em que o uso e somado para uma regra de limite.
+type LimitWindow string
+
+const (
+	// LimitWindowTransaction limita o valor de cada fatura.
+	LimitWindowTransaction LimitWindow = "transaction"
+	// LimitWindowDay e LimitWindowMonth seguem o calendario no fuso da conta.
+	LimitWindowDay   LimitWindow = "day"
+	LimitWindowMonth LimitWindow = "month"
+	// Janelas moveis terminam no instante da requisicao.
+	LimitWindowRolling1h  LimitWindow = "rolling_1h"
+	LimitWindowRolling24h LimitWindow = "rolling_24h"
+	LimitWindowRolling7d  LimitWindow = "rolling_7d"
+)
+
+// LimitEnforcement define o que acontece quando um limite e excedido.
+type LimitEnforcement string
+
+const (
+	// LimitEnforcementHard rejeita a fatura com limit_exceeded.
+	LimitEnforcementHard LimitEnforcement = "hard"
+	// LimitEnforcementSoft cria a fatura em pending para revisao manual.
+	LimitEnforcementSoft LimitEnforcement = "soft"
+)
+
+// Tipos de pagamento aceitos nas regras de limite.
+const (
+	PaymentTypeCreditCard = "credit_card"
+	PaymentTypeBoleto     = "boleto"
+	PaymentTypePix        = "pix"
+)
+
+// AccountLimit define politicas de limite por conta, modo e moeda, em unidades minimas da moeda.
+// Os campos Max* valem para todos os tipos de pagamento no dia do fuso da conta; Rules
+// acrescenta janelas moveis, mensais e limites por tipo de pagamento.
 type AccountLimit struct {
 	AccountID            string
+	Mode                 Mode
+	Currency             string
 	MaxAmountPerTxCents  int64
 	MaxDailyVolumeCents  int64
 	MaxDailyTransactions int64
-	CreatedAt            time.Time
-	UpdatedAt            time.Time
+	Rules                []LimitRule
+	Enforcement          LimitEnforcement
+	// Timezone (IANA) define os limites de dia e mes; vazio usa UTC.
+	Timezone  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
-type DailyUsage struct {
+// LimitRule limita volume e/ou quantidade de faturas em uma janela (0 = sem limite).
+// Na janela transaction, MaxVolumeCents e o valor maximo de cada fatura.
+type LimitRule struct {
+	Window LimitWindow `json:"window"`
+	// PaymentType restringe a regra a um tipo de pagamento; vazio vale para todos.
+	PaymentType     string `json:"payment_type,omitempty"`
+	MaxVolumeCents  int64  `json:"max_volume_cents,omitempty"`
+	MaxTransactions int64  `json:"max_transactions,omitempty"`
+}
+
+// LimitUsage e o total e a contagem de faturas em uma janela.
+type LimitUsage struct {
 	TotalCents int64
 	Count      int64
 }
 
 // LimitExceededError representa violacoes de politica da conta.
 type LimitExceededError struct {
-	Reason string
+	Reason      string
+	Window      LimitWindow
+	PaymentType string
 }
 
 func (e LimitExceededError) Error() string {
 	return e.Reason
 }
+
+// Valid indica se a janela e conhecida.
+func (w LimitWindow) Valid() bool {
+	switch w {
+	case LimitWindowTransaction, LimitWindowDay, LimitWindowMonth, LimitWindowRolling1h, LimitWindowRolling24h, LimitWindowRolling7d:
+		return true
+	}
+	return false
+}
+
+// Bounds retorna o intervalo [start, end) da janela que contem now, no fuso loc.
+func (w LimitWindow) Bounds(now time.Time, loc *time.Location) (time.Time, time.Time) {
+	local := now.In(loc)
+	switch w {
+	case LimitWindowDay:
+		start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 0, 1)
+	case LimitWindowMonth:
+		start := time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 1, 0)
+	case LimitWindowRolling1h:
+		return now.Add(-time.Hour), now
+	case LimitWindowRolling24h:
+		return now.Add(-24 * time.Hour), now
+	case LimitWindowRolling7d:
+		return now.Add(-7 * 24 * time.Hour), now
+	}
+	return now, now
+}
+
+// label e o trecho da janela usado nos motivos de violacao (ex.: max_daily_volume_exceeded).
+func (w LimitWindow) label() string {
+	switch w {
+	case LimitWindowDay:
+		return "daily"
+	case LimitWindowMonth:
+		return "monthly"
+	}
+	return string(w)
+}
+
+// ValidEnforcement indica se o modo de aplicacao e conhecido.
+func (e LimitEnforcement) Valid() bool {
+	return e == LimitEnforcementHard || e == LimitEnforcementSoft
+}
+
+// ValidLimitPaymentType indica se o tipo de pagamento pode ser usado em uma regra.
+func ValidLimitPaymentType(paymentType string) bool {
+	return ValidPaymentType(paymentType)
+}
+
+// Location retorna o fuso da conta para as janelas de calendario; fusos invalidos usam UTC.
+func (l AccountLimit) Location() *time.Location {
+	if l.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(l.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Soft indica se violacoes levam a revisao manual em vez de rejeicao.
+func (l AccountLimit) Soft() bool {
+	return l.Enforcement == LimitEnforcementSoft
+}
+
+// EffectiveRules retorna as regras aplicaveis ao tipo de pagamento: primeiro os limites
+// base (por fatura e diario, todos os tipos), depois as regras adicionais.
+func (l AccountLimit) EffectiveRules(paymentType string) []LimitRule {
+	rules := []LimitRule{
+		{Window: LimitWindowTransaction, MaxVolumeCents: l.MaxAmountPerTxCents},
+		{Window: LimitWindowDay, MaxVolumeCents: l.MaxDailyVolumeCents, MaxTransactions: l.MaxDailyTransactions},
+	}
+	for _, rule := range l.Rules {
+		if rule.PaymentType == "" || rule.PaymentType == paymentType {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// CheckAmount verifica a regra contra o valor da fatura e o uso ja registrado na janela.
+// Retorna nil quando a fatura cabe no limite.
+func (r LimitRule) CheckAmount(amountCents int64, usage LimitUsage) *LimitExceededError {
+	if r.Window == LimitWindowTransaction {
+		if r.MaxVolumeCents > 0 && amountCents > r.MaxVolumeCents {
+			return r.exceeded("max_amount_per_tx_exceeded")
+		}
+		return nil
+	}
+
+	label := r.Window.label()
+	if r.MaxVolumeCents > 0 && usage.TotalCents+amountCents > r.MaxVolumeCents {
+		return r.exceeded("max_" + label + "_volume_exceeded")
+	}
+	if r.MaxTransactions > 0 && usage.Count+1 > r.MaxTransactions {
+		return r.exceeded("max_" + label + "_transactions_exceeded")
+	}
+	return nil
+}
+
+func (r LimitRule) exceeded(reason string) *LimitExceededError {
+	if r.PaymentType != "" {
+		reason = strings.Replace(reason, "max_", "max_"+r.PaymentType+"_", 1)
+	}
+	return &LimitExceededError{Reason: reason, Window: r.Window, PaymentType: r.PaymentType}
+}
+
+// Acoes registradas na auditoria de limites.
+const (
+	LimitAuditActionSet   = "set"
+	LimitAuditActionReset = "reset"
+)
+
+// AccountLimitAudit registra uma alteracao de limites feita por um administrador.
+// Previous e nil quando a conta ainda nao tinha limites para o modo e a moeda.
+type AccountLimitAudit struct {
+	ID        string
+	AccountID string
+	Mode      Mode
+	Currency  string
+	Action    string
+	ChangedBy string
+	Reason    string
+	Previous  *AccountLimit
+	Current   AccountLimit
+	CreatedAt time.Time
+}
+
+// AccountLimitStatus combina os limites vigentes com o uso do dia (no fuso da conta) em que Validate se baseia.
+type AccountLimitStatus struct {
+	Limit    AccountLimit
+	Usage    LimitUsage
+	DayStart time.Time
+	DayEnd   time.Time
+	Rules    []LimitRuleStatus
+}
+
+// LimitRuleStatus e o uso atual de uma regra adicional. Start e End ficam zerados na janela transaction.
+type LimitRuleStatus struct {
+	Rule  LimitRule
+	Usage LimitUsage
+	Start time.Time
+	End   time.Time
+}
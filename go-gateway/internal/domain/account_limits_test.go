@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimitWindowBoundsUseAccountTimezone(t *testing.T) {
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	// 01:30 UTC de 1 de marco ainda e 28 de fevereiro em Sao Paulo (UTC-3).
+	now := time.Date(2025, 3, 1, 1, 30, 0, 0, time.UTC)
+
+	start, end := LimitWindowDay.Bounds(now, loc)
+	if want := time.Date(2025, 2, 28, 3, 0, 0, 0, time.UTC); !start.Equal(want) || !end.Equal(want.Add(24*time.Hour)) {
+		t.Fatalf("unexpected day bounds %s - %s", start, end)
+	}
+
+	start, end = LimitWindowMonth.Bounds(now, loc)
+	if !start.Equal(time.Date(2025, 2, 1, 3, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2025, 3, 1, 3, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected month bounds %s - %s", start, end)
+	}
+
+	start, end = LimitWindowRolling24h.Bounds(now, loc)
+	if !end.Equal(now) || end.Sub(start) != 24*time.Hour {
+		t.Fatalf("unexpected rolling bounds %s - %s", start, end)
+	}
+}
+
+func TestEffectiveRulesFilterByPaymentType(t *testing.T) {
+	limit := AccountLimit{
+		MaxAmountPerTxCents: 1000,
+		Rules: []LimitRule{
+			{Window: LimitWindowMonth, MaxVolumeCents: 50000},
+			{Window: LimitWindowDay, PaymentType: PaymentTypeBoleto, MaxTransactions: 5},
+		},
+	}
+
+	if rules := limit.EffectiveRules(PaymentTypeCreditCard); len(rules) != 3 {
+		t.Fatalf("expected base rules plus month rule, got %d", len(rules))
+	}
+	if rules := limit.EffectiveRules(PaymentTypeBoleto); len(rules) != 4 {
+		t.Fatalf("expected boleto rule to apply, got %d", len(rules))
+	}
+}
+
+func TestLimitRuleCheckAmountReasons(t *testing.T) {
+	cases := []struct {
+		rule   LimitRule
+		amount int64
+		usage  LimitUsage
+		want   string
+	}{
+		{LimitRule{Window: LimitWindowTransaction, MaxVolumeCents: 100}, 101, LimitUsage{}, "max_amount_per_tx_exceeded"},
+		{LimitRule{Window: LimitWindowDay, MaxVolumeCents: 1000}, 200, LimitUsage{TotalCents: 900}, "max_daily_volume_exceeded"},
+		{LimitRule{Window: LimitWindowMonth, MaxTransactions: 3}, 1, LimitUsage{Count: 3}, "max_monthly_transactions_exceeded"},
+		{LimitRule{Window: LimitWindowRolling1h, PaymentType: PaymentTypeBoleto, MaxVolumeCents: 500}, 600, LimitUsage{}, "max_boleto_rolling_1h_volume_exceeded"},
+		{LimitRule{Window: LimitWindowRolling7d, MaxVolumeCents: 1000, MaxTransactions: 10}, 100, LimitUsage{TotalCents: 900, Count: 9}, ""},
+	}
+	for _, tc := range cases {
+		violation := tc.rule.CheckAmount(tc.amount, tc.usage)
+		got := ""
+		if violation != nil {
+			got = violation.Reason
+		}
+		if got != tc.want {
+			t.Fatalf("%+v: expected %q, got %q", tc.rule, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Escopos concedidos a uma API key. Cada rota autenticada exige um escopo.
+const (
+	ScopeAccountRead   = "account:read"
+	ScopeInvoicesRead  = "invoices:read"
+	ScopeInvoicesWrite = "invoices:write"
+	ScopeRefundsWrite  = "refunds:write"
+	ScopeWebhooksRead  = "webhooks:read"
+	ScopeWebhooksWrite = "webhooks:write"
+	ScopeAPIKeysRead   = "api_keys:read"
+	ScopeAPIKeysWrite  = "api_keys:write"
+	// ScopePayoutsWrite cadastra contas bancarias e solicita saques; consultas usam account:read.
+	ScopePayoutsWrite = "payouts:write"
+)
+
+// AllScopes sao os escopos das chaves emitidas na criacao da conta.
+var AllScopes = []string{
+	ScopeAccountRead,
+	ScopeInvoicesRead,
+	ScopeInvoicesWrite,
+	ScopeRefundsWrite,
+	ScopeWebhooksRead,
+	ScopeWebhooksWrite,
+	ScopeAPIKeysRead,
+	ScopeAPIKeysWrite,
+	ScopePayoutsWrite,
+}
+
+// APIKeyRevokedChannel e o canal LISTEN/NOTIFY do Postgres que avisa as replicas sobre
+// chaves revogadas ou com escopos alterados; o payload e o id da chave.
+const APIKeyRevokedChannel = "api_key_revoked"
+
+// DefaultAPIKeyLabel identifica as chaves emitidas na criacao da conta.
+const DefaultAPIKeyLabel = "default"
+
+// APIKey e uma credencial da conta. Apenas o hash e persistido; Secret so
+// existe em memoria logo apos a criacao.
+type APIKey struct {
+	ID        string
+	AccountID string
+	Mode      Mode
+	Label     string
+	Scopes    []string
+	// Hash e KeyID identificam a chave no banco (HMAC com o secret KeyID).
+	Hash  string
+	KeyID string
+	// Last4 permite reconhecer a chave sem expor o segredo.
+	Last4      string
+	Secret     string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	ExpiresAt  *time.Time
+	RevokedAt  *time.Time
+}
+
+// ValidScope indica se o escopo e conhecido.
+func ValidScope(scope string) bool {
+	for _, known := range AllScopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+// NewAPIKey gera uma chave com os escopos informados (sem duplicados).
+// Retorna ErrInvalidScope se algum escopo for desconhecido ou a lista estiver vazia.
+func NewAPIKey(accountID string, mode Mode, label string, scopes []string, expiresAt *time.Time) (*APIKey, error) {
+	if len(scopes) == 0 {
+		return nil, ErrInvalidScope
+	}
+
+	unique := make([]string, 0, len(scopes))
+	seen := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		if !ValidScope(scope) {
+			return nil, ErrInvalidScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			unique = append(unique, scope)
+		}
+	}
+
+	secret, err := generateAPIKey(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &APIKey{
+		ID:        uuid.New().String(),
+		AccountID: accountID,
+		Mode:      mode,
+		Label:     strings.TrimSpace(label),
+		Scopes:    unique,
+		Last4:     secret[len(secret)-4:],
+		Secret:    secret,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// NewDefaultAPIKey envolve uma chave emitida na criacao da conta, com todos os escopos.
+// O modo e definido pelo prefixo do segredo.
+func NewDefaultAPIKey(accountID, secret string, createdAt time.Time) *APIKey {
+	return &APIKey{
+		ID:        uuid.New().String(),
+		AccountID: accountID,
+		Mode:      ModeFromAPIKey(secret),
+		Label:     DefaultAPIKeyLabel,
+		Scopes:    append([]string(nil), AllScopes...),
+		Last4:     secret[len(secret)-4:],
+		Secret:    secret,
+		CreatedAt: createdAt,
+	}
+}
+
+// HasScope indica se a chave concede o escopo.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Revoked indica se a chave foi revogada.
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// Expired indica se a chave passou da expiracao em now.
+func (k *APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// Active indica se a chave pode autenticar em now.
+func (k *APIKey) Active(now time.Time) bool {
+	return !k.Revoked() && !k.Expired(now)
+}
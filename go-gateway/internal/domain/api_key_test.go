@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewAPIKeyDeduplicatesScopes(t *testing.T) {
+	key, err := NewAPIKey("acc-1", ModeTest, " ci ", []string{ScopeInvoicesRead, ScopeInvoicesRead}, nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.HasPrefix(key.Secret, APIKeyPrefixTest) {
+		t.Fatalf("expected test key, got %q", key.Secret)
+	}
+	if key.Label != "ci" || len(key.Scopes) != 1 || key.Last4 != key.Secret[len(key.Secret)-4:] {
+		t.Fatalf("unexpected key: %+v", key)
+	}
+	if !key.HasScope(ScopeInvoicesRead) || key.HasScope(ScopeInvoicesWrite) {
+		t.Fatalf("unexpected scopes: %v", key.Scopes)
+	}
+}
+
+func TestNewAPIKeyRejectsUnknownOrEmptyScopes(t *testing.T) {
+	if _, err := NewAPIKey("acc-1", ModeLive, "", nil, nil); err != ErrInvalidScope {
+		t.Fatalf("expected ErrInvalidScope for empty scopes, got %v", err)
+	}
+	if _, err := NewAPIKey("acc-1", ModeLive, "", []string{"invoices:delete"}, nil); err != ErrInvalidScope {
+		t.Fatalf("expected ErrInvalidScope for unknown scope, got %v", err)
+	}
+}
+
+func TestAPIKeyActive(t *testing.T) {
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+	key := &APIKey{ExpiresAt: &expiresAt}
+
+	if !key.Active(now) {
+		t.Fatal("expected key to be active before expiry")
+	}
+	if key.Active(expiresAt) {
+		t.Fatal("expected key to be inactive at expiry")
+	}
+
+	revokedAt := now
+	key.RevokedAt = &revokedAt
+	if key.Active(now) {
+		t.Fatal("expected revoked key to be inactive")
+	}
+}
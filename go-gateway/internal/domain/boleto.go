@@ -0,0 +1,234 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BoletoZone e o fuso de Brasilia, usado para datas de vencimento (sem horario de verao desde 2019).
+var BoletoZone = time.FixedZone("America/Sao_Paulo", -3*60*60)
+
+// BoletoCurrency e a unica moeda aceita em boletos.
+const BoletoCurrency = "BRL"
+
+// boletoCurrencyCode e o codigo de moeda Real no padrao FEBRABAN.
+const boletoCurrencyCode = "9"
+
+// maxBoletoAmountCents e o maior valor que cabe nos 10 digitos do codigo de barras.
+const maxBoletoAmountCents int64 = 9999999999
+
+// maxBoletoOurNumber e o maior nosso numero que cabe no campo livre (10 digitos).
+const maxBoletoOurNumber int64 = 9999999999
+
+// dueFactorBase e a data base do fator de vencimento; o fator 1000 reinicia em 22/02/2025.
+var dueFactorBase = time.Date(1997, time.October, 7, 0, 0, 0, 0, time.UTC)
+
+// Boleto guarda o codigo de barras, a linha digitavel e a liquidacao de uma fatura boleto.
+type Boleto struct {
+	InvoiceID     string
+	BankCode      string
+	OurNumber     int64
+	Barcode       string
+	DigitableLine string
+	// DueDate e a data de vencimento (meia-noite UTC representando o dia em Brasilia).
+	DueDate          time.Time
+	PaidAt           *time.Time
+	PaidAmountCents  int64
+	SettlementSource string
+	CreatedAt        time.Time
+}
+
+// BoletoSettlement e um pagamento informado pelo banco (retorno ou endpoint de liquidacao).
+type BoletoSettlement struct {
+	Barcode         string
+	PaidAmountCents int64
+	PaidAt          time.Time
+	Source          string
+}
+
+// Paid informa se o boleto ja foi liquidado.
+func (b *Boleto) Paid() bool {
+	return b.PaidAt != nil
+}
+
+// BoletoIssuer monta boletos no layout de convenio de 7 posicoes: o campo livre e
+// "000000" + convenio + nosso numero (10 digitos) + carteira.
+type BoletoIssuer struct {
+	BankCode  string
+	Agreement string
+	Wallet    string
+}
+
+// NewBoletoIssuer valida banco (3 digitos), convenio (7) e carteira (2).
+func NewBoletoIssuer(bankCode, agreement, wallet string) (BoletoIssuer, error) {
+	if len(bankCode) != 3 || !isDigits(bankCode) {
+		return BoletoIssuer{}, fmt.Errorf("boleto bank code must have 3 digits")
+	}
+	if len(agreement) != 7 || !isDigits(agreement) {
+		return BoletoIssuer{}, fmt.Errorf("boleto agreement must have 7 digits")
+	}
+	if len(wallet) != 2 || !isDigits(wallet) {
+		return BoletoIssuer{}, fmt.Errorf("boleto wallet must have 2 digits")
+	}
+	return BoletoIssuer{BankCode: bankCode, Agreement: agreement, Wallet: wallet}, nil
+}
+
+// Issue gera o boleto da fatura com o nosso numero e o vencimento informados.
+func (b BoletoIssuer) Issue(invoiceID string, ourNumber, amountCents int64, dueDate time.Time) (*Boleto, error) {
+	if amountCents <= 0 || amountCents > maxBoletoAmountCents {
+		return nil, ErrInvalidAmount
+	}
+	if ourNumber <= 0 || ourNumber > maxBoletoOurNumber {
+		return nil, fmt.Errorf("boleto our number out of range: %d", ourNumber)
+	}
+	factor, err := DueDateFactor(dueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	freeField := "000000" + b.Agreement + fmt.Sprintf("%010d", ourNumber) + b.Wallet
+	barcode := buildBarcode(b.BankCode, factor, amountCents, freeField)
+	return &Boleto{
+		InvoiceID:     invoiceID,
+		BankCode:      b.BankCode,
+		OurNumber:     ourNumber,
+		Barcode:       barcode,
+		DigitableLine: DigitableLine(barcode),
+		DueDate:       civilDate(dueDate),
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// BoletoDueDate retorna o dia de vencimento days dias apos now, no fuso de Brasilia.
+func BoletoDueDate(now time.Time, days int) time.Time {
+	return civilDate(now.In(BoletoZone)).AddDate(0, 0, days)
+}
+
+// BoletoToday retorna o dia corrente em Brasilia no mesmo formato de DueDate.
+func BoletoToday(now time.Time) time.Time {
+	return civilDate(now.In(BoletoZone))
+}
+
+// civilDate descarta horario e fuso, mantendo o dia do calendario.
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// DueDateFactor calcula o fator de vencimento FEBRABAN (4 digitos). Apos o fator 9999
+// (21/02/2025) a contagem reinicia em 1000.
+func DueDateFactor(dueDate time.Time) (string, error) {
+	days := int(civilDate(dueDate).Sub(dueFactorBase).Hours() / 24)
+	if days < 1000 {
+		return "", fmt.Errorf("boleto due date before factor range")
+	}
+	if days > 9999 {
+		days = (days-10000)%9000 + 1000
+	}
+	return fmt.Sprintf("%04d", days), nil
+}
+
+// buildBarcode monta os 44 digitos: banco, moeda, DV geral, fator, valor e campo livre.
+func buildBarcode(bankCode, factor string, amountCents int64, freeField string) string {
+	withoutDV := bankCode + boletoCurrencyCode + factor + fmt.Sprintf("%010d", amountCents) + freeField
+	dv := barcodeDV(withoutDV)
+	return withoutDV[:4] + strconv.Itoa(dv) + withoutDV[4:]
+}
+
+// barcodeDV calcula o digito verificador geral (modulo 11, pesos 2 a 9). Resultados
+// 0, 10 e 11 viram 1.
+func barcodeDV(digits string) int {
+	sum := 0
+	weight := 2
+	for i := len(digits) - 1; i >= 0; i-- {
+		sum += int(digits[i]-'0') * weight
+		weight++
+		if weight > 9 {
+			weight = 2
+		}
+	}
+	dv := 11 - sum%11
+	if dv == 0 || dv == 10 || dv == 11 {
+		return 1
+	}
+	return dv
+}
+
+// mod10 calcula o digito verificador dos campos da linha digitavel (pesos 2 e 1).
+func mod10(digits string) int {
+	sum := 0
+	weight := 2
+	for i := len(digits) - 1; i >= 0; i-- {
+		product := int(digits[i]-'0') * weight
+		if product > 9 {
+			product -= 9
+		}
+		sum += product
+		if weight == 2 {
+			weight = 1
+		} else {
+			weight = 2
+		}
+	}
+	return (10 - sum%10) % 10
+}
+
+// DigitableLine converte o codigo de barras (44) na linha digitavel (47): tres campos
+// com DV modulo 10, o DV geral e fator + valor.
+func DigitableLine(barcode string) string {
+	field1 := barcode[0:4] + barcode[19:24]
+	field2 := barcode[24:34]
+	field3 := barcode[34:44]
+	return field1 + strconv.Itoa(mod10(field1)) +
+		field2 + strconv.Itoa(mod10(field2)) +
+		field3 + strconv.Itoa(mod10(field3)) +
+		barcode[4:5] + barcode[5:19]
+}
+
+// ParseBoletoCode aceita codigo de barras ou linha digitavel (pontos e espacos sao ignorados),
+// confere os digitos verificadores e retorna o codigo de barras.
+func ParseBoletoCode(code string) (string, error) {
+	digits := strings.Map(func(r rune) rune {
+		if r == '.' || r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, code)
+	if !isDigits(digits) {
+		return "", ErrInvalidBoletoCode
+	}
+
+	var barcode string
+	switch len(digits) {
+	case 44:
+		barcode = digits
+	case 47:
+		field1, field2, field3 := digits[0:9], digits[10:20], digits[21:31]
+		if mod10(field1) != int(digits[9]-'0') ||
+			mod10(field2) != int(digits[20]-'0') ||
+			mod10(field3) != int(digits[31]-'0') {
+			return "", ErrInvalidBoletoCode
+		}
+		barcode = digits[0:4] + digits[32:33] + digits[33:47] + digits[4:9] + field2 + field3
+	default:
+		return "", ErrInvalidBoletoCode
+	}
+
+	if barcodeDV(barcode[:4]+barcode[5:]) != int(barcode[4]-'0') {
+		return "", ErrInvalidBoletoCode
+	}
+	return barcode, nil
+}
+
+func isDigits(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDueDateFactorRestartsAfter9999(t *testing.T) {
+	cases := []struct {
+		due  time.Time
+		want string
+	}{
+		{time.Date(2000, 7, 3, 0, 0, 0, 0, time.UTC), "1000"},
+		{time.Date(2025, 2, 21, 0, 0, 0, 0, time.UTC), "9999"},
+		{time.Date(2025, 2, 22, 0, 0, 0, 0, time.UTC), "1000"},
+		{time.Date(2025, 2, 23, 0, 0, 0, 0, time.UTC), "1001"},
+	}
+	for _, tc := range cases {
+		factor, err := DueDateFactor(tc.due)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if factor != tc.want {
+			t.Fatalf("expected factor %s for %s, got %s", tc.want, tc.due.Format("2006-01-02"), factor)
+		}
+	}
+}
+
+func TestParseBoletoCodeAcceptsKnownDigitableLine(t *testing.T) {
+	// Linha digitavel publica de exemplo do Banco do Brasil.
+	barcode, err := ParseBoletoCode("00190.50095 40144.816069 06809.350314 3 37370000000100")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(barcode) != 44 {
+		t.Fatalf("expected 44 digits, got %d", len(barcode))
+	}
+	if line := DigitableLine(barcode); line != "00190500954014481606906809350314337370000000100" {
+		t.Fatalf("unexpected digitable line %s", line)
+	}
+
+	if _, err := ParseBoletoCode("00190500954014481606906809350314437370000000100"); err != ErrInvalidBoletoCode {
+		t.Fatalf("expected invalid general check digit, got %v", err)
+	}
+}
+
+func TestBoletoIssuerIssue(t *testing.T) {
+	issuer, err := NewBoletoIssuer("001", "1234567", "18")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	due := time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC)
+	boleto, err := issuer.Issue("invoice-1", 42, 15050, due)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(boleto.Barcode) != 44 || len(boleto.DigitableLine) != 47 {
+		t.Fatalf("unexpected lengths %d/%d", len(boleto.Barcode), len(boleto.DigitableLine))
+	}
+	if boleto.Barcode[19:44] != "0000001234567000000004218" {
+		t.Fatalf("unexpected free field %s", boleto.Barcode[19:44])
+	}
+	if boleto.Barcode[9:19] != "0000015050" {
+		t.Fatalf("unexpected amount field %s", boleto.Barcode[9:19])
+	}
+	parsed, err := ParseBoletoCode(boleto.DigitableLine)
+	if err != nil || parsed != boleto.Barcode {
+		t.Fatalf("digitable line does not round trip: %s %v", parsed, err)
+	}
+
+	if _, err := issuer.Issue("invoice-1", 42, maxBoletoAmountCents+1, due); err != ErrInvalidAmount {
+		t.Fatalf("expected ErrInvalidAmount, got %v", err)
+	}
+}
+
+func TestBoletoDueDateUsesBrasiliaCalendar(t *testing.T) {
+	// 01:00 UTC de 19/10 ainda e 18/10 em Brasilia.
+	now := time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC)
+	if got := BoletoDueDate(now, 3); !got.Equal(time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected due date %s", got)
+	}
+}
@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bandeiras reconhecidas pelo prefixo (BIN) do numero do cartao.
+const (
+	CardBrandVisa       = "visa"
+	CardBrandMastercard = "mastercard"
+	CardBrandAmex       = "amex"
+	CardBrandElo        = "elo"
+	CardBrandHipercard  = "hipercard"
+	CardBrandUnknown    = "unknown"
+)
+
+// Tipos de cartao (funding) vindos da tabela de BINs.
+const (
+	CardFundingCredit  = "credit"
+	CardFundingDebit   = "debit"
+	CardFundingPrepaid = "prepaid"
+)
+
+// ValidCardFunding informa se o tipo de cartao e conhecido.
+func ValidCardFunding(funding string) bool {
+	return funding == CardFundingCredit || funding == CardFundingDebit || funding == CardFundingPrepaid
+}
+
+// CardBrandRule define os comprimentos de numero e o tamanho do CVV aceitos pela bandeira.
+type CardBrandRule struct {
+	Lengths   []int
+	CVVLength int
+}
+
+var cardBrandRules = map[string]CardBrandRule{
+	CardBrandVisa:       {Lengths: []int{13, 16, 19}, CVVLength: 3},
+	CardBrandMastercard: {Lengths: []int{16}, CVVLength: 3},
+	CardBrandAmex:       {Lengths: []int{15}, CVVLength: 4},
+	CardBrandElo:        {Lengths: []int{16}, CVVLength: 3},
+	CardBrandHipercard:  {Lengths: []int{13, 16, 19}, CVVLength: 3},
+}
+
+// CardRule retorna as regras da bandeira; bandeiras desconhecidas nao sao aceitas.
+func CardRule(brand string) (CardBrandRule, bool) {
+	rule, ok := cardBrandRules[brand]
+	return rule, ok
+}
+
+// ValidLength informa se o comprimento do numero e aceito pela bandeira.
+func (r CardBrandRule) ValidLength(length int) bool {
+	for _, allowed := range r.Lengths {
+		if length == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateCardNumber confere bandeira, comprimento da bandeira e Luhn. Retorna
+// ErrUnsupportedCardBrand ou ErrInvalidCardNumber.
+func ValidateCardNumber(number string) error {
+	if !isDigits(number) {
+		return ErrInvalidCardNumber
+	}
+	rule, ok := CardRule(DetectCardBrand(number))
+	if !ok {
+		return ErrUnsupportedCardBrand
+	}
+	if !rule.ValidLength(len(number)) || !LuhnValid(number) {
+		return ErrInvalidCardNumber
+	}
+	return nil
+}
+
+// CardBIN retorna os 6 primeiros digitos do numero, ou vazio se o numero for curto.
+func CardBIN(number string) string {
+	if len(number) < 6 {
+		return ""
+	}
+	return number[:6]
+}
+
+// binRange e um intervalo fechado de BINs de 6 digitos.
+type binRange struct {
+	from, to int
+}
+
+// eloBINs lista as faixas da Elo; varias ficam dentro de prefixos Visa e Discover,
+// por isso sao avaliadas antes dos prefixos genericos.
+var eloBINs = []binRange{
+	{401178, 401179}, {431274, 431274}, {438935, 438935}, {451416, 451416},
+	{457393, 457393}, {457631, 457632}, {504175, 504175}, {506699, 506778},
+	{509000, 509999}, {627780, 627780}, {636297, 636297}, {636368, 636368},
+	{650031, 650033}, {650035, 650051}, {650405, 650439}, {650485, 650538},
+	{650541, 650598}, {650700, 650718}, {650720, 650727}, {650901, 650920},
+	{651652, 651679}, {655000, 655019}, {655021, 655058},
+}
+
+var hipercardBINs = []binRange{
+	{606282, 606282}, {384100, 384100}, {384140, 384140}, {384160, 384160},
+}
+
+// DetectCardBrand identifica a bandeira pelo BIN. Numeros curtos ou desconhecidos
+// retornam CardBrandUnknown.
+func DetectCardBrand(number string) string {
+	if len(number) < 6 || !isDigits(number) {
+		return CardBrandUnknown
+	}
+	bin, _ := strconv.Atoi(number[:6])
+	switch {
+	case inBINRanges(bin, eloBINs):
+		return CardBrandElo
+	case inBINRanges(bin, hipercardBINs):
+		return CardBrandHipercard
+	case strings.HasPrefix(number, "34"), strings.HasPrefix(number, "37"):
+		return CardBrandAmex
+	case strings.HasPrefix(number, "4"):
+		return CardBrandVisa
+	case bin >= 510000 && bin <= 559999, bin >= 222100 && bin <= 272099:
+		return CardBrandMastercard
+	default:
+		return CardBrandUnknown
+	}
+}
+
+func inBINRanges(bin int, ranges []binRange) bool {
+	for _, r := range ranges {
+		if bin >= r.from && bin <= r.to {
+			return true
+		}
+	}
+	return false
+}
+
+// LuhnValid confere o digito verificador (modulo 10) do numero do cartao.
+func LuhnValid(number string) bool {
+	if len(number) < 2 || !isDigits(number) {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// CardExpired informa se o cartao venceu; o cartao vale ate o fim do mes de validade.
+func CardExpired(month, year int, now time.Time) bool {
+	return year < now.Year() || (year == now.Year() && month < int(now.Month()))
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "testing"
+
+func TestDetectCardBrand(t *testing.T) {
+	cases := map[string]string{
+		"4242424242424242": CardBrandVisa,
+		"5555555555554444": CardBrandMastercard,
+		"2223000048400011": CardBrandMastercard,
+		"378282246310005":  CardBrandAmex,
+		"6362970000457013": CardBrandElo,
+		"4011780000000000": CardBrandElo,
+		"6062825624254001": CardBrandHipercard,
+		"6011111111111117": CardBrandUnknown,
+	}
+	for number, want := range cases {
+		if got := DetectCardBrand(number); got != want {
+			t.Fatalf("expected brand %s for %s, got %s", want, number, got)
+		}
+	}
+}
+
+func TestValidateCardNumberAppliesBrandRules(t *testing.T) {
+	cases := map[string]error{
+		"4242424242424242": nil,
+		"4222222222222":    nil,
+		"378282246310005":  nil,
+		"3782822463100050": ErrInvalidCardNumber,
+		"5555555555554444": nil,
+		"555555555555444":  ErrInvalidCardNumber,
+		"4242424242424241": ErrInvalidCardNumber,
+		"6011111111111117": ErrUnsupportedCardBrand,
+		"42424242424242x2": ErrInvalidCardNumber,
+	}
+	for number, want := range cases {
+		if err := ValidateCardNumber(number); err != want {
+			t.Fatalf("expected %v for %s, got %v", want, number, err)
+		}
+	}
+
+	if rule, ok := CardRule(CardBrandAmex); !ok || rule.CVVLength != 4 {
+		t.Fatalf("expected amex cvv length 4, got %+v", rule)
+	}
+}
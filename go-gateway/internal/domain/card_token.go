@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// cardTokenPrefix identifica tokens do cofre de cartoes nos payloads.
+const cardTokenPrefix = "tok_"
+
+// CardToken e um cartao guardado no cofre. O PAN fica apenas cifrado (Ciphertext, com a
+// chave KeyID); o CVV nunca e persistido.
+type CardToken struct {
+	ID        string
+	AccountID string
+	// Mode impede que tokens de teste sejam usados com chaves live e vice-versa.
+	Mode           Mode
+	Brand          string
+	BIN            string
+	Last4          string
+	ExpiryMonth    int
+	ExpiryYear     int
+	CardholderName string
+	Ciphertext     []byte
+	KeyID          string
+	// CustomerID e o cliente em que o token esta salvo como meio de pagamento; vazio se nenhum.
+	CustomerID string
+	// Number so existe em memoria: na criacao e depois de decifrado para uma fatura.
+	Number    string
+	CreatedAt time.Time
+}
+
+// NewCardToken valida o cartao (bandeira, comprimento, Luhn e validade) e gera o token opaco.
+func NewCardToken(accountID string, mode Mode, card CreditCard, now time.Time) (*CardToken, error) {
+	if err := ValidateCardNumber(card.Number); err != nil {
+		return nil, err
+	}
+	if CardExpired(card.ExpiryMonth, card.ExpiryYear, now) {
+		return nil, ErrCardExpired
+	}
+
+	return &CardToken{
+		ID:             cardTokenPrefix + strings.ReplaceAll(uuid.New().String(), "-", ""),
+		AccountID:      accountID,
+		Mode:           mode,
+		Brand:          DetectCardBrand(card.Number),
+		BIN:            CardBIN(card.Number),
+		Last4:          card.Number[len(card.Number)-4:],
+		ExpiryMonth:    card.ExpiryMonth,
+		ExpiryYear:     card.ExpiryYear,
+		CardholderName: strings.TrimSpace(card.CardholderName),
+		Number:         card.Number,
+		CreatedAt:      now,
+	}, nil
+}
+
+// IsCardToken informa se o valor tem o formato de um token do cofre.
+func IsCardToken(value string) bool {
+	return strings.HasPrefix(value, cardTokenPrefix) && len(value) > len(cardTokenPrefix)
+}
+
+// Expired informa se o cartao do token venceu.
+func (t *CardToken) Expired(now time.Time) bool {
+	return CardExpired(t.ExpiryMonth, t.ExpiryYear, now)
+}
+
+// Card monta o cartao usado na fatura. O CVV fica vazio: ele so e conferido na tokenizacao.
+func (t *CardToken) Card() CreditCard {
+	return CreditCard{
+		Number:         t.Number,
+		ExpiryMonth:    t.ExpiryMonth,
+		ExpiryYear:     t.ExpiryYear,
+		CardholderName: t.CardholderName,
+	}
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewCardTokenValidatesCard(t *testing.T) {
+	now := time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)
+	card := CreditCard{Number: "4242424242424242", CVV: "123", ExpiryMonth: 3, ExpiryYear: 2026, CardholderName: " Demo User "}
+
+	token, err := NewCardToken("acc-1", ModeTest, card, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !IsCardToken(token.ID) || token.Brand != CardBrandVisa || token.BIN != "424242" || token.Last4 != "4242" {
+		t.Fatalf("unexpected token %+v", token)
+	}
+	if token.CardholderName != "Demo User" || token.Card().CVV != "" {
+		t.Fatalf("expected trimmed name and no cvv, got %+v", token.Card())
+	}
+
+	card.Number = "4242424242424241"
+	if _, err := NewCardToken("acc-1", ModeTest, card, now); err != ErrInvalidCardNumber {
+		t.Fatalf("expected ErrInvalidCardNumber, got %v", err)
+	}
+
+	card.Number = "4242424242424242"
+	card.ExpiryMonth = 2
+	if _, err := NewCardToken("acc-1", ModeTest, card, now); err != ErrCardExpired {
+		t.Fatalf("expected ErrCardExpired, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de documento do cliente, identificados pela quantidade de digitos.
+const (
+	DocumentTypeCPF  = "cpf"
+	DocumentTypeCNPJ = "cnpj"
+)
+
+const (
+	// DefaultCustomerPageSize e o tamanho de pagina usado quando limit nao e informado.
+	DefaultCustomerPageSize = 20
+	// MaxCustomerPageSize limita quantos clientes uma pagina pode retornar.
+	MaxCustomerPageSize = 100
+)
+
+// Customer e um cliente do merchant. Clientes sao separados por modo (live/test), como as
+// faturas, e podem ter tokens do cofre salvos como meios de pagamento.
+type Customer struct {
+	ID        string
+	AccountID string
+	Mode      Mode
+	Name      string
+	// Email e guardado em minusculas; Document apenas com digitos.
+	Email        string
+	Document     string
+	DocumentType string
+	// ExternalReference e o identificador do cliente no sistema do merchant, unico por conta e modo.
+	ExternalReference string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// CustomerChanges lista os campos alterados de um cliente; nil mantem o valor atual.
+type CustomerChanges struct {
+	Name              *string
+	Email             *string
+	Document          *string
+	ExternalReference *string
+}
+
+// NewCustomer cria o cliente normalizando nome, email e documento.
+func NewCustomer(accountID string, mode Mode, name, email, document, externalReference string, now time.Time) (*Customer, error) {
+	customer := &Customer{
+		ID:        uuid.New().String(),
+		AccountID: accountID,
+		Mode:      mode,
+		CreatedAt: now,
+	}
+	err := customer.Apply(CustomerChanges{
+		Name:              &name,
+		Email:             &email,
+		Document:          &document,
+		ExternalReference: &externalReference,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+// Apply aplica as alteracoes informadas. Em caso de erro o cliente nao e alterado.
+func (c *Customer) Apply(changes CustomerChanges, now time.Time) error {
+	updated := *c
+	if changes.Name != nil {
+		updated.Name = strings.TrimSpace(*changes.Name)
+		if updated.Name == "" {
+			return ErrInvalidCustomerName
+		}
+	}
+	if changes.Email != nil {
+		updated.Email = strings.ToLower(strings.TrimSpace(*changes.Email))
+	}
+	if changes.Document != nil {
+		document, documentType, err := NormalizeDocument(*changes.Document)
+		if err != nil {
+			return err
+		}
+		updated.Document, updated.DocumentType = document, documentType
+	}
+	if changes.ExternalReference != nil {
+		updated.ExternalReference = strings.TrimSpace(*changes.ExternalReference)
+	}
+	updated.UpdatedAt = now
+	*c = updated
+	return nil
+}
+
+// NormalizeDocument remove a pontuacao e valida CPF (11 digitos) ou CNPJ (14 digitos) pelos
+// digitos verificadores. Documento vazio e aceito e retorna tipo vazio.
+func NormalizeDocument(document string) (string, string, error) {
+	digits := strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '-', '/', ' ':
+			return -1
+		}
+		return r
+	}, document)
+	if digits == "" {
+		return "", "", nil
+	}
+	if !isDigits(digits) || strings.Count(digits, digits[:1]) == len(digits) {
+		return "", "", ErrInvalidDocument
+	}
+
+	switch {
+	case len(digits) == 11 && validCheckDigits(digits, []int{10, 9, 8, 7, 6, 5, 4, 3, 2}):
+		return digits, DocumentTypeCPF, nil
+	case len(digits) == 14 && validCheckDigits(digits, []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}):
+		return digits, DocumentTypeCNPJ, nil
+	default:
+		return "", "", ErrInvalidDocument
+	}
+}
+
+// validCheckDigits confere os dois digitos verificadores (modulo 11) de CPF e CNPJ. weights
+// sao os pesos do primeiro digito; o segundo usa o peso inicial + 1 antes da mesma sequencia.
+func validCheckDigits(digits string, weights []int) bool {
+	base := len(weights)
+	first := checkDigit(digits[:base], weights)
+	second := checkDigit(digits[:base+1], append([]int{weights[0] + 1}, weights...))
+	return int(digits[base]-'0') == first && int(digits[base+1]-'0') == second
+}
+
+func checkDigit(digits string, weights []int) int {
+	sum := 0
+	for i, weight := range weights {
+		sum += int(digits[i]-'0') * weight
+	}
+	if remainder := sum % 11; remainder >= 2 {
+		return 11 - remainder
+	}
+	return 0
+}
+
+// CustomerFilter define filtros e paginacao por keyset da listagem de clientes, com o mesmo
+// cursor (created_at, id) das faturas. Campos vazios nao filtram.
+type CustomerFilter struct {
+	// Mode e definido pela API key, nao pela query.
+	Mode              Mode
+	Email             string
+	ExternalReference string
+	Cursor            *InvoiceCursor
+	Limit             int
+}
+
+// CustomerPage representa uma pagina de clientes e o cursor da proxima pagina, se houver.
+type CustomerPage struct {
+	Customers  []*Customer
+	NextCursor *InvoiceCursor
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizeDocument(t *testing.T) {
+	cases := []struct {
+		input        string
+		document     string
+		documentType string
+		err          error
+	}{
+		{"529.982.247-25", "52998224725", DocumentTypeCPF, nil},
+		{"11.222.333/0001-81", "11222333000181", DocumentTypeCNPJ, nil},
+		{"", "", "", nil},
+		{"529.982.247-26", "", "", ErrInvalidDocument},
+		{"111.111.111-11", "", "", ErrInvalidDocument},
+		{"11.222.333/0001-80", "", "", ErrInvalidDocument},
+		{"5299822472", "", "", ErrInvalidDocument},
+		{"529a982b247", "", "", ErrInvalidDocument},
+	}
+	for _, tc := range cases {
+		document, documentType, err := NormalizeDocument(tc.input)
+		if document != tc.document || documentType != tc.documentType || err != tc.err {
+			t.Fatalf("%q: expected (%q, %q, %v), got (%q, %q, %v)", tc.input, tc.document, tc.documentType, tc.err, document, documentType, err)
+		}
+	}
+}
+
+func TestCustomerApplyKeepsCustomerOnError(t *testing.T) {
+	now := time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)
+	customer, err := NewCustomer("acc-1", ModeTest, " Maria Silva ", " Maria@Example.com ", "529.982.247-25", "crm-1", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if customer.Name != "Maria Silva" || customer.Email != "maria@example.com" || customer.Document != "52998224725" {
+		t.Fatalf("expected normalized customer, got %+v", customer)
+	}
+
+	name, document := "Maria S.", "123"
+	if err := customer.Apply(CustomerChanges{Name: &name, Document: &document}, now.Add(time.Hour)); err != ErrInvalidDocument {
+		t.Fatalf("expected ErrInvalidDocument, got %v", err)
+	}
+	if customer.Name != "Maria Silva" || !customer.UpdatedAt.Equal(now) {
+		t.Fatalf("expected unchanged customer, got %+v", customer)
+	}
+
+	empty := ""
+	if err := customer.Apply(CustomerChanges{Document: &empty}, now.Add(time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if customer.Document != "" || customer.DocumentType != "" || customer.ExternalReference != "crm-1" {
+		t.Fatalf("expected cleared document only, got %+v", customer)
+	}
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// Outcome e o resultado da decisao local de uma fatura.
+type Outcome string
+
+const (
+	OutcomeApprove Outcome = "approve"
+	OutcomeReject  Outcome = "reject"
+	// OutcomeReview mantem a fatura pending para o antifraude externo.
+	OutcomeReview Outcome = "review"
+)
+
+// DefaultApprovalRate e a taxa de aprovacao das decisoes aleatorias.
+const DefaultApprovalRate = 0.7
+
+// Decider decide localmente o status inicial de uma fatura. cardNumber e o
+// numero completo do cartao informado na criacao (vazio fora de cartao).
+type Decider interface {
+	Decide(invoice *Invoice, cardNumber string) Outcome
+}
+
+// RandomDecider envia faturas de alto valor para revisao e aprova as demais
+// com probabilidade approvalRate.
+type RandomDecider struct {
+	approvalRate float64
+	float64      func() float64
+}
+
+func NewRandomDecider(approvalRate float64) *RandomDecider {
+	return &RandomDecider{approvalRate: approvalRate, float64: rand.Float64}
+}
+
+// NewSeededDecider tem o mesmo comportamento do RandomDecider, mas com uma
+// sequencia reproduzivel a partir de seed.
+func NewSeededDecider(approvalRate float64, seed int64) *RandomDecider {
+	rng := rand.New(rand.NewSource(seed))
+	var mu sync.Mutex
+	return &RandomDecider{
+		approvalRate: approvalRate,
+		float64: func() float64 {
+			mu.Lock()
+			defer mu.Unlock()
+			return rng.Float64()
+		},
+	}
+}
+
+func (d *RandomDecider) Decide(invoice *Invoice, _ string) Outcome {
+	if invoice.RequiresReview() {
+		return OutcomeReview
+	}
+	if d.float64() < d.approvalRate {
+		return OutcomeApprove
+	}
+	return OutcomeReject
+}
+
+// SandboxDeclineCard e o cartao magico que o sandbox sempre rejeita.
+const SandboxDeclineCard = "4000000000000002"
+
+// SandboxDecider produz resultados previsiveis para testes e sandbox:
+// SandboxDeclineCard sempre rejeita, valores terminados em .99 vao para
+// revisao, alto valor segue o limite normal e o resto aprova.
+type SandboxDecider struct{}
+
+func NewSandboxDecider() *SandboxDecider {
+	return &SandboxDecider{}
+}
+
+func (d *SandboxDecider) Decide(invoice *Invoice, cardNumber string) Outcome {
+	switch {
+	case cardNumber == SandboxDeclineCard:
+		return OutcomeReject
+	case endsWith99(invoice), invoice.RequiresReview():
+		return OutcomeReview
+	default:
+		return OutcomeApprove
+	}
+}
+
+// endsWith99 indica valores com fracao .99 em moedas com pelo menos 2 casas.
+func endsWith99(invoice *Invoice) bool {
+	factor := MinorFactor(invoice.Currency)
+	if factor < 100 {
+		return false
+	}
+	return invoice.AmountCents%factor == 99*factor/100
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "testing"
+
+func TestSandboxDeciderMagicValues(t *testing.T) {
+	decider := NewSandboxDecider()
+
+	declined := &Invoice{Currency: "BRL", AmountCents: 1000, Status: StatusPending}
+	if outcome := decider.Decide(declined, SandboxDeclineCard); outcome != OutcomeReject {
+		t.Fatalf("expected reject for decline card, got %v", outcome)
+	}
+
+	review := &Invoice{Currency: "BRL", AmountCents: 1099, Status: StatusPending}
+	if outcome := decider.Decide(review, "4242424242424242"); outcome != OutcomeReview {
+		t.Fatalf("expected review for .99 amount, got %v", outcome)
+	}
+
+	approved := &Invoice{Currency: "BRL", AmountCents: 1000, Status: StatusPending}
+	if outcome := decider.Decide(approved, "4242424242424242"); outcome != OutcomeApprove {
+		t.Fatalf("expected approve, got %v", outcome)
+	}
+}
+
+func TestSeededDeciderIsReproducible(t *testing.T) {
+	first := NewSeededDecider(DefaultApprovalRate, 42)
+	second := NewSeededDecider(DefaultApprovalRate, 42)
+
+	for i := 0; i < 20; i++ {
+		invoice := &Invoice{Currency: "BRL", AmountCents: 1000, Status: StatusPending}
+		if a, b := first.Decide(invoice, ""), second.Decide(invoice, ""); a != b {
+			t.Fatalf("expected same outcome for same seed at %d, got %v and %v", i, a, b)
+		}
+	}
+}
@@ -14,7 +14,104 @@ var (
 	// ErrUnauthorizedAccess é retornado quando há tentativa de acesso não autorizado a um recurso.
 	ErrUnauthorizedAccess = errors.New("unauthorized access")
 
-	ErrInvalidAmount     = errors.New("invalid amount")
-	ErrInvalidStatus     = errors.New("invalid status")
-	ErrInvalidCardNumber = errors.New("invalid card number")
+	ErrInvalidAmount = errors.New("invalid amount")
+	// ErrUnsupportedCurrency é retornado quando o código ISO 4217 não é suportado.
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrInvalidStatus       = errors.New("invalid status")
+	ErrInvalidCardNumber   = errors.New("invalid card number")
+
+	// ErrInvoiceNotRefundable é retornado quando a fatura não está em status que permite estorno.
+	ErrInvoiceNotRefundable = errors.New("invoice not refundable")
+	// ErrRefundExceedsAmount é retornado quando o estorno excede o valor restante da fatura.
+	ErrRefundExceedsAmount = errors.New("refund exceeds remaining amount")
+	// ErrInvalidCursor é retornado quando o cursor de paginação não pode ser interpretado.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvoiceNotCancellable é retornado quando a fatura não está mais pendente.
+	ErrInvoiceNotCancellable = errors.New("invoice not cancellable")
+	// ErrInvoiceNotCapturable é retornado quando a fatura não está autorizada.
+	ErrInvoiceNotCapturable = errors.New("invoice not capturable")
+	// ErrCaptureExceedsAmount é retornado quando a captura excede o valor autorizado.
+	ErrCaptureExceedsAmount = errors.New("capture amount exceeds authorized amount")
+	// ErrInvoiceNotVoidable é retornado quando a fatura não está autorizada.
+	ErrInvoiceNotVoidable = errors.New("invoice not voidable")
+	// ErrWebhookEndpointNotFound é retornado quando o endpoint de webhook não existe para a conta.
+	ErrWebhookEndpointNotFound = errors.New("webhook endpoint not found")
+	// ErrAPIKeyNotFound é retornado quando a API key não existe para a conta.
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrAPIKeyRevoked é retornado quando a API key foi revogada.
+	ErrAPIKeyRevoked = errors.New("api key revoked")
+	// ErrAPIKeyExpired é retornado quando a API key passou da expiração.
+	ErrAPIKeyExpired = errors.New("api key expired")
+	// ErrInvalidScope é retornado quando um escopo é desconhecido ou nenhum foi informado.
+	ErrInvalidScope = errors.New("invalid scope")
+	// ErrInsufficientScope é retornado quando uma chave tenta conceder um escopo que não possui.
+	ErrInsufficientScope = errors.New("insufficient scope")
+	// ErrBoletoNotFound é retornado quando nenhum boleto corresponde ao código informado.
+	ErrBoletoNotFound = errors.New("boleto not found")
+	// ErrInvalidBoletoCode é retornado quando o código de barras ou a linha digitável é inválido.
+	ErrInvalidBoletoCode = errors.New("invalid boleto code")
+	// ErrBoletoAlreadyPaid é retornado quando o boleto já foi liquidado.
+	ErrBoletoAlreadyPaid = errors.New("boleto already paid")
+	// ErrBoletoNotPayable é retornado quando a fatura do boleto não está mais pendente.
+	ErrBoletoNotPayable = errors.New("boleto not payable")
+	// ErrBoletoAmountMismatch é retornado quando o valor pago é menor que o valor do boleto.
+	ErrBoletoAmountMismatch = errors.New("boleto paid amount below invoice amount")
+	// ErrPixDisabled é retornado quando PIX é solicitado sem recebedor configurado.
+	ErrPixDisabled = errors.New("pix is not enabled")
+	// ErrPixChargeNotFound é retornado quando nenhuma cobrança PIX corresponde ao txid.
+	ErrPixChargeNotFound = errors.New("pix charge not found")
+	// ErrPixAmountMismatch é retornado quando o valor pago difere do valor da cobrança.
+	ErrPixAmountMismatch = errors.New("pix paid amount differs from charge amount")
+	// ErrPixInvoiceClosed é retornado quando o PIX chega para uma fatura que não está mais pendente.
+	ErrPixInvoiceClosed = errors.New("pix paid for a closed invoice")
+	// ErrCardExpired é retornado quando a validade do cartão já passou.
+	ErrCardExpired = errors.New("card expired")
+	// ErrUnsupportedCardBrand é retornado quando a bandeira do cartão não é aceita.
+	ErrUnsupportedCardBrand = errors.New("unsupported card brand")
+	// ErrCardTokenNotFound é retornado quando o token de cartão não existe para a conta e o modo.
+	ErrCardTokenNotFound = errors.New("card token not found")
+	// ErrCardVaultDisabled é retornado quando o cofre de cartões não tem chaves configuradas.
+	ErrCardVaultDisabled = errors.New("card vault is not enabled")
+	// ErrCardTokenAttached é retornado quando o token de cartão já está salvo em outro cliente.
+	ErrCardTokenAttached = errors.New("card token attached to another customer")
+	// ErrCustomerNotFound é retornado quando o cliente não existe para a conta e o modo.
+	ErrCustomerNotFound = errors.New("customer not found")
+	// ErrInvalidCustomerName é retornado quando o nome do cliente está vazio.
+	ErrInvalidCustomerName = errors.New("invalid customer name")
+	// ErrInvalidDocument é retornado quando o documento não é um CPF ou CNPJ válido.
+	ErrInvalidDocument = errors.New("invalid document")
+	// ErrCustomerReferenceConflict é retornado quando a referência externa já pertence a outro cliente.
+	ErrCustomerReferenceConflict = errors.New("customer external reference already exists")
+	// ErrPlanNotFound é retornado quando o plano não existe para a conta e o modo.
+	ErrPlanNotFound = errors.New("plan not found")
+	// ErrInvalidPlan é retornado quando o intervalo ou o trial do plano está fora dos limites.
+	ErrInvalidPlan = errors.New("invalid plan")
+	// ErrPlanInactive é retornado quando o plano arquivado é usado em uma nova assinatura.
+	ErrPlanInactive = errors.New("plan is archived")
+	// ErrSubscriptionNotFound é retornado quando a assinatura não existe para a conta e o modo.
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	// ErrSubscriptionCanceled é retornado ao alterar uma assinatura já cancelada.
+	ErrSubscriptionCanceled = errors.New("subscription already canceled")
+	// ErrSubscriptionConflict é retornado quando a assinatura mudou desde a leitura.
+	ErrSubscriptionConflict = errors.New("subscription changed concurrently")
+	// ErrBankAccountNotFound é retornado quando a conta bancária não existe para a conta e o modo.
+	ErrBankAccountNotFound = errors.New("bank account not found")
+	// ErrInvalidBankAccount é retornado quando titular, banco, agência, número ou tipo da conta bancária são inválidos.
+	ErrInvalidBankAccount = errors.New("invalid bank account")
+	// ErrPayoutNotFound é retornado quando o saque não existe para a conta e o modo.
+	ErrPayoutNotFound = errors.New("payout not found")
+	// ErrInsufficientBalance é retornado quando o saldo disponível não cobre o valor do saque.
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	// ErrInvalidPayoutTransition é retornado quando o saque não pode ir para o status informado.
+	ErrInvalidPayoutTransition = errors.New("invalid payout status transition")
+	// ErrPayoutNotDeadLettered é retornado quando o evento do saque não esgotou as tentativas de entrega.
+	ErrPayoutNotDeadLettered = errors.New("payout delivery is not dead-lettered")
+	// ErrPayoutRequestInFlight é retornado quando o pedido do saque está sendo publicado e o saque ainda não pode falhar.
+	ErrPayoutRequestInFlight = errors.New("payout request is being published")
+	// ErrPricingPlanNotFound é retornado quando a conta não tem plano de tarifas no modo e na moeda.
+	ErrPricingPlanNotFound = errors.New("pricing plan not found")
+	// ErrLimitBusy é retornado quando o lock de limites da conta não é obtido dentro do prazo.
+	ErrLimitBusy = errors.New("account limits busy")
+	// ErrInvoiceNotInReview é retornado ao decidir a revisão de uma fatura que não aguarda revisão manual.
+	ErrInvoiceNotInReview = errors.New("invoice is not awaiting manual review")
 )
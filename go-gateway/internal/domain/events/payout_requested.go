@@ -0,0 +1,55 @@
+package events
+
+import (
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/google/uuid"
+)
+
+// PayoutBankAccount e a conta de destino enviada ao processador de liquidacao.
+type PayoutBankAccount struct {
+	HolderName     string `json:"holder_name"`
+	HolderDocument string `json:"holder_document"`
+	BankCode       string `json:"bank_code"`
+	Branch         string `json:"branch"`
+	AccountNumber  string `json:"account_number"`
+	AccountType    string `json:"account_type"`
+}
+
+type PayoutRequested struct {
+	SchemaVersion int               `json:"schema_version"`
+	EventID       string            `json:"event_id"`
+	PayoutID      string            `json:"payout_id"`
+	AccountID     string            `json:"account_id"`
+	Mode          string            `json:"mode"`
+	Currency      string            `json:"currency"`
+	MinorUnits    int               `json:"minor_units"`
+	Amount        float64           `json:"amount"`
+	AmountCents   int64             `json:"amount_cents"`
+	BankAccount   PayoutBankAccount `json:"bank_account"`
+	OccurredAt    time.Time         `json:"occurred_at"`
+}
+
+func NewPayoutRequested(payout *domain.Payout, bankAccount *domain.BankAccount) *PayoutRequested {
+	return &PayoutRequested{
+		SchemaVersion: 1,
+		EventID:       uuid.NewString(),
+		PayoutID:      payout.ID,
+		AccountID:     payout.AccountID,
+		Mode:          string(payout.Mode),
+		Currency:      payout.Currency,
+		MinorUnits:    domain.MinorUnits(payout.Currency),
+		Amount:        domain.MinorToAmount(payout.AmountCents, payout.Currency),
+		AmountCents:   payout.AmountCents,
+		BankAccount: PayoutBankAccount{
+			HolderName:     bankAccount.HolderName,
+			HolderDocument: bankAccount.HolderDocument,
+			BankCode:       bankAccount.BankCode,
+			Branch:         bankAccount.Branch,
+			AccountNumber:  bankAccount.AccountNumber,
+			AccountType:    bankAccount.AccountType,
+		},
+		OccurredAt: time.Now(),
+	}
+}
@@ -3,25 +3,31 @@ package events
 import (
 	"time"
 
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
 	"github.com/google/uuid"
 )
 
 type PendingTransaction struct {
-	SchemaVersion int       `json:"schema_version"`
-	EventID       string    `json:"event_id"`
-	AccountID     string    `json:"account_id"`
-	InvoiceID     string    `json:"invoice_id"`
-	Amount        float64   `json:"amount"`
-	AmountCents   int64     `json:"amount_cents"`
-	OccurredAt    time.Time `json:"occurred_at"`
+	SchemaVersion int     `json:"schema_version"`
+	EventID       string  `json:"event_id"`
+	AccountID     string  `json:"account_id"`
+	InvoiceID     string  `json:"invoice_id"`
+	Currency      string  `json:"currency"`
+	MinorUnits    int     `json:"minor_units"`
+	Amount        float64 `json:"amount"`
+	// AmountCents esta em unidades minimas de Currency (nome mantido por compatibilidade).
+	AmountCents int64     `json:"amount_cents"`
+	OccurredAt  time.Time `json:"occurred_at"`
 }
 
-func NewPendingTransaction(accountID, invoiceID string, amount float64, amountCents int64) *PendingTransaction {
+func NewPendingTransaction(accountID, invoiceID, currency string, amount float64, amountCents int64) *PendingTransaction {
 	return &PendingTransaction{
-		SchemaVersion: 2,
+		SchemaVersion: 3,
 		EventID:       uuid.NewString(),
 		AccountID:     accountID,
 		InvoiceID:     invoiceID,
+		Currency:      currency,
+		MinorUnits:    domain.MinorUnits(currency),
 		Amount:        amount,
 		AmountCents:   amountCents,
 		OccurredAt:    time.Now(),
@@ -1,36 +1,114 @@
 package domain
 
 import (
-	"math/rand"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 type Status string
 
 const (
 	StatusPending  Status = "pending"
 	StatusApproved Status = "approved"
 	StatusRejected Status = "rejected"
+	// StatusRefunded indica que todo o valor aprovado foi devolvido.
+	StatusRefunded Status = "refunded"
+	// StatusPartiallyRefunded indica que parte do valor aprovado foi devolvida.
+	StatusPartiallyRefunded Status = "partially_refunded"
+	// StatusCancelled indica que o merchant cancelou a fatura antes do resultado do antifraude.
+	StatusCancelled Status = "cancelled"
+	// StatusExpired indica que a fatura ficou pendente alem do TTL configurado.
+	StatusExpired Status = "expired"
+	// StatusAuthorized indica uma aprovacao com captura manual ainda nao capturada.
+	StatusAuthorized Status = "authorized"
+	// StatusVoided indica que a autorizacao foi liberada sem captura.
+	StatusVoided Status = "voided"
+)
+
+// CaptureMethod define se a aprovacao credita o saldo na hora ou aguarda captura.
+type CaptureMethod string
+
+const (
+	CaptureAutomatic CaptureMethod = "automatic"
+	CaptureManual    CaptureMethod = "manual"
 )
 
-const pendingThresholdCents int64 = 10000 * centsFactor
+// Valid informa se o metodo de captura e conhecido pelo dominio.
+func (c CaptureMethod) Valid() bool {
+	return c == CaptureAutomatic || c == CaptureManual
+}
+
+// Valid informa se o status e conhecido pelo dominio.
+func (s Status) Valid() bool {
+	switch s {
+	case StatusPending, StatusApproved, StatusRejected, StatusRefunded,
+		StatusPartiallyRefunded, StatusCancelled, StatusExpired,
+		StatusAuthorized, StatusVoided:
+		return true
+	default:
+		return false
+	}
+}
+
+// Closed informa se a fatura saiu de pending sem resultado do antifraude.
+// Resultados tardios para faturas fechadas sao apenas registrados.
+func (s Status) Closed() bool {
+	return s == StatusCancelled || s == StatusExpired
+}
+
+// pendingThreshold e o valor (em unidades da moeda) acima do qual a fatura vai para o antifraude.
+const pendingThreshold int64 = 10000
+
+// pendingThresholdMinor retorna o limite de analise em unidades minimas da moeda.
+func pendingThresholdMinor(currency string) int64 {
+	return pendingThreshold * MinorFactor(currency)
+}
 
 type Invoice struct {
-	ID             string
-	AccountID      string
+	ID        string
+	AccountID string
+	// Mode separa faturas de teste das reais; faturas test nunca vao ao antifraude real.
+	Mode     Mode
+	Currency string
+	// AmountCents, CapturedCents e RefundedCents estao em unidades minimas de Currency.
 	AmountCents    int64
+	CapturedCents  int64
+	RefundedCents  int64
+	CaptureMethod  CaptureMethod
 	Status         Status
 	Description    string
 	PaymentType    string
 	CardLastDigits string
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
+	// CardBrand e CardBIN vem do numero; CardFunding e CardCountry da tabela de BINs (vazios se o BIN nao estiver nela).
+	CardBrand   string
+	CardBIN     string
+	CardFunding string
+	CardCountry string
+	// CustomerID e o cliente da fatura; vazio quando nao informado.
+	CustomerID string
+	// Installments e o numero de parcelas (apenas credit_card; 1 a vista).
+	Installments int
+	// ManualReview marca faturas de cartao retidas por limite soft: ficam pending ate a decisao
+	// de um operador e resultados do antifraude nao as aprovam.
+	ManualReview bool
+	// FeeCents e NetCents sao definidos no credito do saldo: apenas NetCents (capturado menos
+	// a tarifa) chega ao saldo do merchant. Fee detalha a tarifa aplicada.
+	FeeCents  int64
+	NetCents  int64
+	Fee       *FeeBreakdown
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ValidPaymentType informa se o tipo de pagamento e aceito na criacao de faturas.
+func ValidPaymentType(paymentType string) bool {
+	switch paymentType {
+	case PaymentTypeCreditCard, PaymentTypeBoleto, PaymentTypePix:
+		return true
+	default:
+		return false
+	}
 }
 
 type CreditCard struct {
@@ -46,43 +124,67 @@ func NewInvoice(accountID string, amountCents int64, description string, payment
 		return nil, ErrInvalidAmount
 	}
 
-	if len(card.Number) < 4 {
-		return nil, ErrInvalidCardNumber
+	// Apenas cartao guarda ultimos digitos, bandeira e BIN; boleto e PIX nao tem cartao.
+	lastDigits, brand, bin := "", "", ""
+	if paymentType == PaymentTypeCreditCard {
+		if len(card.Number) < 4 {
+			return nil, ErrInvalidCardNumber
+		}
+		lastDigits = card.Number[len(card.Number)-4:]
+		brand = DetectCardBrand(card.Number)
+		bin = CardBIN(card.Number)
 	}
 
-	lastDigits := card.Number[len(card.Number)-4:]
-
 	return &Invoice{
 		ID:             uuid.New().String(),
 		AccountID:      accountID,
+		Mode:           ModeLive,
+		Currency:       DefaultCurrency,
 		AmountCents:    amountCents,
+		CaptureMethod:  CaptureAutomatic,
 		Status:         StatusPending,
 		Description:    description,
 		PaymentType:    paymentType,
 		CardLastDigits: lastDigits,
+		CardBrand:      brand,
+		CardBIN:        bin,
+		Installments:   1,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
 	}, nil
 }
 
-func (i *Invoice) Process() error {
-	if i.AmountCents > pendingThresholdCents {
-		return nil
-	}
+// ApplyFee registra a tarifa calculada sobre o valor capturado.
+func (i *Invoice) ApplyFee(fee FeeBreakdown) {
+	i.Fee = &fee
+	i.FeeCents = fee.FeeCents
+	i.NetCents = i.CapturedCents - fee.FeeCents
+}
 
-	var newStatus Status
+// RequiresReview indica faturas acima do limite de analise, que ficam pending
+// ate o resultado do antifraude externo.
+func (i *Invoice) RequiresReview() bool {
+	return i.AmountCents > pendingThresholdMinor(i.Currency)
+}
 
-	if rand.Float64() <= 0.7 {
-		newStatus = StatusApproved
-	} else {
-		newStatus = StatusRejected
+// Process aplica a decisao local do decider. Faturas em revisao permanecem pending.
+func (i *Invoice) Process(decider Decider, cardNumber string) error {
+	switch decider.Decide(i, cardNumber) {
+	case OutcomeApprove:
+		i.approve()
+	case OutcomeReject:
+		i.Status = StatusRejected
 	}
 
-	i.Status = newStatus
 	return nil
 }
 
+// UpdateStatus aplica o resultado de uma fatura pendente. Aprovacoes de faturas
+// com captura manual viram authorized.
 func (i *Invoice) UpdateStatus(newStatus Status) error {
+	if newStatus == StatusApproved && i.CaptureMethod == CaptureManual {
+		newStatus = StatusAuthorized
+	}
 	if i.Status == newStatus {
 		return nil
 	}
@@ -90,7 +192,124 @@ func (i *Invoice) UpdateStatus(newStatus Status) error {
 		return ErrInvalidStatus
 	}
 
-	i.Status = newStatus
+	if newStatus == StatusAuthorized && i.CaptureMethod != CaptureManual {
+		return ErrInvalidStatus
+	}
+
+	if newStatus == i.ApprovedStatus() {
+		i.approve()
+	} else {
+		i.Status = newStatus
+	}
+	i.UpdatedAt = time.Now()
+	return nil
+}
+
+// ApprovedStatus retorna o status final de uma aprovacao conforme o metodo de captura.
+func (i *Invoice) ApprovedStatus() Status {
+	if i.CaptureMethod == CaptureManual {
+		return StatusAuthorized
+	}
+	return StatusApproved
+}
+
+// approve aprova a fatura; na captura automatica todo o valor e capturado.
+func (i *Invoice) approve() {
+	i.Status = i.ApprovedStatus()
+	if i.Status == StatusApproved {
+		i.CapturedCents = i.AmountCents
+	}
+}
+
+// Capture captura total ou parcialmente uma fatura autorizada.
+// Quando amountCents e zero, captura todo o valor autorizado; o restante e liberado.
+func (i *Invoice) Capture(amountCents int64) error {
+	if i.Status != StatusAuthorized {
+		return ErrInvoiceNotCapturable
+	}
+	if amountCents == 0 {
+		amountCents = i.AmountCents
+	}
+	if amountCents < 0 {
+		return ErrInvalidAmount
+	}
+	if amountCents > i.AmountCents {
+		return ErrCaptureExceedsAmount
+	}
+
+	i.CapturedCents = amountCents
+	i.Status = StatusApproved
+	i.UpdatedAt = time.Now()
+	return nil
+}
+
+// Void libera uma autorizacao sem captura.
+func (i *Invoice) Void() error {
+	if i.Status != StatusAuthorized {
+		return ErrInvoiceNotVoidable
+	}
+	i.Status = StatusVoided
+	i.UpdatedAt = time.Now()
+	return nil
+}
+
+// RefundableCents retorna quanto do valor capturado ainda pode ser estornado.
+func (i *Invoice) RefundableCents() int64 {
+	switch i.Status {
+	case StatusApproved, StatusPartiallyRefunded:
+		return i.CapturedCents - i.RefundedCents
+	default:
+		return 0
+	}
+}
+
+// Refund estorna total ou parcialmente uma fatura aprovada.
+// Quando amountCents e zero, estorna todo o saldo restante. A tarifa retida e devolvida
+// na proporcao do valor estornado; o estorno do restante devolve toda a tarifa que sobrou.
+func (i *Invoice) Refund(amountCents int64) error {
+	if i.Status != StatusApproved && i.Status != StatusPartiallyRefunded {
+		return ErrInvoiceNotRefundable
+	}
+
+	remaining := i.RefundableCents()
+	if amountCents == 0 {
+		amountCents = remaining
+	}
+	if amountCents < 0 {
+		return ErrInvalidAmount
+	}
+	if amountCents > remaining {
+		return ErrRefundExceedsAmount
+	}
+
+	i.FeeCents -= i.FeeCents * amountCents / remaining
+	i.RefundedCents += amountCents
+	i.NetCents = i.CapturedCents - i.RefundedCents - i.FeeCents
+	if i.RefundedCents == i.CapturedCents {
+		i.Status = StatusRefunded
+	} else {
+		i.Status = StatusPartiallyRefunded
+	}
+	i.UpdatedAt = time.Now()
+	return nil
+}
+
+// Cancel cancela uma fatura ainda pendente.
+func (i *Invoice) Cancel() error {
+	if i.Status != StatusPending {
+		return ErrInvoiceNotCancellable
+	}
+	i.Status = StatusCancelled
+	i.UpdatedAt = time.Now()
+	return nil
+}
+
+// Expire expira uma fatura que ficou pendente alem do TTL.
+func (i *Invoice) Expire() error {
+	if i.Status != StatusPending {
+		return ErrInvalidStatus
+	}
+	i.Status = StatusExpired
 	i.UpdatedAt = time.Now()
 	return nil
 }
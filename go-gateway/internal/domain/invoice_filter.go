@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+const (
+	// DefaultInvoicePageSize e o tamanho de pagina usado quando limit nao e informado.
+	DefaultInvoicePageSize = 20
+	// MaxInvoicePageSize limita quantas faturas uma pagina pode retornar.
+	MaxInvoicePageSize = 100
+)
+
+// InvoiceCursor aponta para a ultima fatura de uma pagina na ordenacao (created_at, id) decrescente.
+type InvoiceCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// InvoiceFilter define filtros e paginacao por keyset para listagem de faturas.
+// Campos vazios ou nil nao filtram.
+type InvoiceFilter struct {
+	// Mode e definido pela API key, nao pela query.
+	Mode           Mode
+	Status         Status
+	Currency       string
+	PaymentType    string
+	CardBrand      string
+	CustomerID     string
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
+	MinAmountCents *int64
+	MaxAmountCents *int64
+	Cursor         *InvoiceCursor
+	Limit          int
+}
+
+// InvoicePage representa uma pagina de faturas e o cursor da proxima pagina, se houver.
+type InvoicePage struct {
+	Invoices   []*Invoice
+	NextCursor *InvoiceCursor
+}
@@ -13,11 +13,123 @@ func TestInvoiceUpdateStatusIdempotent(t *testing.T) {
 }
 
 func TestInvoiceProcessKeepsPendingForHighValue(t *testing.T) {
-	invoice := &Invoice{AmountCents: pendingThresholdCents + 1, Status: StatusPending}
-	if err := invoice.Process(); err != nil {
+	invoice := &Invoice{Currency: "BRL", AmountCents: pendingThresholdMinor("BRL") + 1, Status: StatusPending}
+	if err := invoice.Process(NewRandomDecider(1), ""); err != nil {
 		t.Fatalf("expected nil error, got %v", err)
 	}
 	if invoice.Status != StatusPending {
 		t.Fatalf("expected status pending, got %v", invoice.Status)
 	}
 }
+
+func TestInvoiceRefundPartialThenFull(t *testing.T) {
+	invoice := &Invoice{AmountCents: 1000, CapturedCents: 1000, Status: StatusApproved}
+
+	if err := invoice.Refund(400); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if invoice.Status != StatusPartiallyRefunded || invoice.RefundedCents != 400 {
+		t.Fatalf("expected partially_refunded with 400, got %v with %d", invoice.Status, invoice.RefundedCents)
+	}
+
+	if err := invoice.Refund(0); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if invoice.Status != StatusRefunded || invoice.RefundedCents != 1000 {
+		t.Fatalf("expected refunded with 1000, got %v with %d", invoice.Status, invoice.RefundedCents)
+	}
+}
+
+func TestInvoiceRefundReturnsFeeProRata(t *testing.T) {
+	invoice := &Invoice{AmountCents: 1000, CapturedCents: 1000, FeeCents: 99, NetCents: 901, Status: StatusApproved}
+
+	if err := invoice.Refund(300); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	// 99 * 300 / 1000 = 29,7: a parte fracionaria fica retida ate o estorno do restante.
+	if invoice.FeeCents != 70 || invoice.NetCents != 630 {
+		t.Fatalf("expected fee 70 and net 630, got %d and %d", invoice.FeeCents, invoice.NetCents)
+	}
+
+	if err := invoice.Refund(0); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if invoice.FeeCents != 0 || invoice.NetCents != 0 {
+		t.Fatalf("expected the whole fee returned, got fee %d and net %d", invoice.FeeCents, invoice.NetCents)
+	}
+}
+
+func TestInvoiceRefundRejectsExcessAndNonApproved(t *testing.T) {
+	invoice := &Invoice{AmountCents: 1000, CapturedCents: 1000, RefundedCents: 900, Status: StatusPartiallyRefunded}
+	if err := invoice.Refund(101); err != ErrRefundExceedsAmount {
+		t.Fatalf("expected ErrRefundExceedsAmount, got %v", err)
+	}
+
+	pending := &Invoice{AmountCents: 1000, Status: StatusPending}
+	if err := pending.Refund(100); err != ErrInvoiceNotRefundable {
+		t.Fatalf("expected ErrInvoiceNotRefundable, got %v", err)
+	}
+}
+
+func TestInvoiceCancelOnlyFromPending(t *testing.T) {
+	invoice := &Invoice{Status: StatusPending}
+	if err := invoice.Cancel(); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if invoice.Status != StatusCancelled || !invoice.Status.Closed() {
+		t.Fatalf("expected closed cancelled status, got %v", invoice.Status)
+	}
+
+	approved := &Invoice{Status: StatusApproved}
+	if err := approved.Cancel(); err != ErrInvoiceNotCancellable {
+		t.Fatalf("expected ErrInvoiceNotCancellable, got %v", err)
+	}
+}
+
+func TestInvoiceManualCaptureAuthorizesThenCapturesPartially(t *testing.T) {
+	invoice := &Invoice{AmountCents: 1000, CaptureMethod: CaptureManual, Status: StatusPending}
+	if err := invoice.UpdateStatus(StatusApproved); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if invoice.Status != StatusAuthorized || invoice.CapturedCents != 0 {
+		t.Fatalf("expected authorized without capture, got %v with %d", invoice.Status, invoice.CapturedCents)
+	}
+	if invoice.RefundableCents() != 0 {
+		t.Fatalf("expected nothing refundable before capture, got %d", invoice.RefundableCents())
+	}
+
+	if err := invoice.Capture(1001); err != ErrCaptureExceedsAmount {
+		t.Fatalf("expected ErrCaptureExceedsAmount, got %v", err)
+	}
+	if err := invoice.Capture(600); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if invoice.Status != StatusApproved || invoice.CapturedCents != 600 {
+		t.Fatalf("expected approved with 600 captured, got %v with %d", invoice.Status, invoice.CapturedCents)
+	}
+
+	if err := invoice.Refund(0); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if invoice.Status != StatusRefunded || invoice.RefundedCents != 600 {
+		t.Fatalf("expected refunded with 600, got %v with %d", invoice.Status, invoice.RefundedCents)
+	}
+}
+
+func TestInvoiceVoidOnlyFromAuthorized(t *testing.T) {
+	approved := &Invoice{AmountCents: 1000, CapturedCents: 1000, Status: StatusApproved}
+	if err := approved.Void(); err != ErrInvoiceNotVoidable {
+		t.Fatalf("expected ErrInvoiceNotVoidable, got %v", err)
+	}
+	if err := approved.Capture(0); err != ErrInvoiceNotCapturable {
+		t.Fatalf("expected ErrInvoiceNotCapturable, got %v", err)
+	}
+
+	authorized := &Invoice{AmountCents: 1000, CaptureMethod: CaptureManual, Status: StatusAuthorized}
+	if err := authorized.Void(); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if authorized.Status != StatusVoided {
+		t.Fatalf("expected status voided, got %v", authorized.Status)
+	}
+}
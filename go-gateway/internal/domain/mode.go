@@ -0,0 +1,29 @@
+package domain
+
+import "strings"
+
+// Mode separa dados reais (live) de dados de teste (test). E definido pela API key usada.
+type Mode string
+
+const (
+	ModeLive Mode = "live"
+	ModeTest Mode = "test"
+)
+
+const (
+	APIKeyPrefixLive = "sk_live_"
+	APIKeyPrefixTest = "sk_test_"
+)
+
+// ModeFromAPIKey identifica o modo pelo prefixo da chave. Chaves sem prefixo
+// (emitidas antes dos modos) sao live.
+func ModeFromAPIKey(apiKey string) Mode {
+	if strings.HasPrefix(apiKey, APIKeyPrefixTest) {
+		return ModeTest
+	}
+	return ModeLive
+}
+
+func (m Mode) Valid() bool {
+	return m == ModeLive || m == ModeTest
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewAccountIssuesLiveAndTestKeys(t *testing.T) {
+	account, err := NewAccount("Demo", "demo@local")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.HasPrefix(account.APIKey, APIKeyPrefixLive) || ModeFromAPIKey(account.APIKey) != ModeLive {
+		t.Fatalf("expected live key, got %q", account.APIKey)
+	}
+	if !strings.HasPrefix(account.TestAPIKey, APIKeyPrefixTest) || ModeFromAPIKey(account.TestAPIKey) != ModeTest {
+		t.Fatalf("expected test key, got %q", account.TestAPIKey)
+	}
+}
+
+func TestModeFromLegacyAPIKeyIsLive(t *testing.T) {
+	if mode := ModeFromAPIKey("0123456789abcdef0123456789abcdef"); mode != ModeLive {
+		t.Fatalf("expected live mode for legacy key, got %v", mode)
+	}
+}
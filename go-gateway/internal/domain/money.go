@@ -1,13 +1,86 @@
 package domain
 
-import "math"
+import (
+	"math"
+	"strings"
+)
 
-const centsFactor = 100
+// DefaultCurrency e usada quando a conta ou a fatura nao informam moeda.
+const DefaultCurrency = "BRL"
 
-func AmountToCents(amount float64) int64 {
-	return int64(math.Round(amount * centsFactor))
+// currencyMinorUnits mapeia o codigo ISO 4217 para a quantidade de casas decimais da moeda.
+var currencyMinorUnits = map[string]int{
+	"ARS": 2,
+	"AUD": 2,
+	"BHD": 3,
+	"BRL": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"CLP": 0,
+	"CNY": 2,
+	"COP": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"HKD": 2,
+	"IQD": 3,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"LYD": 3,
+	"MXN": 2,
+	"OMR": 3,
+	"PEN": 2,
+	"PYG": 0,
+	"TND": 3,
+	"USD": 2,
+	"UYU": 2,
+	"VND": 0,
 }
 
-func CentsToAmount(cents int64) float64 {
-	return float64(cents) / float64(centsFactor)
+// NormalizeCurrency valida o codigo ISO 4217 e retorna em maiusculas.
+// Codigo vazio resulta em DefaultCurrency.
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return DefaultCurrency, nil
+	}
+	if _, ok := currencyMinorUnits[code]; !ok {
+		return "", ErrUnsupportedCurrency
+	}
+	return code, nil
+}
+
+// MinorUnits retorna as casas decimais da moeda; moedas desconhecidas usam as da DefaultCurrency.
+func MinorUnits(currency string) int {
+	if units, ok := currencyMinorUnits[strings.ToUpper(currency)]; ok {
+		return units
+	}
+	return currencyMinorUnits[DefaultCurrency]
+}
+
+// MinorFactor retorna 10^MinorUnits(currency).
+func MinorFactor(currency string) int64 {
+	factor := int64(1)
+	for i := 0; i < MinorUnits(currency); i++ {
+		factor *= 10
+	}
+	return factor
+}
+
+// AmountToMinor converte um valor decimal para unidades minimas da moeda.
+func AmountToMinor(amount float64, currency string) int64 {
+	return int64(math.Round(amount * float64(MinorFactor(currency))))
+}
+
+// MinorToAmount converte unidades minimas da moeda para valor decimal.
+func MinorToAmount(minor int64, currency string) float64 {
+	return float64(minor) / float64(MinorFactor(currency))
+}
+
+// HasValidPrecision informa se o valor nao tem mais casas decimais do que a moeda permite.
+func HasValidPrecision(amount float64, currency string) bool {
+	scaled := amount * float64(MinorFactor(currency))
+	return math.Abs(scaled-math.Round(scaled)) < 1e-6
 }
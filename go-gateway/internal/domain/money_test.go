@@ -0,0 +1,47 @@
+package domain
+
+import "testing"
+
+func TestAmountToMinorUsesCurrencyMinorUnits(t *testing.T) {
+	cases := []struct {
+		currency string
+		amount   float64
+		want     int64
+	}{
+		{"BRL", 129.9, 12990},
+		{"JPY", 1500, 1500},
+		{"BHD", 1.234, 1234},
+	}
+	for _, tc := range cases {
+		if got := AmountToMinor(tc.amount, tc.currency); got != tc.want {
+			t.Fatalf("%s: expected %d, got %d", tc.currency, tc.want, got)
+		}
+		if got := MinorToAmount(tc.want, tc.currency); got != tc.amount {
+			t.Fatalf("%s: expected %v, got %v", tc.currency, tc.amount, got)
+		}
+	}
+}
+
+func TestHasValidPrecision(t *testing.T) {
+	if HasValidPrecision(10.5, "JPY") {
+		t.Fatalf("expected JPY to reject decimals")
+	}
+	if !HasValidPrecision(1.234, "BHD") {
+		t.Fatalf("expected BHD to accept 3 decimals")
+	}
+	if HasValidPrecision(1.234, "BRL") {
+		t.Fatalf("expected BRL to reject 3 decimals")
+	}
+}
+
+func TestNormalizeCurrency(t *testing.T) {
+	if got, err := NormalizeCurrency(""); err != nil || got != DefaultCurrency {
+		t.Fatalf("expected default currency, got %q (%v)", got, err)
+	}
+	if got, err := NormalizeCurrency("usd"); err != nil || got != "USD" {
+		t.Fatalf("expected USD, got %q (%v)", got, err)
+	}
+	if _, err := NormalizeCurrency("XYZ"); err != ErrUnsupportedCurrency {
+		t.Fatalf("expected ErrUnsupportedCurrency, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PayoutStatus e o estado de um saque. Saques nascem pending, seguem para in_transit quando o
+// processador de liquidacao envia a transferencia e terminam em paid ou failed.
+type PayoutStatus string
+
+const (
+	PayoutPending   PayoutStatus = "pending"
+	PayoutInTransit PayoutStatus = "in_transit"
+	PayoutPaid      PayoutStatus = "paid"
+	PayoutFailed    PayoutStatus = "failed"
+)
+
+func (s PayoutStatus) Valid() bool {
+	switch s {
+	case PayoutPending, PayoutInTransit, PayoutPaid, PayoutFailed:
+		return true
+	}
+	return false
+}
+
+// Tipos de conta bancaria de destino.
+const (
+	BankAccountChecking = "checking"
+	BankAccountSavings  = "savings"
+)
+
+const (
+	// DefaultPayoutPageSize e o tamanho de pagina usado quando limit nao e informado.
+	DefaultPayoutPageSize = 20
+	// MaxPayoutPageSize limita quantos saques uma pagina pode retornar.
+	MaxPayoutPageSize = 100
+)
+
+var (
+	bankCodePattern      = regexp.MustCompile(`^\d{3}$`)
+	bankBranchPattern    = regexp.MustCompile(`^\d{1,5}$`)
+	bankAccountNoPattern = regexp.MustCompile(`^\d{1,12}-?[\dX]?$`)
+)
+
+// BankAccount e uma conta bancaria do merchant para receber saques. Contas removidas ficam com
+// deleted_at para que os saques continuem apontando para elas.
+type BankAccount struct {
+	ID        string
+	AccountID string
+	Mode      Mode
+	// HolderDocument e guardado apenas com digitos (CPF ou CNPJ).
+	HolderName         string
+	HolderDocument     string
+	HolderDocumentType string
+	// BankCode e o codigo COMPE de 3 digitos; AccountNumber pode terminar com o digito verificador.
+	BankCode      string
+	Branch        string
+	AccountNumber string
+	AccountType   string
+	CreatedAt     time.Time
+}
+
+// NewBankAccount cria a conta bancaria normalizando o documento do titular.
+// Retorna ErrInvalidDocument ou ErrInvalidBankAccount.
+func NewBankAccount(accountID string, mode Mode, holderName, holderDocument, bankCode, branch, accountNumber, accountType string, now time.Time) (*BankAccount, error) {
+	holderName = strings.TrimSpace(holderName)
+	if holderName == "" {
+		return nil, ErrInvalidBankAccount
+	}
+	document, documentType, err := NormalizeDocument(holderDocument)
+	if err != nil {
+		return nil, err
+	}
+	if document == "" {
+		return nil, ErrInvalidDocument
+	}
+
+	accountNumber = strings.ToUpper(strings.TrimSpace(accountNumber))
+	if !bankCodePattern.MatchString(bankCode) || !bankBranchPattern.MatchString(branch) || !bankAccountNoPattern.MatchString(accountNumber) {
+		return nil, ErrInvalidBankAccount
+	}
+	if accountType != BankAccountChecking && accountType != BankAccountSavings {
+		return nil, ErrInvalidBankAccount
+	}
+
+	return &BankAccount{
+		ID:                 uuid.New().String(),
+		AccountID:          accountID,
+		Mode:               mode,
+		HolderName:         holderName,
+		HolderDocument:     document,
+		HolderDocumentType: documentType,
+		BankCode:           bankCode,
+		Branch:             branch,
+		AccountNumber:      accountNumber,
+		AccountType:        accountType,
+		CreatedAt:          now,
+	}, nil
+}
+
+// Payout e um saque do saldo do merchant para uma conta bancaria. O valor fica reservado
+// (fora do saldo) desde a criacao; um saque failed devolve a reserva ao saldo.
+type Payout struct {
+	ID            string
+	AccountID     string
+	Mode          Mode
+	BankAccountID string
+	AmountCents   int64
+	Currency      string
+	Status        PayoutStatus
+	Description   string
+	FailureReason string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	PaidAt        *time.Time
+	FailedAt      *time.Time
+}
+
+// NewPayout cria um saque pending. Retorna ErrInvalidAmount se o valor nao for positivo.
+func NewPayout(accountID string, mode Mode, bankAccountID string, amountCents int64, currency, description string, now time.Time) (*Payout, error) {
+	if amountCents <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	return &Payout{
+		ID:            uuid.New().String(),
+		AccountID:     accountID,
+		Mode:          mode,
+		BankAccountID: bankAccountID,
+		AmountCents:   amountCents,
+		Currency:      currency,
+		Status:        PayoutPending,
+		Description:   strings.TrimSpace(description),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+// Transition move o saque para status. Sao aceitos pending -> in_transit ou failed e
+// in_transit -> paid ou failed; um saque so e pago depois de enviado ao banco. paid e failed
+// sao finais. Retorna ErrInvalidPayoutTransition.
+func (p *Payout) Transition(status PayoutStatus, failureReason string, now time.Time) error {
+	switch {
+	case p.Status == PayoutPending && (status == PayoutInTransit || status == PayoutFailed):
+	case p.Status == PayoutInTransit && (status == PayoutPaid || status == PayoutFailed):
+	default:
+		return ErrInvalidPayoutTransition
+	}
+
+	p.Status = status
+	p.UpdatedAt = now
+	switch status {
+	case PayoutPaid:
+		p.PaidAt = &now
+	case PayoutFailed:
+		p.FailedAt = &now
+		p.FailureReason = strings.TrimSpace(failureReason)
+	}
+	return nil
+}
+
+// PayoutFilter define filtros e paginacao por keyset da listagem de saques, com o mesmo
+// cursor (created_at, id) das faturas. Campos vazios nao filtram.
+type PayoutFilter struct {
+	// Mode e definido pela API key, nao pela query.
+	Mode   Mode
+	Status PayoutStatus
+	Cursor *InvoiceCursor
+	Limit  int
+}
+
+// PayoutPage representa uma pagina de saques e o cursor da proxima pagina, se houver.
+type PayoutPage struct {
+	Payouts    []*Payout
+	NextCursor *InvoiceCursor
+}
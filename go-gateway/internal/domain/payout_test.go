@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPayoutTransitions(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	payout, err := NewPayout("acc", ModeLive, "bank", 1000, "BRL", "", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := payout.Transition(PayoutInTransit, "", now); err != nil {
+		t.Fatalf("expected pending -> in_transit, got %v", err)
+	}
+	if err := payout.Transition(PayoutPending, "", now); err != ErrInvalidPayoutTransition {
+		t.Fatalf("expected in_transit -> pending to be rejected, got %v", err)
+	}
+	if err := payout.Transition(PayoutFailed, " account closed ", now); err != nil {
+		t.Fatalf("expected in_transit -> failed, got %v", err)
+	}
+	if payout.FailedAt == nil || payout.FailureReason != "account closed" {
+		t.Fatalf("expected failed_at and trimmed reason, got %v %q", payout.FailedAt, payout.FailureReason)
+	}
+	if err := payout.Transition(PayoutPaid, "", now); err != ErrInvalidPayoutTransition {
+		t.Fatalf("expected failed to be final, got %v", err)
+	}
+
+	if _, err := NewPayout("acc", ModeLive, "bank", 0, "BRL", "", now); err != ErrInvalidAmount {
+		t.Fatalf("expected ErrInvalidAmount, got %v", err)
+	}
+}
+
+func TestPayoutTransitionMatrix(t *testing.T) {
+	statuses := []PayoutStatus{PayoutPending, PayoutInTransit, PayoutPaid, PayoutFailed}
+	allowed := map[PayoutStatus][]PayoutStatus{
+		PayoutPending:   {PayoutInTransit, PayoutFailed},
+		PayoutInTransit: {PayoutPaid, PayoutFailed},
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			valid := false
+			for _, status := range allowed[from] {
+				valid = valid || status == to
+			}
+			t.Run(string(from)+"->"+string(to), func(t *testing.T) {
+				payout := &Payout{Status: from}
+				err := payout.Transition(to, "reason", time.Now())
+				if valid && err != nil {
+					t.Fatalf("expected transition to be accepted, got %v", err)
+				}
+				if !valid && err != ErrInvalidPayoutTransition {
+					t.Fatalf("expected ErrInvalidPayoutTransition, got %v", err)
+				}
+				if !valid && payout.Status != from {
+					t.Fatalf("expected status to stay %s, got %s", from, payout.Status)
+				}
+			})
+		}
+	}
+}
+
+func TestNewBankAccountValidatesFields(t *testing.T) {
+	now := time.Now()
+
+	bankAccount, err := NewBankAccount("acc", ModeLive, "Loja", "529.982.247-25", "341", "0001", "12345-x", BankAccountChecking, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bankAccount.HolderDocument != "52998224725" || bankAccount.HolderDocumentType != DocumentTypeCPF || bankAccount.AccountNumber != "12345-X" {
+		t.Fatalf("expected normalized document and account number, got %+v", bankAccount)
+	}
+
+	if _, err := NewBankAccount("acc", ModeLive, "Loja", "", "341", "0001", "12345", BankAccountChecking, now); err != ErrInvalidDocument {
+		t.Fatalf("expected holder document to be required, got %v", err)
+	}
+	if _, err := NewBankAccount("acc", ModeLive, "Loja", "52998224725", "34", "0001", "12345", BankAccountChecking, now); err != ErrInvalidBankAccount {
+		t.Fatalf("expected invalid bank code, got %v", err)
+	}
+	if _, err := NewBankAccount("acc", ModeLive, "Loja", "52998224725", "341", "0001", "12345", "salary", now); err != ErrInvalidBankAccount {
+		t.Fatalf("expected invalid account type, got %v", err)
+	}
+}
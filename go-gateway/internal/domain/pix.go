@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PixCurrency e a unica moeda aceita em PIX.
+const PixCurrency = "BRL"
+
+// pixGUI identifica o arranjo PIX no campo 26 do BR Code.
+const pixGUI = "br.gov.bcb.pix"
+
+// Tamanhos maximos dos campos do BR Code (manual do BR Code do Banco Central).
+const (
+	maxPixMerchantName = 25
+	maxPixMerchantCity = 15
+	pixTxIDLength      = 25
+)
+
+// PixCharge e a cobranca PIX de uma fatura: payload copia e cola, txid e expiracao.
+type PixCharge struct {
+	InvoiceID string
+	TxID      string
+	// Payload e o BR Code (EMV) usado no QR Code e no copia e cola.
+	Payload         string
+	ExpiresAt       time.Time
+	EndToEndID      string
+	PaidAt          *time.Time
+	PaidAmountCents int64
+	CreatedAt       time.Time
+}
+
+// Paid informa se a cobranca ja foi paga.
+func (c *PixCharge) Paid() bool {
+	return c.PaidAt != nil
+}
+
+// PixPayment e uma confirmacao de pagamento recebida do PSP.
+type PixPayment struct {
+	TxID        string
+	EndToEndID  string
+	AmountCents int64
+	PaidAt      time.Time
+}
+
+// PixReceiver e o recebedor das cobrancas: chave PIX, nome e cidade exibidos ao pagador.
+type PixReceiver struct {
+	Key          string
+	MerchantName string
+	MerchantCity string
+}
+
+// NewPixReceiver valida a chave e normaliza nome e cidade para os limites do BR Code.
+func NewPixReceiver(key, merchantName, merchantCity string) (PixReceiver, error) {
+	key = strings.TrimSpace(key)
+	if key == "" || len(key) > 77 {
+		return PixReceiver{}, fmt.Errorf("pix key must have 1 to 77 characters")
+	}
+	name := emvText(merchantName, maxPixMerchantName)
+	city := emvText(merchantCity, maxPixMerchantCity)
+	if name == "" || city == "" {
+		return PixReceiver{}, fmt.Errorf("pix merchant name and city are required")
+	}
+	return PixReceiver{Key: key, MerchantName: name, MerchantCity: city}, nil
+}
+
+// Enabled informa se o recebedor foi configurado.
+func (p PixReceiver) Enabled() bool {
+	return p.Key != ""
+}
+
+// NewCharge gera a cobranca da fatura com txid derivado do ID da fatura.
+func (p PixReceiver) NewCharge(invoiceID string, amountCents int64, expiresAt time.Time) (*PixCharge, error) {
+	if amountCents <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	txID := PixTxID(invoiceID)
+	return &PixCharge{
+		InvoiceID: invoiceID,
+		TxID:      txID,
+		Payload:   p.BRCode(txID, amountCents),
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// PixTxID deriva o txid (25 caracteres alfanumericos) do ID da fatura.
+func PixTxID(invoiceID string) string {
+	txID := strings.ReplaceAll(invoiceID, "-", "")
+	if len(txID) > pixTxIDLength {
+		txID = txID[:pixTxIDLength]
+	}
+	return txID
+}
+
+// BRCode monta o payload EMV copia e cola de uso unico com valor e txid, terminado pelo CRC16.
+func (p PixReceiver) BRCode(txID string, amountCents int64) string {
+	account := emvField("00", pixGUI) + emvField("01", p.Key)
+	payload := emvField("00", "01") +
+		emvField("01", "12") +
+		emvField("26", account) +
+		emvField("52", "0000") +
+		emvField("53", "986") +
+		emvField("54", fmt.Sprintf("%d.%02d", amountCents/100, amountCents%100)) +
+		emvField("58", "BR") +
+		emvField("59", p.MerchantName) +
+		emvField("60", p.MerchantCity) +
+		emvField("62", emvField("05", txID)) +
+		"6304"
+	return payload + fmt.Sprintf("%04X", crc16CCITT([]byte(payload)))
+}
+
+// ParsePixAmount converte o valor textual do PIX ("150.50") em centavos.
+func ParsePixAmount(value string) (int64, error) {
+	whole, fraction, found := strings.Cut(strings.TrimSpace(value), ".")
+	if whole == "" || !isDigits(whole) || (found && (len(fraction) != 2 || !isDigits(fraction))) {
+		return 0, ErrInvalidAmount
+	}
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	cents := int64(0)
+	if found {
+		cents, _ = strconv.ParseInt(fraction, 10, 64)
+	}
+	return units*100 + cents, nil
+}
+
+// emvField codifica um campo EMV: ID, tamanho com 2 digitos e valor.
+func emvField(id, value string) string {
+	return id + fmt.Sprintf("%02d", len(value)) + value
+}
+
+// pixAccents troca letras acentuadas pela letra sem acento, ja que o BR Code aceita apenas ASCII.
+var pixAccents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "é", "e", "ê", "e", "í", "i", "ó", "o", "ô", "o", "õ", "o", "ú", "u", "ü", "u", "ç", "c",
+	"Á", "A", "À", "A", "Â", "A", "Ã", "A", "É", "E", "Ê", "E", "Í", "I", "Ó", "O", "Ô", "O", "Õ", "O", "Ú", "U", "Ü", "U", "Ç", "C",
+)
+
+// emvText remove acentos e caracteres fora do ASCII imprimivel e corta no tamanho maximo do campo.
+func emvText(value string, max int) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e {
+			return -1
+		}
+		return r
+	}, pixAccents.Replace(strings.TrimSpace(value)))
+	if len(cleaned) > max {
+		cleaned = strings.TrimSpace(cleaned[:max])
+	}
+	return cleaned
+}
+
+// crc16CCITT calcula o CRC16-CCITT (polinomio 0x1021, valor inicial 0xFFFF) exigido no campo 63.
+func crc16CCITT(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCRC16MatchesBRCodeExample(t *testing.T) {
+	// Exemplo de BR Code estatico do manual do Banco Central.
+	example := "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***6304"
+	if got := fmt.Sprintf("%04X", crc16CCITT([]byte(example))); got != "1D3D" {
+		t.Fatalf("expected CRC 1D3D, got %s", got)
+	}
+}
+
+func TestPixReceiverBRCode(t *testing.T) {
+	receiver, err := NewPixReceiver("pix@example.com", "Loja São Paulo Comércio de Roupas", "Sao Paulo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if receiver.MerchantName != "Loja Sao Paulo Comercio d" {
+		t.Fatalf("unexpected merchant name %q", receiver.MerchantName)
+	}
+
+	charge, err := receiver.NewCharge("123e4567-e89b-12d3-a456-426614174000", 15050, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if charge.TxID != "123e4567e89b12d3a45642661" {
+		t.Fatalf("unexpected txid %s", charge.TxID)
+	}
+
+	payload := charge.Payload
+	for _, field := range []string{"000201", "010212", "0014br.gov.bcb.pix", "0115pix@example.com", "5406150.50", "5303986", "0525" + charge.TxID} {
+		if !strings.Contains(payload, field) {
+			t.Fatalf("payload %s missing %s", payload, field)
+		}
+	}
+	body, crc := payload[:len(payload)-4], payload[len(payload)-4:]
+	if !strings.HasSuffix(body, "6304") || fmt.Sprintf("%04X", crc16CCITT([]byte(body))) != crc {
+		t.Fatalf("invalid CRC in %s", payload)
+	}
+}
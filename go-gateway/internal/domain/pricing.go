@@ -0,0 +1,74 @@
+package domain
+
+import "time"
+
+const (
+	// MaxInstallments limita o parcelamento de faturas de cartao.
+	MaxInstallments = 12
+	// bpsDenominator converte pontos-base (1% = 100) em fracao.
+	bpsDenominator = 10000
+)
+
+// FeeRate e a tarifa de um tipo de pagamento: percentual em pontos-base mais valor fixo em
+// unidades minimas da moeda do plano. InstallmentSurchargesBps acrescenta pontos-base ao
+// percentual conforme o numero de parcelas (apenas credit_card).
+type FeeRate struct {
+	PercentBps               int64         `json:"percent_bps"`
+	FixedCents               int64         `json:"fixed_cents"`
+	InstallmentSurchargesBps map[int]int64 `json:"installment_surcharges_bps,omitempty"`
+}
+
+// PricingPlan define as tarifas cobradas do merchant por conta, modo e moeda. Tipos de
+// pagamento sem tarifa no plano (ou contas sem plano) nao pagam tarifa.
+type PricingPlan struct {
+	AccountID string
+	Mode      Mode
+	Currency  string
+	Rates     map[string]FeeRate
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// FeeBreakdown detalha a tarifa de uma fatura, em unidades minimas da moeda. E gravado na
+// fatura na aprovacao para que mudancas posteriores no plano nao alterem faturas antigas.
+type FeeBreakdown struct {
+	PaymentType               string `json:"payment_type"`
+	Installments              int    `json:"installments"`
+	PercentBps                int64  `json:"percent_bps"`
+	PercentCents              int64  `json:"percent_cents"`
+	FixedCents                int64  `json:"fixed_cents"`
+	InstallmentSurchargeBps   int64  `json:"installment_surcharge_bps"`
+	InstallmentSurchargeCents int64  `json:"installment_surcharge_cents"`
+	FeeCents                  int64  `json:"fee_cents"`
+}
+
+// Fee calcula a tarifa sobre amountCents. Percentuais sao arredondados para o centavo mais
+// proximo e a tarifa total nunca passa do valor. Um plano nil nao cobra tarifa.
+func (p *PricingPlan) Fee(paymentType string, installments int, amountCents int64) FeeBreakdown {
+	if installments < 1 {
+		installments = 1
+	}
+	breakdown := FeeBreakdown{PaymentType: paymentType, Installments: installments}
+	if p == nil {
+		return breakdown
+	}
+	rate, ok := p.Rates[paymentType]
+	if !ok {
+		return breakdown
+	}
+
+	breakdown.PercentBps = rate.PercentBps
+	breakdown.PercentCents = applyBps(amountCents, rate.PercentBps)
+	breakdown.FixedCents = rate.FixedCents
+	if installments > 1 {
+		breakdown.InstallmentSurchargeBps = rate.InstallmentSurchargesBps[installments]
+		breakdown.InstallmentSurchargeCents = applyBps(amountCents, breakdown.InstallmentSurchargeBps)
+	}
+	breakdown.FeeCents = min(breakdown.PercentCents+breakdown.FixedCents+breakdown.InstallmentSurchargeCents, amountCents)
+	return breakdown
+}
+
+// applyBps aplica pontos-base a um valor, arredondando metade para cima.
+func applyBps(amountCents, bps int64) int64 {
+	return (amountCents*bps + bpsDenominator/2) / bpsDenominator
+}
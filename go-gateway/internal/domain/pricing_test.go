@@ -0,0 +1,25 @@
+package domain
+
+import "testing"
+
+func TestPricingPlanFee(t *testing.T) {
+	plan := &PricingPlan{Rates: map[string]FeeRate{
+		PaymentTypeCreditCard: {PercentBps: 299, FixedCents: 39, InstallmentSurchargesBps: map[int]int64{3: 150}},
+	}}
+
+	fee := plan.Fee(PaymentTypeCreditCard, 3, 10050)
+	// 2.99% de 100.50 = 3.00495 -> 300; 1.5% = 1.5075 -> 151; mais 39 fixos.
+	if fee.PercentCents != 300 || fee.InstallmentSurchargeCents != 151 || fee.FeeCents != 490 {
+		t.Fatalf("expected 300 + 39 + 151 = 490, got %+v", fee)
+	}
+
+	if fee := plan.Fee(PaymentTypeCreditCard, 1, 20); fee.FeeCents != 20 {
+		t.Fatalf("expected fee capped at the amount, got %d", fee.FeeCents)
+	}
+	if fee := plan.Fee(PaymentTypePix, 1, 10000); fee.FeeCents != 0 {
+		t.Fatalf("expected no fee for payment type without rate, got %d", fee.FeeCents)
+	}
+	if fee := (*PricingPlan)(nil).Fee(PaymentTypeCreditCard, 1, 10000); fee.FeeCents != 0 || fee.Installments != 1 {
+		t.Fatalf("expected nil plan to charge no fee, got %+v", fee)
+	}
+}
@@ -0,0 +1,41 @@
+package domain
+
+// Principal identifica quem autenticou a requisicao: a conta, a API key usada,
+// seus escopos e o modo (live/test). E resolvido uma unica vez pelo middleware.
+type Principal struct {
+	AccountID string
+	// KeyID e o id da API key (api_keys.id), nao o key id do segredo HMAC.
+	KeyID  string
+	Scopes []string
+	Mode   Mode
+}
+
+// NewPrincipal monta o principal a partir da API key autenticada
+func NewPrincipal(key *APIKey) *Principal {
+	return &Principal{
+		AccountID: key.AccountID,
+		KeyID:     key.ID,
+		Scopes:    key.Scopes,
+		Mode:      key.Mode,
+	}
+}
+
+// MissingScope retorna o primeiro escopo que a API key do principal nao concede, ou vazio.
+func (p *Principal) MissingScope(scopes []string) string {
+	for _, scope := range scopes {
+		if !p.HasScope(scope) {
+			return scope
+		}
+	}
+	return ""
+}
+
+// HasScope indica se a API key do principal concede o escopo.
+func (p *Principal) HasScope(scope string) bool {
+	for _, granted := range p.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type AccountRepository interface {
 	Save(account *Account) error
@@ -9,16 +12,141 @@ type AccountRepository interface {
 	FindByID(id string) (*Account, error)
 	UpdateBalance(account *Account) error
 	AddBalance(accountID string, amountCents int64) error
+	ListBalances(accountID string, mode Mode) ([]Balance, error)
+}
+
+type APIKeyRepository interface {
+	Save(key *APIKey) error
+	// FindByKey busca a chave pelo segredo, inclusive revogadas ou expiradas.
+	FindByKey(secret string) (*APIKey, error)
+	// RehashIfStale migra o hash da chave para o key id ativo.
+	RehashIfStale(key *APIKey, secret string) error
+	ListByAccountID(accountID string, mode Mode) ([]*APIKey, error)
+	Revoke(accountID string, mode Mode, id string, at time.Time) (*APIKey, error)
+	// GrantScopes acrescenta escopos a uma chave nao revogada da conta; retorna ErrAPIKeyNotFound.
+	GrantScopes(accountID, id string, scopes []string) (*APIKey, error)
+	TouchLastUsed(id string, at time.Time) error
+}
+
+type CardTokenRepository interface {
+	// Save cifra o PAN do token com a chave ativa do cofre antes de gravar.
+	Save(token *CardToken) error
+	FindByID(id string) (*CardToken, error)
+	// Reveal decifra o PAN em token.Number.
+	Reveal(token *CardToken) error
+	// RekeyIfStale recifra o PAN revelado com a chave ativa do cofre.
+	RekeyIfStale(token *CardToken) error
+	// AttachToCustomer salva o token no cliente; retorna ErrCardTokenAttached se ele ja for de outro cliente.
+	AttachToCustomer(tokenID, customerID string) error
+	// DetachFromCustomer retorna ErrCardTokenNotFound se o token nao estiver salvo no cliente.
+	DetachFromCustomer(tokenID, customerID string) error
+	ListByCustomerID(customerID string) ([]*CardToken, error)
+}
+
+type CustomerRepository interface {
+	// Save retorna ErrCustomerReferenceConflict se external_reference ja estiver em uso na conta e no modo.
+	Save(customer *Customer) error
+	// FindByID ignora clientes removidos e retorna ErrCustomerNotFound.
+	FindByID(id string) (*Customer, error)
+	Update(customer *Customer) error
+	// Delete remove o cliente (soft delete) e desvincula os cartoes salvos nele.
+	Delete(id string, at time.Time) error
+	ListByAccountID(accountID string, filter CustomerFilter) (*CustomerPage, error)
+}
+
+type SubscriptionRepository interface {
+	SavePlan(plan *Plan) error
+	// FindPlanByID retorna ErrPlanNotFound; planos arquivados tambem sao retornados.
+	FindPlanByID(id string) (*Plan, error)
+	ListPlans(accountID string, mode Mode) ([]*Plan, error)
+	ArchivePlan(id string, at time.Time) error
+	// Save grava a assinatura e o evento created na mesma transacao.
+	Save(subscription *Subscription, event *SubscriptionEvent) error
+	FindByID(id string) (*Subscription, error)
+	ListByAccountID(accountID string, filter SubscriptionFilter) (*SubscriptionPage, error)
+	// Update grava a assinatura e os eventos se Version nao mudou desde a leitura; caso
+	// contrario retorna ErrSubscriptionConflict.
+	Update(subscription *Subscription, events []*SubscriptionEvent) error
+	// ClaimDue reserva ate limit assinaturas com cobranca vencida, adiando next_billing_at
+	// para now+lease para que outra instancia nao as cobre ao mesmo tempo, e grava o ID
+	// reservado da fatura (Subscription.ReserveInvoice).
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]*Subscription, error)
+	// ListSettledInvoices retorna assinaturas cuja fatura pendente ja tem status final.
+	ListSettledInvoices(limit int) ([]SubscriptionInvoice, error)
+	ListEvents(subscriptionID string) ([]*SubscriptionEvent, error)
+}
+
+type PayoutRepository interface {
+	SaveBankAccount(bankAccount *BankAccount) error
+	// FindBankAccountByID ignora contas bancarias removidas e retorna ErrBankAccountNotFound.
+	FindBankAccountByID(id string) (*BankAccount, error)
+	ListBankAccounts(accountID string, mode Mode) ([]*BankAccount, error)
+	DeleteBankAccount(id string, at time.Time) error
+	// Create reserva o valor no saldo do modo e da moeda do saque e grava o saque com o evento
+	// do outbox na mesma transacao. Retorna ErrInsufficientBalance se o saldo nao cobrir o valor.
+	Create(payout *Payout, eventType string, payload []byte, correlationID string) error
+	FindByID(id string) (*Payout, error)
+	ListByAccountID(accountID string, filter PayoutFilter) (*PayoutPage, error)
+	// Transition aplica a mudanca de status com o saque bloqueado; failed devolve a reserva ao saldo.
+	// Repetir o status atual retorna o saque sem alteracao.
+	Transition(id string, status PayoutStatus, failureReason string, at time.Time) (*Payout, error)
+	// ListDeadLettered lista ate limit saques pending cujo evento do outbox esgotou as tentativas.
+	ListDeadLettered(limit int) ([]*Payout, error)
+	// Redeliver devolve o evento dead de um saque pending a fila do outbox. Retorna
+	// ErrPayoutNotDeadLettered se nao houver evento dead para reenviar.
+	Redeliver(id string) error
+}
+
+type PricingPlanRepository interface {
+	// Get retorna ErrPricingPlanNotFound quando a conta nao tem plano no modo e na moeda.
+	Get(accountID string, mode Mode, currency string) (*PricingPlan, error)
+	ListByAccountID(accountID string) ([]*PricingPlan, error)
+	// Replace cria ou substitui o plano; retorna ErrAccountNotFound se a conta nao existir.
+	Replace(plan PricingPlan) (*PricingPlan, error)
+	Delete(accountID string, mode Mode, currency string) error
 }
 
 type InvoiceRepository interface {
-	Save(invoice *Invoice, requestID string) error
-	SaveWithOutbox(invoice *Invoice, eventType string, payload []byte, correlationID string) error
+	// fraudMetadata, quando informado, e gravado no evento fraud_evaluated.
+	Save(invoice *Invoice, requestID string, fraudMetadata map[string]any) error
+	SaveWithOutbox(invoice *Invoice, eventType string, payload []byte, correlationID string, fraudMetadata map[string]any) error
 	AddInvoiceEvent(invoiceID, eventType string, fromStatus, toStatus *Status, metadata map[string]any, requestID string, createdAt *time.Time) error
 	FindByID(id string) (*Invoice, error)
 	FindByAccountID(accountID string) ([]*Invoice, error)
-	GetDailyUsage(accountID string, start, end time.Time) (*DailyUsage, error)
+	ListByAccountID(accountID string, filter InvoiceFilter) (*InvoicePage, error)
+	GetUsage(accountID string, mode Mode, currency, paymentType string, start, end time.Time) (*LimitUsage, error)
 	UpdateStatus(invoice *Invoice) error
+	// ApplyTransactionResult ignora resultados para faturas em revisao manual (ManualReview).
 	ApplyTransactionResult(invoiceID string, status Status, requestID string) error
+	// ResolveReview aplica a decisao do operador a uma fatura em revisao manual.
+	ResolveReview(invoiceID string, status Status, metadata map[string]any, requestID string) (*Invoice, error)
+	// ApplyRefund retorna ErrInsufficientBalance se o estorno deixaria o saldo disponivel negativo.
+	ApplyRefund(invoiceID string, amountCents int64, requestID string) (*Invoice, error)
+	Cancel(invoiceID string, requestID string) (*Invoice, error)
+	ExpirePending(olderThan time.Time, limit int, requestID string) (int, error)
+	Capture(invoiceID string, amountCents int64, requestID string) (*Invoice, error)
+	Void(invoiceID string, requestID string) (*Invoice, error)
+	VoidExpiredAuthorizations(olderThan time.Time, limit int, requestID string) (int, error)
+	// NextBoletoNumber reserva o proximo nosso numero.
+	NextBoletoNumber() (int64, error)
+	SaveBoleto(invoice *Invoice, boleto *Boleto, requestID string) error
+	FindBoletoByInvoiceID(invoiceID string) (*Boleto, error)
+	SettleBoleto(settlement BoletoSettlement, requestID string) (*Invoice, *Boleto, error)
+	ExpireOverdueBoletos(olderThan time.Time, limit int, requestID string) (int, error)
+	SavePix(invoice *Invoice, charge *PixCharge, requestID string) error
+	FindPixChargeByInvoiceID(invoiceID string) (*PixCharge, error)
+	SettlePix(payment PixPayment, requestID string) (*Invoice, error)
+	ExpireOverduePix(olderThan time.Time, limit int, requestID string) (int, error)
 	ListEventsByInvoiceID(invoiceID string) ([]*InvoiceEvent, error)
+	// BeginLimitScope abre a transacao que serializa a criacao de faturas de uma chave de limite.
+	BeginLimitScope(ctx context.Context, key string) (LimitScope, error)
+}
+
+// LimitScope e um InvoiceRepository preso a uma transacao que segura o lock de limites. GetUsage,
+// NextBoletoNumber, AddInvoiceEvent e os Save* usam a transacao; nada e gravado ate o Commit, e
+// Commit ou Rollback liberam o lock.
+type LimitScope interface {
+	InvoiceRepository
+	Commit() error
+	Rollback() error
 }
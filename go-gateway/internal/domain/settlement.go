@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// SettlementSchedule define por tipo de pagamento quanto tempo um valor aprovado fica pendente
+// antes de ficar disponivel para saque. Tipos ausentes ficam disponiveis na aprovacao.
+type SettlementSchedule map[string]time.Duration
+
+// DefaultSettlementSchedule libera cartao em D+30, boleto em D+1 e PIX na hora.
+var DefaultSettlementSchedule = SettlementSchedule{
+	PaymentTypeCreditCard: 30 * 24 * time.Hour,
+	PaymentTypeBoleto:     24 * time.Hour,
+	PaymentTypePix:        0,
+}
+
+// Delay retorna a espera configurada para paymentType.
+func (s SettlementSchedule) Delay(paymentType string) time.Duration {
+	delay := s[paymentType]
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+// BalanceRelease e a parte de uma fatura aprovada que fica pendente ate AvailableAt.
+// O job de liquidacao move AmountCents para o saldo disponivel e preenche ReleasedAt.
+type BalanceRelease struct {
+	ID          string
+	AccountID   string
+	InvoiceID   string
+	Mode        Mode
+	Currency    string
+	AmountCents int64
+	AvailableAt time.Time
+	ReleasedAt  *time.Time
+	CreatedAt   time.Time
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSettlementScheduleDelay(t *testing.T) {
+	schedule := SettlementSchedule{PaymentTypeCreditCard: 30 * 24 * time.Hour, PaymentTypeBoleto: -time.Hour}
+
+	if got := schedule.Delay(PaymentTypeCreditCard); got != 30*24*time.Hour {
+		t.Fatalf("expected 720h for credit card, got %v", got)
+	}
+	if got := schedule.Delay(PaymentTypePix); got != 0 {
+		t.Fatalf("expected missing payment type to be available at once, got %v", got)
+	}
+	if got := schedule.Delay(PaymentTypeBoleto); got != 0 {
+		t.Fatalf("expected negative delay to be ignored, got %v", got)
+	}
+	if got := SettlementSchedule(nil).Delay(PaymentTypeCreditCard); got != 0 {
+		t.Fatalf("expected nil schedule to be available at once, got %v", got)
+	}
+}
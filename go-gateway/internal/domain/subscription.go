@@ -0,0 +1,366 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PlanInterval e a unidade do ciclo de cobranca de um plano.
+type PlanInterval string
+
+const (
+	PlanIntervalDay   PlanInterval = "day"
+	PlanIntervalWeek  PlanInterval = "week"
+	PlanIntervalMonth PlanInterval = "month"
+	PlanIntervalYear  PlanInterval = "year"
+)
+
+func (i PlanInterval) Valid() bool {
+	switch i {
+	case PlanIntervalDay, PlanIntervalWeek, PlanIntervalMonth, PlanIntervalYear:
+		return true
+	}
+	return false
+}
+
+// SubscriptionStatus representa o estado de cobranca de uma assinatura.
+type SubscriptionStatus string
+
+const (
+	// SubscriptionTrialing: dentro do periodo de teste do plano, sem cobranca.
+	SubscriptionTrialing SubscriptionStatus = "trialing"
+	SubscriptionActive   SubscriptionStatus = "active"
+	// SubscriptionPastDue: a ultima cobranca falhou e ha retentativas agendadas.
+	SubscriptionPastDue SubscriptionStatus = "past_due"
+	// SubscriptionCanceled e final; a assinatura nao gera mais faturas.
+	SubscriptionCanceled SubscriptionStatus = "canceled"
+)
+
+func (s SubscriptionStatus) Valid() bool {
+	switch s {
+	case SubscriptionTrialing, SubscriptionActive, SubscriptionPastDue, SubscriptionCanceled:
+		return true
+	}
+	return false
+}
+
+// Tipos de evento do historico da assinatura.
+const (
+	SubscriptionEventCreated          = "created"
+	SubscriptionEventInvoiceCreated   = "invoice_created"
+	SubscriptionEventPaymentSucceeded = "payment_succeeded"
+	SubscriptionEventPaymentFailed    = "payment_failed"
+	SubscriptionEventCancelScheduled  = "cancel_scheduled"
+	SubscriptionEventCanceled         = "canceled"
+)
+
+// Motivos de cancelamento gravados no evento canceled.
+const (
+	CancelReasonRequested        = "requested"
+	CancelReasonPeriodEnd        = "period_end"
+	CancelReasonDunningExhausted = "dunning_exhausted"
+	CancelReasonCustomerDeleted  = "customer_deleted"
+)
+
+const (
+	// DefaultSubscriptionPageSize e o tamanho de pagina usado quando limit nao e informado.
+	DefaultSubscriptionPageSize = 20
+	// MaxSubscriptionPageSize limita quantas assinaturas uma pagina pode retornar.
+	MaxSubscriptionPageSize = 100
+	// MaxPlanIntervalCount limita o ciclo a um ano em dias, semanas ou meses.
+	MaxPlanIntervalCount = 365
+	// MaxPlanTrialDays limita o periodo de teste dos planos.
+	MaxPlanTrialDays = 730
+)
+
+// subscriptionInvoiceNamespace deriva os IDs das faturas de assinatura (UUID v5).
+var subscriptionInvoiceNamespace = uuid.MustParse("8f6d1c2e-4b7a-5e39-9c0d-2a51f3e7b864")
+
+// DunningSchedule lista as esperas entre cobrancas falhas de uma assinatura. A primeira
+// falha agenda a retentativa apos o primeiro item; falhar depois do ultimo cancela a assinatura.
+type DunningSchedule []time.Duration
+
+// DefaultDunningSchedule retenta apos 1, 3 e 5 dias.
+var DefaultDunningSchedule = DunningSchedule{24 * time.Hour, 72 * time.Hour, 120 * time.Hour}
+
+// Plan define o valor e o ciclo de cobranca das assinaturas. Planos nao mudam depois de
+// criados; arquivar (Active=false) impede novas assinaturas sem afetar as existentes.
+type Plan struct {
+	ID            string
+	AccountID     string
+	Mode          Mode
+	Name          string
+	AmountCents   int64
+	Currency      string
+	Interval      PlanInterval
+	IntervalCount int
+	TrialDays     int
+	Active        bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func NewPlan(accountID string, mode Mode, name string, amountCents int64, currency string, interval PlanInterval, intervalCount, trialDays int, now time.Time) (*Plan, error) {
+	if amountCents <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if !interval.Valid() || intervalCount < 1 || intervalCount > MaxPlanIntervalCount {
+		return nil, ErrInvalidPlan
+	}
+	if trialDays < 0 || trialDays > MaxPlanTrialDays {
+		return nil, ErrInvalidPlan
+	}
+	return &Plan{
+		ID:            uuid.New().String(),
+		AccountID:     accountID,
+		Mode:          mode,
+		Name:          name,
+		AmountCents:   amountCents,
+		Currency:      currency,
+		Interval:      interval,
+		IntervalCount: intervalCount,
+		TrialDays:     trialDays,
+		Active:        true,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+// NextPeriodEnd retorna o fim do ciclo que comeca em start. Ciclos mensais e anuais usam o
+// dia de anchor, limitado ao ultimo dia do mes, para nao escorregar apos meses curtos.
+func (p *Plan) NextPeriodEnd(start, anchor time.Time) time.Time {
+	switch p.Interval {
+	case PlanIntervalDay:
+		return start.AddDate(0, 0, p.IntervalCount)
+	case PlanIntervalWeek:
+		return start.AddDate(0, 0, 7*p.IntervalCount)
+	case PlanIntervalYear:
+		return addMonthsAnchored(start, 12*p.IntervalCount, anchor.Day())
+	default:
+		return addMonthsAnchored(start, p.IntervalCount, anchor.Day())
+	}
+}
+
+func addMonthsAnchored(t time.Time, months, day int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// Subscription cobra o plano de um cliente em um cartao salvo nele. O periodo atual
+// [CurrentPeriodStart, CurrentPeriodEnd) ja foi pago (ou e trial); a cobranca do proximo
+// periodo acontece em NextBillingAt, que sob dunning aponta para a proxima retentativa.
+type Subscription struct {
+	ID                 string
+	AccountID          string
+	Mode               Mode
+	PlanID             string
+	CustomerID         string
+	CardToken          string
+	Status             SubscriptionStatus
+	BillingAnchor      time.Time
+	CurrentPeriodStart time.Time
+	CurrentPeriodEnd   time.Time
+	NextBillingAt      *time.Time
+	FailedAttempts     int
+	PendingInvoiceID   string
+	LatestInvoiceID    string
+	CancelAtPeriodEnd  bool
+	CanceledAt         *time.Time
+	// BillingInvoiceID e o ID reservado pelo scheduler para a fatura da cobranca em andamento.
+	BillingInvoiceID string
+	// Version e incrementada a cada gravacao (controle de concorrencia otimista).
+	Version   int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SubscriptionEvent registra uma transicao ou cobranca no historico da assinatura.
+type SubscriptionEvent struct {
+	ID             string
+	SubscriptionID string
+	EventType      string
+	FromStatus     *SubscriptionStatus
+	ToStatus       *SubscriptionStatus
+	InvoiceID      string
+	Metadata       json.RawMessage
+	CreatedAt      time.Time
+}
+
+// SubscriptionFilter define filtros e paginacao da listagem de assinaturas.
+type SubscriptionFilter struct {
+	Mode       Mode
+	Status     SubscriptionStatus
+	CustomerID string
+	PlanID     string
+	Cursor     *InvoiceCursor
+	Limit      int
+}
+
+// SubscriptionPage representa uma pagina de assinaturas.
+type SubscriptionPage struct {
+	Subscriptions []*Subscription
+	NextCursor    *InvoiceCursor
+}
+
+// SubscriptionInvoice liga uma assinatura ao status atual da sua fatura pendente.
+type SubscriptionInvoice struct {
+	Subscription  *Subscription
+	InvoiceStatus Status
+}
+
+// NewSubscription inicia a assinatura em trialing quando o plano tem trial; sem trial ela
+// fica active com a primeira cobranca agendada para agora.
+func NewSubscription(plan *Plan, customerID, cardToken string, now time.Time) (*Subscription, *SubscriptionEvent) {
+	subscription := &Subscription{
+		ID:                 uuid.New().String(),
+		AccountID:          plan.AccountID,
+		Mode:               plan.Mode,
+		PlanID:             plan.ID,
+		CustomerID:         customerID,
+		CardToken:          cardToken,
+		Status:             SubscriptionActive,
+		CurrentPeriodStart: now,
+		CurrentPeriodEnd:   now,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if plan.TrialDays > 0 {
+		subscription.Status = SubscriptionTrialing
+		subscription.CurrentPeriodEnd = now.AddDate(0, 0, plan.TrialDays)
+	}
+	subscription.BillingAnchor = subscription.CurrentPeriodEnd
+	nextBilling := subscription.CurrentPeriodEnd
+	subscription.NextBillingAt = &nextBilling
+
+	return subscription, subscription.event(SubscriptionEventCreated, "", nil, &subscription.Status, "", map[string]any{"plan_id": plan.ID}, now)
+}
+
+// Billable informa se a assinatura pode gerar uma nova fatura em now.
+func (s *Subscription) Billable(now time.Time) bool {
+	return s.Status != SubscriptionCanceled &&
+		s.PendingInvoiceID == "" &&
+		s.NextBillingAt != nil &&
+		!s.NextBillingAt.After(now)
+}
+
+// ReserveInvoice reserva o ID da fatura da cobranca atual. O ID e derivado da assinatura, do
+// periodo e da tentativa, entao repetir a cobranca (queda da instancia ou falha ao gravar o
+// resultado) reencontra a mesma fatura em vez de criar outra.
+func (s *Subscription) ReserveInvoice() string {
+	key := fmt.Sprintf("%s:%d:%d", s.ID, s.CurrentPeriodEnd.Unix(), s.FailedAttempts+1)
+	s.BillingInvoiceID = uuid.NewSHA1(subscriptionInvoiceNamespace, []byte(key)).String()
+	return s.BillingInvoiceID
+}
+
+// InvoiceCreated registra a fatura do ciclo que aguarda resultado (status pending).
+func (s *Subscription) InvoiceCreated(invoiceID string, now time.Time) *SubscriptionEvent {
+	s.BillingInvoiceID = ""
+	s.PendingInvoiceID = invoiceID
+	s.LatestInvoiceID = invoiceID
+	s.UpdatedAt = now
+	return s.event(SubscriptionEventInvoiceCreated, invoiceID, nil, nil, "", map[string]any{"attempt": s.FailedAttempts + 1}, now)
+}
+
+// PaymentSucceeded avanca o periodo e volta a assinatura para active.
+func (s *Subscription) PaymentSucceeded(plan *Plan, invoiceID string, now time.Time) *SubscriptionEvent {
+	from := s.Status
+	s.Status = SubscriptionActive
+	s.FailedAttempts = 0
+	s.PendingInvoiceID = ""
+	s.BillingInvoiceID = ""
+	s.LatestInvoiceID = invoiceID
+	s.CurrentPeriodStart = s.CurrentPeriodEnd
+	s.CurrentPeriodEnd = plan.NextPeriodEnd(s.CurrentPeriodStart, s.BillingAnchor)
+	nextBilling := s.CurrentPeriodEnd
+	s.NextBillingAt = &nextBilling
+	s.UpdatedAt = now
+	return s.event(SubscriptionEventPaymentSucceeded, invoiceID, &from, &s.Status, "", map[string]any{
+		"current_period_start": s.CurrentPeriodStart,
+		"current_period_end":   s.CurrentPeriodEnd,
+	}, now)
+}
+
+// PaymentFailed conta a falha e agenda a proxima retentativa do dunning em past_due. Sem
+// retentativas restantes a assinatura e cancelada. invoiceID e vazio quando a fatura nem
+// chegou a ser criada (cartao expirado, limite excedido).
+func (s *Subscription) PaymentFailed(invoiceID, reason string, dunning DunningSchedule, now time.Time) []*SubscriptionEvent {
+	from := s.Status
+	s.FailedAttempts++
+	s.PendingInvoiceID = ""
+	s.BillingInvoiceID = ""
+	if invoiceID != "" {
+		s.LatestInvoiceID = invoiceID
+	}
+	s.UpdatedAt = now
+
+	if s.FailedAttempts > len(dunning) {
+		failed := s.event(SubscriptionEventPaymentFailed, invoiceID, nil, nil, reason, map[string]any{"attempt": s.FailedAttempts}, now)
+		return []*SubscriptionEvent{failed, s.Cancel(CancelReasonDunningExhausted, now)}
+	}
+
+	s.Status = SubscriptionPastDue
+	nextBilling := now.Add(dunning[s.FailedAttempts-1])
+	s.NextBillingAt = &nextBilling
+	return []*SubscriptionEvent{s.event(SubscriptionEventPaymentFailed, invoiceID, &from, &s.Status, reason, map[string]any{
+		"attempt":         s.FailedAttempts,
+		"next_attempt_at": nextBilling,
+	}, now)}
+}
+
+// Cancel encerra a assinatura. Uma fatura pendente deixa de ser acompanhada.
+func (s *Subscription) Cancel(reason string, now time.Time) *SubscriptionEvent {
+	from := s.Status
+	s.Status = SubscriptionCanceled
+	s.PendingInvoiceID = ""
+	s.BillingInvoiceID = ""
+	s.NextBillingAt = nil
+	s.CancelAtPeriodEnd = false
+	s.CanceledAt = &now
+	s.UpdatedAt = now
+	return s.event(SubscriptionEventCanceled, "", &from, &s.Status, reason, nil, now)
+}
+
+// ScheduleCancel cancela a assinatura no fim do periodo atual em vez de cobrar o proximo.
+func (s *Subscription) ScheduleCancel(now time.Time) *SubscriptionEvent {
+	s.CancelAtPeriodEnd = true
+	s.UpdatedAt = now
+	return s.event(SubscriptionEventCancelScheduled, "", nil, nil, "", map[string]any{"cancel_at": s.CurrentPeriodEnd}, now)
+}
+
+func (s *Subscription) event(eventType, invoiceID string, from, to *SubscriptionStatus, reason string, metadata map[string]any, now time.Time) *SubscriptionEvent {
+	if reason != "" {
+		if metadata == nil {
+			metadata = map[string]any{}
+		}
+		metadata["reason"] = reason
+	}
+	var raw json.RawMessage
+	if metadata != nil {
+		raw, _ = json.Marshal(metadata)
+	}
+	var fromStatus, toStatus *SubscriptionStatus
+	if from != nil {
+		value := *from
+		fromStatus = &value
+	}
+	if to != nil {
+		value := *to
+		toStatus = &value
+	}
+	return &SubscriptionEvent{
+		ID:             uuid.New().String(),
+		SubscriptionID: s.ID,
+		EventType:      eventType,
+		FromStatus:     fromStatus,
+		ToStatus:       toStatus,
+		InvoiceID:      invoiceID,
+		Metadata:       raw,
+		CreatedAt:      now,
+	}
+}
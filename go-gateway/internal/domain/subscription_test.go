@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlanNextPeriodEndKeepsAnchorDay(t *testing.T) {
+	plan := &Plan{Interval: PlanIntervalMonth, IntervalCount: 1}
+	anchor := time.Date(2025, 1, 31, 10, 0, 0, 0, time.UTC)
+
+	feb := plan.NextPeriodEnd(anchor, anchor)
+	if !feb.Equal(time.Date(2025, 2, 28, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected end of february, got %v", feb)
+	}
+	mar := plan.NextPeriodEnd(feb, anchor)
+	if !mar.Equal(time.Date(2025, 3, 31, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected march 31 after a short month, got %v", mar)
+	}
+
+	weekly := &Plan{Interval: PlanIntervalWeek, IntervalCount: 2}
+	if end := weekly.NextPeriodEnd(anchor, anchor); !end.Equal(anchor.AddDate(0, 0, 14)) {
+		t.Fatalf("expected two weeks later, got %v", end)
+	}
+}
+
+func TestSubscriptionDunningCancelsAfterSchedule(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	plan := &Plan{ID: "plan", Interval: PlanIntervalMonth, IntervalCount: 1, TrialDays: 7}
+	dunning := DunningSchedule{24 * time.Hour, 72 * time.Hour}
+
+	subscription, _ := NewSubscription(plan, "customer", "tok_1", now)
+	if subscription.Status != SubscriptionTrialing || !subscription.NextBillingAt.Equal(now.AddDate(0, 0, 7)) {
+		t.Fatalf("expected trial until %v, got %s until %v", now.AddDate(0, 0, 7), subscription.Status, subscription.NextBillingAt)
+	}
+
+	subscription.PaymentFailed("inv-1", "invoice_rejected", dunning, now)
+	if subscription.Status != SubscriptionPastDue || !subscription.NextBillingAt.Equal(now.Add(24*time.Hour)) {
+		t.Fatalf("expected past_due retry in 24h, got %s at %v", subscription.Status, subscription.NextBillingAt)
+	}
+	subscription.PaymentFailed("inv-2", "invoice_rejected", dunning, now)
+	if !subscription.NextBillingAt.Equal(now.Add(72 * time.Hour)) {
+		t.Fatalf("expected retry in 72h, got %v", subscription.NextBillingAt)
+	}
+
+	events := subscription.PaymentFailed("inv-3", "invoice_rejected", dunning, now)
+	if subscription.Status != SubscriptionCanceled || subscription.NextBillingAt != nil {
+		t.Fatalf("expected canceled without next billing, got %s at %v", subscription.Status, subscription.NextBillingAt)
+	}
+	if len(events) != 2 || events[1].EventType != SubscriptionEventCanceled {
+		t.Fatalf("expected payment_failed and canceled events, got %d", len(events))
+	}
+}
+
+func TestSubscriptionPaymentSucceededResetsDunning(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	plan := &Plan{ID: "plan", Interval: PlanIntervalMonth, IntervalCount: 1}
+
+	subscription, _ := NewSubscription(plan, "customer", "tok_1", now)
+	subscription.PaymentFailed("", "card expired", DefaultDunningSchedule, now)
+	subscription.InvoiceCreated("inv-2", now.Add(24*time.Hour))
+	subscription.PaymentSucceeded(plan, "inv-2", now.Add(24*time.Hour))
+
+	if subscription.Status != SubscriptionActive || subscription.FailedAttempts != 0 || subscription.PendingInvoiceID != "" {
+		t.Fatalf("expected active without pending invoice, got %s attempts=%d pending=%q", subscription.Status, subscription.FailedAttempts, subscription.PendingInvoiceID)
+	}
+	// O periodo segue a data de cobranca original, nao a data da retentativa.
+	if !subscription.CurrentPeriodStart.Equal(now) || !subscription.CurrentPeriodEnd.Equal(now.AddDate(0, 1, 0)) {
+		t.Fatalf("expected period %v-%v, got %v-%v", now, now.AddDate(0, 1, 0), subscription.CurrentPeriodStart, subscription.CurrentPeriodEnd)
+	}
+}
+
+func TestSubscriptionReserveInvoiceIsStablePerAttempt(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	plan := &Plan{ID: "plan", Interval: PlanIntervalMonth, IntervalCount: 1}
+
+	subscription, _ := NewSubscription(plan, "customer", "tok_1", now)
+	first := subscription.ReserveInvoice()
+	if again := subscription.ReserveInvoice(); again != first {
+		t.Fatalf("expected the same reservation for the same attempt, got %s and %s", first, again)
+	}
+
+	subscription.PaymentFailed("", "card expired", DefaultDunningSchedule, now)
+	if subscription.BillingInvoiceID != "" {
+		t.Fatalf("expected reservation cleared, got %s", subscription.BillingInvoiceID)
+	}
+	retry := subscription.ReserveInvoice()
+	if retry == first {
+		t.Fatal("expected a new reservation for the next dunning attempt")
+	}
+
+	subscription.PaymentSucceeded(plan, retry, now)
+	if next := subscription.ReserveInvoice(); next == first || next == retry {
+		t.Fatal("expected a new reservation for the next period")
+	}
+}
@@ -10,33 +10,80 @@ import (
 type CreateAccountInput struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
+	// Currency e a moeda padrao da conta (ISO 4217); vazio usa BRL.
+	Currency string `json:"currency,omitempty"`
 }
 
 // AccountOutput representa dados da conta nas respostas da API
 type AccountOutput struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Balance   float64   `json:"balance"`
-	APIKey    string    `json:"api_key,omitempty"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Currency string `json:"currency"`
+	// Balance e o saldo total; PendingBalance aguarda liquidacao e AvailableBalance pode ser sacado.
+	Balance          float64         `json:"balance"`
+	PendingBalance   float64         `json:"pending_balance"`
+	AvailableBalance float64         `json:"available_balance"`
+	Balances         []BalanceOutput `json:"balances,omitempty"`
+	APIKey           string          `json:"api_key,omitempty"`
+	// TestAPIKey so e exibida na criacao da conta.
+	TestAPIKey string `json:"test_api_key,omitempty"`
+	// Mode indica o modo da API key usada na consulta (live ou test).
+	Mode      string    `json:"mode,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// BalanceOutput representa o saldo da conta em uma moeda
+type BalanceOutput struct {
+	Currency  string  `json:"currency"`
+	Amount    float64 `json:"amount"`
+	Pending   float64 `json:"pending"`
+	Available float64 `json:"available"`
+}
+
 // ToAccount converte CreateAccountInput para domain.Account
 func ToAccount(input CreateAccountInput) (*domain.Account, error) {
-	return domain.NewAccount(input.Name, input.Email)
+	currency, err := domain.NormalizeCurrency(input.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := domain.NewAccount(input.Name, input.Email)
+	if err != nil {
+		return nil, err
+	}
+	account.Currency = currency
+	return account, nil
 }
 
 // FromAccount converte domain.Account para AccountOutput
 func FromAccount(account *domain.Account) AccountOutput {
 	return AccountOutput{
-		ID:        account.ID,
-		Name:      account.Name,
-		Email:     account.Email,
-		Balance:   domain.CentsToAmount(account.BalanceCents),
-		APIKey:    account.APIKey,
-		CreatedAt: account.CreatedAt,
-		UpdatedAt: account.UpdatedAt,
+		ID:               account.ID,
+		Name:             account.Name,
+		Email:            account.Email,
+		Currency:         account.Currency,
+		Balance:          domain.MinorToAmount(account.BalanceCents, account.Currency),
+		PendingBalance:   domain.MinorToAmount(account.PendingCents, account.Currency),
+		AvailableBalance: domain.MinorToAmount(account.BalanceCents-account.PendingCents, account.Currency),
+		APIKey:           account.APIKey,
+		TestAPIKey:       account.TestAPIKey,
+		CreatedAt:        account.CreatedAt,
+		UpdatedAt:        account.UpdatedAt,
+	}
+}
+
+// FromBalances converte os saldos por moeda para a resposta da API
+func FromBalances(balances []domain.Balance) []BalanceOutput {
+	output := make([]BalanceOutput, 0, len(balances))
+	for _, balance := range balances {
+		output = append(output, BalanceOutput{
+			Currency:  balance.Currency,
+			Amount:    domain.MinorToAmount(balance.BalanceCents, balance.Currency),
+			Pending:   domain.MinorToAmount(balance.PendingCents, balance.Currency),
+			Available: domain.MinorToAmount(balance.AvailableCents(), balance.Currency),
+		})
 	}
+	return output
 }
@@ -0,0 +1,197 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+)
+
+// SetAccountLimitInput substitui os limites de um modo e moeda da conta (0 = sem limite).
+// Todos os limites sao obrigatorios para que a alteracao fique explicita na auditoria.
+type SetAccountLimitInput struct {
+	Mode                 string `json:"mode"`
+	Currency             string `json:"currency"`
+	MaxAmountPerTxCents  *int64 `json:"max_amount_per_tx_cents"`
+	MaxDailyVolumeCents  *int64 `json:"max_daily_volume_cents"`
+	MaxDailyTransactions *int64 `json:"max_daily_transactions"`
+	// Rules acrescenta janelas moveis (rolling_1h, rolling_24h, rolling_7d), mensais (month) e
+	// limites por tipo de pagamento. Omitido remove as regras adicionais.
+	Rules []domain.LimitRule `json:"rules,omitempty"`
+	// Enforcement e hard (rejeita, padrao) ou soft (fatura vai para revisao manual).
+	Enforcement string `json:"enforcement,omitempty"`
+	// Timezone (IANA) define os limites de dia e mes; padrao UTC.
+	Timezone string `json:"timezone,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// ResetAccountLimitInput volta os limites de um modo e moeda para os padroes configurados.
+type ResetAccountLimitInput struct {
+	Mode     string `json:"mode"`
+	Currency string `json:"currency"`
+	Reason   string `json:"reason"`
+}
+
+// AccountLimitValues sao os limites em unidades minimas da moeda (0 = sem limite).
+type AccountLimitValues struct {
+	MaxAmountPerTxCents  int64              `json:"max_amount_per_tx_cents"`
+	MaxDailyVolumeCents  int64              `json:"max_daily_volume_cents"`
+	MaxDailyTransactions int64              `json:"max_daily_transactions"`
+	Rules                []domain.LimitRule `json:"rules"`
+	Enforcement          string             `json:"enforcement"`
+	Timezone             string             `json:"timezone"`
+}
+
+// AccountLimitOutput representa os limites de um modo e moeda da conta.
+type AccountLimitOutput struct {
+	AccountID string `json:"account_id"`
+	Mode      string `json:"mode"`
+	Currency  string `json:"currency"`
+	AccountLimitValues
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AccountLimitUsageOutput e o uso do dia corrente no fuso da conta. Remaining* sao omitidos quando nao ha limite.
+type AccountLimitUsageOutput struct {
+	Date                  string    `json:"date"`
+	VolumeCents           int64     `json:"volume_cents"`
+	Transactions          int64     `json:"transactions"`
+	RemainingVolumeCents  *int64    `json:"remaining_volume_cents,omitempty"`
+	RemainingTransactions *int64    `json:"remaining_transactions,omitempty"`
+	ResetsAt              time.Time `json:"resets_at"`
+}
+
+// AccountLimitRuleUsageOutput e o uso atual de uma regra adicional.
+// Na janela transaction nao ha uso acumulado e os campos de periodo sao omitidos.
+type AccountLimitRuleUsageOutput struct {
+	domain.LimitRule
+	VolumeCents           int64      `json:"volume_cents"`
+	Transactions          int64      `json:"transactions"`
+	RemainingVolumeCents  *int64     `json:"remaining_volume_cents,omitempty"`
+	RemainingTransactions *int64     `json:"remaining_transactions,omitempty"`
+	WindowStart           *time.Time `json:"window_start,omitempty"`
+	WindowEnd             *time.Time `json:"window_end,omitempty"`
+}
+
+// AccountLimitStatusOutput representa os limites da conta com o uso do dia e de cada regra.
+type AccountLimitStatusOutput struct {
+	Mode     string `json:"mode"`
+	Currency string `json:"currency"`
+	AccountLimitValues
+	Usage      AccountLimitUsageOutput       `json:"usage"`
+	RulesUsage []AccountLimitRuleUsageOutput `json:"rules_usage"`
+}
+
+// AccountLimitAuditOutput representa uma alteracao de limites na trilha de auditoria.
+type AccountLimitAuditOutput struct {
+	ID        string              `json:"id"`
+	Mode      string              `json:"mode"`
+	Currency  string              `json:"currency"`
+	Action    string              `json:"action"`
+	ChangedBy string              `json:"changed_by"`
+	Reason    string              `json:"reason,omitempty"`
+	Previous  *AccountLimitValues `json:"previous,omitempty"`
+	Current   AccountLimitValues  `json:"current"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+func FromAccountLimit(limit *domain.AccountLimit) *AccountLimitOutput {
+	return &AccountLimitOutput{
+		AccountID:          limit.AccountID,
+		Mode:               string(limit.Mode),
+		Currency:           limit.Currency,
+		AccountLimitValues: fromLimitValues(*limit),
+		CreatedAt:          limit.CreatedAt,
+		UpdatedAt:          limit.UpdatedAt,
+	}
+}
+
+func FromAccountLimits(limits []*domain.AccountLimit) []*AccountLimitOutput {
+	output := make([]*AccountLimitOutput, 0, len(limits))
+	for _, limit := range limits {
+		output = append(output, FromAccountLimit(limit))
+	}
+	return output
+}
+
+func FromAccountLimitStatus(status *domain.AccountLimitStatus) *AccountLimitStatusOutput {
+	limit := status.Limit
+	usage := AccountLimitUsageOutput{
+		Date:         status.DayStart.Format("2006-01-02"),
+		VolumeCents:  status.Usage.TotalCents,
+		Transactions: status.Usage.Count,
+		ResetsAt:     status.DayEnd,
+	}
+	usage.RemainingVolumeCents = remaining(limit.MaxDailyVolumeCents, status.Usage.TotalCents)
+	usage.RemainingTransactions = remaining(limit.MaxDailyTransactions, status.Usage.Count)
+
+	rulesUsage := make([]AccountLimitRuleUsageOutput, 0, len(status.Rules))
+	for _, rule := range status.Rules {
+		item := AccountLimitRuleUsageOutput{
+			LimitRule:    rule.Rule,
+			VolumeCents:  rule.Usage.TotalCents,
+			Transactions: rule.Usage.Count,
+		}
+		if rule.Rule.Window != domain.LimitWindowTransaction {
+			start, end := rule.Start, rule.End
+			item.WindowStart, item.WindowEnd = &start, &end
+			item.RemainingVolumeCents = remaining(rule.Rule.MaxVolumeCents, rule.Usage.TotalCents)
+			item.RemainingTransactions = remaining(rule.Rule.MaxTransactions, rule.Usage.Count)
+		}
+		rulesUsage = append(rulesUsage, item)
+	}
+
+	return &AccountLimitStatusOutput{
+		Mode:               string(limit.Mode),
+		Currency:           limit.Currency,
+		AccountLimitValues: fromLimitValues(limit),
+		Usage:              usage,
+		RulesUsage:         rulesUsage,
+	}
+}
+
+// remaining retorna quanto falta para o limite; nil quando nao ha limite (0).
+func remaining(limit, used int64) *int64 {
+	if limit <= 0 {
+		return nil
+	}
+	value := max(limit-used, 0)
+	return &value
+}
+
+func FromAccountLimitAudits(audits []*domain.AccountLimitAudit) []*AccountLimitAuditOutput {
+	output := make([]*AccountLimitAuditOutput, 0, len(audits))
+	for _, audit := range audits {
+		item := &AccountLimitAuditOutput{
+			ID:        audit.ID,
+			Mode:      string(audit.Mode),
+			Currency:  audit.Currency,
+			Action:    audit.Action,
+			ChangedBy: audit.ChangedBy,
+			Reason:    audit.Reason,
+			Current:   fromLimitValues(audit.Current),
+			CreatedAt: audit.CreatedAt,
+		}
+		if audit.Previous != nil {
+			previous := fromLimitValues(*audit.Previous)
+			item.Previous = &previous
+		}
+		output = append(output, item)
+	}
+	return output
+}
+
+func fromLimitValues(limit domain.AccountLimit) AccountLimitValues {
+	rules := limit.Rules
+	if rules == nil {
+		rules = []domain.LimitRule{}
+	}
+	return AccountLimitValues{
+		MaxAmountPerTxCents:  limit.MaxAmountPerTxCents,
+		MaxDailyVolumeCents:  limit.MaxDailyVolumeCents,
+		MaxDailyTransactions: limit.MaxDailyTransactions,
+		Rules:                rules,
+		Enforcement:          string(limit.Enforcement),
+		Timezone:             limit.Timezone,
+	}
+}
@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+)
+
+// CreateAPIKeyInput representa a criacao de uma API key.
+// A chave e emitida no mesmo modo (live/test) da chave usada na requisicao.
+type CreateAPIKeyInput struct {
+	Label     string     `json:"label"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// GrantAPIKeyScopesInput representa os escopos concedidos a uma chave pelo admin.
+type GrantAPIKeyScopesInput struct {
+	Scopes []string `json:"scopes"`
+}
+
+// APIKeyOutput representa uma API key. Key so e retornada na criacao.
+type APIKeyOutput struct {
+	ID         string     `json:"id"`
+	Label      string     `json:"label"`
+	Mode       string     `json:"mode"`
+	Scopes     []string   `json:"scopes"`
+	Key        string     `json:"key,omitempty"`
+	Last4      string     `json:"last4,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+func FromAPIKey(key *domain.APIKey) *APIKeyOutput {
+	return &APIKeyOutput{
+		ID:         key.ID,
+		Label:      key.Label,
+		Mode:       string(key.Mode),
+		Scopes:     key.Scopes,
+		Key:        key.Secret,
+		Last4:      key.Last4,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		ExpiresAt:  key.ExpiresAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
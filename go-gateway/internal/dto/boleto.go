@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+)
+
+// Resultados de cada pagamento na liquidacao de boletos.
+const (
+	BoletoResultSettled        = "settled"
+	BoletoResultAlreadyPaid    = "already_paid"
+	BoletoResultNotFound       = "not_found"
+	BoletoResultNotPayable     = "not_payable"
+	BoletoResultAmountMismatch = "amount_mismatch"
+	BoletoResultInvalidCode    = "invalid_code"
+	BoletoResultError          = "error"
+)
+
+// BoletoOutput expoe os dados de pagamento de uma fatura boleto.
+type BoletoOutput struct {
+	Barcode       string     `json:"barcode"`
+	DigitableLine string     `json:"digitable_line"`
+	DueDate       string     `json:"due_date"`
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+	PaidAmount    *float64   `json:"paid_amount,omitempty"`
+}
+
+// BoletoPaymentInput e um pagamento do retorno bancario. Code aceita o codigo de barras
+// (44 digitos) ou a linha digitavel (47); sem paid_at, vale o horario do processamento.
+type BoletoPaymentInput struct {
+	Code       string     `json:"code"`
+	PaidAmount float64    `json:"paid_amount"`
+	PaidAt     *time.Time `json:"paid_at,omitempty"`
+}
+
+// SettleBoletosInput representa a importacao de um lote de pagamentos de boleto.
+type SettleBoletosInput struct {
+	// Source identifica a origem do lote (ex.: nome do arquivo de retorno); padrao admin:<operador>.
+	Source   string               `json:"source,omitempty"`
+	Payments []BoletoPaymentInput `json:"payments"`
+}
+
+// BoletoSettlementResult e o resultado de um pagamento do lote.
+type BoletoSettlementResult struct {
+	Code      string `json:"code"`
+	InvoiceID string `json:"invoice_id,omitempty"`
+	Result    string `json:"result"`
+	Status    string `json:"status,omitempty"`
+}
+
+// BoletoSettlementOutput resume a importacao; cada pagamento e processado de forma independente.
+type BoletoSettlementOutput struct {
+	Settled int                      `json:"settled"`
+	Results []BoletoSettlementResult `json:"results"`
+}
+
+func FromBoleto(boleto *domain.Boleto) *BoletoOutput {
+	output := &BoletoOutput{
+		Barcode:       boleto.Barcode,
+		DigitableLine: boleto.DigitableLine,
+		DueDate:       boleto.DueDate.Format("2006-01-02"),
+		PaidAt:        boleto.PaidAt,
+	}
+	if boleto.Paid() {
+		paid := domain.MinorToAmount(boleto.PaidAmountCents, domain.BoletoCurrency)
+		output.PaidAmount = &paid
+	}
+	return output
+}
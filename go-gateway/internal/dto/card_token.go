@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+)
+
+// CreateCardTokenInput representa o cartao enviado ao cofre. O CVV e conferido e descartado.
+type CreateCardTokenInput struct {
+	CardNumber     string `json:"card_number"`
+	CVV            string `json:"cvv"`
+	ExpiryMonth    int    `json:"expiry_month"`
+	ExpiryYear     int    `json:"expiry_year"`
+	CardholderName string `json:"cardholder_name"`
+}
+
+// CardTokenOutput representa um token do cofre; o numero do cartao nunca e retornado.
+type CardTokenOutput struct {
+	Token          string    `json:"token"`
+	Mode           string    `json:"mode"`
+	Brand          string    `json:"brand"`
+	Last4          string    `json:"last4"`
+	ExpiryMonth    int       `json:"expiry_month"`
+	ExpiryYear     int       `json:"expiry_year"`
+	CardholderName string    `json:"cardholder_name"`
+	CustomerID     string    `json:"customer_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func FromCardToken(token *domain.CardToken) *CardTokenOutput {
+	return &CardTokenOutput{
+		Token:          token.ID,
+		Mode:           string(token.Mode),
+		Brand:          token.Brand,
+		Last4:          token.Last4,
+		ExpiryMonth:    token.ExpiryMonth,
+		ExpiryYear:     token.ExpiryYear,
+		CardholderName: token.CardholderName,
+		CustomerID:     token.CustomerID,
+		CreatedAt:      token.CreatedAt,
+	}
+}
@@ -0,0 +1,79 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+)
+
+// CreateCustomerInput representa o payload de criacao de cliente. Apenas name e obrigatorio.
+type CreateCustomerInput struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	// Document aceita CPF ou CNPJ, com ou sem pontuacao.
+	Document          string `json:"document,omitempty"`
+	ExternalReference string `json:"external_reference,omitempty"`
+}
+
+// UpdateCustomerInput representa o PATCH de cliente; campos ausentes nao sao alterados e
+// string vazia limpa email, document e external_reference.
+type UpdateCustomerInput struct {
+	Name              *string `json:"name,omitempty"`
+	Email             *string `json:"email,omitempty"`
+	Document          *string `json:"document,omitempty"`
+	ExternalReference *string `json:"external_reference,omitempty"`
+}
+
+// AttachPaymentMethodInput salva um token de POST /tokens no cliente.
+type AttachPaymentMethodInput struct {
+	CardToken string `json:"card_token"`
+}
+
+type CustomerOutput struct {
+	ID                string    `json:"id"`
+	Mode              string    `json:"mode"`
+	Name              string    `json:"name"`
+	Email             string    `json:"email"`
+	Document          string    `json:"document"`
+	DocumentType      string    `json:"document_type"`
+	ExternalReference string    `json:"external_reference"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// CustomerListOutput representa uma pagina de clientes.
+// NextCursor e nulo quando nao ha mais paginas.
+type CustomerListOutput struct {
+	Data       []*CustomerOutput `json:"data"`
+	NextCursor *string           `json:"next_cursor"`
+}
+
+func FromCustomer(customer *domain.Customer) *CustomerOutput {
+	return &CustomerOutput{
+		ID:                customer.ID,
+		Mode:              string(customer.Mode),
+		Name:              customer.Name,
+		Email:             customer.Email,
+		Document:          customer.Document,
+		DocumentType:      customer.DocumentType,
+		ExternalReference: customer.ExternalReference,
+		CreatedAt:         customer.CreatedAt,
+		UpdatedAt:         customer.UpdatedAt,
+	}
+}
+
+// FromCustomerPage converte domain.CustomerPage para CustomerListOutput. O cursor usa o
+// mesmo formato das faturas.
+func FromCustomerPage(page *domain.CustomerPage) *CustomerListOutput {
+	output := &CustomerListOutput{
+		Data: make([]*CustomerOutput, len(page.Customers)),
+	}
+	for i, customer := range page.Customers {
+		output.Data[i] = FromCustomer(customer)
+	}
+	if page.NextCursor != nil {
+		next := EncodeInvoiceCursor(*page.NextCursor)
+		output.NextCursor = &next
+	}
+	return output
+}
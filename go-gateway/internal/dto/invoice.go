@@ -10,34 +10,118 @@ const (
 	StatusPending  = string(domain.StatusPending)
 	StatusApproved = string(domain.StatusApproved)
 	StatusRejected = string(domain.StatusRejected)
+
+	StatusRefunded          = string(domain.StatusRefunded)
+	StatusPartiallyRefunded = string(domain.StatusPartiallyRefunded)
+	StatusCancelled         = string(domain.StatusCancelled)
+	StatusExpired           = string(domain.StatusExpired)
+	StatusAuthorized        = string(domain.StatusAuthorized)
+	StatusVoided            = string(domain.StatusVoided)
 )
 
 type CreateInvoiceInput struct {
-	APIKey         string
-	Amount         float64 `json:"amount"`
-	Description    string  `json:"description"`
-	PaymentType    string  `json:"payment_type"`
-	CardNumber     string  `json:"card_number"`
-	CVV            string  `json:"cvv"`
-	ExpiryMonth    int     `json:"expiry_month"`
-	ExpiryYear     int     `json:"expiry_year"`
-	CardholderName string  `json:"cardholder_name"`
-	Metadata       map[string]string
+	// AccountID e Mode vem do principal autenticado, nunca do payload.
+	AccountID      string      `json:"-"`
+	Mode           domain.Mode `json:"-"`
+	Amount         float64     `json:"amount"`
+	Currency       string      `json:"currency,omitempty"`
+	Description    string      `json:"description"`
+	PaymentType    string      `json:"payment_type"`
+	CardNumber     string      `json:"card_number"`
+	CVV            string      `json:"cvv"`
+	ExpiryMonth    int         `json:"expiry_month"`
+	ExpiryYear     int         `json:"expiry_year"`
+	CardholderName string      `json:"cardholder_name"`
+	// CardToken (credit_card) substitui os campos de cartao por um token de POST /tokens.
+	CardToken string `json:"card_token,omitempty"`
+	// CustomerID (opcional) liga a fatura a um cliente de POST /customers.
+	CustomerID string `json:"customer_id,omitempty"`
+	// CaptureMethod aceita automatic (padrao) ou manual; manual apenas autoriza a fatura.
+	CaptureMethod string `json:"capture_method,omitempty"`
+	// Installments (credit_card, 1 a 12) define o parcelamento; sem ele a fatura e a vista.
+	Installments int `json:"installments,omitempty"`
+	// DueDate (YYYY-MM-DD, boleto) define o vencimento; sem ele vale o prazo padrao.
+	DueDate string `json:"due_date,omitempty"`
+	// PixExpiresIn (segundos, pix) define a expiracao da cobranca; sem ele vale PIX_EXPIRATION.
+	PixExpiresIn int `json:"pix_expires_in,omitempty"`
+	Metadata     map[string]string
+	// InvoiceID (uso interno) fixa o ID da fatura; se ela ja existir, Create a retorna sem
+	// cobrar de novo. Usado pela cobranca de assinaturas.
+	InvoiceID string `json:"-"`
+}
+
+// RefundInvoiceInput representa o payload de estorno. Sem amount, estorna o valor restante.
+type RefundInvoiceInput struct {
+	AccountID string            `json:"-"`
+	Mode      domain.Mode       `json:"-"`
+	InvoiceID string            `json:"-"`
+	Amount    *float64          `json:"amount,omitempty"`
+	Metadata  map[string]string `json:"-"`
+}
+
+// ReviewInvoiceInput e a decisao do operador sobre uma fatura em revisao manual (admin).
+type ReviewInvoiceInput struct {
+	// Decision e approve ou reject.
+	Decision string `json:"decision"`
+	Reason   string `json:"reason"`
+}
+
+// CaptureInvoiceInput representa o payload de captura. Sem amount, captura o valor autorizado.
+type CaptureInvoiceInput struct {
+	AccountID string            `json:"-"`
+	Mode      domain.Mode       `json:"-"`
+	InvoiceID string            `json:"-"`
+	Amount    *float64          `json:"amount,omitempty"`
+	Metadata  map[string]string `json:"-"`
 }
 
 type InvoiceOutput struct {
 	ID             string    `json:"id"`
 	AccountID      string    `json:"account_id"`
+	Mode           string    `json:"mode"`
+	Currency       string    `json:"currency"`
 	Amount         float64   `json:"amount"`
+	CapturedAmount float64   `json:"captured_amount"`
+	RefundedAmount float64   `json:"refunded_amount"`
+	CaptureMethod  string    `json:"capture_method"`
 	Status         string    `json:"status"`
 	Description    string    `json:"description"`
 	PaymentType    string    `json:"payment_type"`
 	CardLastDigits string    `json:"card_last_digits"`
+	CardBrand      string    `json:"card_brand"`
+	CardBIN        string    `json:"card_bin"`
+	CardFunding    string    `json:"card_funding"`
+	CardCountry    string    `json:"card_country"`
+	CustomerID     string    `json:"customer_id,omitempty"`
+	Installments   int       `json:"installments"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
+
+	// ManualReview indica fatura pending aguardando a decisao de um operador (limite soft).
+	ManualReview bool `json:"manual_review"`
+
+	// FeeAmount e NetAmount sao definidos quando o saldo e creditado (aprovacao ou captura).
+	FeeAmount float64           `json:"fee_amount"`
+	NetAmount float64           `json:"net_amount"`
+	Fee       *InvoiceFeeOutput `json:"fee,omitempty"`
+
+	// Boleto e Pix so sao preenchidos na criacao e na consulta por ID, conforme o tipo de pagamento.
+	Boleto *BoletoOutput `json:"boleto,omitempty"`
+	Pix    *PixOutput    `json:"pix,omitempty"`
+}
+
+// InvoiceFeeOutput detalha a tarifa retida da fatura; percentuais em pontos-base (1% = 100).
+type InvoiceFeeOutput struct {
+	PercentBps                 int64   `json:"percent_bps"`
+	PercentAmount              float64 `json:"percent_amount"`
+	FixedAmount                float64 `json:"fixed_amount"`
+	InstallmentSurchargeBps    int64   `json:"installment_surcharge_bps"`
+	InstallmentSurchargeAmount float64 `json:"installment_surcharge_amount"`
+	Total                      float64 `json:"total"`
 }
 
-func ToInvoice(input CreateInvoiceInput, accountID string) (*domain.Invoice, error) {
+// ToInvoice converte o payload em fatura na moeda ja resolvida (currency).
+func ToInvoice(input CreateInvoiceInput, accountID, currency string) (*domain.Invoice, error) {
 	card := domain.CreditCard{
 		Number:         input.CardNumber,
 		CVV:            input.CVV,
@@ -46,27 +130,64 @@ func ToInvoice(input CreateInvoiceInput, accountID string) (*domain.Invoice, err
 		CardholderName: input.CardholderName,
 	}
 
-	amountCents := domain.AmountToCents(input.Amount)
+	amountCents := domain.AmountToMinor(input.Amount, currency)
 
-	return domain.NewInvoice(
+	invoice, err := domain.NewInvoice(
 		accountID,
 		amountCents,
 		input.Description,
 		input.PaymentType,
 		card,
 	)
+	if err != nil {
+		return nil, err
+	}
+	invoice.Currency = currency
+	invoice.CustomerID = input.CustomerID
+	if input.CaptureMethod != "" {
+		invoice.CaptureMethod = domain.CaptureMethod(input.CaptureMethod)
+	}
+	if input.Installments > 0 {
+		invoice.Installments = input.Installments
+	}
+	return invoice, nil
 }
 
 func FromInvoice(invoice *domain.Invoice) *InvoiceOutput {
-	return &InvoiceOutput{
+	output := &InvoiceOutput{
 		ID:             invoice.ID,
 		AccountID:      invoice.AccountID,
-		Amount:         domain.CentsToAmount(invoice.AmountCents),
+		Mode:           string(invoice.Mode),
+		Currency:       invoice.Currency,
+		Amount:         domain.MinorToAmount(invoice.AmountCents, invoice.Currency),
+		CapturedAmount: domain.MinorToAmount(invoice.CapturedCents, invoice.Currency),
+		RefundedAmount: domain.MinorToAmount(invoice.RefundedCents, invoice.Currency),
+		CaptureMethod:  string(invoice.CaptureMethod),
 		Status:         string(invoice.Status),
 		Description:    invoice.Description,
 		PaymentType:    invoice.PaymentType,
 		CardLastDigits: invoice.CardLastDigits,
+		CardBrand:      invoice.CardBrand,
+		CardBIN:        invoice.CardBIN,
+		CardFunding:    invoice.CardFunding,
+		CardCountry:    invoice.CardCountry,
+		CustomerID:     invoice.CustomerID,
+		Installments:   invoice.Installments,
+		ManualReview:   invoice.ManualReview,
+		FeeAmount:      domain.MinorToAmount(invoice.FeeCents, invoice.Currency),
+		NetAmount:      domain.MinorToAmount(invoice.NetCents, invoice.Currency),
 		CreatedAt:      invoice.CreatedAt,
 		UpdatedAt:      invoice.UpdatedAt,
 	}
+	if fee := invoice.Fee; fee != nil {
+		output.Fee = &InvoiceFeeOutput{
+			PercentBps:                 fee.PercentBps,
+			PercentAmount:              domain.MinorToAmount(fee.PercentCents, invoice.Currency),
+			FixedAmount:                domain.MinorToAmount(fee.FixedCents, invoice.Currency),
+			InstallmentSurchargeBps:    fee.InstallmentSurchargeBps,
+			InstallmentSurchargeAmount: domain.MinorToAmount(fee.InstallmentSurchargeCents, invoice.Currency),
+			Total:                      domain.MinorToAmount(fee.FeeCents, invoice.Currency),
+		}
+	}
+	return output
 }
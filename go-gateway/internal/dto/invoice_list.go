@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/google/uuid"
+)
+
+// InvoiceListOutput representa uma pagina de faturas.
+// NextCursor e nulo quando nao ha mais paginas.
+type InvoiceListOutput struct {
+	Data       []*InvoiceOutput `json:"data"`
+	NextCursor *string          `json:"next_cursor"`
+}
+
+// EncodeInvoiceCursor serializa o cursor em um token opaco seguro para URL.
+func EncodeInvoiceCursor(cursor domain.InvoiceCursor) string {
+	raw := cursor.CreatedAt.Format(time.RFC3339Nano) + "|" + cursor.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeInvoiceCursor interpreta um token gerado por EncodeInvoiceCursor. O ID precisa ser um
+// UUID: o cursor e comparado com colunas UUID e um valor invalido falharia no banco.
+func DecodeInvoiceCursor(token string) (*domain.InvoiceCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, domain.ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(parts[1]); err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	return &domain.InvoiceCursor{CreatedAt: createdAt, ID: parts[1]}, nil
+}
+
+// FromInvoicePage converte domain.InvoicePage para InvoiceListOutput.
+func FromInvoicePage(page *domain.InvoicePage) *InvoiceListOutput {
+	output := &InvoiceListOutput{
+		Data: make([]*InvoiceOutput, len(page.Invoices)),
+	}
+	for i, invoice := range page.Invoices {
+		output.Data[i] = FromInvoice(invoice)
+	}
+	if page.NextCursor != nil {
+		next := EncodeInvoiceCursor(*page.NextCursor)
+		output.NextCursor = &next
+	}
+	return output
+}
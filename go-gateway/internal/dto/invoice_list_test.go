@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+)
+
+func TestInvoiceCursorRoundTrip(t *testing.T) {
+	cursor := domain.InvoiceCursor{
+		CreatedAt: time.Date(2025, 1, 10, 12, 0, 0, 123456000, time.UTC),
+		ID:        "4f9d2c3e-2b1a-4c5d-8e7f-0a1b2c3d4e5f",
+	}
+
+	decoded, err := DecodeInvoiceCursor(EncodeInvoiceCursor(cursor))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !decoded.CreatedAt.Equal(cursor.CreatedAt) || decoded.ID != cursor.ID {
+		t.Fatalf("expected %+v, got %+v", cursor, *decoded)
+	}
+}
+
+func TestDecodeInvoiceCursorRejectsGarbage(t *testing.T) {
+	invalidID := base64.RawURLEncoding.EncodeToString([]byte("2024-01-01T00:00:00Z|x"))
+	for _, token := range []string{"not base64!", "bm8tc2VwYXJhdG9y", "fGlk", invalidID} {
+		if _, err := DecodeInvoiceCursor(token); err != domain.ErrInvalidCursor {
+			t.Fatalf("token %q: expected ErrInvalidCursor, got %v", token, err)
+		}
+	}
+}
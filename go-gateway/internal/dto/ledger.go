@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/ledger"
+)
+
+// LedgerPostingOutput representa uma partida de um lancamento
+type LedgerPostingOutput struct {
+	LedgerAccount string  `json:"ledger_account"`
+	Amount        float64 `json:"amount"`
+}
+
+// LedgerEntryOutput representa um lancamento do ledger nas respostas da API
+type LedgerEntryOutput struct {
+	ID          string                `json:"id"`
+	Type        string                `json:"type"`
+	InvoiceID   *string               `json:"invoice_id,omitempty"`
+	Currency    string                `json:"currency"`
+	Description string                `json:"description,omitempty"`
+	Postings    []LedgerPostingOutput `json:"postings"`
+	CreatedAt   time.Time             `json:"created_at"`
+}
+
+// FromLedgerEntries converte lancamentos do ledger para a resposta da API
+func FromLedgerEntries(entries []*ledger.Entry) []LedgerEntryOutput {
+	output := make([]LedgerEntryOutput, 0, len(entries))
+	for _, entry := range entries {
+		item := LedgerEntryOutput{
+			ID:          entry.ID,
+			Type:        entry.Type,
+			Currency:    entry.Currency,
+			Description: entry.Description,
+			Postings:    make([]LedgerPostingOutput, 0, len(entry.Postings)),
+			CreatedAt:   entry.CreatedAt,
+		}
+		if entry.InvoiceID != "" {
+			invoiceID := entry.InvoiceID
+			item.InvoiceID = &invoiceID
+		}
+		for _, posting := range entry.Postings {
+			item.Postings = append(item.Postings, LedgerPostingOutput{
+				LedgerAccount: posting.LedgerAccount,
+				Amount:        domain.MinorToAmount(posting.AmountCents, entry.Currency),
+			})
+		}
+		output = append(output, item)
+	}
+	return output
+}
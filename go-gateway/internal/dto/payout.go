@@ -0,0 +1,126 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+)
+
+// CreateBankAccountInput cadastra uma conta bancaria de destino dos saques. HolderDocument aceita
+// CPF ou CNPJ com ou sem pontuacao; AccountType aceita checking ou savings.
+type CreateBankAccountInput struct {
+	HolderName     string `json:"holder_name"`
+	HolderDocument string `json:"holder_document"`
+	BankCode       string `json:"bank_code"`
+	Branch         string `json:"branch"`
+	AccountNumber  string `json:"account_number"`
+	AccountType    string `json:"account_type"`
+}
+
+type BankAccountOutput struct {
+	ID                 string    `json:"id"`
+	Mode               string    `json:"mode"`
+	HolderName         string    `json:"holder_name"`
+	HolderDocument     string    `json:"holder_document"`
+	HolderDocumentType string    `json:"holder_document_type"`
+	BankCode           string    `json:"bank_code"`
+	Branch             string    `json:"branch"`
+	AccountNumber      string    `json:"account_number"`
+	AccountType        string    `json:"account_type"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// CreatePayoutInput solicita um saque para uma conta bancaria cadastrada. Sem currency vale a
+// moeda da conta.
+type CreatePayoutInput struct {
+	BankAccountID string  `json:"bank_account_id"`
+	Amount        float64 `json:"amount"`
+	Currency      string  `json:"currency,omitempty"`
+	Description   string  `json:"description,omitempty"`
+}
+
+// UpdatePayoutStatusInput e enviado pelo processador de liquidacao (admin).
+type UpdatePayoutStatusInput struct {
+	Status        string `json:"status"`
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+type PayoutOutput struct {
+	ID            string     `json:"id"`
+	Mode          string     `json:"mode"`
+	BankAccountID string     `json:"bank_account_id"`
+	Amount        float64    `json:"amount"`
+	Currency      string     `json:"currency"`
+	Status        string     `json:"status"`
+	Description   string     `json:"description,omitempty"`
+	FailureReason string     `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+	FailedAt      *time.Time `json:"failed_at,omitempty"`
+}
+
+// PayoutListOutput representa uma pagina de saques.
+// NextCursor e nulo quando nao ha mais paginas.
+type PayoutListOutput struct {
+	Data       []*PayoutOutput `json:"data"`
+	NextCursor *string         `json:"next_cursor"`
+}
+
+// DeadLetteredPayoutOutput e um saque cujo pedido esgotou as tentativas de entrega (admin).
+type DeadLetteredPayoutOutput struct {
+	AccountID string `json:"account_id"`
+	PayoutOutput
+}
+
+func FromBankAccount(bankAccount *domain.BankAccount) *BankAccountOutput {
+	return &BankAccountOutput{
+		ID:                 bankAccount.ID,
+		Mode:               string(bankAccount.Mode),
+		HolderName:         bankAccount.HolderName,
+		HolderDocument:     bankAccount.HolderDocument,
+		HolderDocumentType: bankAccount.HolderDocumentType,
+		BankCode:           bankAccount.BankCode,
+		Branch:             bankAccount.Branch,
+		AccountNumber:      bankAccount.AccountNumber,
+		AccountType:        bankAccount.AccountType,
+		CreatedAt:          bankAccount.CreatedAt,
+	}
+}
+
+func FromPayout(payout *domain.Payout) *PayoutOutput {
+	return &PayoutOutput{
+		ID:            payout.ID,
+		Mode:          string(payout.Mode),
+		BankAccountID: payout.BankAccountID,
+		Amount:        domain.MinorToAmount(payout.AmountCents, payout.Currency),
+		Currency:      payout.Currency,
+		Status:        string(payout.Status),
+		Description:   payout.Description,
+		FailureReason: payout.FailureReason,
+		CreatedAt:     payout.CreatedAt,
+		UpdatedAt:     payout.UpdatedAt,
+		PaidAt:        payout.PaidAt,
+		FailedAt:      payout.FailedAt,
+	}
+}
+
+func FromDeadLetteredPayout(payout *domain.Payout) *DeadLetteredPayoutOutput {
+	return &DeadLetteredPayoutOutput{AccountID: payout.AccountID, PayoutOutput: *FromPayout(payout)}
+}
+
+// FromPayoutPage converte domain.PayoutPage para PayoutListOutput. O cursor usa o mesmo formato
+// das faturas.
+func FromPayoutPage(page *domain.PayoutPage) *PayoutListOutput {
+	output := &PayoutListOutput{
+		Data: make([]*PayoutOutput, len(page.Payouts)),
+	}
+	for i, payout := range page.Payouts {
+		output.Data[i] = FromPayout(payout)
+	}
+	if page.NextCursor != nil {
+		next := EncodeInvoiceCursor(*page.NextCursor)
+		output.NextCursor = &next
+	}
+	return output
+}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+)
+
+// PixOutput expoe a cobranca PIX de uma fatura.
+type PixOutput struct {
+	TxID string `json:"txid"`
+	// Payload e o BR Code copia e cola (tambem usado para gerar o QR Code).
+	Payload    string     `json:"payload"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	EndToEndID string     `json:"end_to_end_id,omitempty"`
+	PaidAt     *time.Time `json:"paid_at,omitempty"`
+}
+
+// PixWebhookInput segue o corpo do webhook PIX do Banco Central: uma lista de pagamentos recebidos.
+type PixWebhookInput struct {
+	Pix []PixWebhookPayment `json:"pix"`
+}
+
+// PixWebhookPayment e um pagamento recebido; valor vem como texto com duas casas (ex.: "150.50").
+type PixWebhookPayment struct {
+	EndToEndID  string    `json:"endToEndId"`
+	TxID        string    `json:"txid"`
+	Valor       string    `json:"valor"`
+	Horario     time.Time `json:"horario"`
+	InfoPagador string    `json:"infoPagador,omitempty"`
+}
+
+func FromPixCharge(charge *domain.PixCharge) *PixOutput {
+	return &PixOutput{
+		TxID:       charge.TxID,
+		Payload:    charge.Payload,
+		ExpiresAt:  charge.ExpiresAt,
+		EndToEndID: charge.EndToEndID,
+		PaidAt:     charge.PaidAt,
+	}
+}
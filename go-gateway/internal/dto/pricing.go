@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+)
+
+// SetPricingPlanInput substitui as tarifas de um modo e moeda da conta. Rates e indexado por
+// tipo de pagamento; tipos omitidos nao pagam tarifa.
+type SetPricingPlanInput struct {
+	Mode     string                    `json:"mode"`
+	Currency string                    `json:"currency"`
+	Rates    map[string]domain.FeeRate `json:"rates"`
+}
+
+// PricingPlanOutput representa as tarifas de um modo e moeda da conta. Sem plano, rates vem
+// vazio e as datas sao omitidas.
+type PricingPlanOutput struct {
+	AccountID string                    `json:"account_id"`
+	Mode      string                    `json:"mode"`
+	Currency  string                    `json:"currency"`
+	Rates     map[string]domain.FeeRate `json:"rates"`
+	CreatedAt *time.Time                `json:"created_at,omitempty"`
+	UpdatedAt *time.Time                `json:"updated_at,omitempty"`
+}
+
+func FromPricingPlan(plan *domain.PricingPlan) *PricingPlanOutput {
+	output := &PricingPlanOutput{
+		AccountID: plan.AccountID,
+		Mode:      string(plan.Mode),
+		Currency:  plan.Currency,
+		Rates:     plan.Rates,
+	}
+	if output.Rates == nil {
+		output.Rates = map[string]domain.FeeRate{}
+	}
+	if !plan.CreatedAt.IsZero() {
+		output.CreatedAt = &plan.CreatedAt
+		output.UpdatedAt = &plan.UpdatedAt
+	}
+	return output
+}
+
+func FromPricingPlans(plans []*domain.PricingPlan) []*PricingPlanOutput {
+	output := make([]*PricingPlanOutput, 0, len(plans))
+	for _, plan := range plans {
+		output = append(output, FromPricingPlan(plan))
+	}
+	return output
+}
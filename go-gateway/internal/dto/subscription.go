@@ -0,0 +1,162 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+)
+
+// CreatePlanInput representa o payload de criacao de plano. Sem currency vale a moeda da conta.
+type CreatePlanInput struct {
+	Name     string  `json:"name"`
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency,omitempty"`
+	// Interval aceita day, week, month ou year; IntervalCount multiplica o ciclo (padrao 1).
+	Interval      string `json:"interval"`
+	IntervalCount int    `json:"interval_count,omitempty"`
+	TrialDays     int    `json:"trial_days,omitempty"`
+}
+
+type PlanOutput struct {
+	ID            string    `json:"id"`
+	Mode          string    `json:"mode"`
+	Name          string    `json:"name"`
+	Amount        float64   `json:"amount"`
+	Currency      string    `json:"currency"`
+	Interval      string    `json:"interval"`
+	IntervalCount int       `json:"interval_count"`
+	TrialDays     int       `json:"trial_days"`
+	Active        bool      `json:"active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// CreateSubscriptionInput assina um plano para um cliente com um card_token salvo nele.
+type CreateSubscriptionInput struct {
+	PlanID     string `json:"plan_id"`
+	CustomerID string `json:"customer_id"`
+	CardToken  string `json:"card_token"`
+}
+
+// CancelSubscriptionInput com at_period_end cancela no fim do periodo atual em vez de agora.
+type CancelSubscriptionInput struct {
+	AtPeriodEnd bool `json:"at_period_end,omitempty"`
+}
+
+type SubscriptionOutput struct {
+	ID                 string     `json:"id"`
+	Mode               string     `json:"mode"`
+	PlanID             string     `json:"plan_id"`
+	CustomerID         string     `json:"customer_id"`
+	CardToken          string     `json:"card_token"`
+	Status             string     `json:"status"`
+	CurrentPeriodStart time.Time  `json:"current_period_start"`
+	CurrentPeriodEnd   time.Time  `json:"current_period_end"`
+	NextBillingAt      *time.Time `json:"next_billing_at"`
+	FailedAttempts     int        `json:"failed_attempts"`
+	LatestInvoiceID    string     `json:"latest_invoice_id,omitempty"`
+	PendingInvoiceID   string     `json:"pending_invoice_id,omitempty"`
+	CancelAtPeriodEnd  bool       `json:"cancel_at_period_end"`
+	CanceledAt         *time.Time `json:"canceled_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// SubscriptionListOutput representa uma pagina de assinaturas.
+// NextCursor e nulo quando nao ha mais paginas.
+type SubscriptionListOutput struct {
+	Data       []*SubscriptionOutput `json:"data"`
+	NextCursor *string               `json:"next_cursor"`
+}
+
+type SubscriptionEventOutput struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	EventType      string          `json:"event_type"`
+	FromStatus     *string         `json:"from_status,omitempty"`
+	ToStatus       *string         `json:"to_status,omitempty"`
+	InvoiceID      string          `json:"invoice_id,omitempty"`
+	Metadata       json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func FromPlan(plan *domain.Plan) *PlanOutput {
+	return &PlanOutput{
+		ID:            plan.ID,
+		Mode:          string(plan.Mode),
+		Name:          plan.Name,
+		Amount:        domain.MinorToAmount(plan.AmountCents, plan.Currency),
+		Currency:      plan.Currency,
+		Interval:      string(plan.Interval),
+		IntervalCount: plan.IntervalCount,
+		TrialDays:     plan.TrialDays,
+		Active:        plan.Active,
+		CreatedAt:     plan.CreatedAt,
+		UpdatedAt:     plan.UpdatedAt,
+	}
+}
+
+func FromSubscription(subscription *domain.Subscription) *SubscriptionOutput {
+	return &SubscriptionOutput{
+		ID:                 subscription.ID,
+		Mode:               string(subscription.Mode),
+		PlanID:             subscription.PlanID,
+		CustomerID:         subscription.CustomerID,
+		CardToken:          subscription.CardToken,
+		Status:             string(subscription.Status),
+		CurrentPeriodStart: subscription.CurrentPeriodStart,
+		CurrentPeriodEnd:   subscription.CurrentPeriodEnd,
+		NextBillingAt:      subscription.NextBillingAt,
+		FailedAttempts:     subscription.FailedAttempts,
+		LatestInvoiceID:    subscription.LatestInvoiceID,
+		PendingInvoiceID:   subscription.PendingInvoiceID,
+		CancelAtPeriodEnd:  subscription.CancelAtPeriodEnd,
+		CanceledAt:         subscription.CanceledAt,
+		CreatedAt:          subscription.CreatedAt,
+		UpdatedAt:          subscription.UpdatedAt,
+	}
+}
+
+// FromSubscriptionPage converte domain.SubscriptionPage para SubscriptionListOutput. O cursor
+// usa o mesmo formato das faturas.
+func FromSubscriptionPage(page *domain.SubscriptionPage) *SubscriptionListOutput {
+	output := &SubscriptionListOutput{
+		Data: make([]*SubscriptionOutput, len(page.Subscriptions)),
+	}
+	for i, subscription := range page.Subscriptions {
+		output.Data[i] = FromSubscription(subscription)
+	}
+	if page.NextCursor != nil {
+		next := EncodeInvoiceCursor(*page.NextCursor)
+		output.NextCursor = &next
+	}
+	return output
+}
+
+func FromSubscriptionEvents(events []*domain.SubscriptionEvent) []*SubscriptionEventOutput {
+	output := make([]*SubscriptionEventOutput, 0, len(events))
+	for _, event := range events {
+		var fromStatus *string
+		if event.FromStatus != nil {
+			value := string(*event.FromStatus)
+			fromStatus = &value
+		}
+		var toStatus *string
+		if event.ToStatus != nil {
+			value := string(*event.ToStatus)
+			toStatus = &value
+		}
+		output = append(output, &SubscriptionEventOutput{
+			ID:             event.ID,
+			SubscriptionID: event.SubscriptionID,
+			EventType:      event.EventType,
+			FromStatus:     fromStatus,
+			ToStatus:       toStatus,
+			InvoiceID:      event.InvoiceID,
+			Metadata:       event.Metadata,
+			CreatedAt:      event.CreatedAt,
+		})
+	}
+	return output
+}
@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/webhook"
+)
+
+// CreateWebhookEndpointInput representa o cadastro de um endpoint de webhook
+type CreateWebhookEndpointInput struct {
+	URL string `json:"url"`
+}
+
+// WebhookEndpointOutput representa um endpoint de webhook.
+// Secret so e retornado na criacao.
+type WebhookEndpointOutput struct {
+	ID        string    `json:"id"`
+	Mode      string    `json:"mode"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookDeliveryOutput representa uma tentativa de entrega
+type WebhookDeliveryOutput struct {
+	ID             string    `json:"id"`
+	EventID        string    `json:"event_id"`
+	EventType      string    `json:"event_type"`
+	Attempt        int       `json:"attempt"`
+	Success        bool      `json:"success"`
+	ResponseStatus int       `json:"response_status,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	DurationMs     int64     `json:"duration_ms"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func FromWebhookEndpoint(endpoint *webhook.Endpoint) *WebhookEndpointOutput {
+	return &WebhookEndpointOutput{
+		ID:        endpoint.ID,
+		Mode:      string(endpoint.Mode),
+		URL:       endpoint.URL,
+		Secret:    endpoint.Secret,
+		Active:    endpoint.Active,
+		CreatedAt: endpoint.CreatedAt,
+		UpdatedAt: endpoint.UpdatedAt,
+	}
+}
+
+func FromWebhookDeliveries(deliveries []*webhook.Delivery) []*WebhookDeliveryOutput {
+	output := make([]*WebhookDeliveryOutput, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		output = append(output, &WebhookDeliveryOutput{
+			ID:             delivery.ID,
+			EventID:        delivery.EventID,
+			EventType:      delivery.EventType,
+			Attempt:        delivery.Attempt,
+			Success:        delivery.Success,
+			ResponseStatus: delivery.ResponseStatus,
+			Error:          delivery.Error,
+			DurationMs:     delivery.DurationMs,
+			CreatedAt:      delivery.CreatedAt,
+		})
+	}
+	return output
+}
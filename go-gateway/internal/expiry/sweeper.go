@@ -0,0 +1,80 @@
+package expiry
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// requestID identifica as transicoes feitas pelo sweeper em invoice_events.
+const requestID = "expiry-sweeper"
+
+// Job altera ate limit faturas mais antigas que olderThan e retorna quantas foram alteradas.
+// Implementado por InvoiceRepository.ExpirePending, ExpireOverdueBoletos, ExpireOverduePix,
+// VoidExpiredAuthorizations e ReleaseMaturedFunds.
+type Job func(olderThan time.Time, limit int, requestID string) (int, error)
+
+// Sweeper executa periodicamente um Job sobre faturas mais antigas que o TTL.
+type Sweeper struct {
+	name      string
+	job       Job
+	ttl       time.Duration
+	pollEvery time.Duration
+	batchSize int
+	now       func() time.Time
+}
+
+func NewSweeper(name string, job Job, ttl time.Duration, pollEvery time.Duration, batchSize int) *Sweeper {
+	if pollEvery <= 0 {
+		pollEvery = time.Minute
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	return &Sweeper{
+		name:      name,
+		job:       job,
+		ttl:       ttl,
+		pollEvery: pollEvery,
+		batchSize: batchSize,
+		now:       time.Now,
+	}
+}
+
+func (s *Sweeper) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.pollEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.sweep(); err != nil {
+				slog.Error("invoice sweep failed", "sweeper", s.name, "error", err)
+			}
+		}
+	}
+}
+
+// sweep processa lotes ate nao restarem faturas vencidas.
+func (s *Sweeper) sweep() (int, error) {
+	olderThan := s.now().Add(-s.ttl)
+	total := 0
+	for {
+		changed, err := s.job(olderThan, s.batchSize, requestID)
+		if err != nil {
+			return total, err
+		}
+		total += changed
+		if changed < s.batchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		slog.Info("faturas vencidas processadas", "sweeper", s.name, "count", total, "older_than", olderThan)
+	}
+	return total, nil
+}
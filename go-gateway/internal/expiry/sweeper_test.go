@@ -0,0 +1,41 @@
+package expiry
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeStore struct {
+	pending   int
+	calls     int
+	olderThan time.Time
+}
+
+func (s *fakeStore) ExpirePending(olderThan time.Time, limit int, _ string) (int, error) {
+	s.calls++
+	s.olderThan = olderThan
+	expired := limit
+	if s.pending < limit {
+		expired = s.pending
+	}
+	s.pending -= expired
+	return expired, nil
+}
+
+func TestSweepDrainsAllBatches(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	store := &fakeStore{pending: 25}
+	sweeper := NewSweeper("pending_expiry", store.ExpirePending, 2*time.Hour, time.Minute, 10)
+	sweeper.now = func() time.Time { return now }
+
+	total, err := sweeper.sweep()
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if total != 25 || store.calls != 3 {
+		t.Fatalf("expected 25 invoices in 3 calls, got %d in %d", total, store.calls)
+	}
+	if !store.olderThan.Equal(now.Add(-2 * time.Hour)) {
+		t.Fatalf("expected cutoff %v, got %v", now.Add(-2*time.Hour), store.olderThan)
+	}
+}
@@ -0,0 +1,44 @@
+package fraud
+
+import "context"
+
+// Engine executa as regras habilitadas para a conta e soma as pontuacoes.
+type Engine struct {
+	store    Store
+	defaults Settings
+	rules    []Rule
+}
+
+func NewEngine(store Store, defaults Settings, rules ...Rule) *Engine {
+	return &Engine{store: store, defaults: defaults, rules: rules}
+}
+
+func (e *Engine) Evaluate(ctx context.Context, input Input) (*Assessment, error) {
+	settings := e.defaults
+	override, err := e.store.GetSettings(ctx, input.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if override != nil {
+		settings = settings.Merge(*override)
+	}
+
+	assessment := &Assessment{}
+	for _, rule := range e.rules {
+		config, ok := settings.Rules[rule.Name()]
+		if !ok || !config.Enabled {
+			continue
+		}
+		reason, err := rule.Evaluate(ctx, input, config)
+		if err != nil {
+			return nil, err
+		}
+		if reason == nil {
+			continue
+		}
+		assessment.Score += reason.Score
+		assessment.Reasons = append(assessment.Reasons, *reason)
+	}
+	assessment.Decision = settings.decide(assessment.Score)
+	return assessment, nil
+}
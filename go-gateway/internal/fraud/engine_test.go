@@ -0,0 +1,124 @@
+package fraud
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+)
+
+type stubStore struct {
+	settings      *Settings
+	cardUsage     int
+	customerUsage int
+	stats         AmountStats
+}
+
+func (s *stubStore) GetSettings(context.Context, string) (*Settings, error) { return s.settings, nil }
+
+func (s *stubStore) CountCardUsage(context.Context, string, domain.Mode, string, time.Time) (int, error) {
+	return s.cardUsage, nil
+}
+
+func (s *stubStore) CountCustomerUsage(context.Context, string, domain.Mode, string, time.Time) (int, error) {
+	return s.customerUsage, nil
+}
+
+func (s *stubStore) AmountStats(context.Context, string, domain.Mode, string, time.Time) (AmountStats, error) {
+	return s.stats, nil
+}
+
+func newTestInput(now time.Time) Input {
+	return Input{
+		AccountID:        "acc",
+		Mode:             domain.ModeLive,
+		AccountCreatedAt: now.Add(-30 * 24 * time.Hour),
+		Currency:         "BRL",
+		AmountCents:      5000,
+		CardBIN:          "424242",
+		CardLastDigits:   "4242",
+		CardholderName:   "Demo User",
+		Now:              now,
+	}
+}
+
+func TestEngineApprovesWithoutSignals(t *testing.T) {
+	store := &stubStore{}
+	engine := NewEngine(store, DefaultSettings(), DefaultRules(store)...)
+
+	assessment, err := engine.Evaluate(context.Background(), newTestInput(time.Now()))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if assessment.Decision != DecisionApprove || assessment.Score != 0 || len(assessment.Reasons) != 0 {
+		t.Fatalf("expected clean approve, got %+v", assessment)
+	}
+}
+
+func TestEngineSumsScoresIntoReview(t *testing.T) {
+	now := time.Now()
+	store := &stubStore{cardUsage: 5, stats: AmountStats{Count: 20, Mean: 1000, StdDev: 500}}
+	engine := NewEngine(store, DefaultSettings(), DefaultRules(store)...)
+
+	assessment, err := engine.Evaluate(context.Background(), newTestInput(now))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	// velocidade (40) + z-score 8 (30)
+	if assessment.Score != 70 || assessment.Decision != DecisionReview {
+		t.Fatalf("expected review with score 70, got %+v", assessment)
+	}
+	if len(assessment.Reasons) != 2 || assessment.Reasons[0].Rule != RuleCardVelocity || assessment.Reasons[1].Rule != RuleAmountZScore {
+		t.Fatalf("unexpected reasons: %+v", assessment.Reasons)
+	}
+}
+
+func TestEngineUsesAccountSettings(t *testing.T) {
+	store := &stubStore{settings: &Settings{Rules: map[string]RuleConfig{
+		RuleBlocklist: {Enabled: true, Weight: 100, BlockedNames: []string{"  demo   USER "}},
+	}}}
+	engine := NewEngine(store, DefaultSettings(), DefaultRules(store)...)
+
+	assessment, err := engine.Evaluate(context.Background(), newTestInput(time.Now()))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if assessment.Decision != DecisionReject || assessment.Reasons[0].Rule != RuleBlocklist {
+		t.Fatalf("expected blocklist reject, got %+v", assessment)
+	}
+
+	store.settings = &Settings{Rules: map[string]RuleConfig{RuleNewAccount: {Enabled: false}}}
+	input := newTestInput(time.Now())
+	input.AccountCreatedAt = input.Now.Add(-time.Hour)
+	assessment, err = engine.Evaluate(context.Background(), input)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if assessment.Score != 0 {
+		t.Fatalf("expected disabled new_account rule, got %+v", assessment)
+	}
+}
+
+func TestEngineScoresCustomerVelocity(t *testing.T) {
+	store := &stubStore{customerUsage: 5}
+	engine := NewEngine(store, DefaultSettings(), DefaultRules(store)...)
+
+	input := newTestInput(time.Now())
+	assessment, err := engine.Evaluate(context.Background(), input)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if assessment.Score != 0 {
+		t.Fatalf("expected customer rule to skip invoices without customer, got %+v", assessment)
+	}
+
+	input.CustomerID = "cus-1"
+	assessment, err = engine.Evaluate(context.Background(), input)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if assessment.Score != 30 || len(assessment.Reasons) != 1 || assessment.Reasons[0].Rule != RuleCustomerVelocity {
+		t.Fatalf("expected customer velocity score 30, got %+v", assessment)
+	}
+}
@@ -0,0 +1,73 @@
+package fraud
+
+import (
+	"context"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+)
+
+// Decision e a recomendacao do motor antifraude para a fatura.
+type Decision string
+
+const (
+	// DecisionApprove segue para a decisao local da fatura.
+	DecisionApprove Decision = "approve"
+	// DecisionReview mantem a fatura pending e a envia ao antifraude externo.
+	DecisionReview Decision = "review"
+	// DecisionReject rejeita a fatura na criacao.
+	DecisionReject Decision = "reject"
+)
+
+// Input reune os dados avaliados pelas regras. CardBIN e os primeiros digitos do
+// cartao e nunca e persistido; CustomerID e vazio quando a fatura nao informa cliente.
+type Input struct {
+	AccountID        string
+	Mode             domain.Mode
+	AccountCreatedAt time.Time
+	Currency         string
+	AmountCents      int64
+	CardBIN          string
+	CardLastDigits   string
+	CardholderName   string
+	CustomerID       string
+	Now              time.Time
+}
+
+// Reason explica a pontuacao atribuida por uma regra.
+type Reason struct {
+	Rule   string `json:"rule"`
+	Score  int    `json:"score"`
+	Detail string `json:"detail"`
+}
+
+// Assessment consolida o resultado de todas as regras habilitadas.
+type Assessment struct {
+	Score    int
+	Decision Decision
+	Reasons  []Reason
+}
+
+// Metadata formata a avaliacao para o metadata de invoice_events.
+func (a *Assessment) Metadata() map[string]any {
+	reasons := a.Reasons
+	if reasons == nil {
+		reasons = []Reason{}
+	}
+	return map[string]any{
+		"score":    a.Score,
+		"decision": a.Decision,
+		"reasons":  reasons,
+	}
+}
+
+// RuleEngine avalia o risco de uma fatura antes da decisao local.
+type RuleEngine interface {
+	Evaluate(ctx context.Context, input Input) (*Assessment, error)
+}
+
+// Rule e uma regra do motor. Retorna nil quando a fatura nao pontua na regra.
+type Rule interface {
+	Name() string
+	Evaluate(ctx context.Context, input Input, config RuleConfig) (*Reason, error)
+}
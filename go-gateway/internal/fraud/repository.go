@@ -0,0 +1,86 @@
+package fraud
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+)
+
+// AmountStats resume o historico de valores da conta em uma moeda.
+type AmountStats struct {
+	Count  int
+	Mean   float64
+	StdDev float64
+}
+
+// Store fornece a configuracao por conta e o historico usado pelas regras.
+type Store interface {
+	// GetSettings retorna nil quando a conta usa apenas a configuracao padrao.
+	GetSettings(ctx context.Context, accountID string) (*Settings, error)
+	// O historico considera apenas faturas do mesmo modo (live/test).
+	CountCardUsage(ctx context.Context, accountID string, mode domain.Mode, cardLastDigits string, since time.Time) (int, error)
+	CountCustomerUsage(ctx context.Context, accountID string, mode domain.Mode, customerID string, since time.Time) (int, error)
+	AmountStats(ctx context.Context, accountID string, mode domain.Mode, currency string, since time.Time) (AmountStats, error)
+}
+
+// Repository implementa Store sobre o Postgres.
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) GetSettings(ctx context.Context, accountID string) (*Settings, error) {
+	var raw []byte
+	err := r.db.QueryRowContext(ctx, `
+		SELECT settings FROM account_fraud_settings WHERE account_id = $1
+	`, accountID).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var settings Settings
+	if err := json.Unmarshal(raw, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r *Repository) CountCardUsage(ctx context.Context, accountID string, mode domain.Mode, cardLastDigits string, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM invoices
+		WHERE account_id = $1 AND mode = $2 AND card_last_digits = $3 AND created_at >= $4
+	`, accountID, mode, cardLastDigits, since).Scan(&count)
+	return count, err
+}
+
+func (r *Repository) CountCustomerUsage(ctx context.Context, accountID string, mode domain.Mode, customerID string, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM invoices
+		WHERE account_id = $1 AND mode = $2 AND customer_id = $3 AND created_at >= $4
+	`, accountID, mode, customerID, since).Scan(&count)
+	return count, err
+}
+
+// AmountStats considera apenas faturas nao rejeitadas.
+func (r *Repository) AmountStats(ctx context.Context, accountID string, mode domain.Mode, currency string, since time.Time) (AmountStats, error) {
+	var stats AmountStats
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(AVG(amount_cents), 0)::float8, COALESCE(STDDEV_POP(amount_cents), 0)::float8
+		FROM invoices
+		WHERE account_id = $1 AND mode = $2 AND currency = $3 AND created_at >= $4 AND status <> 'rejected'
+	`, accountID, mode, currency, since).Scan(&stats.Count, &stats.Mean, &stats.StdDev)
+	return stats, err
+}
@@ -0,0 +1,166 @@
+package fraud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultRules retorna as regras embutidas do motor.
+func DefaultRules(store Store) []Rule {
+	return []Rule{
+		&CardVelocityRule{store: store},
+		&CustomerVelocityRule{store: store},
+		&AmountZScoreRule{store: store},
+		&BlocklistRule{},
+		&NewAccountRule{},
+	}
+}
+
+// CardVelocityRule pontua cartoes (pelos ultimos 4 digitos) usados muitas vezes na janela.
+type CardVelocityRule struct {
+	store Store
+}
+
+func (r *CardVelocityRule) Name() string { return RuleCardVelocity }
+
+func (r *CardVelocityRule) Evaluate(ctx context.Context, input Input, config RuleConfig) (*Reason, error) {
+	if input.CardLastDigits == "" {
+		return nil, nil
+	}
+	window := time.Duration(orDefault(config.WindowMinutes, 60)) * time.Minute
+	maxCount := orDefault(config.MaxCount, 3)
+
+	count, err := r.store.CountCardUsage(ctx, input.AccountID, input.Mode, input.CardLastDigits, input.Now.Add(-window))
+	if err != nil {
+		return nil, err
+	}
+	if count < maxCount {
+		return nil, nil
+	}
+	return &Reason{
+		Rule:   RuleCardVelocity,
+		Score:  config.Weight,
+		Detail: fmt.Sprintf("card ending %s used %d times in the last %s", input.CardLastDigits, count, window),
+	}, nil
+}
+
+// CustomerVelocityRule pontua clientes com muitas faturas na janela, independente do cartao.
+type CustomerVelocityRule struct {
+	store Store
+}
+
+func (r *CustomerVelocityRule) Name() string { return RuleCustomerVelocity }
+
+func (r *CustomerVelocityRule) Evaluate(ctx context.Context, input Input, config RuleConfig) (*Reason, error) {
+	if input.CustomerID == "" {
+		return nil, nil
+	}
+	window := time.Duration(orDefault(config.WindowMinutes, 60)) * time.Minute
+	maxCount := orDefault(config.MaxCount, 5)
+
+	count, err := r.store.CountCustomerUsage(ctx, input.AccountID, input.Mode, input.CustomerID, input.Now.Add(-window))
+	if err != nil {
+		return nil, err
+	}
+	if count < maxCount {
+		return nil, nil
+	}
+	return &Reason{
+		Rule:   RuleCustomerVelocity,
+		Score:  config.Weight,
+		Detail: fmt.Sprintf("customer %s has %d invoices in the last %s", input.CustomerID, count, window),
+	}, nil
+}
+
+// AmountZScoreRule pontua valores muito acima do historico da conta na mesma moeda.
+type AmountZScoreRule struct {
+	store Store
+}
+
+func (r *AmountZScoreRule) Name() string { return RuleAmountZScore }
+
+func (r *AmountZScoreRule) Evaluate(ctx context.Context, input Input, config RuleConfig) (*Reason, error) {
+	historyDays := orDefault(config.HistoryDays, 90)
+	minSamples := orDefault(config.MinSamples, 10)
+	threshold := config.Threshold
+	if threshold <= 0 {
+		threshold = 3
+	}
+
+	stats, err := r.store.AmountStats(ctx, input.AccountID, input.Mode, input.Currency, input.Now.AddDate(0, 0, -historyDays))
+	if err != nil {
+		return nil, err
+	}
+	if stats.Count < minSamples || stats.StdDev <= 0 {
+		return nil, nil
+	}
+
+	zScore := (float64(input.AmountCents) - stats.Mean) / stats.StdDev
+	if zScore < threshold {
+		return nil, nil
+	}
+	return &Reason{
+		Rule:   RuleAmountZScore,
+		Score:  config.Weight,
+		Detail: fmt.Sprintf("amount z-score %.2f over %d invoices (threshold %.2f)", zScore, stats.Count, threshold),
+	}, nil
+}
+
+// BlocklistRule pontua BINs e nomes de portador bloqueados pela conta.
+type BlocklistRule struct{}
+
+func (r *BlocklistRule) Name() string { return RuleBlocklist }
+
+func (r *BlocklistRule) Evaluate(_ context.Context, input Input, config RuleConfig) (*Reason, error) {
+	if input.CardBIN != "" {
+		for _, bin := range config.BlockedBINs {
+			if bin != "" && strings.HasPrefix(input.CardBIN, bin) {
+				return &Reason{Rule: RuleBlocklist, Score: config.Weight, Detail: "card BIN " + bin + " is blocked"}, nil
+			}
+		}
+	}
+
+	name := normalizeName(input.CardholderName)
+	if name != "" {
+		for _, blocked := range config.BlockedNames {
+			if normalizeName(blocked) == name {
+				return &Reason{Rule: RuleBlocklist, Score: config.Weight, Detail: "cardholder name is blocked"}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// NewAccountRule pontua faturas de contas criadas recentemente.
+type NewAccountRule struct{}
+
+func (r *NewAccountRule) Name() string { return RuleNewAccount }
+
+func (r *NewAccountRule) Evaluate(_ context.Context, input Input, config RuleConfig) (*Reason, error) {
+	if input.AccountCreatedAt.IsZero() {
+		return nil, nil
+	}
+	maxAge := time.Duration(orDefault(config.MaxAgeHours, 24)) * time.Hour
+	age := input.Now.Sub(input.AccountCreatedAt)
+	if age >= maxAge {
+		return nil, nil
+	}
+	return &Reason{
+		Rule:   RuleNewAccount,
+		Score:  config.Weight,
+		Detail: fmt.Sprintf("account created %s ago (less than %s)", age.Truncate(time.Minute), maxAge),
+	}, nil
+}
+
+func orDefault(value, fallback int) int {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
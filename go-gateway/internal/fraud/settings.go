@@ -0,0 +1,89 @@
+package fraud
+
+const (
+	RuleCardVelocity     = "card_velocity"
+	RuleCustomerVelocity = "customer_velocity"
+	RuleAmountZScore     = "amount_zscore"
+	RuleBlocklist        = "blocklist"
+	RuleNewAccount       = "new_account"
+)
+
+// RuleConfig configura uma regra para uma conta. Cada regra usa apenas os
+// parametros que lhe dizem respeito; valores zerados usam o padrao da regra.
+type RuleConfig struct {
+	Enabled bool `json:"enabled"`
+	Weight  int  `json:"weight"`
+
+	// card_velocity e customer_velocity
+	WindowMinutes int `json:"window_minutes,omitempty"`
+	MaxCount      int `json:"max_count,omitempty"`
+
+	// amount_zscore
+	HistoryDays int     `json:"history_days,omitempty"`
+	MinSamples  int     `json:"min_samples,omitempty"`
+	Threshold   float64 `json:"threshold,omitempty"`
+
+	// blocklist
+	BlockedBINs  []string `json:"blocked_bins,omitempty"`
+	BlockedNames []string `json:"blocked_names,omitempty"`
+
+	// new_account
+	MaxAgeHours int `json:"max_age_hours,omitempty"`
+}
+
+// Settings define as regras e os limiares de decisao de uma conta.
+// Score >= RejectScore rejeita; score >= ReviewScore envia para revisao.
+type Settings struct {
+	ReviewScore int                   `json:"review_score,omitempty"`
+	RejectScore int                   `json:"reject_score,omitempty"`
+	Rules       map[string]RuleConfig `json:"rules,omitempty"`
+}
+
+// DefaultSettings retorna a configuracao usada por contas sem ajuste proprio.
+func DefaultSettings() Settings {
+	return Settings{
+		ReviewScore: 50,
+		RejectScore: 100,
+		Rules: map[string]RuleConfig{
+			RuleCardVelocity:     {Enabled: true, Weight: 40, WindowMinutes: 60, MaxCount: 3},
+			RuleCustomerVelocity: {Enabled: true, Weight: 30, WindowMinutes: 60, MaxCount: 5},
+			RuleAmountZScore:     {Enabled: true, Weight: 30, HistoryDays: 90, MinSamples: 10, Threshold: 3},
+			RuleBlocklist:        {Enabled: true, Weight: 100},
+			RuleNewAccount:       {Enabled: true, Weight: 20, MaxAgeHours: 24},
+		},
+	}
+}
+
+// Merge aplica os ajustes da conta sobre s. Regras informadas pela conta
+// substituem a regra padrao inteira; limiares zerados mantem o padrao.
+func (s Settings) Merge(override Settings) Settings {
+	merged := Settings{
+		ReviewScore: s.ReviewScore,
+		RejectScore: s.RejectScore,
+		Rules:       make(map[string]RuleConfig, len(s.Rules)),
+	}
+	for name, config := range s.Rules {
+		merged.Rules[name] = config
+	}
+	if override.ReviewScore > 0 {
+		merged.ReviewScore = override.ReviewScore
+	}
+	if override.RejectScore > 0 {
+		merged.RejectScore = override.RejectScore
+	}
+	for name, config := range override.Rules {
+		merged.Rules[name] = config
+	}
+	return merged
+}
+
+func (s Settings) decide(score int) Decision {
+	switch {
+	case score >= s.RejectScore:
+		return DecisionReject
+	case score >= s.ReviewScore:
+		return DecisionReview
+	default:
+		return DecisionApprove
+	}
+}
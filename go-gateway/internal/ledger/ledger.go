@@ -0,0 +1,220 @@
+package ledger
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/google/uuid"
+)
+
+// Contas contabeis. Valores positivos aumentam o saldo do merchant em
+// AccountMerchantBalance e AccountMerchantPending; as demais contas registram a contrapartida.
+const (
+	// AccountMerchantBalance e o saldo disponivel do merchant.
+	AccountMerchantBalance = "merchant_balance"
+	// AccountMerchantPending e o saldo do merchant aguardando liquidacao. A soma com
+	// AccountMerchantBalance e espelhada em accounts.balance_cents.
+	AccountMerchantPending = "merchant_pending"
+	// AccountSettlementClearing e a contrapartida dos valores liquidados pelo adquirente.
+	AccountSettlementClearing = "settlement_clearing"
+	// AccountManualAdjustment e a contrapartida de ajustes manuais de saldo.
+	AccountManualAdjustment = "manual_adjustment"
+	// AccountOpeningBalance e a contrapartida dos saldos migrados antes do ledger.
+	AccountOpeningBalance = "opening_balance"
+	// AccountPayoutClearing guarda os valores reservados por saques ainda nao pagos.
+	AccountPayoutClearing = "payout_clearing"
+	// AccountFeeRevenue recebe as tarifas retidas do valor das faturas.
+	AccountFeeRevenue = "fee_revenue"
+)
+
+// Tipos de lancamento.
+const (
+	EntryInvoiceApproved   = "invoice_approved"
+	EntryInvoiceCaptured   = "invoice_captured"
+	EntryInvoiceRefunded   = "invoice_refunded"
+	EntryBalanceAdjustment = "balance_adjustment"
+	EntryPayoutRequested   = "payout_requested"
+	EntryPayoutPaid        = "payout_paid"
+	EntryPayoutFailed      = "payout_failed"
+	EntryFundsReleased     = "funds_released"
+)
+
+var (
+	// ErrUnbalancedEntry e retornado quando a soma das partidas nao e zero.
+	ErrUnbalancedEntry = errors.New("ledger entry is not balanced")
+	// ErrInvalidEntry e retornado quando o lancamento nao tem moeda, tem menos de duas partidas ou partidas zeradas.
+	ErrInvalidEntry = errors.New("ledger entry must have at least two non-zero postings")
+)
+
+// Posting e uma partida de um lancamento.
+type Posting struct {
+	ID            string
+	LedgerAccount string
+	AmountCents   int64
+}
+
+// Entry e um lancamento contabil de uma conta (merchant) em uma unica moeda.
+// Os valores das partidas estao em unidades minimas de Currency.
+type Entry struct {
+	ID        string
+	AccountID string
+	InvoiceID string
+	// Mode separa lancamentos live e test; o padrao e live.
+	Mode        domain.Mode
+	Currency    string
+	Type        string
+	Description string
+	Metadata    json.RawMessage
+	Postings    []Posting
+	CreatedAt   time.Time
+}
+
+// NewEntry cria um lancamento com as partidas informadas.
+func NewEntry(accountID, invoiceID, currency, entryType string, postings ...Posting) *Entry {
+	return &Entry{
+		ID:        uuid.NewString(),
+		AccountID: accountID,
+		InvoiceID: invoiceID,
+		Mode:      domain.ModeLive,
+		Currency:  currency,
+		Type:      entryType,
+		Postings:  postings,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Transfer cria um lancamento que move amountCents de from para to.
+func Transfer(accountID, invoiceID, currency, entryType, from, to string, amountCents int64) *Entry {
+	return NewEntry(accountID, invoiceID, currency, entryType,
+		Posting{LedgerAccount: to, AmountCents: amountCents},
+		Posting{LedgerAccount: from, AmountCents: -amountCents},
+	)
+}
+
+// Validate garante que o lancamento tem ao menos duas partidas e soma zero.
+func (e *Entry) Validate() error {
+	if len(e.Postings) < 2 || e.Currency == "" || !e.Mode.Valid() {
+		return ErrInvalidEntry
+	}
+
+	var sum int64
+	for _, posting := range e.Postings {
+		if posting.AmountCents == 0 || posting.LedgerAccount == "" {
+			return ErrInvalidEntry
+		}
+		sum += posting.AmountCents
+	}
+	if sum != 0 {
+		return ErrUnbalancedEntry
+	}
+	return nil
+}
+
+// MerchantDelta retorna a variacao do saldo total (disponivel + pendente) do merchant causada pelo lancamento.
+func (e *Entry) MerchantDelta() int64 {
+	var delta int64
+	for _, posting := range e.Postings {
+		if posting.LedgerAccount == AccountMerchantBalance || posting.LedgerAccount == AccountMerchantPending {
+			delta += posting.AmountCents
+		}
+	}
+	return delta
+}
+
+// PendingDelta retorna a variacao do saldo pendente do merchant causada pelo lancamento.
+func (e *Entry) PendingDelta() int64 {
+	var delta int64
+	for _, posting := range e.Postings {
+		if posting.LedgerAccount == AccountMerchantPending {
+			delta += posting.AmountCents
+		}
+	}
+	return delta
+}
+
+// Post grava o lancamento e aplica a variacao em account_balances (e em accounts.balance_cents
+// quando o lancamento e live na moeda padrao da conta) usando a transacao do chamador.
+// E o unico caminho que altera saldos.
+func Post(tx *sql.Tx, entry *Entry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+	if entry.ID == "" {
+		entry.ID = uuid.NewString()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	var invoiceID sql.NullString
+	if entry.InvoiceID != "" {
+		invoiceID = sql.NullString{String: entry.InvoiceID, Valid: true}
+	}
+	var description sql.NullString
+	if entry.Description != "" {
+		description = sql.NullString{String: entry.Description, Valid: true}
+	}
+	var metadata interface{}
+	if len(entry.Metadata) > 0 {
+		metadata = entry.Metadata
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO ledger_entries (id, account_id, invoice_id, mode, currency, entry_type, description, metadata, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, entry.ID, entry.AccountID, invoiceID, entry.Mode, entry.Currency, entry.Type, description, metadata, entry.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	for i := range entry.Postings {
+		posting := &entry.Postings[i]
+		if posting.ID == "" {
+			posting.ID = uuid.NewString()
+		}
+		_, err := tx.Exec(`
+			INSERT INTO ledger_postings (id, entry_id, account_id, mode, currency, ledger_account, amount_cents, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, posting.ID, entry.ID, entry.AccountID, entry.Mode, entry.Currency, posting.LedgerAccount, posting.AmountCents, entry.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	delta := entry.MerchantDelta()
+	pendingDelta := entry.PendingDelta()
+	if delta == 0 && pendingDelta == 0 {
+		return nil
+	}
+
+	// accounts.balance_cents espelha apenas o saldo live na moeda padrao da conta.
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE accounts
+		SET balance_cents = balance_cents + CASE WHEN currency = $2 AND $5 = 'live' THEN $1 ELSE 0 END, updated_at = $3
+		WHERE id = $4
+	`, delta, entry.Currency, now, entry.AccountID, entry.Mode)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrAccountNotFound
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO account_balances (account_id, mode, currency, balance_cents, pending_cents, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (account_id, mode, currency)
+		DO UPDATE SET balance_cents = account_balances.balance_cents + EXCLUDED.balance_cents,
+		              pending_cents = account_balances.pending_cents + EXCLUDED.pending_cents,
+		              updated_at = EXCLUDED.updated_at
+	`, entry.AccountID, entry.Mode, entry.Currency, delta, pendingDelta, now)
+	return err
+}
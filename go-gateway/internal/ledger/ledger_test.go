@@ -0,0 +1,59 @@
+package ledger
+
+import "testing"
+
+func TestTransferIsBalanced(t *testing.T) {
+	entry := Transfer("acc", "inv", "BRL", EntryInvoiceApproved, AccountSettlementClearing, AccountMerchantBalance, 1500)
+
+	if err := entry.Validate(); err != nil {
+		t.Fatalf("expected balanced entry, got %v", err)
+	}
+	if entry.MerchantDelta() != 1500 {
+		t.Fatalf("expected merchant delta 1500, got %d", entry.MerchantDelta())
+	}
+
+	refund := Transfer("acc", "inv", "BRL", EntryInvoiceRefunded, AccountMerchantBalance, AccountSettlementClearing, 500)
+	if refund.MerchantDelta() != -500 {
+		t.Fatalf("expected merchant delta -500, got %d", refund.MerchantDelta())
+	}
+}
+
+func TestPendingPostingsCountTowardsMerchantTotal(t *testing.T) {
+	approved := Transfer("acc", "inv", "BRL", EntryInvoiceApproved, AccountSettlementClearing, AccountMerchantPending, 2000)
+	if approved.MerchantDelta() != 2000 || approved.PendingDelta() != 2000 {
+		t.Fatalf("expected merchant and pending delta 2000, got %d and %d", approved.MerchantDelta(), approved.PendingDelta())
+	}
+
+	released := Transfer("acc", "inv", "BRL", EntryFundsReleased, AccountMerchantPending, AccountMerchantBalance, 2000)
+	if released.MerchantDelta() != 0 || released.PendingDelta() != -2000 {
+		t.Fatalf("expected merchant delta 0 and pending delta -2000, got %d and %d", released.MerchantDelta(), released.PendingDelta())
+	}
+}
+
+func TestValidateRejectsUnbalancedEntry(t *testing.T) {
+	entry := NewEntry("acc", "", "BRL", EntryBalanceAdjustment,
+		Posting{LedgerAccount: AccountMerchantBalance, AmountCents: 1000},
+		Posting{LedgerAccount: AccountManualAdjustment, AmountCents: -900},
+	)
+
+	if err := entry.Validate(); err != ErrUnbalancedEntry {
+		t.Fatalf("expected ErrUnbalancedEntry, got %v", err)
+	}
+}
+
+func TestValidateRejectsInvalidPostings(t *testing.T) {
+	single := NewEntry("acc", "", "BRL", EntryBalanceAdjustment,
+		Posting{LedgerAccount: AccountMerchantBalance, AmountCents: 1000},
+	)
+	if err := single.Validate(); err != ErrInvalidEntry {
+		t.Fatalf("expected ErrInvalidEntry for single posting, got %v", err)
+	}
+
+	zero := NewEntry("acc", "", "BRL", EntryBalanceAdjustment,
+		Posting{LedgerAccount: AccountMerchantBalance, AmountCents: 0},
+		Posting{LedgerAccount: AccountManualAdjustment, AmountCents: 0},
+	)
+	if err := zero.Validate(); err != ErrInvalidEntry {
+		t.Fatalf("expected ErrInvalidEntry for zero postings, got %v", err)
+	}
+}
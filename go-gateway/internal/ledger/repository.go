@@ -0,0 +1,141 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+)
+
+// Drift representa a diferenca entre o saldo armazenado e o saldo recalculado pelas partidas.
+// Projection indica qual projecao diverge: account_balances, account_balances.pending (saldo
+// pendente) ou accounts (moeda padrao).
+type Drift struct {
+	AccountID    string
+	Mode         string
+	Currency     string
+	Projection   string
+	BalanceCents int64
+	LedgerCents  int64
+	DriftCents   int64
+}
+
+// Repository consulta lancamentos e reconcilia saldos.
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// ListByAccountID retorna os lancamentos mais recentes da conta no modo informado, com suas partidas.
+func (r *Repository) ListByAccountID(ctx context.Context, accountID string, mode domain.Mode, limit int) ([]*Entry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT e.id, e.account_id, e.invoice_id, e.mode, e.currency, e.entry_type, e.description, e.metadata, e.created_at,
+		       p.id, p.ledger_account, p.amount_cents
+		FROM (
+			SELECT id, account_id, invoice_id, mode, currency, entry_type, description, metadata, created_at
+			FROM ledger_entries
+			WHERE account_id = $1 AND mode = $2
+			ORDER BY created_at DESC, id DESC
+			LIMIT $3
+		) e
+		JOIN ledger_postings p ON p.entry_id = e.id
+		ORDER BY e.created_at DESC, e.id DESC, p.amount_cents DESC
+	`, accountID, mode, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*Entry, 0)
+	var current *Entry
+	for rows.Next() {
+		var entry Entry
+		var invoiceID, description sql.NullString
+		var metadata []byte
+		var posting Posting
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.AccountID,
+			&invoiceID,
+			&entry.Mode,
+			&entry.Currency,
+			&entry.Type,
+			&description,
+			&metadata,
+			&entry.CreatedAt,
+			&posting.ID,
+			&posting.LedgerAccount,
+			&posting.AmountCents,
+		); err != nil {
+			return nil, err
+		}
+
+		if current == nil || current.ID != entry.ID {
+			entry.InvoiceID = invoiceID.String
+			entry.Description = description.String
+			if len(metadata) > 0 {
+				entry.Metadata = metadata
+			}
+			current = &entry
+			entries = append(entries, current)
+		}
+		current.Postings = append(current.Postings, posting)
+	}
+	return entries, rows.Err()
+}
+
+// Reconcile recalcula o saldo de cada conta, modo e moeda a partir das partidas e retorna
+// as divergencias com account_balances e com accounts.balance_cents (live, moeda padrao).
+func (r *Repository) Reconcile(ctx context.Context) ([]Drift, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH ledger AS (
+			SELECT account_id, mode, currency, SUM(amount_cents) AS total,
+			       SUM(CASE WHEN ledger_account = $2 THEN amount_cents ELSE 0 END) AS pending
+			FROM ledger_postings
+			WHERE ledger_account IN ($1, $2)
+			GROUP BY account_id, mode, currency
+		)
+		SELECT COALESCE(b.account_id, l.account_id), COALESCE(b.mode, l.mode), COALESCE(b.currency, l.currency),
+		       'account_balances', COALESCE(b.balance_cents, 0), COALESCE(l.total, 0)
+		FROM account_balances b
+		FULL OUTER JOIN ledger l ON l.account_id = b.account_id AND l.mode = b.mode AND l.currency = b.currency
+		WHERE COALESCE(b.balance_cents, 0) <> COALESCE(l.total, 0)
+		UNION ALL
+		SELECT COALESCE(b.account_id, l.account_id), COALESCE(b.mode, l.mode), COALESCE(b.currency, l.currency),
+		       'account_balances.pending', COALESCE(b.pending_cents, 0), COALESCE(l.pending, 0)
+		FROM account_balances b
+		FULL OUTER JOIN ledger l ON l.account_id = b.account_id AND l.mode = b.mode AND l.currency = b.currency
+		WHERE COALESCE(b.pending_cents, 0) <> COALESCE(l.pending, 0)
+		UNION ALL
+		SELECT a.id, 'live', a.currency, 'accounts', a.balance_cents, COALESCE(l.total, 0)
+		FROM accounts a
+		LEFT JOIN ledger l ON l.account_id = a.id AND l.mode = 'live' AND l.currency = a.currency
+		WHERE a.balance_cents <> COALESCE(l.total, 0)
+		ORDER BY 1, 2, 3, 4
+	`, AccountMerchantBalance, AccountMerchantPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var drifts []Drift
+	for rows.Next() {
+		var drift Drift
+		if err := rows.Scan(&drift.AccountID, &drift.Mode, &drift.Currency, &drift.Projection, &drift.BalanceCents, &drift.LedgerCents); err != nil {
+			return nil, err
+		}
+		drift.DriftCents = drift.BalanceCents - drift.LedgerCents
+		drifts = append(drifts, drift)
+	}
+	return drifts, rows.Err()
+}
+
+// CountAccounts retorna quantas contas foram verificadas na reconciliacao.
+func (r *Repository) CountAccounts(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM accounts`).Scan(&count)
+	return count, err
+}
@@ -5,8 +5,10 @@ import (
 	"database/sql"
 	"encoding/json"
 	"log/slog"
+	"sync"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/segmentio/kafka-go"
 )
 
@@ -29,7 +31,14 @@ func NewRepository(db *sql.DB) *Repository {
 	return &Repository{db: db}
 }
 
-func (r *Repository) ClaimPending(ctx context.Context, limit int) ([]Event, error) {
+// TypeFilter restringe os tipos de evento reivindicados. Sem tipos, todos sao reivindicados;
+// com Exclude, os tipos listados ficam para outro worker.
+type TypeFilter struct {
+	Types   []string
+	Exclude bool
+}
+
+func (r *Repository) ClaimPending(ctx context.Context, limit int, filter TypeFilter) ([]Event, error) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -44,10 +53,11 @@ func (r *Repository) ClaimPending(ctx context.Context, limit int) ([]Event, erro
 		SELECT id, aggregate_id, type, payload, status, attempts, next_attempt_at, correlation_id
 		FROM outbox_events
 		WHERE status IN ('pending', 'failed') AND next_attempt_at <= NOW()
+			AND (cardinality($2::text[]) = 0 OR (type = ANY($2::text[])) <> $3)
 		ORDER BY created_at ASC
 		LIMIT $1
 		FOR UPDATE SKIP LOCKED
-	`, limit)
+	`, limit, pq.StringArray(append([]string{}, filter.Types...)), filter.Exclude)
 	if err != nil {
 		return nil, err
 	}
@@ -89,6 +99,16 @@ func (r *Repository) MarkSent(ctx context.Context, id string) error {
 	return err
 }
 
+// MarkDead encerra as tentativas de um evento que excedeu o limite de entregas.
+func (r *Repository) MarkDead(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE outbox_events
+		SET status = 'dead', updated_at = NOW()
+		WHERE id = $1
+	`, id)
+	return err
+}
+
 func (r *Repository) MarkFailed(ctx context.Context, id string, nextAttemptAt time.Time) error {
 	_, err := r.db.ExecContext(ctx, `
 		UPDATE outbox_events
@@ -98,15 +118,54 @@ func (r *Repository) MarkFailed(ctx context.Context, id string, nextAttemptAt ti
 	return err
 }
 
+// Publisher entrega um evento de outbox ao seu destino.
+type Publisher interface {
+	Publish(ctx context.Context, ev Event) error
+}
+
+// KafkaPublisher publica o payload do evento no topico do writer.
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaPublisher(writer *kafka.Writer) *KafkaPublisher {
+	return &KafkaPublisher{writer: writer}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, ev Event) error {
+	var headers []kafka.Header
+	if ev.CorrelationID.Valid {
+		headers = append(headers, kafka.Header{Key: "x-request-id", Value: []byte(ev.CorrelationID.String)})
+	}
+
+	msg := kafka.Message{
+		Value:   ev.Payload,
+		Headers: headers,
+	}
+
+	return p.writer.WriteMessages(ctx, msg)
+}
+
+// route define o destino de um tipo de evento. maxAttempts zero significa
+// retentar indefinidamente.
+type route struct {
+	publisher   Publisher
+	maxAttempts int
+	maxBackoff  time.Duration
+}
+
 type Worker struct {
 	repo        *Repository
-	writer      *kafka.Writer
+	publisher   Publisher
+	routes      map[string]route
+	filter      TypeFilter
+	concurrency int
 	pollEvery   time.Duration
 	batchSize   int
 	maxAttempts int
 }
 
-func NewWorker(repo *Repository, writer *kafka.Writer, pollEvery time.Duration, batchSize int, maxAttempts int) *Worker {
+func NewWorker(repo *Repository, publisher Publisher, pollEvery time.Duration, batchSize int, maxAttempts int) *Worker {
 	if pollEvery <= 0 {
 		pollEvery = 500 * time.Millisecond
 	}
@@ -119,55 +178,129 @@ func NewWorker(repo *Repository, writer *kafka.Writer, pollEvery time.Duration,
 
 	return &Worker{
 		repo:        repo,
-		writer:      writer,
+		publisher:   publisher,
+		routes:      make(map[string]route),
+		concurrency: 1,
 		pollEvery:   pollEvery,
 		batchSize:   batchSize,
 		maxAttempts: maxAttempts,
 	}
 }
 
+// Route direciona eventos do tipo informado para um publisher proprio.
+// Apos maxAttempts falhas o evento e marcado como dead; zero retenta sempre.
+// Tipos sem rota usam o publisher padrao do worker.
+func (w *Worker) Route(eventType string, publisher Publisher, maxAttempts int, maxBackoff time.Duration) *Worker {
+	w.routes[eventType] = route{
+		publisher:   publisher,
+		maxAttempts: maxAttempts,
+		maxBackoff:  maxBackoff,
+	}
+	return w
+}
+
+// Only restringe o worker aos tipos informados.
+func (w *Worker) Only(eventTypes ...string) *Worker {
+	w.filter = TypeFilter{Types: eventTypes}
+	return w
+}
+
+// Except faz o worker ignorar os tipos informados, atendidos por outro worker.
+func (w *Worker) Except(eventTypes ...string) *Worker {
+	w.filter = TypeFilter{Types: eventTypes, Exclude: true}
+	return w
+}
+
+// WithConcurrency entrega ate n eventos em paralelo. Cada evento ocupa uma vaga ate terminar,
+// entao um destino lento nao atrasa os demais. Com 1 (padrao) os eventos saem em ordem.
+func (w *Worker) WithConcurrency(n int) *Worker {
+	if n > 0 {
+		w.concurrency = n
+	}
+	return w
+}
+
 func (w *Worker) Start(ctx context.Context) {
 	ticker := time.NewTicker(w.pollEvery)
 	defer ticker.Stop()
 
+	slots := make(chan struct{}, w.concurrency)
+	var inFlight sync.WaitGroup
+	defer inFlight.Wait()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			events, err := w.repo.ClaimPending(ctx, w.batchSize)
+			// Em paralelo, so reivindica o que pode entregar agora; o resto fica livre para outras replicas.
+			limit := w.batchSize
+			if w.concurrency > 1 {
+				limit = min(limit, w.concurrency-len(slots))
+			}
+			if limit <= 0 {
+				continue
+			}
+			events, err := w.repo.ClaimPending(ctx, limit, w.filter)
 			if err != nil {
 				slog.Error("outbox claim failed", "error", err)
 				continue
 			}
-			for _, ev := range events {
-				if err := w.publish(ctx, ev); err != nil {
-					backoff := time.Duration(1<<min(ev.Attempts, w.maxAttempts)) * time.Second
-					if backoff > 30*time.Second {
-						backoff = 30 * time.Second
-					}
-					slog.Error("outbox publish failed", "error", err, "event_id", ev.ID)
-					_ = w.repo.MarkFailed(ctx, ev.ID, time.Now().Add(backoff))
-					continue
+			if w.concurrency == 1 {
+				for _, ev := range events {
+					w.dispatch(ctx, ev)
 				}
-				_ = w.repo.MarkSent(ctx, ev.ID)
+				continue
+			}
+			for _, ev := range events {
+				slots <- struct{}{}
+				inFlight.Add(1)
+				go func(ev Event) {
+					defer func() {
+						<-slots
+						inFlight.Done()
+					}()
+					w.dispatch(ctx, ev)
+				}(ev)
 			}
 		}
 	}
 }
 
-func (w *Worker) publish(ctx context.Context, ev Event) error {
-	var headers []kafka.Header
-	if ev.CorrelationID.Valid {
-		headers = append(headers, kafka.Header{Key: "x-request-id", Value: []byte(ev.CorrelationID.String)})
+func (w *Worker) dispatch(ctx context.Context, ev Event) {
+	rt, ok := w.routes[ev.Type]
+	if !ok {
+		rt = route{publisher: w.publisher}
 	}
 
-	msg := kafka.Message{
-		Value:   ev.Payload,
-		Headers: headers,
+	err := rt.publisher.Publish(ctx, ev)
+	if err == nil {
+		_ = w.repo.MarkSent(ctx, ev.ID)
+		return
+	}
+
+	// ev.Attempts reflete o valor anterior ao claim desta rodada.
+	attempt := ev.Attempts + 1
+	if rt.maxAttempts > 0 && attempt >= rt.maxAttempts {
+		slog.Error("outbox publish failed, giving up", "error", err, "event_id", ev.ID, "type", ev.Type, "attempts", attempt)
+		_ = w.repo.MarkDead(ctx, ev.ID)
+		return
 	}
 
-	return w.writer.WriteMessages(ctx, msg)
+	exponentCap := w.maxAttempts
+	if rt.maxAttempts > 0 {
+		exponentCap = rt.maxAttempts
+	}
+	maxBackoff := rt.maxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+	backoff := time.Duration(1<<min(ev.Attempts, exponentCap)) * time.Second
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	slog.Error("outbox publish failed", "error", err, "event_id", ev.ID, "type", ev.Type)
+	_ = w.repo.MarkFailed(ctx, ev.ID, time.Now().Add(backoff))
 }
 
 func min(a, b int) int {
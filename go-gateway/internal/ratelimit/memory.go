@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// idleTTL e o tempo sem requisicoes apos o qual um bucket e descartado (ja estaria cheio).
+const idleTTL = 10 * time.Minute
+
+// MemoryLimiter aplica token bucket por cliente no processo. Cada replica aplica o limite
+// separadamente; use PostgresLimiter para um limite compartilhado.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	clients   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens   float64
+	lastTime time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{clients: make(map[string]*bucket), now: time.Now}
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	client, ok := l.clients[key]
+	if !ok {
+		client = &bucket{tokens: float64(limit.Burst), lastTime: now}
+		l.clients[key] = client
+	}
+
+	var result Result
+	client.tokens, result = take(client.tokens, now.Sub(client.lastTime), limit)
+	client.lastTime = now
+	return result, nil
+}
+
+// sweep descarta buckets ociosos, no maximo uma vez por idleTTL.
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleTTL {
+		return
+	}
+	l.lastSweep = now
+	for key, client := range l.clients {
+		if now.Sub(client.lastTime) >= idleTTL {
+			delete(l.clients, key)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiterBurstAndRefill(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+	limit := Limit{PerMinute: 60, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		result, err := limiter.Allow(context.Background(), "account:a", limit)
+		if err != nil || !result.Allowed {
+			t.Fatalf("request %d: expected allowed, got %+v (err %v)", i, result, err)
+		}
+	}
+
+	result, _ := limiter.Allow(context.Background(), "account:a", limit)
+	if result.Allowed {
+		t.Fatal("expected third request to be limited")
+	}
+	if result.RetryAfter != time.Second {
+		t.Fatalf("expected retry after 1s, got %s", result.RetryAfter)
+	}
+
+	// Outra chave tem o proprio bucket.
+	if result, _ := limiter.Allow(context.Background(), "account:b", limit); !result.Allowed {
+		t.Fatal("expected other key to be allowed")
+	}
+
+	now = now.Add(time.Second)
+	result, _ = limiter.Allow(context.Background(), "account:a", limit)
+	if !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("expected refill of one token, got %+v", result)
+	}
+}
+
+func TestMemoryLimiterUsesPerKeyLimit(t *testing.T) {
+	limiter := NewMemoryLimiter()
+
+	small, _ := limiter.Allow(context.Background(), "account:small", Limit{PerMinute: 60, Burst: 1})
+	large, _ := limiter.Allow(context.Background(), "account:large", Limit{PerMinute: 600, Burst: 100})
+
+	if small.Limit != 1 || small.Remaining != 0 {
+		t.Fatalf("unexpected small plan result %+v", small)
+	}
+	if large.Limit != 100 || large.Remaining != 99 {
+		t.Fatalf("unexpected large plan result %+v", large)
+	}
+}
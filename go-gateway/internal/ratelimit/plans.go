@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+)
+
+// planCacheTTL evita uma consulta ao banco por requisicao para resolver o limite da conta.
+const planCacheTTL = time.Minute
+
+// maxPlanEntries limita o cache; ao enche-lo, entradas expiradas saem primeiro e, se nao bastar,
+// as mais antigas.
+const maxPlanEntries = 10000
+
+// Plans resolve o limite de cada conta: override da conta > plano da conta > padrao global.
+type Plans struct {
+	db       *sql.DB
+	defaults Limit
+
+	mu      sync.Mutex
+	entries map[string]planEntry
+}
+
+type planEntry struct {
+	limit     Limit
+	expiresAt time.Time
+}
+
+// NewPlans cria o resolvedor de limites; valores nao positivos nos padroes viram 60/min e burst 10.
+func NewPlans(db *sql.DB, defaults Limit) *Plans {
+	if defaults.PerMinute <= 0 {
+		defaults.PerMinute = 60
+	}
+	if defaults.Burst <= 0 {
+		defaults.Burst = 10
+	}
+	return &Plans{db: db, defaults: defaults, entries: make(map[string]planEntry)}
+}
+
+// Default retorna o limite global, usado sem conta autenticada.
+func (p *Plans) Default() Limit {
+	return p.defaults
+}
+
+// ForAccount retorna o limite da conta, com cache de planCacheTTL.
+func (p *Plans) ForAccount(ctx context.Context, accountID string) (Limit, error) {
+	now := time.Now()
+
+	p.mu.Lock()
+	entry, ok := p.entries[accountID]
+	p.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.limit, nil
+	}
+
+	var perMinute, burst sql.NullInt64
+	err := p.db.QueryRowContext(ctx, `
+		SELECT COALESCE(a.rate_limit_per_minute, p.requests_per_minute),
+		       COALESCE(a.rate_limit_burst, p.burst)
+		FROM accounts a
+		LEFT JOIN rate_limit_plans p ON p.name = a.rate_limit_plan
+		WHERE a.id = $1
+	`, accountID).Scan(&perMinute, &burst)
+	if err != nil && err != sql.ErrNoRows {
+		return Limit{}, err
+	}
+
+	limit := p.defaults
+	if perMinute.Valid && perMinute.Int64 > 0 {
+		limit.PerMinute = int(perMinute.Int64)
+	}
+	if burst.Valid && burst.Int64 > 0 {
+		limit.Burst = int(burst.Int64)
+	}
+
+	p.mu.Lock()
+	if _, cached := p.entries[accountID]; !cached && len(p.entries) >= maxPlanEntries {
+		p.evict(now)
+	}
+	p.entries[accountID] = planEntry{limit: limit, expiresAt: now.Add(planCacheTTL)}
+	p.mu.Unlock()
+	return limit, nil
+}
+
+// evict abre espaco no cache: remove as entradas expiradas e, se todas ainda valem, as que
+// expiram primeiro ate liberar um decimo da capacidade. Chamado com mu travado.
+func (p *Plans) evict(now time.Time) {
+	for accountID, entry := range p.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(p.entries, accountID)
+		}
+	}
+	if len(p.entries) < maxPlanEntries {
+		return
+	}
+
+	oldest := make([]string, 0, len(p.entries))
+	for accountID := range p.entries {
+		oldest = append(oldest, accountID)
+	}
+	sort.Slice(oldest, func(i, j int) bool {
+		return p.entries[oldest[i]].expiresAt.Before(p.entries[oldest[j]].expiresAt)
+	})
+	for _, accountID := range oldest[:maxPlanEntries/10] {
+		delete(p.entries, accountID)
+	}
+}
@@ -0,0 +1,33 @@
+package ratelimit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPlansEvictKeepsCacheBounded(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	plans := NewPlans(nil, Limit{})
+	for i := 0; i < maxPlanEntries; i++ {
+		plans.entries[fmt.Sprintf("acc-%d", i)] = planEntry{expiresAt: now.Add(time.Duration(i+1) * time.Millisecond)}
+	}
+
+	plans.evict(now)
+
+	if len(plans.entries) != maxPlanEntries-maxPlanEntries/10 {
+		t.Fatalf("expected %d entries, got %d", maxPlanEntries-maxPlanEntries/10, len(plans.entries))
+	}
+	if _, ok := plans.entries["acc-0"]; ok {
+		t.Fatal("expected the entry closest to expiring to be evicted")
+	}
+	if _, ok := plans.entries[fmt.Sprintf("acc-%d", maxPlanEntries-1)]; !ok {
+		t.Fatal("expected the newest entry to be kept")
+	}
+
+	// Entradas expiradas saem antes das validas.
+	plans.evict(now.Add(time.Hour))
+	if len(plans.entries) != 0 {
+		t.Fatalf("expected expired entries to be evicted, got %d", len(plans.entries))
+	}
+}
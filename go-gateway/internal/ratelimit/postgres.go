@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// cleanupInterval e o intervalo entre as limpezas de janelas expiradas.
+const cleanupInterval = time.Minute
+
+// PostgresLimiter aplica janela deslizante compartilhada entre replicas usando a tabela
+// rate_limit_windows. Cada janela dura Limit.Window() e aceita Limit.Burst requisicoes, entao o
+// limite e o mesmo do MemoryLimiter: Burst de uma vez e PerMinute por minuto. A contagem estimada
+// e a da janela atual somada a fracao da anterior que ainda se sobrepoe a ultima janela.
+type PostgresLimiter struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+func NewPostgresLimiter(db *sql.DB) *PostgresLimiter {
+	return &PostgresLimiter{db: db, now: time.Now}
+}
+
+func (l *PostgresLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := l.now().UTC()
+	window := limit.Window()
+	current := now.Truncate(window)
+	previous := current.Add(-window)
+
+	// O incremento e atomico por linha; a janela anterior ja fechou e so e lida.
+	var count, previousCount int
+	err := l.db.QueryRowContext(ctx, `
+		WITH previous AS (
+			SELECT COALESCE(SUM(count), 0) AS count
+			FROM rate_limit_windows
+			WHERE key = $1 AND window_start = $3
+		)
+		INSERT INTO rate_limit_windows (key, window_start, count, expires_at)
+		VALUES ($1, $2, 1, $4)
+		ON CONFLICT (key, window_start) DO UPDATE SET count = rate_limit_windows.count + 1
+		RETURNING count, (SELECT count FROM previous)
+	`, key, current, previous, current.Add(2*window)).Scan(&count, &previousCount)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := slide(count, previousCount, now.Sub(current), window, limit.Burst)
+	if result.Allowed {
+		return result, nil
+	}
+
+	// Requisicoes negadas nao consomem a cota.
+	if _, err := l.db.ExecContext(ctx, `
+		UPDATE rate_limit_windows SET count = count - 1
+		WHERE key = $1 AND window_start = $2 AND count > 0
+	`, key, current); err != nil {
+		return Result{}, err
+	}
+	return result, nil
+}
+
+// slide decide a requisicao ja contada em count (janela atual), com previousCount requisicoes
+// na janela anterior e elapsed decorrido da janela atual.
+func slide(count, previousCount int, elapsed, window time.Duration, limit int) Result {
+	weight := 1 - elapsed.Seconds()/window.Seconds()
+	estimated := float64(count) + float64(previousCount)*weight
+
+	result := Result{Limit: limit}
+	if estimated <= float64(limit) {
+		result.Allowed = true
+		result.Remaining = max(limit-int(math.Ceil(estimated)), 0)
+	} else {
+		count--
+		result.RetryAfter = retryAfter(count, previousCount, limit, elapsed, window)
+	}
+
+	// A cota volta inteira quando as janelas com requisicoes deixam de se sobrepor.
+	switch {
+	case count > 0:
+		result.Reset = 2*window - elapsed
+	case previousCount > 0:
+		result.Reset = window - elapsed
+	}
+	return result
+}
+
+// retryAfter estima quando a fracao da janela anterior tera decaido o suficiente para liberar
+// uma requisicao; se nao bastar, calcula o decaimento da janela atual depois que ela fechar.
+func retryAfter(count, previousCount, limit int, elapsed, window time.Duration) time.Duration {
+	if previousCount > 0 {
+		// count + 1 + previousCount*(1 - t/window) <= limit  =>  t >= window*(1 - (limit-1-count)/previousCount)
+		needed := float64(limit-1-count) / float64(previousCount)
+		if needed >= 0 {
+			if at := time.Duration(math.Ceil((1 - needed) * float64(window))); at > elapsed {
+				return at - elapsed
+			}
+		}
+	}
+
+	// Na proxima janela a atual vira a anterior: 1 + count*(1 - t/window) <= limit.
+	wait := window - elapsed
+	if count >= limit {
+		wait += time.Duration(math.Ceil((1 - float64(limit-1)/float64(count)) * float64(window)))
+	}
+	return wait
+}
+
+// Start remove periodicamente as janelas que ja nao entram no calculo.
+func (l *PostgresLimiter) Start(ctx context.Context) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := l.db.ExecContext(ctx, `DELETE FROM rate_limit_windows WHERE expires_at < $1`, l.now().UTC()); err != nil {
+				slog.Error("rate limit cleanup failed", "error", err)
+			}
+		}
+	}
+}
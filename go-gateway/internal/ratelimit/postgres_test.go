@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimitWindowKeepsTheBucketQuota(t *testing.T) {
+	tests := []struct {
+		limit  Limit
+		window time.Duration
+	}{
+		{Limit{PerMinute: 60, Burst: 10}, 10 * time.Second},
+		{Limit{PerMinute: 600, Burst: 100}, 10 * time.Second},
+		{Limit{PerMinute: 30, Burst: 60}, 2 * time.Minute},
+		{Limit{PerMinute: 7, Burst: 10}, 85714 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if window := tt.limit.Window(); window != tt.window {
+			t.Fatalf("%+v: expected window %s, got %s", tt.limit, tt.window, window)
+		}
+	}
+}
+
+func TestSlide(t *testing.T) {
+	window := 10 * time.Second
+	tests := []struct {
+		name          string
+		count         int
+		previousCount int
+		elapsed       time.Duration
+		want          Result
+	}{
+		{"first request", 1, 0, 0, Result{Allowed: true, Limit: 10, Remaining: 9, Reset: 20 * time.Second}},
+		{"last of the quota", 10, 0, 2 * time.Second, Result{Allowed: true, Limit: 10, Remaining: 0, Reset: 18 * time.Second}},
+		// Na proxima janela, 1s depois: 1 + 10*0.9 = 10.
+		{"over the quota", 11, 0, 2 * time.Second, Result{Limit: 10, Reset: 18 * time.Second, RetryAfter: 9 * time.Second}},
+		{"previous window overlap", 1, 10, 5 * time.Second, Result{Allowed: true, Limit: 10, Remaining: 4, Reset: 15 * time.Second}},
+		// Aos 5s da janela: 4 + 1 + 10*0.5 = 10.
+		{"limited by previous window", 5, 10, 2 * time.Second, Result{Limit: 10, Reset: 18 * time.Second, RetryAfter: 3 * time.Second}},
+		{"only previous window counted", 1, 20, 2 * time.Second, Result{Limit: 10, Reset: 8 * time.Second, RetryAfter: 3500 * time.Millisecond}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slide(tt.count, tt.previousCount, tt.elapsed, window, 10); got != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
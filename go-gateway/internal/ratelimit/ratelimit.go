@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit define a cota de requisicoes de um cliente.
+type Limit struct {
+	// PerMinute e a taxa sustentada: reposicao do token bucket e cota por minuto da janela deslizante.
+	PerMinute int
+	// Burst e a capacidade do bucket e a cota de cada janela; e o valor reportado em RateLimit-Limit.
+	Burst int
+}
+
+// Window e a janela deslizante com a mesma cota do token bucket: Burst requisicoes a cada
+// Burst/PerMinute minutos. Arredondada para milissegundos, a precisao guardada no banco; sem
+// PerMinute positivo a janela e de um minuto.
+func (l Limit) Window() time.Duration {
+	if l.PerMinute <= 0 {
+		return time.Minute
+	}
+	window := (time.Duration(l.Burst) * time.Minute / time.Duration(l.PerMinute)).Truncate(time.Millisecond)
+	return max(window, time.Millisecond)
+}
+
+// Result e a decisao de um RateLimiter e os dados dos headers RateLimit-*.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset e o tempo ate a cota ser totalmente restaurada.
+	Reset time.Duration
+	// RetryAfter e o tempo ate a proxima requisicao ser aceita (apenas quando negada).
+	RetryAfter time.Duration
+}
+
+// RateLimiter consome uma requisicao da cota do cliente identificado por key.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// take repoe os tokens acumulados em elapsed e consome um, se houver.
+func take(tokens float64, elapsed time.Duration, limit Limit) (float64, Result) {
+	ratePerSecond := float64(limit.PerMinute) / 60.0
+	burst := float64(limit.Burst)
+	if elapsed > 0 {
+		tokens = math.Min(burst, tokens+(elapsed.Seconds()*ratePerSecond))
+	}
+
+	result := Result{Limit: limit.Burst}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - tokens) / ratePerSecond)
+	}
+	result.Remaining = int(tokens)
+	result.Reset = secondsToDuration((burst - tokens) / ratePerSecond)
+	return tokens, result
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
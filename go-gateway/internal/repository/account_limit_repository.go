@@ -2,12 +2,16 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/lib/pq"
 )
 
-// AccountLimitRepository lida com politicas de limite por conta.
+const accountLimitColumns = `account_id, mode, currency, max_amount_per_tx_cents, max_daily_volume_cents, max_daily_transactions, rules, enforcement, timezone, created_at, updated_at`
+
+// AccountLimitRepository lida com politicas de limite por conta, modo e moeda.
 type AccountLimitRepository struct {
 	db *sql.DB
 }
@@ -16,41 +20,263 @@ func NewAccountLimitRepository(db *sql.DB) *AccountLimitRepository {
 	return &AccountLimitRepository{db: db}
 }
 
-func (r *AccountLimitRepository) EnsureDefaults(accountID string, defaults domain.AccountLimit) (*domain.AccountLimit, error) {
-	_, err := r.db.Exec(`
-		INSERT INTO account_limits (account_id, max_amount_per_tx_cents, max_daily_volume_cents, max_daily_transactions, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		ON CONFLICT (account_id) DO NOTHING
-	`, accountID, defaults.MaxAmountPerTxCents, defaults.MaxDailyVolumeCents, defaults.MaxDailyTransactions, time.Now(), time.Now())
+func (r *AccountLimitRepository) EnsureDefaults(accountID string, mode domain.Mode, currency string, defaults domain.AccountLimit) (*domain.AccountLimit, error) {
+	rules, err := json.Marshal(nonNilRules(defaults.Rules))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO account_limits (account_id, mode, currency, max_amount_per_tx_cents, max_daily_volume_cents, max_daily_transactions, rules, enforcement, timezone, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (account_id, mode, currency) DO NOTHING
+	`, accountID, mode, currency, defaults.MaxAmountPerTxCents, defaults.MaxDailyVolumeCents, defaults.MaxDailyTransactions, rules, defaults.Enforcement, defaults.Timezone, time.Now(), time.Now())
 	if err != nil {
 		return nil, err
 	}
 
-	return r.GetByAccountID(accountID)
+	return r.GetByAccountID(accountID, mode, currency)
 }
 
-func (r *AccountLimitRepository) GetByAccountID(accountID string) (*domain.AccountLimit, error) {
-	var limit domain.AccountLimit
-	var createdAt, updatedAt time.Time
-	row := r.db.QueryRow(`
-		SELECT account_id, max_amount_per_tx_cents, max_daily_volume_cents, max_daily_transactions, created_at, updated_at
+func (r *AccountLimitRepository) GetByAccountID(accountID string, mode domain.Mode, currency string) (*domain.AccountLimit, error) {
+	limit, err := scanAccountLimit(r.db.QueryRow(`
+		SELECT `+accountLimitColumns+`
+		FROM account_limits
+		WHERE account_id = $1 AND mode = $2 AND currency = $3
+	`, accountID, mode, currency))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return limit, nil
+}
+
+// ListByAccountID lista os limites da conta em todos os modos e moedas.
+func (r *AccountLimitRepository) ListByAccountID(accountID string) ([]*domain.AccountLimit, error) {
+	rows, err := r.db.Query(`
+		SELECT `+accountLimitColumns+`
 		FROM account_limits
 		WHERE account_id = $1
+		ORDER BY mode, currency
 	`, accountID)
-	if err := row.Scan(
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	limits := make([]*domain.AccountLimit, 0)
+	for rows.Next() {
+		limit, err := scanAccountLimit(rows)
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, limit)
+	}
+	return limits, rows.Err()
+}
+
+// Replace grava os limites e a auditoria na mesma transacao. O limite anterior e lido com
+// FOR UPDATE para que alteracoes concorrentes fiquem em sequencia na trilha.
+// Retorna ErrAccountNotFound se a conta nao existir.
+func (r *AccountLimitRepository) Replace(limit domain.AccountLimit, audit *domain.AccountLimitAudit) (*domain.AccountLimit, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	previous, err := scanAccountLimit(tx.QueryRow(`
+		SELECT `+accountLimitColumns+`
+		FROM account_limits
+		WHERE account_id = $1 AND mode = $2 AND currency = $3
+		FOR UPDATE
+	`, limit.AccountID, limit.Mode, limit.Currency))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if err == sql.ErrNoRows {
+		previous = nil
+	}
+
+	rules, err := json.Marshal(nonNilRules(limit.Rules))
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := scanAccountLimit(tx.QueryRow(`
+		INSERT INTO account_limits (account_id, mode, currency, max_amount_per_tx_cents, max_daily_volume_cents, max_daily_transactions, rules, enforcement, timezone, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
+		ON CONFLICT (account_id, mode, currency) DO UPDATE SET
+			max_amount_per_tx_cents = EXCLUDED.max_amount_per_tx_cents,
+			max_daily_volume_cents = EXCLUDED.max_daily_volume_cents,
+			max_daily_transactions = EXCLUDED.max_daily_transactions,
+			rules = EXCLUDED.rules,
+			enforcement = EXCLUDED.enforcement,
+			timezone = EXCLUDED.timezone,
+			updated_at = EXCLUDED.updated_at
+		RETURNING `+accountLimitColumns,
+		limit.AccountID, limit.Mode, limit.Currency, limit.MaxAmountPerTxCents, limit.MaxDailyVolumeCents, limit.MaxDailyTransactions,
+		rules, limit.Enforcement, limit.Timezone, audit.CreatedAt,
+	))
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return nil, domain.ErrAccountNotFound
+		}
+		return nil, err
+	}
+
+	audit.Previous = previous
+	audit.Current = *current
+	previousJSON, err := marshalLimitValues(previous)
+	if err != nil {
+		return nil, err
+	}
+	currentJSON, err := marshalLimitValues(current)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO account_limit_audits (id, account_id, mode, currency, action, changed_by, reason, previous_limits, new_limits, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, audit.ID, audit.AccountID, audit.Mode, audit.Currency, audit.Action, audit.ChangedBy, audit.Reason, previousJSON, currentJSON, audit.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+// ListAudits lista as alteracoes de limites da conta, das mais recentes para as mais antigas.
+func (r *AccountLimitRepository) ListAudits(accountID string, limit int) ([]*domain.AccountLimitAudit, error) {
+	rows, err := r.db.Query(`
+		SELECT id, account_id, mode, currency, action, changed_by, COALESCE(reason, ''), previous_limits, new_limits, created_at
+		FROM account_limit_audits
+		WHERE account_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`, accountID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	audits := make([]*domain.AccountLimitAudit, 0)
+	for rows.Next() {
+		var audit domain.AccountLimitAudit
+		var mode string
+		var previousJSON, currentJSON []byte
+		if err := rows.Scan(
+			&audit.ID,
+			&audit.AccountID,
+			&mode,
+			&audit.Currency,
+			&audit.Action,
+			&audit.ChangedBy,
+			&audit.Reason,
+			&previousJSON,
+			&currentJSON,
+			&audit.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		audit.Mode = domain.Mode(mode)
+
+		if previousJSON != nil {
+			previous, err := unmarshalLimitValues(previousJSON, &audit)
+			if err != nil {
+				return nil, err
+			}
+			audit.Previous = &previous
+		}
+		current, err := unmarshalLimitValues(currentJSON, &audit)
+		if err != nil {
+			return nil, err
+		}
+		audit.Current = current
+		audits = append(audits, &audit)
+	}
+	return audits, rows.Err()
+}
+
+// limitValues e o formato JSONB dos limites guardados na auditoria.
+type limitValues struct {
+	MaxAmountPerTxCents  int64                   `json:"max_amount_per_tx_cents"`
+	MaxDailyVolumeCents  int64                   `json:"max_daily_volume_cents"`
+	MaxDailyTransactions int64                   `json:"max_daily_transactions"`
+	Rules                []domain.LimitRule      `json:"rules,omitempty"`
+	Enforcement          domain.LimitEnforcement `json:"enforcement,omitempty"`
+	Timezone             string                  `json:"timezone,omitempty"`
+}
+
+func marshalLimitValues(limit *domain.AccountLimit) (any, error) {
+	if limit == nil {
+		return nil, nil
+	}
+	return json.Marshal(limitValues{
+		MaxAmountPerTxCents:  limit.MaxAmountPerTxCents,
+		MaxDailyVolumeCents:  limit.MaxDailyVolumeCents,
+		MaxDailyTransactions: limit.MaxDailyTransactions,
+		Rules:                limit.Rules,
+		Enforcement:          limit.Enforcement,
+		Timezone:             limit.Timezone,
+	})
+}
+
+func unmarshalLimitValues(raw []byte, audit *domain.AccountLimitAudit) (domain.AccountLimit, error) {
+	var values limitValues
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return domain.AccountLimit{}, err
+	}
+	return domain.AccountLimit{
+		AccountID:            audit.AccountID,
+		Mode:                 audit.Mode,
+		Currency:             audit.Currency,
+		MaxAmountPerTxCents:  values.MaxAmountPerTxCents,
+		MaxDailyVolumeCents:  values.MaxDailyVolumeCents,
+		MaxDailyTransactions: values.MaxDailyTransactions,
+		Rules:                nonNilRules(values.Rules),
+		Enforcement:          values.Enforcement,
+		Timezone:             values.Timezone,
+	}, nil
+}
+
+func nonNilRules(rules []domain.LimitRule) []domain.LimitRule {
+	if rules == nil {
+		return []domain.LimitRule{}
+	}
+	return rules
+}
+
+func scanAccountLimit(scanner rowScanner) (*domain.AccountLimit, error) {
+	var limit domain.AccountLimit
+	var mode, enforcement string
+	var rules []byte
+	err := scanner.Scan(
 		&limit.AccountID,
+		&mode,
+		&limit.Currency,
 		&limit.MaxAmountPerTxCents,
 		&limit.MaxDailyVolumeCents,
 		&limit.MaxDailyTransactions,
-		&createdAt,
-		&updatedAt,
-	); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, domain.ErrAccountNotFound
-		}
+		&rules,
+		&enforcement,
+		&limit.Timezone,
+		&limit.CreatedAt,
+		&limit.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	limit.Mode = domain.Mode(mode)
+	limit.Enforcement = domain.LimitEnforcement(enforcement)
+	if err := json.Unmarshal(rules, &limit.Rules); err != nil {
 		return nil, err
 	}
-	limit.CreatedAt = createdAt
-	limit.UpdatedAt = updatedAt
 	return &limit, nil
 }
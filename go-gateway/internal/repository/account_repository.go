@@ -5,9 +5,12 @@ import (
 	"time"
 
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
-	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/security"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/ledger"
 )
 
+// accountPendingColumn le o saldo pendente live na moeda padrao, o mesmo recorte de accounts.balance_cents.
+const accountPendingColumn = `COALESCE((SELECT b.pending_cents FROM account_balances b WHERE b.account_id = a.id AND b.mode = 'live' AND b.currency = a.currency), 0)`
+
 // AccountRepository implementa operações de persistência para Account
 type AccountRepository struct {
 	db *sql.DB
@@ -18,41 +21,27 @@ func NewAccountRepository(db *sql.DB) *AccountRepository {
 	return &AccountRepository{db: db}
 }
 
-// Save persiste uma nova conta no banco de dados
+// Save persiste uma nova conta e suas API keys live e test no banco de dados
 // Retorna erro se houver falha na inserção
 func (r *AccountRepository) Save(account *domain.Account) error {
-	keyID := account.APIKeyKeyID
-	var apiKeyHash string
-	var err error
-
-	if keyID == "" {
-		apiKeyHash, keyID, err = security.HashAPIKeyWithActiveKey(account.APIKey)
-		if err != nil {
-			return err
-		}
-		account.APIKeyKeyID = keyID
-	} else {
-		apiKeyHash, err = security.HashAPIKey(account.APIKey, keyID)
-		if err != nil {
-			return err
-		}
+	if account.Currency == "" {
+		account.Currency = domain.DefaultCurrency
 	}
 
-	stmt, err := r.db.Prepare(`
-        INSERT INTO accounts (id, name, email, api_key, api_key_key_id, balance_cents, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-    `)
+	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
-	defer stmt.Close()
+	defer tx.Rollback()
 
-	_, err = stmt.Exec(
+	_, err = tx.Exec(`
+        INSERT INTO accounts (id, name, email, currency, balance_cents, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+    `,
 		account.ID,
 		account.Name,
 		account.Email,
-		apiKeyHash,
-		account.APIKeyKeyID,
+		account.Currency,
 		account.BalanceCents,
 		account.CreatedAt,
 		account.UpdatedAt,
@@ -60,115 +49,97 @@ func (r *AccountRepository) Save(account *domain.Account) error {
 	if err != nil {
 		return err
 	}
-	return nil
+
+	// As chaves da criacao ficam em api_keys com todos os escopos e o mesmo key id.
+	for _, secret := range []string{account.APIKey, account.TestAPIKey} {
+		if secret == "" {
+			continue
+		}
+		key := domain.NewDefaultAPIKey(account.ID, secret, account.CreatedAt)
+		key.KeyID = account.APIKeyKeyID
+		if err := insertAPIKey(tx, key); err != nil {
+			return err
+		}
+		account.APIKeyKeyID = key.KeyID
+		if key.Mode == domain.ModeTest {
+			account.TestAPIKeyKeyID = key.KeyID
+		}
+	}
+
+	// Saldo zerado na moeda padrao, em cada modo, para que ListBalances sempre a retorne.
+	_, err = tx.Exec(`
+        INSERT INTO account_balances (account_id, mode, currency, balance_cents, updated_at)
+        VALUES ($1, 'live', $2, $3, $4), ($1, 'test', $2, 0, $4)
+    `, account.ID, account.Currency, account.BalanceCents, account.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// FindByAPIKey busca uma conta pelo API Key
+// FindByAPIKey busca a conta dona de uma API key ativa (nao revogada nem expirada),
+// testando todos os key ids configurados em uma unica query.
 // Retorna ErrAccountNotFound se não encontrada
 func (r *AccountRepository) FindByAPIKey(apiKey string) (*domain.Account, error) {
-	candidates, err := security.HashAPIKeyCandidates(apiKey)
+	hashes, keyIDs, err := candidateArrays(apiKey)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, candidate := range candidates {
-		var account domain.Account
-		var createdAt, updatedAt time.Time
-
-		err := r.db.QueryRow(`
-			SELECT id, name, email, api_key, api_key_key_id, balance_cents, created_at, updated_at
-			FROM accounts
-			WHERE api_key = $1 AND api_key_key_id = $2
-		`, candidate.Hash, candidate.KeyID).Scan(
-			&account.ID,
-			&account.Name,
-			&account.Email,
-			&account.APIKey,
-			&account.APIKeyKeyID,
-			&account.BalanceCents,
-			&createdAt,
-			&updatedAt,
-		)
-		if err == sql.ErrNoRows {
-			continue
-		}
-		if err != nil {
-			return nil, err
-		}
-
-		account.CreatedAt = createdAt
-		account.UpdatedAt = updatedAt
-		return &account, nil
+	account, err := scanAccount(r.db.QueryRow(`
+		SELECT a.id, a.name, a.email, a.currency, a.balance_cents, `+accountPendingColumn+`, a.created_at, a.updated_at
+		FROM api_keys k
+		JOIN accounts a ON a.id = k.account_id
+		WHERE (k.key_hash, k.key_id) IN (SELECT * FROM unnest($1::text[], $2::text[]))
+		  AND k.revoked_at IS NULL
+		  AND (k.expires_at IS NULL OR k.expires_at > $3)
+	`, hashes, keyIDs, time.Now()))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAccountNotFound
 	}
-
-	return nil, domain.ErrAccountNotFound
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
 }
 
 // FindByEmail busca uma conta pelo email
 // Retorna ErrAccountNotFound se não encontrada
 func (r *AccountRepository) FindByEmail(email string) (*domain.Account, error) {
-	var account domain.Account
-	var createdAt, updatedAt time.Time
-
-	err := r.db.QueryRow(`
-		SELECT id, name, email, api_key, api_key_key_id, balance_cents, created_at, updated_at
-		FROM accounts
-		WHERE email = $1
-	`, email).Scan(
-		&account.ID,
-		&account.Name,
-		&account.Email,
-		&account.APIKey,
-		&account.APIKeyKeyID,
-		&account.BalanceCents,
-		&createdAt,
-		&updatedAt,
-	)
+	account, err := scanAccount(r.db.QueryRow(`
+		SELECT a.id, a.name, a.email, a.currency, a.balance_cents, `+accountPendingColumn+`, a.created_at, a.updated_at
+		FROM accounts a
+		WHERE a.email = $1
+	`, email))
 	if err == sql.ErrNoRows {
 		return nil, domain.ErrAccountNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
-
-	account.CreatedAt = createdAt
-	account.UpdatedAt = updatedAt
-	return &account, nil
+	return account, nil
 }
 
 // FindByID busca uma conta pelo ID
 // Retorna ErrAccountNotFound se não encontrada
 func (r *AccountRepository) FindByID(id string) (*domain.Account, error) {
-	var account domain.Account
-	var createdAt, updatedAt time.Time
-
-	err := r.db.QueryRow(`
-		SELECT id, name, email, api_key, api_key_key_id, balance_cents, created_at, updated_at
-		FROM accounts
-		WHERE id = $1
-	`, id).Scan(
-		&account.ID,
-		&account.Name,
-		&account.Email,
-		&account.APIKey,
-		&account.APIKeyKeyID,
-		&account.BalanceCents,
-		&createdAt,
-		&updatedAt,
-	)
+	account, err := scanAccount(r.db.QueryRow(`
+		SELECT a.id, a.name, a.email, a.currency, a.balance_cents, `+accountPendingColumn+`, a.created_at, a.updated_at
+		FROM accounts a
+		WHERE a.id = $1
+	`, id))
 	if err == sql.ErrNoRows {
 		return nil, domain.ErrAccountNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
-
-	account.CreatedAt = createdAt
-	account.UpdatedAt = updatedAt
-	return &account, nil
+	return account, nil
 }
 
-// UpdateBalance atualiza o saldo da conta usando SELECT FOR UPDATE para consistência em acessos concorrentes
+// UpdateBalance ajusta o saldo da conta para account.BalanceCents lancando a diferenca no ledger.
+// Usa SELECT FOR UPDATE para consistência em acessos concorrentes.
 // Retorna ErrAccountNotFound se a conta não existir
 func (r *AccountRepository) UpdateBalance(account *domain.Account) error {
 	tx, err := r.db.Begin()
@@ -179,8 +150,9 @@ func (r *AccountRepository) UpdateBalance(account *domain.Account) error {
 
 	// SELECT FOR UPDATE previne race conditions no saldo
 	var currentBalance int64
-	err = tx.QueryRow(`SELECT balance_cents FROM accounts WHERE id = $1 FOR UPDATE`,
-		account.ID).Scan(&currentBalance)
+	var currency string
+	err = tx.QueryRow(`SELECT balance_cents, currency FROM accounts WHERE id = $1 FOR UPDATE`,
+		account.ID).Scan(&currentBalance, &currency)
 
 	if err == sql.ErrNoRows {
 		return domain.ErrAccountNotFound
@@ -189,41 +161,87 @@ func (r *AccountRepository) UpdateBalance(account *domain.Account) error {
 		return err
 	}
 
-	_, err = tx.Exec(`
-        UPDATE accounts
-        SET balance_cents = $1, updated_at = $2
-        WHERE id = $3
-    `, account.BalanceCents, time.Now(), account.ID)
-	if err != nil {
+	delta := account.BalanceCents - currentBalance
+	if delta == 0 {
+		return tx.Commit()
+	}
+
+	entry := ledger.Transfer(account.ID, "", currency, ledger.EntryBalanceAdjustment,
+		ledger.AccountManualAdjustment, ledger.AccountMerchantBalance, delta)
+	if err := ledger.Post(tx, entry); err != nil {
 		return err
 	}
 	return tx.Commit()
 }
 
-// AddBalance atualiza o saldo somando o amount de forma atômica.
+// AddBalance soma amountCents ao saldo da conta, na moeda padrao, por meio de um lancamento de ajuste.
 func (r *AccountRepository) AddBalance(accountID string, amountCents int64) error {
+	if amountCents == 0 {
+		return nil
+	}
+
 	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	result, err := tx.Exec(`
-        UPDATE accounts
-        SET balance_cents = balance_cents + $1, updated_at = $2
-        WHERE id = $3
-    `, amountCents, time.Now(), accountID)
+	var currency string
+	err = tx.QueryRow(`SELECT currency FROM accounts WHERE id = $1`, accountID).Scan(&currency)
+	if err == sql.ErrNoRows {
+		return domain.ErrAccountNotFound
+	}
 	if err != nil {
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
+	entry := ledger.Transfer(accountID, "", currency, ledger.EntryBalanceAdjustment,
+		ledger.AccountManualAdjustment, ledger.AccountMerchantBalance, amountCents)
+	if err := ledger.Post(tx, entry); err != nil {
 		return err
 	}
-	if rowsAffected == 0 {
-		return domain.ErrAccountNotFound
-	}
 
 	return tx.Commit()
 }
+
+// ListBalances retorna os saldos da conta em cada moeda no modo informado.
+func (r *AccountRepository) ListBalances(accountID string, mode domain.Mode) ([]domain.Balance, error) {
+	rows, err := r.db.Query(`
+		SELECT currency, balance_cents, pending_cents, updated_at
+		FROM account_balances
+		WHERE account_id = $1 AND mode = $2
+		ORDER BY currency
+	`, accountID, mode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := make([]domain.Balance, 0)
+	for rows.Next() {
+		var balance domain.Balance
+		if err := rows.Scan(&balance.Currency, &balance.BalanceCents, &balance.PendingCents, &balance.UpdatedAt); err != nil {
+			return nil, err
+		}
+		balances = append(balances, balance)
+	}
+	return balances, rows.Err()
+}
+
+func scanAccount(row rowScanner) (*domain.Account, error) {
+	var account domain.Account
+	err := row.Scan(
+		&account.ID,
+		&account.Name,
+		&account.Email,
+		&account.Currency,
+		&account.BalanceCents,
+		&account.PendingCents,
+		&account.CreatedAt,
+		&account.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
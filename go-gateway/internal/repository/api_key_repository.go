@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/security"
+	"github.com/lib/pq"
+)
+
+const apiKeyColumns = `id, account_id, mode, label, key_hash, key_id, last4, scopes, created_at, last_used_at, expires_at, revoked_at`
+
+// lastUsedResolution evita uma escrita por requisicao ao registrar o uso da chave.
+const lastUsedResolution = time.Minute
+
+// KeyIDUsage resume quantas contas e chaves dependem de um key id.
+type KeyIDUsage struct {
+	KeyID    string
+	Accounts int
+	Keys     int
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// APIKeyRepository implementa operações de persistência para APIKey
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository cria um novo repositório de API keys
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Save persiste uma nova chave guardando apenas o hash do segredo
+func (r *APIKeyRepository) Save(key *domain.APIKey) error {
+	return insertAPIKey(r.db, key)
+}
+
+// insertAPIKey calcula o hash do segredo (com o key id da chave ou o ativo) e insere a chave.
+func insertAPIKey(db execer, key *domain.APIKey) error {
+	if key.KeyID == "" {
+		hash, keyID, err := security.HashAPIKeyWithActiveKey(key.Secret)
+		if err != nil {
+			return err
+		}
+		key.Hash, key.KeyID = hash, keyID
+	} else {
+		hash, err := security.HashAPIKey(key.Secret, key.KeyID)
+		if err != nil {
+			return err
+		}
+		key.Hash = hash
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO api_keys (id, account_id, mode, label, key_hash, key_id, last4, scopes, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`,
+		key.ID,
+		key.AccountID,
+		key.Mode,
+		key.Label,
+		key.Hash,
+		key.KeyID,
+		key.Last4,
+		pq.Array(key.Scopes),
+		key.CreatedAt,
+		key.ExpiresAt,
+	)
+	return err
+}
+
+// FindByKey busca a chave pelo segredo, testando todos os key ids configurados em uma unica query.
+// Retorna ErrAPIKeyNotFound se não encontrada; revogação e expiração ficam com o chamador.
+func (r *APIKeyRepository) FindByKey(secret string) (*domain.APIKey, error) {
+	hashes, keyIDs, err := candidateArrays(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := scanAPIKey(r.db.QueryRow(`
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE (key_hash, key_id) IN (SELECT * FROM unnest($1::text[], $2::text[]))
+	`, hashes, keyIDs))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// RehashIfStale regrava o hash sob o key id ativo quando a chave ainda usa um segredo antigo.
+// Assim os segredos antigos podem ser removidos quando nenhuma chave depender deles.
+func (r *APIKeyRepository) RehashIfStale(key *domain.APIKey, secret string) error {
+	activeKeyID, err := security.ActiveKeyID()
+	if err != nil {
+		return err
+	}
+	if key.KeyID == activeKeyID {
+		return nil
+	}
+
+	hash, err := security.HashAPIKey(secret, activeKeyID)
+	if err != nil {
+		return err
+	}
+
+	// O filtro pelo key id antigo torna a atualizacao idempotente entre requisicoes concorrentes.
+	_, err = r.db.Exec(`
+		UPDATE api_keys
+		SET key_hash = $1, key_id = $2
+		WHERE id = $3 AND key_id = $4
+	`, hash, activeKeyID, key.ID, key.KeyID)
+	if err != nil {
+		return err
+	}
+
+	key.Hash, key.KeyID = hash, activeKeyID
+	return nil
+}
+
+// CountByKeyID conta, por key id, as contas e chaves que ainda podem autenticar (nao revogadas nem expiradas).
+func (r *APIKeyRepository) CountByKeyID(ctx context.Context) ([]KeyIDUsage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT key_id, COUNT(DISTINCT account_id), COUNT(1)
+		FROM api_keys
+		WHERE revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $1)
+		GROUP BY key_id
+		ORDER BY key_id
+	`, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := make([]KeyIDUsage, 0)
+	for rows.Next() {
+		var item KeyIDUsage
+		if err := rows.Scan(&item.KeyID, &item.Accounts, &item.Keys); err != nil {
+			return nil, err
+		}
+		usage = append(usage, item)
+	}
+	return usage, rows.Err()
+}
+
+// ListByAccountID lista as chaves da conta no modo informado, das mais recentes para as mais antigas
+func (r *APIKeyRepository) ListByAccountID(accountID string, mode domain.Mode) ([]*domain.APIKey, error) {
+	rows, err := r.db.Query(`
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE account_id = $1 AND mode = $2
+		ORDER BY created_at DESC, id DESC
+	`, accountID, mode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]*domain.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// Revoke revoga a chave da conta. Revogar de novo mantem a data original.
+// Retorna ErrAPIKeyNotFound se a chave não pertencer à conta e ao modo.
+func (r *APIKeyRepository) Revoke(accountID string, mode domain.Mode, id string, at time.Time) (*domain.APIKey, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	key, err := scanAPIKey(tx.QueryRow(`
+		UPDATE api_keys
+		SET revoked_at = COALESCE(revoked_at, $4)
+		WHERE id = $1 AND account_id = $2 AND mode = $3
+		RETURNING `+apiKeyColumns+`
+	`, id, accountID, mode, at))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// O aviso sai no commit; cada replica remove a chave do proprio cache.
+	if _, err := tx.Exec(`SELECT pg_notify($1, $2)`, domain.APIKeyRevokedChannel, key.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// GrantScopes acrescenta a chave os escopos que ela ainda nao tem, mantendo a ordem dos atuais.
+// Retorna ErrAPIKeyNotFound se a chave nao pertencer a conta ou estiver revogada.
+func (r *APIKeyRepository) GrantScopes(accountID, id string, scopes []string) (*domain.APIKey, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	key, err := scanAPIKey(tx.QueryRow(`
+		UPDATE api_keys
+		SET scopes = scopes || ARRAY(
+			SELECT scope FROM unnest($3::text[]) WITH ORDINALITY AS granted(scope, position)
+			WHERE NOT scope = ANY(api_keys.scopes)
+			ORDER BY position
+		)
+		WHERE id = $1 AND account_id = $2 AND revoked_at IS NULL
+		RETURNING `+apiKeyColumns+`
+	`, id, accountID, pq.Array(scopes)))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Como na revogacao, cada replica descarta a chave do cache e recarrega os escopos.
+	if _, err := tx.Exec(`SELECT pg_notify($1, $2)`, domain.APIKeyRevokedChannel, key.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// TouchLastUsed registra o uso da chave, no maximo uma vez por lastUsedResolution
+func (r *APIKeyRepository) TouchLastUsed(id string, at time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE api_keys
+		SET last_used_at = $2
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $3)
+	`, id, at, at.Add(-lastUsedResolution))
+	return err
+}
+
+// candidateArrays retorna os hashes e key ids candidatos como arrays para unnest.
+func candidateArrays(secret string) (any, any, error) {
+	candidates, err := security.HashAPIKeyCandidates(secret)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, len(candidates))
+	keyIDs := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		hashes[i] = candidate.Hash
+		keyIDs[i] = candidate.KeyID
+	}
+	return pq.Array(hashes), pq.Array(keyIDs), nil
+}
+
+func scanAPIKey(scanner rowScanner) (*domain.APIKey, error) {
+	var key domain.APIKey
+	var mode string
+	var lastUsedAt, expiresAt, revokedAt sql.NullTime
+
+	err := scanner.Scan(
+		&key.ID,
+		&key.AccountID,
+		&mode,
+		&key.Label,
+		&key.Hash,
+		&key.KeyID,
+		&key.Last4,
+		pq.Array(&key.Scopes),
+		&key.CreatedAt,
+		&lastUsedAt,
+		&expiresAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Mode = domain.Mode(mode)
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return &key, nil
+}
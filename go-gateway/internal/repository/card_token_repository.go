@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/security"
+)
+
+const cardTokenColumns = `id, account_id, mode, brand, bin, last4, expiry_month, expiry_year, cardholder_name, encrypted_pan, key_id, customer_id, created_at`
+
+// CardTokenRepository persiste os tokens do cofre de cartoes. O PAN so entra e sai do banco cifrado.
+type CardTokenRepository struct {
+	db *sql.DB
+}
+
+// NewCardTokenRepository cria um novo repositório de tokens de cartão
+func NewCardTokenRepository(db *sql.DB) *CardTokenRepository {
+	return &CardTokenRepository{db: db}
+}
+
+// Save cifra o PAN com a chave ativa do cofre (o ID do token e o dado associado) e grava o token.
+func (r *CardTokenRepository) Save(token *domain.CardToken) error {
+	ciphertext, keyID, err := security.EncryptCardData([]byte(token.Number), []byte(token.ID))
+	if err != nil {
+		return vaultError(err)
+	}
+	token.Ciphertext, token.KeyID = ciphertext, keyID
+
+	_, err = r.db.Exec(`
+		INSERT INTO card_tokens (id, account_id, mode, brand, bin, last4, expiry_month, expiry_year, cardholder_name, encrypted_pan, key_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`,
+		token.ID,
+		token.AccountID,
+		token.Mode,
+		token.Brand,
+		token.BIN,
+		token.Last4,
+		token.ExpiryMonth,
+		token.ExpiryYear,
+		token.CardholderName,
+		token.Ciphertext,
+		token.KeyID,
+		token.CreatedAt,
+	)
+	return err
+}
+
+// FindByID busca o token sem decifrar o PAN. Retorna ErrCardTokenNotFound se não existir.
+func (r *CardTokenRepository) FindByID(id string) (*domain.CardToken, error) {
+	token, err := scanCardToken(r.db.QueryRow(`SELECT `+cardTokenColumns+` FROM card_tokens WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrCardTokenNotFound
+	}
+	return token, err
+}
+
+// Reveal decifra o PAN em token.Number.
+func (r *CardTokenRepository) Reveal(token *domain.CardToken) error {
+	plaintext, err := security.DecryptCardData(token.Ciphertext, token.KeyID, []byte(token.ID))
+	if err != nil {
+		return vaultError(err)
+	}
+	token.Number = string(plaintext)
+	return nil
+}
+
+// RekeyIfStale recifra o PAN ja revelado com a chave ativa quando o token usa um key id antigo.
+func (r *CardTokenRepository) RekeyIfStale(token *domain.CardToken) error {
+	activeKeyID, err := security.CardVaultActiveKeyID()
+	if err != nil {
+		return vaultError(err)
+	}
+	if token.KeyID == activeKeyID || token.Number == "" {
+		return nil
+	}
+	return rekeyCardToken(r.db, token)
+}
+
+// AttachToCustomer salva o token no cliente. Salvar de novo no mesmo cliente nao e erro.
+func (r *CardTokenRepository) AttachToCustomer(tokenID, customerID string) error {
+	result, err := r.db.Exec(`
+		UPDATE card_tokens
+		SET customer_id = $1
+		WHERE id = $2 AND (customer_id IS NULL OR customer_id = $1)
+	`, customerID, tokenID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, domain.ErrCardTokenAttached)
+}
+
+// DetachFromCustomer remove o token dos meios de pagamento do cliente sem apagar o token.
+func (r *CardTokenRepository) DetachFromCustomer(tokenID, customerID string) error {
+	result, err := r.db.Exec(`
+		UPDATE card_tokens SET customer_id = NULL WHERE id = $1 AND customer_id = $2
+	`, tokenID, customerID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, domain.ErrCardTokenNotFound)
+}
+
+// ListByCustomerID lista os tokens salvos no cliente, do mais recente para o mais antigo.
+func (r *CardTokenRepository) ListByCustomerID(customerID string) ([]*domain.CardToken, error) {
+	rows, err := r.db.Query(`
+		SELECT `+cardTokenColumns+`
+		FROM card_tokens
+		WHERE customer_id = $1
+		ORDER BY created_at DESC, id DESC
+	`, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make([]*domain.CardToken, 0)
+	for rows.Next() {
+		token, err := scanCardToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// RekeyStale recifra com a chave ativa ate limit tokens ainda presos a key ids antigos.
+// Retorna quantos tokens foram migrados; zero indica que a rotacao terminou.
+func (r *CardTokenRepository) RekeyStale(ctx context.Context, limit int) (int, error) {
+	activeKeyID, err := security.CardVaultActiveKeyID()
+	if err != nil {
+		return 0, vaultError(err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+cardTokenColumns+`
+		FROM card_tokens
+		WHERE key_id <> $1
+		ORDER BY id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, activeKeyID, limit)
+	if err != nil {
+		return 0, err
+	}
+	tokens := make([]*domain.CardToken, 0)
+	for rows.Next() {
+		token, err := scanCardToken(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		tokens = append(tokens, token)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, token := range tokens {
+		plaintext, err := security.DecryptCardData(token.Ciphertext, token.KeyID, []byte(token.ID))
+		if err != nil {
+			return 0, err
+		}
+		token.Number = string(plaintext)
+		if err := rekeyCardToken(tx, token); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(tokens), nil
+}
+
+// rekeyCardToken cifra token.Number com a chave ativa. O filtro por key_id evita sobrescrever
+// uma recifragem concorrente.
+func rekeyCardToken(db execer, token *domain.CardToken) error {
+	ciphertext, keyID, err := security.EncryptCardData([]byte(token.Number), []byte(token.ID))
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		UPDATE card_tokens
+		SET encrypted_pan = $1, key_id = $2
+		WHERE id = $3 AND key_id = $4
+	`, ciphertext, keyID, token.ID, token.KeyID)
+	if err != nil {
+		return err
+	}
+
+	token.Ciphertext, token.KeyID = ciphertext, keyID
+	return nil
+}
+
+// vaultError traduz a falta de chaves do cofre para o erro de dominio.
+func vaultError(err error) error {
+	if errors.Is(err, security.ErrCardVaultNotConfigured) {
+		return domain.ErrCardVaultDisabled
+	}
+	return err
+}
+
+func scanCardToken(row rowScanner) (*domain.CardToken, error) {
+	var token domain.CardToken
+	var customerID sql.NullString
+	err := row.Scan(
+		&token.ID,
+		&token.AccountID,
+		&token.Mode,
+		&token.Brand,
+		&token.BIN,
+		&token.Last4,
+		&token.ExpiryMonth,
+		&token.ExpiryYear,
+		&token.CardholderName,
+		&token.Ciphertext,
+		&token.KeyID,
+		&customerID,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	token.CustomerID = customerID.String
+	return &token, nil
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/lib/pq"
+)
+
+const customerColumns = `id, account_id, mode, name, email, document, document_type, external_reference, created_at, updated_at`
+
+// CustomerRepository persiste os clientes das contas. Clientes removidos ficam com deleted_at
+// para que as faturas continuem apontando para eles.
+type CustomerRepository struct {
+	db *sql.DB
+}
+
+// NewCustomerRepository cria um novo repositório de clientes
+func NewCustomerRepository(db *sql.DB) *CustomerRepository {
+	return &CustomerRepository{db: db}
+}
+
+func (r *CustomerRepository) Save(customer *domain.Customer) error {
+	_, err := r.db.Exec(`
+		INSERT INTO customers (id, account_id, mode, name, email, document, document_type, external_reference, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`,
+		customer.ID,
+		customer.AccountID,
+		customer.Mode,
+		customer.Name,
+		customer.Email,
+		customer.Document,
+		customer.DocumentType,
+		customer.ExternalReference,
+		customer.CreatedAt,
+		customer.UpdatedAt,
+	)
+	return customerError(err)
+}
+
+func (r *CustomerRepository) FindByID(id string) (*domain.Customer, error) {
+	customer, err := scanCustomer(r.db.QueryRow(`
+		SELECT `+customerColumns+` FROM customers WHERE id = $1 AND deleted_at IS NULL
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrCustomerNotFound
+	}
+	return customer, err
+}
+
+func (r *CustomerRepository) Update(customer *domain.Customer) error {
+	result, err := r.db.Exec(`
+		UPDATE customers
+		SET name = $1, email = $2, document = $3, document_type = $4, external_reference = $5, updated_at = $6
+		WHERE id = $7 AND deleted_at IS NULL
+	`,
+		customer.Name,
+		customer.Email,
+		customer.Document,
+		customer.DocumentType,
+		customer.ExternalReference,
+		customer.UpdatedAt,
+		customer.ID,
+	)
+	if err != nil {
+		return customerError(err)
+	}
+	return requireAffected(result, domain.ErrCustomerNotFound)
+}
+
+// Delete marca o cliente como removido e desvincula os cartoes salvos nele na mesma transacao.
+func (r *CustomerRepository) Delete(id string, at time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE customers SET deleted_at = $1, updated_at = $1 WHERE id = $2 AND deleted_at IS NULL
+	`, at, id)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result, domain.ErrCustomerNotFound); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE card_tokens SET customer_id = NULL WHERE customer_id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListByAccountID lista os clientes da conta com paginacao por keyset em (created_at, id),
+// do mais recente para o mais antigo.
+func (r *CustomerRepository) ListByAccountID(accountID string, filter domain.CustomerFilter) (*domain.CustomerPage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = domain.DefaultCustomerPageSize
+	}
+	if limit > domain.MaxCustomerPageSize {
+		limit = domain.MaxCustomerPageSize
+	}
+
+	conditions := []string{"account_id = $1", "deleted_at IS NULL"}
+	args := []any{accountID}
+	addCondition := func(format string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if filter.Mode != "" {
+		addCondition("mode = $%d", filter.Mode)
+	}
+	if filter.Email != "" {
+		addCondition("email = $%d", filter.Email)
+	}
+	if filter.ExternalReference != "" {
+		addCondition("external_reference = $%d", filter.ExternalReference)
+	}
+	if filter.Cursor != nil {
+		args = append(args, filter.Cursor.CreatedAt, filter.Cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	// Busca um item a mais para saber se existe proxima pagina.
+	args = append(args, limit+1)
+	query := fmt.Sprintf(
+		`SELECT `+customerColumns+` FROM customers WHERE %s ORDER BY created_at DESC, id DESC LIMIT $%d`,
+		strings.Join(conditions, " AND "),
+		len(args),
+	)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &domain.CustomerPage{Customers: make([]*domain.Customer, 0, limit)}
+	for rows.Next() {
+		customer, err := scanCustomer(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Customers = append(page.Customers, customer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Customers) > limit {
+		page.Customers = page.Customers[:limit]
+		last := page.Customers[limit-1]
+		page.NextCursor = &domain.InvoiceCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	return page, nil
+}
+
+// customerError traduz a violacao do indice unico de external_reference.
+func customerError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return domain.ErrCustomerReferenceConflict
+	}
+	return err
+}
+
+func requireAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+func scanCustomer(row rowScanner) (*domain.Customer, error) {
+	var customer domain.Customer
+	err := row.Scan(
+		&customer.ID,
+		&customer.AccountID,
+		&customer.Mode,
+		&customer.Name,
+		&customer.Email,
+		&customer.Document,
+		&customer.DocumentType,
+		&customer.ExternalReference,
+		&customer.CreatedAt,
+		&customer.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
@@ -1,34 +1,191 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/ledger"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/webhook"
+	"github.com/lib/pq"
 )
 
 type InvoiceRepository struct {
 	db *sql.DB
+	// settlement define quando os valores aprovados ficam disponiveis; nil libera na aprovacao.
+	settlement domain.SettlementSchedule
+	// scope e a transacao de BeginLimitScope; nil fora de um escopo de limite.
+	scope *sql.Tx
+}
+
+// invoiceColumns lista as colunas lidas por scanInvoice, na mesma ordem.
+const invoiceColumns = `id, account_id, mode, currency, amount_cents, captured_cents, refunded_cents, capture_method, status, description, payment_type, card_last_digits, card_brand, card_bin, card_funding, card_country, customer_id, installments, fee_cents, net_cents, fee_breakdown, manual_review, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanInvoice(row rowScanner) (*domain.Invoice, error) {
+	var invoice domain.Invoice
+	var customerID sql.NullString
+	var fee []byte
+	err := row.Scan(
+		&invoice.ID,
+		&invoice.AccountID,
+		&invoice.Mode,
+		&invoice.Currency,
+		&invoice.AmountCents,
+		&invoice.CapturedCents,
+		&invoice.RefundedCents,
+		&invoice.CaptureMethod,
+		&invoice.Status,
+		&invoice.Description,
+		&invoice.PaymentType,
+		&invoice.CardLastDigits,
+		&invoice.CardBrand,
+		&invoice.CardBIN,
+		&invoice.CardFunding,
+		&invoice.CardCountry,
+		&customerID,
+		&invoice.Installments,
+		&invoice.FeeCents,
+		&invoice.NetCents,
+		&fee,
+		&invoice.ManualReview,
+		&invoice.CreatedAt,
+		&invoice.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	invoice.CustomerID = customerID.String
+	if len(fee) > 0 {
+		if err := json.Unmarshal(fee, &invoice.Fee); err != nil {
+			return nil, err
+		}
+	}
+	return &invoice, nil
 }
 
 func NewInvoiceRepository(db *sql.DB) *InvoiceRepository {
 	return &InvoiceRepository{db: db}
 }
 
-// Save salva uma fatura no banco de dados e registra eventos iniciais.
-func (r *InvoiceRepository) Save(invoice *domain.Invoice, requestID string) error {
+// WithSettlementSchedule define a espera, por tipo de pagamento, ate os valores aprovados
+// passarem de pendentes para disponiveis.
+func (r *InvoiceRepository) WithSettlementSchedule(schedule domain.SettlementSchedule) *InvoiceRepository {
+	r.settlement = schedule
+	return r
+}
+
+// limitScope e o InvoiceRepository preso a transacao de BeginLimitScope.
+type limitScope struct {
+	*InvoiceRepository
+}
+
+func (s limitScope) Commit() error   { return s.scope.Commit() }
+func (s limitScope) Rollback() error { return s.scope.Rollback() }
+
+// BeginLimitScope abre uma transacao e obtem nela pg_advisory_xact_lock(key): o lock usa a mesma
+// conexao em que a fatura e gravada e sai no Commit ou Rollback. A espera respeita o prazo de ctx;
+// sem o lock a tempo, retorna domain.ErrLimitBusy.
+func (r *InvoiceRepository) BeginLimitScope(ctx context.Context, key string) (domain.LimitScope, error) {
+	// O prazo de ctx vale so para o lock; a transacao segue ate o Commit.
+	tx, err := r.db.BeginTx(context.WithoutCancel(ctx), nil)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout := time.Until(deadline).Milliseconds()
+		if timeout < 1 {
+			timeout = 1
+		}
+		if _, err := tx.Exec(`SELECT set_config('lock_timeout', $1, true)`, fmt.Sprintf("%dms", timeout)); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, key); err != nil {
+		tx.Rollback()
+		if lockUnavailable(ctx, err) {
+			return nil, domain.ErrLimitBusy
+		}
+		return nil, err
+	}
+	if _, err := tx.Exec(`SET LOCAL lock_timeout TO DEFAULT`); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	scoped := *r
+	scoped.scope = tx
+	return limitScope{&scoped}, nil
+}
+
+// lockUnavailable identifica lock_timeout (55P03) e cancelamento da espera pelo lock.
+func lockUnavailable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && (pqErr.Code == "55P03" || pqErr.Code == "57014")
+}
+
+// writeTx e a transacao de uma gravacao. Dentro de um escopo de limite e a transacao do escopo,
+// e commit e rollback ficam para o escopo.
+type writeTx struct {
+	*sql.Tx
+	scoped bool
+}
+
+func (t writeTx) commit() error {
+	if t.scoped {
+		return nil
+	}
+	return t.Commit()
+}
+
+func (t writeTx) rollback() {
+	if !t.scoped {
+		t.Rollback()
+	}
+}
+
+// begin abre a transacao de uma gravacao ou reaproveita a do escopo de limite.
+func (r *InvoiceRepository) begin() (writeTx, error) {
+	if r.scope != nil {
+		return writeTx{Tx: r.scope, scoped: true}, nil
+	}
 	tx, err := r.db.Begin()
+	return writeTx{Tx: tx}, err
+}
+
+// querier retorna a transacao do escopo de limite, se houver, ou o pool.
+func (r *InvoiceRepository) querier() interface {
+	QueryRow(query string, args ...any) *sql.Row
+} {
+	if r.scope != nil {
+		return r.scope
+	}
+	return r.db
+}
+
+// Save salva uma fatura no banco de dados e registra eventos iniciais.
+func (r *InvoiceRepository) Save(invoice *domain.Invoice, requestID string, fraudMetadata map[string]any) error {
+	write, err := r.begin()
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
+	defer write.rollback()
+	tx := write.Tx
 
-	_, err = tx.Exec(
-		"INSERT INTO invoices (id, account_id, amount_cents, status, description, payment_type, card_last_digits, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
-		invoice.ID, invoice.AccountID, invoice.AmountCents, invoice.Status, invoice.Description, invoice.PaymentType, invoice.CardLastDigits, invoice.CreatedAt, invoice.UpdatedAt,
-	)
-	if err != nil {
+	if err := r.insertInvoice(tx, invoice); err != nil {
 		return err
 	}
 
@@ -36,33 +193,47 @@ func (r *InvoiceRepository) Save(invoice *domain.Invoice, requestID string) erro
 		return err
 	}
 
+	if err := r.insertFraudEvaluated(tx, invoice, fraudMetadata, requestID); err != nil {
+		return err
+	}
+
 	switch invoice.Status {
 	case domain.StatusApproved:
 		if err := r.insertInvoiceEvent(tx, invoice.ID, "approved", nil, &invoice.Status, nil, requestID); err != nil {
 			return err
 		}
+		// Aprovacao imediata credita o saldo na mesma transacao da fatura.
+		availableAt, err := r.creditInvoice(tx, invoice, ledger.EntryInvoiceApproved)
+		if err != nil {
+			return err
+		}
+		metadata := creditMetadata(invoice, availableAt)
+		if err := r.insertInvoiceEvent(tx, invoice.ID, "balance_applied", &invoice.Status, &invoice.Status, metadata, requestID); err != nil {
+			return err
+		}
+	case domain.StatusAuthorized:
+		if err := r.insertInvoiceEvent(tx, invoice.ID, "authorized", nil, &invoice.Status, nil, requestID); err != nil {
+			return err
+		}
 	case domain.StatusRejected:
 		if err := r.insertInvoiceEvent(tx, invoice.ID, "rejected", nil, &invoice.Status, nil, requestID); err != nil {
 			return err
 		}
 	}
 
-	return tx.Commit()
+	return write.commit()
 }
 
 // SaveWithOutbox salva a fatura e cria um evento de outbox na mesma transacao.
-func (r *InvoiceRepository) SaveWithOutbox(invoice *domain.Invoice, eventType string, payload []byte, correlationID string) error {
-	tx, err := r.db.Begin()
+func (r *InvoiceRepository) SaveWithOutbox(invoice *domain.Invoice, eventType string, payload []byte, correlationID string, fraudMetadata map[string]any) error {
+	write, err := r.begin()
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
+	defer write.rollback()
+	tx := write.Tx
 
-	_, err = tx.Exec(
-		"INSERT INTO invoices (id, account_id, amount_cents, status, description, payment_type, card_last_digits, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
-		invoice.ID, invoice.AccountID, invoice.AmountCents, invoice.Status, invoice.Description, invoice.PaymentType, invoice.CardLastDigits, invoice.CreatedAt, invoice.UpdatedAt,
-	)
-	if err != nil {
+	if err := r.insertInvoice(tx, invoice); err != nil {
 		return err
 	}
 
@@ -70,6 +241,10 @@ func (r *InvoiceRepository) SaveWithOutbox(invoice *domain.Invoice, eventType st
 		return err
 	}
 
+	if err := r.insertFraudEvaluated(tx, invoice, fraudMetadata, correlationID); err != nil {
+		return err
+	}
+
 	_, err = tx.Exec(
 		`INSERT INTO outbox_events (id, aggregate_id, type, payload, status, attempts, next_attempt_at, correlation_id, created_at, updated_at)
          VALUES (gen_random_uuid(), $1, $2, $3, 'pending', 0, NOW(), $4, NOW(), NOW())`,
@@ -83,28 +258,20 @@ func (r *InvoiceRepository) SaveWithOutbox(invoice *domain.Invoice, eventType st
 		return err
 	}
 
-	return tx.Commit()
+	return write.commit()
+}
+
+// insertFraudEvaluated registra pontuacao, decisao e motivos do motor antifraude.
+func (r *InvoiceRepository) insertFraudEvaluated(tx *sql.Tx, invoice *domain.Invoice, metadata map[string]any, requestID string) error {
+	if metadata == nil {
+		return nil
+	}
+	return r.insertInvoiceEvent(tx, invoice.ID, "fraud_evaluated", &invoice.Status, &invoice.Status, metadata, requestID)
 }
 
 // FindByID busca uma fatura pelo ID
 func (r *InvoiceRepository) FindByID(id string) (*domain.Invoice, error) {
-	var invoice domain.Invoice
-	err := r.db.QueryRow(`
-		SELECT id, account_id, amount_cents, status, description, payment_type, card_last_digits, created_at, updated_at
-		FROM invoices
-		WHERE id = $1
-	`, id).Scan(
-		&invoice.ID,
-		&invoice.AccountID,
-		&invoice.AmountCents,
-		&invoice.Status,
-		&invoice.Description,
-		&invoice.PaymentType,
-		&invoice.CardLastDigits,
-		&invoice.CreatedAt,
-		&invoice.UpdatedAt,
-	)
-
+	invoice, err := scanInvoice(r.db.QueryRow(`SELECT `+invoiceColumns+` FROM invoices WHERE id = $1`, id))
 	if err == sql.ErrNoRows {
 		return nil, domain.ErrInvoiceNotFound
 	}
@@ -113,16 +280,12 @@ func (r *InvoiceRepository) FindByID(id string) (*domain.Invoice, error) {
 		return nil, err
 	}
 
-	return &invoice, nil
+	return invoice, nil
 }
 
 // FindByAccountID busca todas as faturas de um determinado accountID
 func (r *InvoiceRepository) FindByAccountID(accountID string) ([]*domain.Invoice, error) {
-	rows, err := r.db.Query(`
-		SELECT id, account_id, amount_cents, status, description, payment_type, card_last_digits, created_at, updated_at
-		FROM invoices
-		WHERE account_id = $1
-	`, accountID)
+	rows, err := r.db.Query(`SELECT `+invoiceColumns+` FROM invoices WHERE account_id = $1`, accountID)
 	if err != nil {
 		return nil, err
 	}
@@ -131,28 +294,116 @@ func (r *InvoiceRepository) FindByAccountID(accountID string) ([]*domain.Invoice
 
 	var invoices []*domain.Invoice
 	for rows.Next() {
-		var invoice domain.Invoice
-		err := rows.Scan(
-			&invoice.ID, &invoice.AccountID, &invoice.AmountCents, &invoice.Status, &invoice.Description, &invoice.PaymentType, &invoice.CardLastDigits, &invoice.CreatedAt, &invoice.UpdatedAt,
-		)
+		invoice, err := scanInvoice(rows)
 		if err != nil {
 			return nil, err
 		}
 
-		invoices = append(invoices, &invoice)
+		invoices = append(invoices, invoice)
 	}
 
 	return invoices, nil
 }
 
-// GetDailyUsage retorna total e contagem de invoices criadas no intervalo informado.
-func (r *InvoiceRepository) GetDailyUsage(accountID string, start, end time.Time) (*domain.DailyUsage, error) {
-	var usage domain.DailyUsage
-	err := r.db.QueryRow(`
+// ListByAccountID lista faturas da conta aplicando filtros e paginacao por keyset
+// em (created_at, id), da mais recente para a mais antiga.
+func (r *InvoiceRepository) ListByAccountID(accountID string, filter domain.InvoiceFilter) (*domain.InvoicePage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = domain.DefaultInvoicePageSize
+	}
+	if limit > domain.MaxInvoicePageSize {
+		limit = domain.MaxInvoicePageSize
+	}
+
+	conditions := []string{"account_id = $1"}
+	args := []any{accountID}
+	addCondition := func(format string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if filter.Mode != "" {
+		addCondition("mode = $%d", filter.Mode)
+	}
+	if filter.Status != "" {
+		addCondition("status = $%d", filter.Status)
+	}
+	if filter.Currency != "" {
+		addCondition("currency = $%d", filter.Currency)
+	}
+	if filter.PaymentType != "" {
+		addCondition("payment_type = $%d", filter.PaymentType)
+	}
+	if filter.CardBrand != "" {
+		addCondition("card_brand = $%d", filter.CardBrand)
+	}
+	if filter.CustomerID != "" {
+		addCondition("customer_id = $%d", filter.CustomerID)
+	}
+	if filter.CreatedFrom != nil {
+		addCondition("created_at >= $%d", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		addCondition("created_at < $%d", *filter.CreatedTo)
+	}
+	if filter.MinAmountCents != nil {
+		addCondition("amount_cents >= $%d", *filter.MinAmountCents)
+	}
+	if filter.MaxAmountCents != nil {
+		addCondition("amount_cents <= $%d", *filter.MaxAmountCents)
+	}
+	if filter.Cursor != nil {
+		args = append(args, filter.Cursor.CreatedAt, filter.Cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	// Busca um item a mais para saber se existe proxima pagina.
+	args = append(args, limit+1)
+	query := fmt.Sprintf(
+		`SELECT `+invoiceColumns+` FROM invoices WHERE %s ORDER BY created_at DESC, id DESC LIMIT $%d`,
+		strings.Join(conditions, " AND "),
+		len(args),
+	)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &domain.InvoicePage{Invoices: make([]*domain.Invoice, 0, limit)}
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Invoices = append(page.Invoices, invoice)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Invoices) > limit {
+		page.Invoices = page.Invoices[:limit]
+		last := page.Invoices[limit-1]
+		page.NextCursor = &domain.InvoiceCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	return page, nil
+}
+
+// GetUsage retorna total e contagem de invoices do modo e da moeda criadas no intervalo [start, end).
+// paymentType vazio considera todos os tipos de pagamento. O intervalo e convertido para UTC
+// porque created_at nao guarda fuso.
+func (r *InvoiceRepository) GetUsage(accountID string, mode domain.Mode, currency, paymentType string, start, end time.Time) (*domain.LimitUsage, error) {
+	var usage domain.LimitUsage
+	err := r.querier().QueryRow(`
 		SELECT COALESCE(SUM(amount_cents), 0), COALESCE(COUNT(1), 0)
 		FROM invoices
-		WHERE account_id = $1 AND created_at >= $2 AND created_at < $3
-	`, accountID, start, end).Scan(&usage.TotalCents, &usage.Count)
+		WHERE account_id = $1 AND mode = $2 AND currency = $3 AND created_at >= $4 AND created_at < $5
+			AND ($6 = '' OR payment_type = $6)
+	`, accountID, mode, currency, start.UTC(), end.UTC(), paymentType).Scan(&usage.TotalCents, &usage.Count)
 	if err != nil {
 		return nil, err
 	}
@@ -182,6 +433,8 @@ func (r *InvoiceRepository) UpdateStatus(invoice *domain.Invoice) error {
 }
 
 // ApplyTransactionResult aplica status e saldo em uma única transação.
+// Aprovacoes de faturas com captura manual viram authorized e nao creditam saldo. Faturas em
+// revisao manual so mudam por ResolveReview: o resultado e apenas auditado.
 func (r *InvoiceRepository) ApplyTransactionResult(invoiceID string, status domain.Status, requestID string) error {
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -189,16 +442,7 @@ func (r *InvoiceRepository) ApplyTransactionResult(invoiceID string, status doma
 	}
 	defer tx.Rollback()
 
-	var currentStatus string
-	var accountID string
-	var amountCents int64
-
-	err = tx.QueryRow(`
-		SELECT status, account_id, amount_cents
-		FROM invoices
-		WHERE id = $1
-		FOR UPDATE
-	`, invoiceID).Scan(&currentStatus, &accountID, &amountCents)
+	invoice, err := scanInvoice(tx.QueryRow(`SELECT `+invoiceColumns+` FROM invoices WHERE id = $1 FOR UPDATE`, invoiceID))
 	if err == sql.ErrNoRows {
 		return domain.ErrInvoiceNotFound
 	}
@@ -206,113 +450,539 @@ func (r *InvoiceRepository) ApplyTransactionResult(invoiceID string, status doma
 		return err
 	}
 
-	current := domain.Status(currentStatus)
-	if current != domain.StatusPending {
-		if current == status {
-			return tx.Commit()
+	if invoice.ManualReview && invoice.Status == domain.StatusPending {
+		metadata := map[string]any{
+			"result_status": status,
 		}
-		return domain.ErrInvalidStatus
+		if err := r.insertInvoiceEvent(tx, invoice.ID, "review_result_ignored", &invoice.Status, &invoice.Status, metadata, requestID); err != nil {
+			return err
+		}
+		return tx.Commit()
 	}
 
-	_, err = tx.Exec(
-		"UPDATE invoices SET status = $1, updated_at = $2 WHERE id = $3",
-		status, time.Now(), invoiceID,
-	)
-	if err != nil {
+	if err := r.applyResultTx(tx, invoice, status, requestID); err != nil {
 		return err
 	}
+	return tx.Commit()
+}
 
-	if status == domain.StatusApproved {
-		result, err := tx.Exec(`
-			UPDATE accounts
-			SET balance_cents = balance_cents + $1, updated_at = $2
-			WHERE id = $3
-		`, amountCents, time.Now(), accountID)
-		if err != nil {
-			return err
+// ResolveReview aplica a decisao do operador (approved ou rejected) a uma fatura pendente em
+// revisao manual e registra limit_review_resolved com metadata; o credito segue applyResultTx.
+func (r *InvoiceRepository) ResolveReview(invoiceID string, status domain.Status, metadata map[string]any, requestID string) (*domain.Invoice, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	invoice, err := scanInvoice(tx.QueryRow(`SELECT `+invoiceColumns+` FROM invoices WHERE id = $1 FOR UPDATE`, invoiceID))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrInvoiceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !invoice.ManualReview || invoice.Status != domain.StatusPending {
+		return nil, domain.ErrInvoiceNotInReview
+	}
+
+	invoice.ManualReview = false
+	if _, err := tx.Exec(`UPDATE invoices SET manual_review = false WHERE id = $1`, invoice.ID); err != nil {
+		return nil, err
+	}
+	if err := r.insertInvoiceEvent(tx, invoice.ID, "limit_review_resolved", &invoice.Status, &invoice.Status, metadata, requestID); err != nil {
+		return nil, err
+	}
+	if err := r.applyResultTx(tx, invoice, status, requestID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// applyResultTx aplica o resultado a uma fatura ja bloqueada: atualiza o status, credita o
+// saldo via ledger, grava os eventos e agenda o webhook. Resultados para faturas fechadas sao
+// apenas auditados como late_transaction_result; repetidos sao ignorados.
+func (r *InvoiceRepository) applyResultTx(tx *sql.Tx, invoice *domain.Invoice, status domain.Status, requestID string) error {
+	current := invoice.Status
+	if current.Closed() {
+		// Fatura cancelada/expirada: o resultado tardio e apenas auditado.
+		metadata := map[string]any{
+			"result_status": status,
+		}
+		return r.insertInvoiceEvent(tx, invoice.ID, "late_transaction_result", &current, &current, metadata, requestID)
+	}
+	if current != domain.StatusPending {
+		if current == status || (status == domain.StatusApproved && current == invoice.ApprovedStatus()) {
+			return nil
 		}
+		return domain.ErrInvalidStatus
+	}
+
+	if err := invoice.UpdateStatus(status); err != nil {
+		return err
+	}
+	if err := r.updateStatusTx(tx, invoice); err != nil {
+		return err
+	}
 
-		rowsAffected, err := result.RowsAffected()
+	var availableAt time.Time
+	if invoice.Status == domain.StatusApproved {
+		credited, err := r.creditInvoice(tx, invoice, ledger.EntryInvoiceApproved)
 		if err != nil {
 			return err
 		}
-		if rowsAffected == 0 {
-			return domain.ErrAccountNotFound
-		}
+		availableAt = credited
 	}
 
 	fromStatus := current
-	if err := r.insertInvoiceEvent(tx, invoiceID, string(status), &fromStatus, &status, nil, requestID); err != nil {
+	if err := r.insertInvoiceEvent(tx, invoice.ID, string(invoice.Status), &fromStatus, &invoice.Status, nil, requestID); err != nil {
 		return err
 	}
 
-	if status == domain.StatusApproved {
-		metadata := map[string]any{
-			"amount_cents": amountCents,
-			"account_id":   accountID,
-		}
-		if err := r.insertInvoiceEvent(tx, invoiceID, "balance_applied", &status, &status, metadata, requestID); err != nil {
+	if invoice.Status == domain.StatusApproved {
+		metadata := creditMetadata(invoice, availableAt)
+		if err := r.insertInvoiceEvent(tx, invoice.ID, "balance_applied", &invoice.Status, &invoice.Status, metadata, requestID); err != nil {
 			return err
 		}
 	}
 
-	return tx.Commit()
+	return r.enqueueStatusWebhook(tx, invoice, fromStatus, map[string]any{"amount_cents": invoice.AmountCents}, requestID)
 }
 
-// ListEventsByInvoiceID retorna eventos ordenados por data.
-func (r *InvoiceRepository) ListEventsByInvoiceID(invoiceID string) ([]*domain.InvoiceEvent, error) {
-	rows, err := r.db.Query(`
-		SELECT id, invoice_id, event_type, from_status, to_status, metadata, request_id, created_at
-		FROM invoice_events
-		WHERE invoice_id = $1
-		ORDER BY created_at ASC
-	`, invoiceID)
+// ApplyRefund estorna total ou parcialmente uma fatura aprovada, debitando o saldo
+// da conta via ledger e registrando o evento refund_applied na mesma transacao.
+// Quando amountCents e zero, estorna todo o valor restante. A tarifa e devolvida na
+// proporcao do estorno, entao o merchant perde apenas a parte liquida. Retorna
+// ErrInsufficientBalance se a parte ja liquidada do estorno exceder o saldo disponivel.
+func (r *InvoiceRepository) ApplyRefund(invoiceID string, amountCents int64, requestID string) (*domain.Invoice, error) {
+	tx, err := r.db.Begin()
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var events []*domain.InvoiceEvent
-	for rows.Next() {
-		var event domain.InvoiceEvent
-		var fromStatus sql.NullString
-		var toStatus sql.NullString
-		var metadata []byte
-		var requestID sql.NullString
+	defer tx.Rollback()
 
-		if err := rows.Scan(
-			&event.ID,
-			&event.InvoiceID,
-			&event.EventType,
-			&fromStatus,
-			&toStatus,
-			&metadata,
-			&requestID,
-			&event.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
+	invoice, err := scanInvoice(tx.QueryRow(`SELECT `+invoiceColumns+` FROM invoices WHERE id = $1 FOR UPDATE`, invoiceID))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrInvoiceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		if fromStatus.Valid {
-			value := domain.Status(fromStatus.String)
-			event.FromStatus = &value
-		}
-		if toStatus.Valid {
-			value := domain.Status(toStatus.String)
-			event.ToStatus = &value
-		}
-		if len(metadata) > 0 {
-			event.Metadata = json.RawMessage(metadata)
-		}
-		if requestID.Valid {
-			event.RequestID = &requestID.String
-		}
+	fromStatus := invoice.Status
+	previousRefunded, previousFee := invoice.RefundedCents, invoice.FeeCents
+	if err := invoice.Refund(amountCents); err != nil {
+		return nil, err
+	}
+	refundCents := invoice.RefundedCents - previousRefunded
+	feeRefundCents := previousFee - invoice.FeeCents
+	merchantCents := refundCents - feeRefundCents
 
-		events = append(events, &event)
+	_, err = tx.Exec(
+		"UPDATE invoices SET status = $1, refunded_cents = $2, fee_cents = $3, net_cents = $4, updated_at = $5 WHERE id = $6",
+		invoice.Status, invoice.RefundedCents, invoice.FeeCents, invoice.NetCents, invoice.UpdatedAt, invoice.ID,
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	return events, nil
-}
+	// O estorno consome primeiro a parte da fatura ainda pendente e depois o saldo disponivel.
+	pendingCents, err := consumePendingReleases(tx, invoice.ID, merchantCents)
+	if err != nil {
+		return nil, err
+	}
+	// A parte que sai do saldo disponivel precisa estar disponivel: depois de um saque o estorno
+	// nao pode deixar o saldo negativo. A conta e bloqueada antes do saldo, como em ledger.Post.
+	availableCents := merchantCents - pendingCents
+	if availableCents > 0 {
+		if err := requireAvailableBalance(tx, invoice, availableCents); err != nil {
+			return nil, err
+		}
+	}
+	postings := []ledger.Posting{{LedgerAccount: ledger.AccountSettlementClearing, AmountCents: refundCents}}
+	if pendingCents > 0 {
+		postings = append(postings, ledger.Posting{LedgerAccount: ledger.AccountMerchantPending, AmountCents: -pendingCents})
+	}
+	if availableCents > 0 {
+		postings = append(postings, ledger.Posting{LedgerAccount: ledger.AccountMerchantBalance, AmountCents: -availableCents})
+	}
+	if feeRefundCents > 0 {
+		postings = append(postings, ledger.Posting{LedgerAccount: ledger.AccountFeeRevenue, AmountCents: -feeRefundCents})
+	}
+	entry := ledger.NewEntry(invoice.AccountID, invoice.ID, invoice.Currency, ledger.EntryInvoiceRefunded, postings...)
+	entry.Mode = invoice.Mode
+	if err := ledger.Post(tx, entry); err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]any{
+		"amount_cents":     refundCents,
+		"refunded_cents":   invoice.RefundedCents,
+		"pending_cents":    pendingCents,
+		"fee_refund_cents": feeRefundCents,
+		"fee_cents":        invoice.FeeCents,
+		"net_cents":        invoice.NetCents,
+		"account_id":       invoice.AccountID,
+	}
+	if err := r.insertInvoiceEvent(tx, invoice.ID, "refund_applied", &fromStatus, &invoice.Status, metadata, requestID); err != nil {
+		return nil, err
+	}
+
+	webhookData := map[string]any{
+		"amount_cents":          invoice.AmountCents,
+		"refund_amount_cents":   refundCents,
+		"refunded_amount_cents": invoice.RefundedCents,
+		"fee_refund_cents":      feeRefundCents,
+	}
+	if err := r.enqueueStatusWebhook(tx, invoice, fromStatus, webhookData, requestID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// Cancel cancela uma fatura pendente e registra o evento cancelled na mesma transacao.
+func (r *InvoiceRepository) Cancel(invoiceID string, requestID string) (*domain.Invoice, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	invoice, err := scanInvoice(tx.QueryRow(`SELECT `+invoiceColumns+` FROM invoices WHERE id = $1 FOR UPDATE`, invoiceID))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrInvoiceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fromStatus := invoice.Status
+	if err := invoice.Cancel(); err != nil {
+		return nil, err
+	}
+
+	if err := r.updateStatusTx(tx, invoice); err != nil {
+		return nil, err
+	}
+	if err := r.insertInvoiceEvent(tx, invoice.ID, "cancelled", &fromStatus, &invoice.Status, nil, requestID); err != nil {
+		return nil, err
+	}
+	if err := r.enqueueStatusWebhook(tx, invoice, fromStatus, map[string]any{"amount_cents": invoice.AmountCents}, requestID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// Capture captura uma fatura autorizada e credita o valor capturado via ledger na mesma transacao.
+// Quando amountCents e zero, captura todo o valor autorizado.
+func (r *InvoiceRepository) Capture(invoiceID string, amountCents int64, requestID string) (*domain.Invoice, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	invoice, err := scanInvoice(tx.QueryRow(`SELECT `+invoiceColumns+` FROM invoices WHERE id = $1 FOR UPDATE`, invoiceID))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrInvoiceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fromStatus := invoice.Status
+	if err := invoice.Capture(amountCents); err != nil {
+		return nil, err
+	}
+	if err := r.updateStatusTx(tx, invoice); err != nil {
+		return nil, err
+	}
+
+	availableAt, err := r.creditInvoice(tx, invoice, ledger.EntryInvoiceCaptured)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := creditMetadata(invoice, availableAt)
+	metadata["released_cents"] = invoice.AmountCents - invoice.CapturedCents
+	if err := r.insertInvoiceEvent(tx, invoice.ID, "captured", &fromStatus, &invoice.Status, metadata, requestID); err != nil {
+		return nil, err
+	}
+
+	webhookData := map[string]any{
+		"amount_cents":          invoice.AmountCents,
+		"captured_amount_cents": invoice.CapturedCents,
+	}
+	if err := r.enqueueStatusWebhook(tx, invoice, fromStatus, webhookData, requestID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// Void libera uma autorizacao sem captura e registra o evento voided na mesma transacao.
+func (r *InvoiceRepository) Void(invoiceID string, requestID string) (*domain.Invoice, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	invoice, err := scanInvoice(tx.QueryRow(`SELECT `+invoiceColumns+` FROM invoices WHERE id = $1 FOR UPDATE`, invoiceID))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrInvoiceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.voidTx(tx, invoice, nil, requestID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// VoidExpiredAuthorizations libera ate limit autorizacoes feitas antes de olderThan.
+// Linhas bloqueadas por outra transacao sao ignoradas e ficam para a proxima rodada.
+func (r *InvoiceRepository) VoidExpiredAuthorizations(olderThan time.Time, limit int, requestID string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	invoices, err := lockInvoices(tx, `
+		SELECT `+invoiceColumns+`
+		FROM invoices
+		WHERE status = $1 AND updated_at < $2
+		ORDER BY updated_at ASC
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`, domain.StatusAuthorized, olderThan, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, invoice := range invoices {
+		metadata := map[string]any{
+			"authorized_before": olderThan,
+		}
+		if err := r.voidTx(tx, invoice, metadata, requestID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(invoices), nil
+}
+
+func (r *InvoiceRepository) voidTx(tx *sql.Tx, invoice *domain.Invoice, metadata map[string]any, requestID string) error {
+	fromStatus := invoice.Status
+	if err := invoice.Void(); err != nil {
+		return err
+	}
+	if err := r.updateStatusTx(tx, invoice); err != nil {
+		return err
+	}
+	if err := r.insertInvoiceEvent(tx, invoice.ID, "voided", &fromStatus, &invoice.Status, metadata, requestID); err != nil {
+		return err
+	}
+	return r.enqueueStatusWebhook(tx, invoice, fromStatus, map[string]any{"amount_cents": invoice.AmountCents}, requestID)
+}
+
+// ExpirePending expira ate limit faturas pendentes criadas antes de olderThan.
+// Boletos e PIX ficam de fora: expiram pelo vencimento em ExpireOverdueBoletos e ExpireOverduePix.
+// Faturas em revisao manual aguardam o operador e tambem ficam de fora.
+// Linhas bloqueadas por outra transacao sao ignoradas e ficam para a proxima rodada.
+func (r *InvoiceRepository) ExpirePending(olderThan time.Time, limit int, requestID string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	invoices, err := lockInvoices(tx, `
+		SELECT `+invoiceColumns+`
+		FROM invoices
+		WHERE status = $1 AND created_at < $2 AND payment_type NOT IN ($4, $5) AND NOT manual_review
+		ORDER BY created_at ASC
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`, domain.StatusPending, olderThan, limit, domain.PaymentTypeBoleto, domain.PaymentTypePix)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, invoice := range invoices {
+		metadata := map[string]any{
+			"expired_before": olderThan,
+		}
+		if err := r.expireTx(tx, invoice, metadata, requestID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(invoices), nil
+}
+
+func (r *InvoiceRepository) expireTx(tx *sql.Tx, invoice *domain.Invoice, metadata map[string]any, requestID string) error {
+	fromStatus := invoice.Status
+	if err := invoice.Expire(); err != nil {
+		return err
+	}
+	if err := r.updateStatusTx(tx, invoice); err != nil {
+		return err
+	}
+	if err := r.insertInvoiceEvent(tx, invoice.ID, "expired", &fromStatus, &invoice.Status, metadata, requestID); err != nil {
+		return err
+	}
+	return r.enqueueStatusWebhook(tx, invoice, fromStatus, map[string]any{"amount_cents": invoice.AmountCents}, requestID)
+}
+
+// ReleaseMaturedFunds move para o saldo disponivel ate limit liberacoes com available_at ate
+// olderThan (merchant_pending -> merchant_balance) e registra funds_released em cada fatura.
+func (r *InvoiceRepository) ReleaseMaturedFunds(olderThan time.Time, limit int, requestID string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		SELECT id, account_id, invoice_id, mode, currency, amount_cents, available_at
+		FROM balance_releases
+		WHERE released_at IS NULL AND available_at <= $1
+		ORDER BY available_at ASC
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, olderThan, limit)
+	if err != nil {
+		return 0, err
+	}
+	var releases []*domain.BalanceRelease
+	for rows.Next() {
+		var release domain.BalanceRelease
+		if err := rows.Scan(&release.ID, &release.AccountID, &release.InvoiceID, &release.Mode, &release.Currency, &release.AmountCents, &release.AvailableAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		releases = append(releases, &release)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// Ordena por conta para que lotes concorrentes bloqueiem as contas na mesma ordem.
+	sort.SliceStable(releases, func(i, j int) bool { return releases[i].AccountID < releases[j].AccountID })
+
+	now := time.Now()
+	for _, release := range releases {
+		// Liberacoes zeradas por estorno sao apenas encerradas.
+		if release.AmountCents > 0 {
+			entry := ledger.Transfer(release.AccountID, release.InvoiceID, release.Currency, ledger.EntryFundsReleased,
+				ledger.AccountMerchantPending, ledger.AccountMerchantBalance, release.AmountCents)
+			entry.Mode = release.Mode
+			if err := ledger.Post(tx, entry); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := tx.Exec(`UPDATE balance_releases SET released_at = $1 WHERE id = $2`, now, release.ID); err != nil {
+			return 0, err
+		}
+		metadata := map[string]any{
+			"amount_cents": release.AmountCents,
+			"account_id":   release.AccountID,
+			"available_at": release.AvailableAt,
+		}
+		if err := r.insertInvoiceEvent(tx, release.InvoiceID, "funds_released", nil, nil, metadata, requestID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(releases), nil
+}
+
+// ListEventsByInvoiceID retorna eventos ordenados por data.
+func (r *InvoiceRepository) ListEventsByInvoiceID(invoiceID string) ([]*domain.InvoiceEvent, error) {
+	rows, err := r.db.Query(`
+		SELECT id, invoice_id, event_type, from_status, to_status, metadata, request_id, created_at
+		FROM invoice_events
+		WHERE invoice_id = $1
+		ORDER BY created_at ASC
+	`, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*domain.InvoiceEvent
+	for rows.Next() {
+		var event domain.InvoiceEvent
+		var fromStatus sql.NullString
+		var toStatus sql.NullString
+		var metadata []byte
+		var requestID sql.NullString
+
+		if err := rows.Scan(
+			&event.ID,
+			&event.InvoiceID,
+			&event.EventType,
+			&fromStatus,
+			&toStatus,
+			&metadata,
+			&requestID,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if fromStatus.Valid {
+			value := domain.Status(fromStatus.String)
+			event.FromStatus = &value
+		}
+		if toStatus.Valid {
+			value := domain.Status(toStatus.String)
+			event.ToStatus = &value
+		}
+		if len(metadata) > 0 {
+			event.Metadata = json.RawMessage(metadata)
+		}
+		if requestID.Valid {
+			event.RequestID = &requestID.String
+		}
+
+		events = append(events, &event)
+	}
+
+	return events, nil
+}
 
 // AddInvoiceEvent adiciona um evento avulso para uma invoice.
 func (r *InvoiceRepository) AddInvoiceEvent(
@@ -324,17 +994,211 @@ func (r *InvoiceRepository) AddInvoiceEvent(
 	requestID string,
 	createdAt *time.Time,
 ) error {
-	tx, err := r.db.Begin()
+	write, err := r.begin()
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
+	defer write.rollback()
+	tx := write.Tx
 
 	if err := r.insertInvoiceEventAt(tx, invoiceID, eventType, fromStatus, toStatus, metadata, requestID, createdAt); err != nil {
 		return err
 	}
 
-	return tx.Commit()
+	return write.commit()
+}
+
+// enqueueStatusWebhook agenda na outbox a notificacao "invoice.<status>" para os
+// endpoints ativos da conta, dentro da transacao da mudanca de status.
+func (r *InvoiceRepository) enqueueStatusWebhook(
+	tx *sql.Tx,
+	invoice *domain.Invoice,
+	fromStatus domain.Status,
+	extra map[string]any,
+	requestID string,
+) error {
+	data := map[string]any{
+		"invoice_id":      invoice.ID,
+		"account_id":      invoice.AccountID,
+		"mode":            invoice.Mode,
+		"currency":        invoice.Currency,
+		"status":          invoice.Status,
+		"previous_status": fromStatus,
+	}
+	for key, value := range extra {
+		data[key] = value
+	}
+	return webhook.Enqueue(tx, invoice.AccountID, invoice.Mode, invoice.ID, webhook.NewEvent("invoice."+string(invoice.Status), data), requestID)
+}
+
+// lockInvoices le as faturas retornadas por uma consulta FOR UPDATE dentro da transacao.
+func lockInvoices(tx *sql.Tx, query string, args ...any) ([]*domain.Invoice, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []*domain.Invoice
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, invoice)
+	}
+	return invoices, rows.Err()
+}
+
+// invoiceTransfer cria o lancamento de uma fatura no modo e na moeda dela.
+func invoiceTransfer(invoice *domain.Invoice, entryType, from, to string, amountCents int64) *ledger.Entry {
+	entry := ledger.Transfer(invoice.AccountID, invoice.ID, invoice.Currency, entryType, from, to, amountCents)
+	entry.Mode = invoice.Mode
+	return entry
+}
+
+// creditInvoice calcula a tarifa do valor capturado pelo plano da conta, grava fee_cents e
+// net_cents na fatura e credita apenas o liquido no saldo do merchant via ledger (a tarifa vai
+// para fee_revenue). Retorna quando o valor fica disponivel: com espera configurada para o tipo
+// de pagamento, o liquido entra em merchant_pending e ganha uma liberacao em balance_releases.
+func (r *InvoiceRepository) creditInvoice(tx *sql.Tx, invoice *domain.Invoice, entryType string) (time.Time, error) {
+	plan, err := findPricingPlan(tx, invoice.AccountID, invoice.Mode, invoice.Currency)
+	if err != nil {
+		return time.Time{}, err
+	}
+	invoice.ApplyFee(plan.Fee(invoice.PaymentType, invoice.Installments, invoice.CapturedCents))
+	fee, err := json.Marshal(invoice.Fee)
+	if err != nil {
+		return time.Time{}, err
+	}
+	_, err = tx.Exec("UPDATE invoices SET fee_cents = $1, net_cents = $2, fee_breakdown = $3 WHERE id = $4",
+		invoice.FeeCents, invoice.NetCents, fee, invoice.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	now := time.Now()
+	delay := r.settlement.Delay(invoice.PaymentType)
+	merchantAccount := ledger.AccountMerchantBalance
+	if delay > 0 {
+		merchantAccount = ledger.AccountMerchantPending
+	}
+	postings := []ledger.Posting{{LedgerAccount: ledger.AccountSettlementClearing, AmountCents: -invoice.CapturedCents}}
+	if invoice.NetCents > 0 {
+		postings = append(postings, ledger.Posting{LedgerAccount: merchantAccount, AmountCents: invoice.NetCents})
+	}
+	if invoice.FeeCents > 0 {
+		postings = append(postings, ledger.Posting{LedgerAccount: ledger.AccountFeeRevenue, AmountCents: invoice.FeeCents})
+	}
+	entry := ledger.NewEntry(invoice.AccountID, invoice.ID, invoice.Currency, entryType, postings...)
+	entry.Mode = invoice.Mode
+	if err := ledger.Post(tx, entry); err != nil {
+		return time.Time{}, err
+	}
+	if delay == 0 || invoice.NetCents == 0 {
+		return now, nil
+	}
+
+	availableAt := now.Add(delay)
+	_, err = tx.Exec(`
+		INSERT INTO balance_releases (id, account_id, invoice_id, mode, currency, amount_cents, available_at, created_at)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7)
+	`, invoice.AccountID, invoice.ID, invoice.Mode, invoice.Currency, invoice.NetCents, availableAt, now)
+	return availableAt, err
+}
+
+// creditMetadata descreve o credito do saldo nos eventos balance_applied e captured.
+func creditMetadata(invoice *domain.Invoice, availableAt time.Time) map[string]any {
+	return map[string]any{
+		"amount_cents": invoice.CapturedCents,
+		"fee_cents":    invoice.FeeCents,
+		"net_cents":    invoice.NetCents,
+		"fee":          invoice.Fee,
+		"account_id":   invoice.AccountID,
+		"available_at": availableAt,
+	}
+}
+
+// requireAvailableBalance bloqueia a conta e o saldo do modo e da moeda da fatura e retorna
+// ErrInsufficientBalance se o saldo disponivel (total menos pendente) nao cobrir amountCents.
+func requireAvailableBalance(tx *sql.Tx, invoice *domain.Invoice, amountCents int64) error {
+	if _, err := tx.Exec(`SELECT 1 FROM accounts WHERE id = $1 FOR UPDATE`, invoice.AccountID); err != nil {
+		return err
+	}
+	var balance, pending int64
+	err := tx.QueryRow(`
+		SELECT balance_cents, pending_cents FROM account_balances
+		WHERE account_id = $1 AND mode = $2 AND currency = $3
+		FOR UPDATE
+	`, invoice.AccountID, invoice.Mode, invoice.Currency).Scan(&balance, &pending)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if balance-pending < amountCents {
+		return domain.ErrInsufficientBalance
+	}
+	return nil
+}
+
+// consumePendingReleases abate ate amountCents das liberacoes ainda pendentes da fatura e
+// retorna quanto foi abatido. O restante de um estorno sai do saldo disponivel.
+func consumePendingReleases(tx *sql.Tx, invoiceID string, amountCents int64) (int64, error) {
+	rows, err := tx.Query(`
+		SELECT id, amount_cents
+		FROM balance_releases
+		WHERE invoice_id = $1 AND released_at IS NULL AND amount_cents > 0
+		ORDER BY available_at DESC
+		FOR UPDATE
+	`, invoiceID)
+	if err != nil {
+		return 0, err
+	}
+	type pendingRelease struct {
+		id          string
+		amountCents int64
+	}
+	var releases []pendingRelease
+	for rows.Next() {
+		var release pendingRelease
+		if err := rows.Scan(&release.id, &release.amountCents); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		releases = append(releases, release)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	var consumed int64
+	for _, release := range releases {
+		if consumed == amountCents {
+			break
+		}
+		take := min(release.amountCents, amountCents-consumed)
+		if _, err := tx.Exec(`UPDATE balance_releases SET amount_cents = amount_cents - $1 WHERE id = $2`, take, release.id); err != nil {
+			return 0, err
+		}
+		consumed += take
+	}
+	return consumed, nil
+}
+
+func (r *InvoiceRepository) updateStatusTx(tx *sql.Tx, invoice *domain.Invoice) error {
+	_, err := tx.Exec(
+		"UPDATE invoices SET status = $1, captured_cents = $2, updated_at = $3 WHERE id = $4",
+		invoice.Status, invoice.CapturedCents, invoice.UpdatedAt, invoice.ID,
+	)
+	return err
+}
+
+func (r *InvoiceRepository) insertInvoice(tx *sql.Tx, invoice *domain.Invoice) error {
+	_, err := tx.Exec(
+		"INSERT INTO invoices (id, account_id, mode, currency, amount_cents, captured_cents, capture_method, status, description, payment_type, card_last_digits, card_brand, card_bin, card_funding, card_country, customer_id, installments, manual_review, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)",
+		invoice.ID, invoice.AccountID, invoice.Mode, invoice.Currency, invoice.AmountCents, invoice.CapturedCents, invoice.CaptureMethod, invoice.Status, invoice.Description, invoice.PaymentType, invoice.CardLastDigits, invoice.CardBrand, invoice.CardBIN, invoice.CardFunding, invoice.CardCountry, nullableUUID(invoice.CustomerID), max(invoice.Installments, 1), invoice.ManualReview, invoice.CreatedAt, invoice.UpdatedAt,
+	)
+	return err
 }
 
 func (r *InvoiceRepository) insertInvoiceEvent(
@@ -400,3 +1264,420 @@ func (r *InvoiceRepository) insertInvoiceEventAt(
 	)
 	return err
 }
+
+// boletoColumns lista as colunas lidas por scanBoleto, na mesma ordem.
+const boletoColumns = `invoice_id, bank_code, our_number, barcode, digitable_line, due_date, paid_at, paid_amount_cents, settlement_source, created_at`
+
+func scanBoleto(row rowScanner) (*domain.Boleto, error) {
+	var boleto domain.Boleto
+	var dueDate time.Time
+	var paidAt sql.NullTime
+	var paidAmount sql.NullInt64
+	var source sql.NullString
+	err := row.Scan(
+		&boleto.InvoiceID,
+		&boleto.BankCode,
+		&boleto.OurNumber,
+		&boleto.Barcode,
+		&boleto.DigitableLine,
+		&dueDate,
+		&paidAt,
+		&paidAmount,
+		&source,
+		&boleto.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	boleto.DueDate = time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day(), 0, 0, 0, 0, time.UTC)
+	if paidAt.Valid {
+		boleto.PaidAt = &paidAt.Time
+	}
+	boleto.PaidAmountCents = paidAmount.Int64
+	boleto.SettlementSource = source.String
+	return &boleto, nil
+}
+
+// NextBoletoNumber reserva o proximo nosso numero; lacunas na sequencia sao aceitas.
+func (r *InvoiceRepository) NextBoletoNumber() (int64, error) {
+	var number int64
+	err := r.querier().QueryRow(`SELECT nextval('boleto_our_number_seq')`).Scan(&number)
+	return number, err
+}
+
+// SaveBoleto grava a fatura pendente e o boleto emitido na mesma transacao.
+func (r *InvoiceRepository) SaveBoleto(invoice *domain.Invoice, boleto *domain.Boleto, requestID string) error {
+	write, err := r.begin()
+	if err != nil {
+		return err
+	}
+	defer write.rollback()
+	tx := write.Tx
+
+	if err := r.insertInvoice(tx, invoice); err != nil {
+		return err
+	}
+	if err := r.insertInvoiceEvent(tx, invoice.ID, "created", nil, &invoice.Status, nil, requestID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO boletos (invoice_id, bank_code, our_number, barcode, digitable_line, due_date, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		boleto.InvoiceID, boleto.BankCode, boleto.OurNumber, boleto.Barcode, boleto.DigitableLine,
+		boleto.DueDate.Format("2006-01-02"), boleto.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	metadata := map[string]any{
+		"due_date":   boleto.DueDate.Format("2006-01-02"),
+		"our_number": boleto.OurNumber,
+	}
+	if err := r.insertInvoiceEvent(tx, invoice.ID, "boleto_issued", &invoice.Status, &invoice.Status, metadata, requestID); err != nil {
+		return err
+	}
+
+	return write.commit()
+}
+
+// FindBoletoByInvoiceID busca o boleto emitido para a fatura.
+func (r *InvoiceRepository) FindBoletoByInvoiceID(invoiceID string) (*domain.Boleto, error) {
+	boleto, err := scanBoleto(r.db.QueryRow(`SELECT `+boletoColumns+` FROM boletos WHERE invoice_id = $1`, invoiceID))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrBoletoNotFound
+	}
+	return boleto, err
+}
+
+// SettleBoleto liquida o boleto: aprova a fatura pendente, credita o saldo via ledger e
+// agenda o webhook na mesma transacao. Pagamentos de faturas ja fechadas (canceladas ou
+// expiradas) sao gravados e auditados como late_boleto_payment, sem credito, para conciliacao.
+func (r *InvoiceRepository) SettleBoleto(settlement domain.BoletoSettlement, requestID string) (*domain.Invoice, *domain.Boleto, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	// A fatura e bloqueada antes do boleto, na mesma ordem dos demais fluxos.
+	var invoiceID string
+	err = tx.QueryRow(`SELECT invoice_id FROM boletos WHERE barcode = $1`, settlement.Barcode).Scan(&invoiceID)
+	if err == sql.ErrNoRows {
+		return nil, nil, domain.ErrBoletoNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	invoice, err := scanInvoice(tx.QueryRow(`SELECT `+invoiceColumns+` FROM invoices WHERE id = $1 FOR UPDATE`, invoiceID))
+	if err != nil {
+		return nil, nil, err
+	}
+	boleto, err := scanBoleto(tx.QueryRow(`SELECT `+boletoColumns+` FROM boletos WHERE invoice_id = $1 FOR UPDATE`, invoiceID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if boleto.Paid() {
+		return invoice, boleto, domain.ErrBoletoAlreadyPaid
+	}
+	if settlement.PaidAmountCents < invoice.AmountCents {
+		return invoice, boleto, domain.ErrBoletoAmountMismatch
+	}
+
+	paidAt := settlement.PaidAt.UTC()
+	boleto.PaidAt = &paidAt
+	boleto.PaidAmountCents = settlement.PaidAmountCents
+	boleto.SettlementSource = settlement.Source
+	if _, err := tx.Exec(
+		`UPDATE boletos SET paid_at = $1, paid_amount_cents = $2, settlement_source = $3 WHERE invoice_id = $4`,
+		paidAt, settlement.PaidAmountCents, settlement.Source, invoiceID,
+	); err != nil {
+		return nil, nil, err
+	}
+
+	metadata := map[string]any{
+		"paid_amount_cents": settlement.PaidAmountCents,
+		"paid_at":           paidAt,
+		"source":            settlement.Source,
+	}
+
+	current := invoice.Status
+	if current != domain.StatusPending {
+		if err := r.insertInvoiceEvent(tx, invoiceID, "late_boleto_payment", &current, &current, metadata, requestID); err != nil {
+			return nil, nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, nil, err
+		}
+		return invoice, boleto, domain.ErrBoletoNotPayable
+	}
+
+	if err := r.insertInvoiceEvent(tx, invoiceID, "boleto_paid", &current, &current, metadata, requestID); err != nil {
+		return nil, nil, err
+	}
+	if err := r.applyResultTx(tx, invoice, domain.StatusApproved, requestID); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return invoice, boleto, nil
+}
+
+// ExpireOverdueBoletos expira ate limit boletos nao pagos com vencimento anterior ao dia
+// (em Brasilia) de olderThan. Linhas bloqueadas ficam para a proxima rodada.
+func (r *InvoiceRepository) ExpireOverdueBoletos(olderThan time.Time, limit int, requestID string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	cutoff := domain.BoletoToday(olderThan).Format("2006-01-02")
+	invoices, err := lockInvoices(tx, `
+		SELECT `+invoiceColumns+`
+		FROM invoices
+		WHERE status = $1
+		  AND id IN (SELECT invoice_id FROM boletos WHERE paid_at IS NULL AND due_date < $2)
+		ORDER BY created_at ASC
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`, domain.StatusPending, cutoff, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, invoice := range invoices {
+		metadata := map[string]any{
+			"reason":     "boleto_overdue",
+			"due_before": cutoff,
+		}
+		if err := r.expireTx(tx, invoice, metadata, requestID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(invoices), nil
+}
+
+// pixChargeColumns lista as colunas lidas por scanPixCharge, na mesma ordem.
+const pixChargeColumns = `invoice_id, txid, payload, expires_at, end_to_end_id, paid_at, paid_amount_cents, created_at`
+
+func scanPixCharge(row rowScanner) (*domain.PixCharge, error) {
+	var charge domain.PixCharge
+	var endToEndID sql.NullString
+	var paidAt sql.NullTime
+	var paidAmount sql.NullInt64
+	err := row.Scan(
+		&charge.InvoiceID,
+		&charge.TxID,
+		&charge.Payload,
+		&charge.ExpiresAt,
+		&endToEndID,
+		&paidAt,
+		&paidAmount,
+		&charge.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	charge.EndToEndID = endToEndID.String
+	if paidAt.Valid {
+		charge.PaidAt = &paidAt.Time
+	}
+	charge.PaidAmountCents = paidAmount.Int64
+	return &charge, nil
+}
+
+// SavePix grava a fatura pendente e a cobranca PIX na mesma transacao.
+func (r *InvoiceRepository) SavePix(invoice *domain.Invoice, charge *domain.PixCharge, requestID string) error {
+	write, err := r.begin()
+	if err != nil {
+		return err
+	}
+	defer write.rollback()
+	tx := write.Tx
+
+	if err := r.insertInvoice(tx, invoice); err != nil {
+		return err
+	}
+	if err := r.insertInvoiceEvent(tx, invoice.ID, "created", nil, &invoice.Status, nil, requestID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO pix_charges (invoice_id, txid, payload, expires_at, created_at) VALUES ($1, $2, $3, $4, $5)`,
+		charge.InvoiceID, charge.TxID, charge.Payload, charge.ExpiresAt.UTC(), charge.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	metadata := map[string]any{
+		"txid":       charge.TxID,
+		"expires_at": charge.ExpiresAt.UTC(),
+	}
+	if err := r.insertInvoiceEvent(tx, invoice.ID, "pix_charge_created", &invoice.Status, &invoice.Status, metadata, requestID); err != nil {
+		return err
+	}
+
+	return write.commit()
+}
+
+// FindPixChargeByInvoiceID busca a cobranca PIX da fatura.
+func (r *InvoiceRepository) FindPixChargeByInvoiceID(invoiceID string) (*domain.PixCharge, error) {
+	charge, err := scanPixCharge(r.db.QueryRow(`SELECT `+pixChargeColumns+` FROM pix_charges WHERE invoice_id = $1`, invoiceID))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrPixChargeNotFound
+	}
+	return charge, err
+}
+
+// SettlePix confirma um pagamento PIX e aprova a fatura pelo mesmo caminho de
+// ApplyTransactionResult (status, ledger, eventos e webhook na mesma transacao).
+// Confirmacoes repetidas do mesmo end_to_end_id sao ignoradas; um segundo pagamento da mesma
+// cobranca e pagamentos de faturas fechadas nao creditam a conta e vao para pix_unapplied_payments
+// (ErrPixInvoiceClosed no segundo caso).
+func (r *InvoiceRepository) SettlePix(payment domain.PixPayment, requestID string) (*domain.Invoice, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// A fatura e bloqueada antes da cobranca, na mesma ordem dos demais fluxos.
+	var invoiceID string
+	err = tx.QueryRow(`SELECT invoice_id FROM pix_charges WHERE txid = $1`, payment.TxID).Scan(&invoiceID)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrPixChargeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	invoice, err := scanInvoice(tx.QueryRow(`SELECT `+invoiceColumns+` FROM invoices WHERE id = $1 FOR UPDATE`, invoiceID))
+	if err != nil {
+		return nil, err
+	}
+	charge, err := scanPixCharge(tx.QueryRow(`SELECT `+pixChargeColumns+` FROM pix_charges WHERE invoice_id = $1 FOR UPDATE`, invoiceID))
+	if err != nil {
+		return nil, err
+	}
+
+	paidAt := payment.PaidAt.UTC()
+	metadata := map[string]any{
+		"txid":              payment.TxID,
+		"end_to_end_id":     payment.EndToEndID,
+		"paid_amount_cents": payment.AmountCents,
+		"paid_at":           paidAt,
+	}
+
+	current := invoice.Status
+	if charge.Paid() {
+		if charge.EndToEndID != payment.EndToEndID {
+			if err := r.recordUnappliedPix(tx, invoice, payment, "duplicate", "pix_duplicate_payment", metadata, requestID); err != nil {
+				return nil, err
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return invoice, nil
+	}
+	// Fatura fora de pending (expirada ou cancelada) nao e reaberta: a cobranca segue sem
+	// pagamento e o valor fica registrado para devolucao ao pagador.
+	if current != domain.StatusPending {
+		if err := r.recordUnappliedPix(tx, invoice, payment, "invoice_closed", "late_pix_payment", metadata, requestID); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return invoice, domain.ErrPixInvoiceClosed
+	}
+	if payment.AmountCents != invoice.AmountCents {
+		return invoice, domain.ErrPixAmountMismatch
+	}
+
+	if _, err := tx.Exec(
+		`UPDATE pix_charges SET end_to_end_id = $1, paid_at = $2, paid_amount_cents = $3 WHERE invoice_id = $4`,
+		payment.EndToEndID, paidAt, payment.AmountCents, invoiceID,
+	); err != nil {
+		return nil, err
+	}
+	if err := r.insertInvoiceEvent(tx, invoiceID, "pix_received", &current, &current, metadata, requestID); err != nil {
+		return nil, err
+	}
+	if err := r.applyResultTx(tx, invoice, domain.StatusApproved, requestID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// recordUnappliedPix registra um PIX que nao credita a fatura em pix_unapplied_payments, para
+// devolucao ao pagador, e audita eventType na fatura. Reenvios do mesmo endToEndId sao ignorados.
+func (r *InvoiceRepository) recordUnappliedPix(tx *sql.Tx, invoice *domain.Invoice, payment domain.PixPayment, reason, eventType string, metadata map[string]any, requestID string) error {
+	result, err := tx.Exec(`
+		INSERT INTO pix_unapplied_payments (end_to_end_id, invoice_id, txid, reason, amount_cents, paid_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (end_to_end_id) DO NOTHING
+	`, payment.EndToEndID, invoice.ID, payment.TxID, reason, payment.AmountCents, payment.PaidAt.UTC())
+	if err != nil {
+		return err
+	}
+	if inserted, err := result.RowsAffected(); err != nil || inserted == 0 {
+		return err
+	}
+
+	metadata["refund_required"] = true
+	return r.insertInvoiceEvent(tx, invoice.ID, eventType, &invoice.Status, &invoice.Status, metadata, requestID)
+}
+
+// ExpireOverduePix expira ate limit faturas PIX nao pagas cuja cobranca expirou antes de olderThan.
+// Linhas bloqueadas ficam para a proxima rodada.
+func (r *InvoiceRepository) ExpireOverduePix(olderThan time.Time, limit int, requestID string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	invoices, err := lockInvoices(tx, `
+		SELECT `+invoiceColumns+`
+		FROM invoices
+		WHERE status = $1
+		  AND id IN (SELECT invoice_id FROM pix_charges WHERE paid_at IS NULL AND expires_at < $2)
+		ORDER BY created_at ASC
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`, domain.StatusPending, olderThan.UTC(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, invoice := range invoices {
+		metadata := map[string]any{
+			"reason":         "pix_expired",
+			"expired_before": olderThan.UTC(),
+		}
+		if err := r.expireTx(tx, invoice, metadata, requestID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(invoices), nil
+}
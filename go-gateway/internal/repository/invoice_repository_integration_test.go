@@ -3,6 +3,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/webhook"
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
 )
@@ -85,3 +87,658 @@ func TestApplyTransactionResult_UpdatesBalance(t *testing.T) {
 		t.Fatalf("expected balance %d, got %d", amountCents, balance)
 	}
 }
+
+func TestApplyTransactionResult_PostsBalancedLedgerEntry(t *testing.T) {
+	db := openIntegrationDB(t)
+	defer db.Close()
+
+	repo := NewInvoiceRepository(db)
+	accountID := uuid.New().String()
+	invoiceID := uuid.New().String()
+	amountCents := int64(2500)
+
+	_, err := db.Exec(`INSERT INTO accounts (id, name, email, api_key, api_key_key_id, balance_cents, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		accountID, "integration", "ledger@test.local", uuid.New().String(), "v1", 0, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert account: %v", err)
+	}
+	defer db.Exec("DELETE FROM accounts WHERE id = $1", accountID)
+
+	_, err = db.Exec(`INSERT INTO invoices (id, account_id, amount_cents, status, description, payment_type, card_last_digits, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		invoiceID, accountID, amountCents, domain.StatusPending, "test", "credit_card", "4242", time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert invoice: %v", err)
+	}
+	defer db.Exec("DELETE FROM invoices WHERE id = $1", invoiceID)
+
+	if err := repo.ApplyTransactionResult(invoiceID, domain.StatusApproved, "integration"); err != nil {
+		t.Fatalf("apply transaction result failed: %v", err)
+	}
+	if _, err := repo.ApplyRefund(invoiceID, 1000, "integration"); err != nil {
+		t.Fatalf("apply refund failed: %v", err)
+	}
+
+	var balance, merchantTotal, entryTotal int64
+	err = db.QueryRow(`
+		SELECT a.balance_cents,
+		       COALESCE(SUM(p.amount_cents) FILTER (WHERE p.ledger_account = 'merchant_balance'), 0),
+		       COALESCE(SUM(p.amount_cents), 0)
+		FROM accounts a
+		LEFT JOIN ledger_postings p ON p.account_id = a.id
+		WHERE a.id = $1
+		GROUP BY a.balance_cents
+	`, accountID).Scan(&balance, &merchantTotal, &entryTotal)
+	if err != nil {
+		t.Fatalf("failed to query ledger: %v", err)
+	}
+
+	if balance != amountCents-1000 {
+		t.Fatalf("expected balance %d, got %d", amountCents-1000, balance)
+	}
+	if merchantTotal != balance {
+		t.Fatalf("expected ledger total %d to match balance %d", merchantTotal, balance)
+	}
+	if entryTotal != 0 {
+		t.Fatalf("expected postings to balance to zero, got %d", entryTotal)
+	}
+}
+
+func TestApplyTransactionResult_DefersSettlementUntilRelease(t *testing.T) {
+	db := openIntegrationDB(t)
+	defer db.Close()
+
+	repo := NewInvoiceRepository(db).WithSettlementSchedule(domain.SettlementSchedule{domain.PaymentTypeCreditCard: time.Hour})
+	accountID := uuid.New().String()
+	invoiceID := uuid.New().String()
+	amountCents := int64(3000)
+
+	_, err := db.Exec(`INSERT INTO accounts (id, name, email, api_key, api_key_key_id, balance_cents, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		accountID, "integration", "settlement@test.local", uuid.New().String(), "v1", 0, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert account: %v", err)
+	}
+	defer db.Exec("DELETE FROM accounts WHERE id = $1", accountID)
+
+	_, err = db.Exec(`INSERT INTO invoices (id, account_id, amount_cents, status, description, payment_type, card_last_digits, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		invoiceID, accountID, amountCents, domain.StatusPending, "test", "credit_card", "4242", time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert invoice: %v", err)
+	}
+	defer db.Exec("DELETE FROM invoices WHERE id = $1", invoiceID)
+
+	if err := repo.ApplyTransactionResult(invoiceID, domain.StatusApproved, "integration"); err != nil {
+		t.Fatalf("apply transaction result failed: %v", err)
+	}
+	if _, err := repo.ApplyRefund(invoiceID, 1000, "integration"); err != nil {
+		t.Fatalf("apply refund failed: %v", err)
+	}
+
+	balances := func() (int64, int64) {
+		var balance, pending int64
+		err := db.QueryRow(`SELECT balance_cents, pending_cents FROM account_balances WHERE account_id = $1 AND mode = 'live'`,
+			accountID).Scan(&balance, &pending)
+		if err != nil {
+			t.Fatalf("failed to query balances: %v", err)
+		}
+		return balance, pending
+	}
+
+	// O estorno consome a parte pendente; nada fica disponivel antes da liberacao.
+	if balance, pending := balances(); balance != amountCents-1000 || pending != amountCents-1000 {
+		t.Fatalf("expected balance and pending %d, got %d and %d", amountCents-1000, balance, pending)
+	}
+
+	if released, err := repo.ReleaseMaturedFunds(time.Now(), 10, "integration"); err != nil || released != 0 {
+		t.Fatalf("expected no release before available_at, got %d (%v)", released, err)
+	}
+	if _, err := repo.ReleaseMaturedFunds(time.Now().Add(2*time.Hour), 100, "integration"); err != nil {
+		t.Fatalf("release matured funds failed: %v", err)
+	}
+
+	if balance, pending := balances(); balance != amountCents-1000 || pending != 0 {
+		t.Fatalf("expected balance %d and pending 0, got %d and %d", amountCents-1000, balance, pending)
+	}
+}
+
+func TestApplyTransactionResult_CreditsNetOfFees(t *testing.T) {
+	db := openIntegrationDB(t)
+	defer db.Close()
+
+	repo := NewInvoiceRepository(db)
+	accountID := uuid.New().String()
+	invoiceID := uuid.New().String()
+	amountCents := int64(10000)
+
+	_, err := db.Exec(`INSERT INTO accounts (id, name, email, api_key, api_key_key_id, balance_cents, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		accountID, "integration", "fees@test.local", uuid.New().String(), "v1", 0, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert account: %v", err)
+	}
+	defer db.Exec("DELETE FROM accounts WHERE id = $1", accountID)
+
+	// 2.99% + 0.39 fixos: 299 + 39 = 338.
+	_, err = NewPricingPlanRepository(db).Replace(domain.PricingPlan{
+		AccountID: accountID,
+		Mode:      domain.ModeLive,
+		Currency:  domain.DefaultCurrency,
+		Rates:     map[string]domain.FeeRate{domain.PaymentTypeCreditCard: {PercentBps: 299, FixedCents: 39}},
+	})
+	if err != nil {
+		t.Fatalf("failed to save pricing plan: %v", err)
+	}
+
+	_, err = db.Exec(`INSERT INTO invoices (id, account_id, amount_cents, status, description, payment_type, card_last_digits, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		invoiceID, accountID, amountCents, domain.StatusPending, "test", "credit_card", "4242", time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert invoice: %v", err)
+	}
+	defer db.Exec("DELETE FROM invoices WHERE id = $1", invoiceID)
+
+	if err := repo.ApplyTransactionResult(invoiceID, domain.StatusApproved, "integration"); err != nil {
+		t.Fatalf("apply transaction result failed: %v", err)
+	}
+
+	invoice, err := repo.FindByID(invoiceID)
+	if err != nil {
+		t.Fatalf("failed to find invoice: %v", err)
+	}
+	if invoice.FeeCents != 338 || invoice.NetCents != amountCents-338 || invoice.Fee == nil {
+		t.Fatalf("expected fee 338 and net %d, got %d and %d", amountCents-338, invoice.FeeCents, invoice.NetCents)
+	}
+
+	var balance int64
+	if err := db.QueryRow("SELECT balance_cents FROM accounts WHERE id = $1", accountID).Scan(&balance); err != nil {
+		t.Fatalf("failed to query balance: %v", err)
+	}
+	if balance != invoice.NetCents {
+		t.Fatalf("expected balance %d, got %d", invoice.NetCents, balance)
+	}
+}
+
+func TestApplyRefund_ReturnsFeeProRata(t *testing.T) {
+	db := openIntegrationDB(t)
+	defer db.Close()
+
+	repo := NewInvoiceRepository(db)
+	accountID := uuid.New().String()
+	invoiceID := uuid.New().String()
+	amountCents := int64(10000)
+
+	_, err := db.Exec(`INSERT INTO accounts (id, name, email, api_key, api_key_key_id, balance_cents, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		accountID, "integration", "fee-refund@test.local", uuid.New().String(), "v1", 0, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert account: %v", err)
+	}
+	defer db.Exec("DELETE FROM accounts WHERE id = $1", accountID)
+
+	// 2.99% + 0.39 fixos: 299 + 39 = 338.
+	_, err = NewPricingPlanRepository(db).Replace(domain.PricingPlan{
+		AccountID: accountID,
+		Mode:      domain.ModeLive,
+		Currency:  domain.DefaultCurrency,
+		Rates:     map[string]domain.FeeRate{domain.PaymentTypeCreditCard: {PercentBps: 299, FixedCents: 39}},
+	})
+	if err != nil {
+		t.Fatalf("failed to save pricing plan: %v", err)
+	}
+
+	_, err = db.Exec(`INSERT INTO invoices (id, account_id, amount_cents, status, description, payment_type, card_last_digits, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		invoiceID, accountID, amountCents, domain.StatusPending, "test", "credit_card", "4242", time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert invoice: %v", err)
+	}
+	defer db.Exec("DELETE FROM invoices WHERE id = $1", invoiceID)
+
+	if err := repo.ApplyTransactionResult(invoiceID, domain.StatusApproved, "integration"); err != nil {
+		t.Fatalf("apply transaction result failed: %v", err)
+	}
+
+	ledgerTotals := func() (balance, feeRevenue, total int64) {
+		t.Helper()
+		err := db.QueryRow(`
+			SELECT a.balance_cents,
+			       COALESCE(SUM(p.amount_cents) FILTER (WHERE p.ledger_account = 'fee_revenue'), 0),
+			       COALESCE(SUM(p.amount_cents), 0)
+			FROM accounts a
+			LEFT JOIN ledger_postings p ON p.account_id = a.id
+			WHERE a.id = $1
+			GROUP BY a.balance_cents
+		`, accountID).Scan(&balance, &feeRevenue, &total)
+		if err != nil {
+			t.Fatalf("failed to query ledger: %v", err)
+		}
+		return balance, feeRevenue, total
+	}
+
+	// 338 * 2500 / 10000 = 84,5: devolve 84 e o merchant perde apenas 2416.
+	invoice, err := repo.ApplyRefund(invoiceID, 2500, "integration")
+	if err != nil {
+		t.Fatalf("apply refund failed: %v", err)
+	}
+	if invoice.FeeCents != 254 || invoice.NetCents != 7246 {
+		t.Fatalf("expected fee 254 and net 7246, got %d and %d", invoice.FeeCents, invoice.NetCents)
+	}
+	stored, err := repo.FindByID(invoiceID)
+	if err != nil {
+		t.Fatalf("failed to find invoice: %v", err)
+	}
+	if stored.FeeCents != 254 || stored.NetCents != 7246 {
+		t.Fatalf("expected stored fee 254 and net 7246, got %d and %d", stored.FeeCents, stored.NetCents)
+	}
+	if balance, feeRevenue, total := ledgerTotals(); balance != 7246 || feeRevenue != 254 || total != 0 {
+		t.Fatalf("expected balance 7246, fee revenue 254 and balanced ledger, got %d, %d and %d", balance, feeRevenue, total)
+	}
+
+	var feeRefund int64
+	err = db.QueryRow(`
+		SELECT (metadata->>'fee_refund_cents')::bigint FROM invoice_events
+		WHERE invoice_id = $1 AND event_type = 'refund_applied'
+	`, invoiceID).Scan(&feeRefund)
+	if err != nil {
+		t.Fatalf("failed to query refund event: %v", err)
+	}
+	if feeRefund != 84 {
+		t.Fatalf("expected fee_refund_cents 84, got %d", feeRefund)
+	}
+
+	// O estorno do restante devolve toda a tarifa que sobrou, inclusive a fracao arredondada.
+	invoice, err = repo.ApplyRefund(invoiceID, 0, "integration")
+	if err != nil {
+		t.Fatalf("apply refund failed: %v", err)
+	}
+	if invoice.Status != domain.StatusRefunded || invoice.FeeCents != 0 || invoice.NetCents != 0 {
+		t.Fatalf("expected refunded with no fee and net, got %s with %d and %d", invoice.Status, invoice.FeeCents, invoice.NetCents)
+	}
+	if balance, feeRevenue, total := ledgerTotals(); balance != 0 || feeRevenue != 0 || total != 0 {
+		t.Fatalf("expected everything returned, got balance %d, fee revenue %d and total %d", balance, feeRevenue, total)
+	}
+}
+
+func TestApplyTransactionResult_EnqueuesWebhooksOnlyForInvoiceMode(t *testing.T) {
+	db := openIntegrationDB(t)
+	defer db.Close()
+
+	repo := NewInvoiceRepository(db)
+	accountID := uuid.New().String()
+	invoiceID := uuid.New().String()
+
+	_, err := db.Exec(`INSERT INTO accounts (id, name, email, api_key, api_key_key_id, balance_cents, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		accountID, "integration", "webhook-mode@test.local", uuid.New().String(), "v1", 0, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert account: %v", err)
+	}
+	defer db.Exec("DELETE FROM accounts WHERE id = $1", accountID)
+
+	webhooks := webhook.NewRepository(db)
+	endpoints := map[domain.Mode]*webhook.Endpoint{}
+	for _, mode := range []domain.Mode{domain.ModeLive, domain.ModeTest} {
+		endpoint, err := webhook.NewEndpoint(accountID, mode, "https://merchant.example/"+string(mode))
+		if err != nil {
+			t.Fatalf("failed to build endpoint: %v", err)
+		}
+		if err := webhooks.CreateEndpoint(context.Background(), endpoint); err != nil {
+			t.Fatalf("failed to insert endpoint: %v", err)
+		}
+		endpoints[mode] = endpoint
+	}
+
+	_, err = db.Exec(`INSERT INTO invoices (id, account_id, mode, amount_cents, status, description, payment_type, card_last_digits, created_at, updated_at)
+		VALUES ($1, $2, 'test', $3, $4, $5, $6, $7, $8, $9)`,
+		invoiceID, accountID, 1500, domain.StatusPending, "test", "credit_card", "4242", time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert invoice: %v", err)
+	}
+	defer db.Exec("DELETE FROM invoices WHERE id = $1", invoiceID)
+	defer db.Exec("DELETE FROM outbox_events WHERE aggregate_id = $1", invoiceID)
+
+	if err := repo.ApplyTransactionResult(invoiceID, domain.StatusApproved, "integration"); err != nil {
+		t.Fatalf("apply transaction result failed: %v", err)
+	}
+
+	var endpointIDs []string
+	rows, err := db.Query(`SELECT payload->>'endpoint_id' FROM outbox_events WHERE aggregate_id = $1 AND type = $2`,
+		invoiceID, webhook.OutboxEventType)
+	if err != nil {
+		t.Fatalf("failed to query outbox: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var endpointID string
+		if err := rows.Scan(&endpointID); err != nil {
+			t.Fatalf("failed to scan outbox: %v", err)
+		}
+		endpointIDs = append(endpointIDs, endpointID)
+	}
+
+	if len(endpointIDs) != 1 || endpointIDs[0] != endpoints[domain.ModeTest].ID {
+		t.Fatalf("expected a single delivery to the test endpoint %s, got %v", endpoints[domain.ModeTest].ID, endpointIDs)
+	}
+}
+
+func TestBeginLimitScope_ReturnsBusyWhileAnotherScopeHoldsTheLock(t *testing.T) {
+	db := openIntegrationDB(t)
+	defer db.Close()
+
+	repo := NewInvoiceRepository(db)
+	key := "acc-" + uuid.New().String() + ":live:BRL"
+
+	holder, err := repo.BeginLimitScope(context.Background(), key)
+	if err != nil {
+		t.Fatalf("failed to open scope: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := repo.BeginLimitScope(ctx, key); err != domain.ErrLimitBusy {
+		t.Fatalf("expected ErrLimitBusy, got %v", err)
+	}
+
+	// O lock sai com a transacao do escopo, sem conexao extra para liberar.
+	if err := holder.Rollback(); err != nil {
+		t.Fatalf("failed to roll back scope: %v", err)
+	}
+	next, err := repo.BeginLimitScope(context.Background(), key)
+	if err != nil {
+		t.Fatalf("expected lock after rollback, got %v", err)
+	}
+	if err := next.Commit(); err != nil {
+		t.Fatalf("failed to commit scope: %v", err)
+	}
+}
+
+func TestApplyTransactionResult_DoesNotApproveInvoicesInManualReview(t *testing.T) {
+	db := openIntegrationDB(t)
+	defer db.Close()
+
+	repo := NewInvoiceRepository(db)
+	accountID := uuid.New().String()
+	invoiceID := uuid.New().String()
+	amountCents := int64(1800)
+
+	_, err := db.Exec(`INSERT INTO accounts (id, name, email, api_key, api_key_key_id, balance_cents, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		accountID, "integration", "review@test.local", uuid.New().String(), "v1", 0, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert account: %v", err)
+	}
+	defer db.Exec("DELETE FROM accounts WHERE id = $1", accountID)
+
+	_, err = db.Exec(`INSERT INTO invoices (id, account_id, amount_cents, status, description, payment_type, card_last_digits, manual_review, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, true, $8, $9)`,
+		invoiceID, accountID, amountCents, domain.StatusPending, "test", "credit_card", "4242", time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert invoice: %v", err)
+	}
+	defer db.Exec("DELETE FROM invoices WHERE id = $1", invoiceID)
+
+	// O resultado automatico e apenas auditado.
+	if err := repo.ApplyTransactionResult(invoiceID, domain.StatusApproved, "integration"); err != nil {
+		t.Fatalf("apply transaction result failed: %v", err)
+	}
+	invoice, err := repo.FindByID(invoiceID)
+	if err != nil {
+		t.Fatalf("failed to find invoice: %v", err)
+	}
+	if invoice.Status != domain.StatusPending || !invoice.ManualReview {
+		t.Fatalf("expected pending invoice in review, got %s (review %v)", invoice.Status, invoice.ManualReview)
+	}
+
+	approved, err := repo.ResolveReview(invoiceID, domain.StatusApproved, map[string]any{"operator": "ops"}, "integration")
+	if err != nil {
+		t.Fatalf("resolve review failed: %v", err)
+	}
+	if approved.Status != domain.StatusApproved || approved.ManualReview {
+		t.Fatalf("expected approved invoice out of review, got %s (review %v)", approved.Status, approved.ManualReview)
+	}
+
+	var balance int64
+	if err := db.QueryRow("SELECT balance_cents FROM accounts WHERE id = $1", accountID).Scan(&balance); err != nil {
+		t.Fatalf("failed to query balance: %v", err)
+	}
+	if balance != amountCents {
+		t.Fatalf("expected balance %d after review approval, got %d", amountCents, balance)
+	}
+
+	if _, err := repo.ResolveReview(invoiceID, domain.StatusRejected, nil, "integration"); err != domain.ErrInvoiceNotInReview {
+		t.Fatalf("expected ErrInvoiceNotInReview, got %v", err)
+	}
+}
+
+func TestSettlePix_RecordsPaymentsOnClosedInvoicesForRefund(t *testing.T) {
+	db := openIntegrationDB(t)
+	defer db.Close()
+
+	repo := NewInvoiceRepository(db)
+	accountID := uuid.New().String()
+	invoiceID := uuid.New().String()
+	txid := "late" + uuid.New().String()[:8]
+	amountCents := int64(2500)
+
+	_, err := db.Exec(`INSERT INTO accounts (id, name, email, api_key, api_key_key_id, balance_cents, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		accountID, "integration", "late-pix@test.local", uuid.New().String(), "v1", 0, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert account: %v", err)
+	}
+	defer db.Exec("DELETE FROM accounts WHERE id = $1", accountID)
+
+	_, err = db.Exec(`INSERT INTO invoices (id, account_id, amount_cents, status, description, payment_type, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		invoiceID, accountID, amountCents, domain.StatusExpired, "test", "pix", time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert invoice: %v", err)
+	}
+	defer db.Exec("DELETE FROM invoices WHERE id = $1", invoiceID)
+
+	_, err = db.Exec(`INSERT INTO pix_charges (invoice_id, txid, payload, expires_at) VALUES ($1, $2, $3, $4)`,
+		invoiceID, txid, "payload", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("failed to insert pix charge: %v", err)
+	}
+
+	payment := domain.PixPayment{TxID: txid, EndToEndID: "E" + uuid.New().String()[:30], AmountCents: amountCents, PaidAt: time.Now()}
+	// Notificacoes repetidas do mesmo endToEndId registram uma unica devolucao.
+	for i := 0; i < 2; i++ {
+		if _, err := repo.SettlePix(payment, "integration"); err != domain.ErrPixInvoiceClosed {
+			t.Fatalf("expected ErrPixInvoiceClosed, got %v", err)
+		}
+	}
+
+	var paid bool
+	if err := db.QueryRow("SELECT paid_at IS NOT NULL FROM pix_charges WHERE invoice_id = $1", invoiceID).Scan(&paid); err != nil {
+		t.Fatalf("failed to query pix charge: %v", err)
+	}
+	var balance int64
+	if err := db.QueryRow("SELECT balance_cents FROM accounts WHERE id = $1", accountID).Scan(&balance); err != nil {
+		t.Fatalf("failed to query balance: %v", err)
+	}
+	if paid || balance != 0 {
+		t.Fatalf("expected unpaid charge and no credit, got paid %v balance %d", paid, balance)
+	}
+
+	var unapplied, events int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM pix_unapplied_payments WHERE invoice_id = $1 AND reason = 'invoice_closed' AND refunded_at IS NULL`, invoiceID).Scan(&unapplied); err != nil {
+		t.Fatalf("failed to query unapplied payments: %v", err)
+	}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM invoice_events WHERE invoice_id = $1 AND event_type = 'late_pix_payment'`, invoiceID).Scan(&events); err != nil {
+		t.Fatalf("failed to query invoice events: %v", err)
+	}
+	if unapplied != 1 || events != 1 {
+		t.Fatalf("expected one unapplied payment and one event, got %d and %d", unapplied, events)
+	}
+}
+
+func TestApplyRefund_RejectsRefundLargerThanAvailableAfterPayout(t *testing.T) {
+	db := openIntegrationDB(t)
+	defer db.Close()
+
+	repo := NewInvoiceRepository(db)
+	payouts := NewPayoutRepository(db)
+	accountID := uuid.New().String()
+	invoiceID := uuid.New().String()
+	amountCents := int64(5000)
+
+	_, err := db.Exec(`INSERT INTO accounts (id, name, email, api_key, api_key_key_id, balance_cents, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		accountID, "integration", "refund-payout@test.local", uuid.New().String(), "v1", 0, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert account: %v", err)
+	}
+	defer db.Exec("DELETE FROM accounts WHERE id = $1", accountID)
+
+	_, err = db.Exec(`INSERT INTO invoices (id, account_id, amount_cents, status, description, payment_type, card_last_digits, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		invoiceID, accountID, amountCents, domain.StatusPending, "test", "credit_card", "4242", time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert invoice: %v", err)
+	}
+	defer db.Exec("DELETE FROM invoices WHERE id = $1", invoiceID)
+
+	if err := repo.ApplyTransactionResult(invoiceID, domain.StatusApproved, "integration"); err != nil {
+		t.Fatalf("apply transaction result failed: %v", err)
+	}
+
+	bankAccount, err := domain.NewBankAccount(accountID, domain.ModeLive, "Loja", "529.982.247-25", "341", "0001", "12345-6", domain.BankAccountChecking, time.Now())
+	if err != nil {
+		t.Fatalf("failed to build bank account: %v", err)
+	}
+	if err := payouts.SaveBankAccount(bankAccount); err != nil {
+		t.Fatalf("failed to save bank account: %v", err)
+	}
+	payout, err := domain.NewPayout(accountID, domain.ModeLive, bankAccount.ID, 4000, "BRL", "", time.Now())
+	if err != nil {
+		t.Fatalf("failed to build payout: %v", err)
+	}
+	if err := payouts.Create(payout, "payout_requested", []byte(`{}`), "integration"); err != nil {
+		t.Fatalf("failed to create payout: %v", err)
+	}
+
+	// Restam 1000 disponiveis: estornar 2000 deixaria o saldo negativo.
+	if _, err := repo.ApplyRefund(invoiceID, 2000, "integration"); err != domain.ErrInsufficientBalance {
+		t.Fatalf("expected ErrInsufficientBalance, got %v", err)
+	}
+	invoice, err := repo.FindByID(invoiceID)
+	if err != nil {
+		t.Fatalf("failed to find invoice: %v", err)
+	}
+	if invoice.Status != domain.StatusApproved || invoice.RefundedCents != 0 {
+		t.Fatalf("expected untouched invoice, got %s with %d refunded", invoice.Status, invoice.RefundedCents)
+	}
+
+	if _, err := repo.ApplyRefund(invoiceID, 1000, "integration"); err != nil {
+		t.Fatalf("expected refund within the available balance, got %v", err)
+	}
+	var balance int64
+	if err := db.QueryRow(`SELECT balance_cents FROM account_balances WHERE account_id = $1 AND mode = 'live'`, accountID).Scan(&balance); err != nil {
+		t.Fatalf("failed to query balance: %v", err)
+	}
+	if balance != 0 {
+		t.Fatalf("expected balance 0 after payout and refund, got %d", balance)
+	}
+}
+
+func TestPayoutTransition_FailingPendingPayoutCancelsItsRequest(t *testing.T) {
+	db := openIntegrationDB(t)
+	defer db.Close()
+
+	repo := NewInvoiceRepository(db)
+	payouts := NewPayoutRepository(db)
+	accountID := uuid.New().String()
+	invoiceID := uuid.New().String()
+
+	_, err := db.Exec(`INSERT INTO accounts (id, name, email, api_key, api_key_key_id, balance_cents, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		accountID, "integration", "payout-cancel@test.local", uuid.New().String(), "v1", 0, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert account: %v", err)
+	}
+	defer db.Exec("DELETE FROM accounts WHERE id = $1", accountID)
+
+	_, err = db.Exec(`INSERT INTO invoices (id, account_id, amount_cents, status, description, payment_type, card_last_digits, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		invoiceID, accountID, 5000, domain.StatusPending, "test", "credit_card", "4242", time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert invoice: %v", err)
+	}
+	defer db.Exec("DELETE FROM invoices WHERE id = $1", invoiceID)
+
+	if err := repo.ApplyTransactionResult(invoiceID, domain.StatusApproved, "integration"); err != nil {
+		t.Fatalf("apply transaction result failed: %v", err)
+	}
+
+	bankAccount, err := domain.NewBankAccount(accountID, domain.ModeLive, "Loja", "529.982.247-25", "341", "0001", "12345-6", domain.BankAccountChecking, time.Now())
+	if err != nil {
+		t.Fatalf("failed to build bank account: %v", err)
+	}
+	if err := payouts.SaveBankAccount(bankAccount); err != nil {
+		t.Fatalf("failed to save bank account: %v", err)
+	}
+	requestPayout := func() *domain.Payout {
+		t.Helper()
+		payout, err := domain.NewPayout(accountID, domain.ModeLive, bankAccount.ID, 2000, "BRL", "", time.Now())
+		if err != nil {
+			t.Fatalf("failed to build payout: %v", err)
+		}
+		if err := payouts.Create(payout, "payout_requested", []byte(`{}`), "integration"); err != nil {
+			t.Fatalf("failed to create payout: %v", err)
+		}
+		return payout
+	}
+	defer db.Exec(`DELETE FROM outbox_events WHERE aggregate_id IN (SELECT id::text FROM payouts WHERE account_id = $1)`, accountID)
+	publishable := func(payoutID string) int {
+		t.Helper()
+		var count int
+		err := db.QueryRow(`
+			SELECT COUNT(*) FROM outbox_events
+			WHERE aggregate_id = $1 AND status IN ('pending', 'failed', 'processing')
+		`, payoutID).Scan(&count)
+		if err != nil {
+			t.Fatalf("failed to query outbox: %v", err)
+		}
+		return count
+	}
+
+	// Pedido ainda na fila (em nova tentativa): falhar o saque cancela o evento.
+	payout := requestPayout()
+	if _, err := db.Exec(`UPDATE outbox_events SET status = 'failed' WHERE aggregate_id = $1`, payout.ID); err != nil {
+		t.Fatalf("failed to update outbox: %v", err)
+	}
+	if _, err := payouts.Transition(payout.ID, domain.PayoutFailed, "conta encerrada", time.Now()); err != nil {
+		t.Fatalf("expected pending payout to fail, got %v", err)
+	}
+	if remaining := publishable(payout.ID); remaining != 0 {
+		t.Fatalf("expected no publishable event, got %d", remaining)
+	}
+	var balance int64
+	if err := db.QueryRow(`SELECT balance_cents FROM account_balances WHERE account_id = $1 AND mode = 'live'`, accountID).Scan(&balance); err != nil {
+		t.Fatalf("failed to query balance: %v", err)
+	}
+	if balance != 5000 {
+		t.Fatalf("expected the payout returned to the balance, got %d", balance)
+	}
+
+	// Pedido sendo publicado: o saque so falha depois que a publicacao terminar.
+	payout = requestPayout()
+	if _, err := db.Exec(`UPDATE outbox_events SET status = 'processing' WHERE aggregate_id = $1`, payout.ID); err != nil {
+		t.Fatalf("failed to update outbox: %v", err)
+	}
+	if _, err := payouts.Transition(payout.ID, domain.PayoutFailed, "conta encerrada", time.Now()); err != domain.ErrPayoutRequestInFlight {
+		t.Fatalf("expected ErrPayoutRequestInFlight, got %v", err)
+	}
+	stored, err := payouts.FindByID(payout.ID)
+	if err != nil {
+		t.Fatalf("failed to find payout: %v", err)
+	}
+	if stored.Status != domain.PayoutPending {
+		t.Fatalf("expected payout still pending, got %s", stored.Status)
+	}
+}
@@ -0,0 +1,440 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/ledger"
+)
+
+const bankAccountColumns = `id, account_id, mode, holder_name, holder_document, holder_document_type,
+	bank_code, branch, account_number, account_type, created_at`
+
+const payoutColumns = `id, account_id, mode, bank_account_id, amount_cents, currency, status, description,
+	failure_reason, created_at, updated_at, paid_at, failed_at`
+
+// PayoutRepository persiste contas bancarias e saques. Toda mudanca de saldo de um saque passa
+// pelo ledger na mesma transacao da mudanca de status.
+type PayoutRepository struct {
+	db *sql.DB
+}
+
+// NewPayoutRepository cria um novo repositório de saques
+func NewPayoutRepository(db *sql.DB) *PayoutRepository {
+	return &PayoutRepository{db: db}
+}
+
+func (r *PayoutRepository) SaveBankAccount(bankAccount *domain.BankAccount) error {
+	_, err := r.db.Exec(`
+		INSERT INTO bank_accounts (id, account_id, mode, holder_name, holder_document, holder_document_type,
+			bank_code, branch, account_number, account_type, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		bankAccount.ID,
+		bankAccount.AccountID,
+		bankAccount.Mode,
+		bankAccount.HolderName,
+		bankAccount.HolderDocument,
+		bankAccount.HolderDocumentType,
+		bankAccount.BankCode,
+		bankAccount.Branch,
+		bankAccount.AccountNumber,
+		bankAccount.AccountType,
+		bankAccount.CreatedAt,
+	)
+	return err
+}
+
+func (r *PayoutRepository) FindBankAccountByID(id string) (*domain.BankAccount, error) {
+	bankAccount, err := scanBankAccount(r.db.QueryRow(`
+		SELECT `+bankAccountColumns+` FROM bank_accounts WHERE id = $1 AND deleted_at IS NULL
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrBankAccountNotFound
+	}
+	return bankAccount, err
+}
+
+func (r *PayoutRepository) ListBankAccounts(accountID string, mode domain.Mode) ([]*domain.BankAccount, error) {
+	rows, err := r.db.Query(`
+		SELECT `+bankAccountColumns+`
+		FROM bank_accounts
+		WHERE account_id = $1 AND mode = $2 AND deleted_at IS NULL
+		ORDER BY created_at DESC
+	`, accountID, mode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bankAccounts := make([]*domain.BankAccount, 0)
+	for rows.Next() {
+		bankAccount, err := scanBankAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		bankAccounts = append(bankAccounts, bankAccount)
+	}
+	return bankAccounts, rows.Err()
+}
+
+// DeleteBankAccount marca a conta bancaria como removida; saques ja criados nao sao afetados.
+func (r *PayoutRepository) DeleteBankAccount(id string, at time.Time) error {
+	result, err := r.db.Exec(`
+		UPDATE bank_accounts SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL
+	`, at, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, domain.ErrBankAccountNotFound)
+}
+
+// Create reserva o valor do saque (merchant_balance -> payout_clearing) e grava o saque e o
+// evento do outbox na mesma transacao.
+func (r *PayoutRepository) Create(payout *domain.Payout, eventType string, payload []byte, correlationID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Bloqueia a conta antes do saldo, na mesma ordem de ledger.Post, para nao disputar
+	// locks em ordem inversa com aprovacoes de faturas concorrentes.
+	var accountID string
+	err = tx.QueryRow(`SELECT id FROM accounts WHERE id = $1 FOR UPDATE`, payout.AccountID).Scan(&accountID)
+	if err == sql.ErrNoRows {
+		return domain.ErrAccountNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	// Apenas o saldo disponivel pode ser sacado; valores ainda pendentes de liquidacao ficam de fora.
+	var balance, pending int64
+	err = tx.QueryRow(`
+		SELECT balance_cents, pending_cents FROM account_balances
+		WHERE account_id = $1 AND mode = $2 AND currency = $3
+		FOR UPDATE
+	`, payout.AccountID, payout.Mode, payout.Currency).Scan(&balance, &pending)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if balance-pending < payout.AmountCents {
+		return domain.ErrInsufficientBalance
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO payouts (id, account_id, mode, bank_account_id, amount_cents, currency, status, description,
+			failure_reason, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		payout.ID,
+		payout.AccountID,
+		payout.Mode,
+		payout.BankAccountID,
+		payout.AmountCents,
+		payout.Currency,
+		payout.Status,
+		payout.Description,
+		payout.FailureReason,
+		payout.CreatedAt,
+		payout.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	entry, err := payoutEntry(payout, ledger.EntryPayoutRequested, ledger.AccountMerchantBalance, ledger.AccountPayoutClearing)
+	if err != nil {
+		return err
+	}
+	if err := ledger.Post(tx, entry); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO outbox_events (id, aggregate_id, type, payload, status, attempts, next_attempt_at, correlation_id, created_at, updated_at)
+         VALUES (gen_random_uuid(), $1, $2, $3, 'pending', 0, NOW(), $4, NOW(), NOW())`,
+		payout.ID, eventType, payload, correlationID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PayoutRepository) FindByID(id string) (*domain.Payout, error) {
+	payout, err := scanPayout(r.db.QueryRow(`SELECT `+payoutColumns+` FROM payouts WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrPayoutNotFound
+	}
+	return payout, err
+}
+
+// ListByAccountID lista os saques da conta com paginacao por keyset em (created_at, id),
+// do mais recente para o mais antigo.
+func (r *PayoutRepository) ListByAccountID(accountID string, filter domain.PayoutFilter) (*domain.PayoutPage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = domain.DefaultPayoutPageSize
+	}
+	if limit > domain.MaxPayoutPageSize {
+		limit = domain.MaxPayoutPageSize
+	}
+
+	conditions := []string{"account_id = $1"}
+	args := []any{accountID}
+	addCondition := func(format string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if filter.Mode != "" {
+		addCondition("mode = $%d", filter.Mode)
+	}
+	if filter.Status != "" {
+		addCondition("status = $%d", filter.Status)
+	}
+	if filter.Cursor != nil {
+		args = append(args, filter.Cursor.CreatedAt, filter.Cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	// Busca um item a mais para saber se existe proxima pagina.
+	args = append(args, limit+1)
+	query := fmt.Sprintf(
+		`SELECT `+payoutColumns+` FROM payouts WHERE %s ORDER BY created_at DESC, id DESC LIMIT $%d`,
+		strings.Join(conditions, " AND "),
+		len(args),
+	)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &domain.PayoutPage{Payouts: make([]*domain.Payout, 0, limit)}
+	for rows.Next() {
+		payout, err := scanPayout(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Payouts = append(page.Payouts, payout)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Payouts) > limit {
+		page.Payouts = page.Payouts[:limit]
+		last := page.Payouts[limit-1]
+		page.NextCursor = &domain.InvoiceCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	return page, nil
+}
+
+// Transition aplica a mudanca de status com o saque bloqueado. failed devolve a reserva ao
+// saldo (payout_clearing -> merchant_balance); paid baixa a reserva para settlement_clearing.
+// Um saque pending que falha tem o pedido ainda nao publicado cancelado na mesma transacao.
+// Repetir o status atual nao altera o saque, para que o processador possa reenviar atualizacoes.
+func (r *PayoutRepository) Transition(id string, status domain.PayoutStatus, failureReason string, at time.Time) (*domain.Payout, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	payout, err := scanPayout(tx.QueryRow(`SELECT `+payoutColumns+` FROM payouts WHERE id = $1 FOR UPDATE`, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrPayoutNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if payout.Status == status {
+		return payout, tx.Commit()
+	}
+
+	fromStatus := payout.Status
+	if err := payout.Transition(status, failureReason, at); err != nil {
+		return nil, err
+	}
+	if fromStatus == domain.PayoutPending && status == domain.PayoutFailed {
+		if err := cancelPayoutRequest(tx, payout.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE payouts
+		SET status = $1, failure_reason = $2, updated_at = $3, paid_at = $4, failed_at = $5
+		WHERE id = $6
+	`, payout.Status, payout.FailureReason, payout.UpdatedAt, payout.PaidAt, payout.FailedAt, payout.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry *ledger.Entry
+	switch status {
+	case domain.PayoutFailed:
+		entry, err = payoutEntry(payout, ledger.EntryPayoutFailed, ledger.AccountPayoutClearing, ledger.AccountMerchantBalance)
+	case domain.PayoutPaid:
+		entry, err = payoutEntry(payout, ledger.EntryPayoutPaid, ledger.AccountPayoutClearing, ledger.AccountSettlementClearing)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if entry != nil {
+		if err := ledger.Post(tx, entry); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return payout, nil
+}
+
+// ListDeadLettered lista os saques pending mais antigos cujo evento do outbox ficou dead. O
+// valor segue reservado ate o operador reenviar o evento ou marcar o saque como failed.
+func (r *PayoutRepository) ListDeadLettered(limit int) ([]*domain.Payout, error) {
+	rows, err := r.db.Query(`
+		SELECT `+prefixColumns("p", payoutColumns)+`
+		FROM payouts p
+		WHERE p.status = 'pending'
+		  AND EXISTS (SELECT 1 FROM outbox_events o WHERE o.aggregate_id = p.id::text AND o.status = 'dead')
+		ORDER BY p.created_at ASC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payouts := make([]*domain.Payout, 0)
+	for rows.Next() {
+		payout, err := scanPayout(rows)
+		if err != nil {
+			return nil, err
+		}
+		payouts = append(payouts, payout)
+	}
+	return payouts, rows.Err()
+}
+
+// Redeliver volta o evento dead do saque para pending, com as tentativas zeradas. Saques que ja
+// sairam de pending nao sao reenviados ao processador.
+func (r *PayoutRepository) Redeliver(id string) error {
+	result, err := r.db.Exec(`
+		UPDATE outbox_events
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+		WHERE aggregate_id = $1
+		  AND status = 'dead'
+		  AND EXISTS (SELECT 1 FROM payouts WHERE id::text = $1 AND status = 'pending')
+	`, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, domain.ErrPayoutNotDeadLettered)
+}
+
+// cancelPayoutRequest cancela o evento do outbox que ainda nao foi publicado, para que o pedido
+// nao chegue ao processador depois que o valor voltou ao saldo. O bloqueio espera um ClaimPending
+// em andamento; um evento em processing pode estar sendo publicado e retorna ErrPayoutRequestInFlight.
+func cancelPayoutRequest(tx *sql.Tx, payoutID string) error {
+	rows, err := tx.Query(`SELECT status FROM outbox_events WHERE aggregate_id = $1 FOR UPDATE`, payoutID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var status string
+		if err := rows.Scan(&status); err != nil {
+			return err
+		}
+		if status == "processing" {
+			return domain.ErrPayoutRequestInFlight
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE outbox_events SET status = 'cancelled', updated_at = NOW()
+		WHERE aggregate_id = $1 AND status IN ('pending', 'failed', 'dead')
+	`, payoutID)
+	return err
+}
+
+// payoutEntry cria o lancamento do saque no modo dele, com o payout_id nos metadados.
+func payoutEntry(payout *domain.Payout, entryType, from, to string) (*ledger.Entry, error) {
+	metadata, err := json.Marshal(map[string]string{"payout_id": payout.ID})
+	if err != nil {
+		return nil, err
+	}
+	entry := ledger.Transfer(payout.AccountID, "", payout.Currency, entryType, from, to, payout.AmountCents)
+	entry.Mode = payout.Mode
+	entry.Metadata = metadata
+	return entry, nil
+}
+
+func scanBankAccount(row rowScanner) (*domain.BankAccount, error) {
+	var bankAccount domain.BankAccount
+	err := row.Scan(
+		&bankAccount.ID,
+		&bankAccount.AccountID,
+		&bankAccount.Mode,
+		&bankAccount.HolderName,
+		&bankAccount.HolderDocument,
+		&bankAccount.HolderDocumentType,
+		&bankAccount.BankCode,
+		&bankAccount.Branch,
+		&bankAccount.AccountNumber,
+		&bankAccount.AccountType,
+		&bankAccount.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &bankAccount, nil
+}
+
+func scanPayout(row rowScanner) (*domain.Payout, error) {
+	var payout domain.Payout
+	var paidAt sql.NullTime
+	var failedAt sql.NullTime
+	err := row.Scan(
+		&payout.ID,
+		&payout.AccountID,
+		&payout.Mode,
+		&payout.BankAccountID,
+		&payout.AmountCents,
+		&payout.Currency,
+		&payout.Status,
+		&payout.Description,
+		&payout.FailureReason,
+		&payout.CreatedAt,
+		&payout.UpdatedAt,
+		&paidAt,
+		&failedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if paidAt.Valid {
+		payout.PaidAt = &paidAt.Time
+	}
+	if failedAt.Valid {
+		payout.FailedAt = &failedAt.Time
+	}
+	return &payout, nil
+}
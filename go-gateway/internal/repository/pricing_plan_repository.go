@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/lib/pq"
+)
+
+const pricingPlanColumns = `account_id, mode, currency, rates, created_at, updated_at`
+
+// rowQuerier e implementado por *sql.DB e *sql.Tx.
+type rowQuerier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// PricingPlanRepository lida com as tarifas por conta, modo e moeda.
+type PricingPlanRepository struct {
+	db *sql.DB
+}
+
+func NewPricingPlanRepository(db *sql.DB) *PricingPlanRepository {
+	return &PricingPlanRepository{db: db}
+}
+
+// Get retorna o plano do modo e da moeda. Retorna ErrPricingPlanNotFound se a conta nao tiver plano.
+func (r *PricingPlanRepository) Get(accountID string, mode domain.Mode, currency string) (*domain.PricingPlan, error) {
+	plan, err := findPricingPlan(r.db, accountID, mode, currency)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, domain.ErrPricingPlanNotFound
+	}
+	return plan, nil
+}
+
+// ListByAccountID lista os planos da conta em todos os modos e moedas.
+func (r *PricingPlanRepository) ListByAccountID(accountID string) ([]*domain.PricingPlan, error) {
+	rows, err := r.db.Query(`
+		SELECT `+pricingPlanColumns+`
+		FROM pricing_plans
+		WHERE account_id = $1
+		ORDER BY mode, currency
+	`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plans := make([]*domain.PricingPlan, 0)
+	for rows.Next() {
+		plan, err := scanPricingPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+	return plans, rows.Err()
+}
+
+// Replace grava o plano do modo e da moeda. Faturas ja creditadas mantem a tarifa calculada.
+// Retorna ErrAccountNotFound se a conta nao existir.
+func (r *PricingPlanRepository) Replace(plan domain.PricingPlan) (*domain.PricingPlan, error) {
+	rates, err := json.Marshal(nonNilRates(plan.Rates))
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := scanPricingPlan(r.db.QueryRow(`
+		INSERT INTO pricing_plans (account_id, mode, currency, rates, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (account_id, mode, currency) DO UPDATE SET
+			rates = EXCLUDED.rates,
+			updated_at = EXCLUDED.updated_at
+		RETURNING `+pricingPlanColumns,
+		plan.AccountID, plan.Mode, plan.Currency, rates, time.Now(),
+	))
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return nil, domain.ErrAccountNotFound
+		}
+		return nil, err
+	}
+	return current, nil
+}
+
+// Delete remove o plano do modo e da moeda; as proximas faturas nao pagam tarifa.
+func (r *PricingPlanRepository) Delete(accountID string, mode domain.Mode, currency string) error {
+	result, err := r.db.Exec(`DELETE FROM pricing_plans WHERE account_id = $1 AND mode = $2 AND currency = $3`,
+		accountID, mode, currency)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, domain.ErrPricingPlanNotFound)
+}
+
+// findPricingPlan le o plano com db ou com a transacao do chamador; retorna nil sem plano.
+func findPricingPlan(q rowQuerier, accountID string, mode domain.Mode, currency string) (*domain.PricingPlan, error) {
+	plan, err := scanPricingPlan(q.QueryRow(`
+		SELECT `+pricingPlanColumns+`
+		FROM pricing_plans
+		WHERE account_id = $1 AND mode = $2 AND currency = $3
+	`, accountID, mode, currency))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return plan, err
+}
+
+func scanPricingPlan(row rowScanner) (*domain.PricingPlan, error) {
+	var plan domain.PricingPlan
+	var rates []byte
+	if err := row.Scan(&plan.AccountID, &plan.Mode, &plan.Currency, &rates, &plan.CreatedAt, &plan.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(rates, &plan.Rates); err != nil {
+		return nil, err
+	}
+	plan.Rates = nonNilRates(plan.Rates)
+	return &plan, nil
+}
+
+func nonNilRates(rates map[string]domain.FeeRate) map[string]domain.FeeRate {
+	if rates == nil {
+		return map[string]domain.FeeRate{}
+	}
+	return rates
+}
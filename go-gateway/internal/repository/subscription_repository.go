@@ -0,0 +1,501 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+)
+
+const planColumns = `id, account_id, mode, name, amount_cents, currency, billing_interval, interval_count, trial_days, active, created_at, updated_at`
+
+const subscriptionColumns = `id, account_id, mode, plan_id, customer_id, card_token, status, billing_anchor, current_period_start, current_period_end,
+	next_billing_at, failed_attempts, pending_invoice_id, latest_invoice_id, cancel_at_period_end, canceled_at, billing_invoice_id, version, created_at, updated_at`
+
+// SubscriptionRepository persiste planos, assinaturas e o historico de eventos das assinaturas.
+type SubscriptionRepository struct {
+	db *sql.DB
+}
+
+// NewSubscriptionRepository cria um novo repositório de assinaturas
+func NewSubscriptionRepository(db *sql.DB) *SubscriptionRepository {
+	return &SubscriptionRepository{db: db}
+}
+
+func (r *SubscriptionRepository) SavePlan(plan *domain.Plan) error {
+	_, err := r.db.Exec(`
+		INSERT INTO subscription_plans (id, account_id, mode, name, amount_cents, currency, billing_interval, interval_count, trial_days, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`,
+		plan.ID,
+		plan.AccountID,
+		plan.Mode,
+		plan.Name,
+		plan.AmountCents,
+		plan.Currency,
+		plan.Interval,
+		plan.IntervalCount,
+		plan.TrialDays,
+		plan.Active,
+		plan.CreatedAt,
+		plan.UpdatedAt,
+	)
+	return err
+}
+
+func (r *SubscriptionRepository) FindPlanByID(id string) (*domain.Plan, error) {
+	plan, err := scanPlan(r.db.QueryRow(`SELECT `+planColumns+` FROM subscription_plans WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrPlanNotFound
+	}
+	return plan, err
+}
+
+// ListPlans lista os planos da conta no modo, do mais recente para o mais antigo.
+func (r *SubscriptionRepository) ListPlans(accountID string, mode domain.Mode) ([]*domain.Plan, error) {
+	rows, err := r.db.Query(`
+		SELECT `+planColumns+`
+		FROM subscription_plans
+		WHERE account_id = $1 AND mode = $2
+		ORDER BY created_at DESC, id DESC
+	`, accountID, mode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plans := make([]*domain.Plan, 0)
+	for rows.Next() {
+		plan, err := scanPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+	return plans, rows.Err()
+}
+
+func (r *SubscriptionRepository) ArchivePlan(id string, at time.Time) error {
+	result, err := r.db.Exec(`UPDATE subscription_plans SET active = FALSE, updated_at = $1 WHERE id = $2`, at, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, domain.ErrPlanNotFound)
+}
+
+func (r *SubscriptionRepository) Save(subscription *domain.Subscription, event *domain.SubscriptionEvent) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	subscription.Version = 1
+	_, err = tx.Exec(`
+		INSERT INTO subscriptions (
+			id, account_id, mode, plan_id, customer_id, card_token, status, billing_anchor, current_period_start, current_period_end,
+			next_billing_at, failed_attempts, pending_invoice_id, latest_invoice_id, cancel_at_period_end, canceled_at, version, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+	`,
+		subscription.ID,
+		subscription.AccountID,
+		subscription.Mode,
+		subscription.PlanID,
+		subscription.CustomerID,
+		subscription.CardToken,
+		subscription.Status,
+		subscription.BillingAnchor,
+		subscription.CurrentPeriodStart,
+		subscription.CurrentPeriodEnd,
+		subscription.NextBillingAt,
+		subscription.FailedAttempts,
+		nullableUUID(subscription.PendingInvoiceID),
+		nullableUUID(subscription.LatestInvoiceID),
+		subscription.CancelAtPeriodEnd,
+		subscription.CanceledAt,
+		subscription.Version,
+		subscription.CreatedAt,
+		subscription.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if err := insertSubscriptionEvents(tx, []*domain.SubscriptionEvent{event}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SubscriptionRepository) FindByID(id string) (*domain.Subscription, error) {
+	subscription, err := scanSubscription(r.db.QueryRow(`SELECT `+subscriptionColumns+` FROM subscriptions WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+	return subscription, err
+}
+
+// Update grava o estado mutavel da assinatura com controle otimista por version.
+func (r *SubscriptionRepository) Update(subscription *domain.Subscription, events []*domain.SubscriptionEvent) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE subscriptions
+		SET status = $1, current_period_start = $2, current_period_end = $3, next_billing_at = $4, failed_attempts = $5,
+			pending_invoice_id = $6, latest_invoice_id = $7, cancel_at_period_end = $8, canceled_at = $9,
+			billing_invoice_id = $10, version = version + 1, updated_at = $11
+		WHERE id = $12 AND version = $13
+	`,
+		subscription.Status,
+		subscription.CurrentPeriodStart,
+		subscription.CurrentPeriodEnd,
+		subscription.NextBillingAt,
+		subscription.FailedAttempts,
+		nullableUUID(subscription.PendingInvoiceID),
+		nullableUUID(subscription.LatestInvoiceID),
+		subscription.CancelAtPeriodEnd,
+		subscription.CanceledAt,
+		nullableUUID(subscription.BillingInvoiceID),
+		subscription.UpdatedAt,
+		subscription.ID,
+		subscription.Version,
+	)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result, domain.ErrSubscriptionConflict); err != nil {
+		return err
+	}
+	if err := insertSubscriptionEvents(tx, events); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	subscription.Version++
+	return nil
+}
+
+// ClaimDue bloqueia as assinaturas vencidas (SKIP LOCKED deixa de fora as linhas reservadas
+// por outra instancia), confere de novo se ainda podem ser cobradas e, na mesma transacao,
+// adia next_billing_at e reserva o ID da fatura da tentativa em billing_invoice_id.
+func (r *SubscriptionRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]*domain.Subscription, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		SELECT `+subscriptionColumns+`
+		FROM subscriptions
+		WHERE status <> 'canceled'
+		  AND pending_invoice_id IS NULL
+		  AND next_billing_at <= $1
+		ORDER BY next_billing_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	locked := make([]*domain.Subscription, 0)
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		locked = append(locked, subscription)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	leaseUntil := now.Add(lease)
+	subscriptions := make([]*domain.Subscription, 0, len(locked))
+	for _, subscription := range locked {
+		if !subscription.Billable(now) {
+			continue
+		}
+		subscription.ReserveInvoice()
+		if _, err := tx.Exec(`
+			UPDATE subscriptions
+			SET next_billing_at = $1, billing_invoice_id = $2, version = version + 1
+			WHERE id = $3
+		`, leaseUntil, subscription.BillingInvoiceID, subscription.ID); err != nil {
+			return nil, err
+		}
+		subscription.NextBillingAt = &leaseUntil
+		subscription.Version++
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// ListSettledInvoices busca assinaturas cuja fatura pendente saiu de pending.
+func (r *SubscriptionRepository) ListSettledInvoices(limit int) ([]domain.SubscriptionInvoice, error) {
+	rows, err := r.db.Query(`
+		SELECT `+prefixColumns("s", subscriptionColumns)+`, i.status
+		FROM subscriptions s
+		JOIN invoices i ON i.id = s.pending_invoice_id
+		WHERE i.status <> 'pending'
+		ORDER BY s.updated_at
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settled := make([]domain.SubscriptionInvoice, 0)
+	for rows.Next() {
+		var status domain.Status
+		subscription, err := scanSubscriptionWith(rows, &status)
+		if err != nil {
+			return nil, err
+		}
+		settled = append(settled, domain.SubscriptionInvoice{Subscription: subscription, InvoiceStatus: status})
+	}
+	return settled, rows.Err()
+}
+
+// ListByAccountID lista as assinaturas da conta com paginacao por keyset em (created_at, id),
+// da mais recente para a mais antiga.
+func (r *SubscriptionRepository) ListByAccountID(accountID string, filter domain.SubscriptionFilter) (*domain.SubscriptionPage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = domain.DefaultSubscriptionPageSize
+	}
+	if limit > domain.MaxSubscriptionPageSize {
+		limit = domain.MaxSubscriptionPageSize
+	}
+
+	conditions := []string{"account_id = $1"}
+	args := []any{accountID}
+	addCondition := func(format string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if filter.Mode != "" {
+		addCondition("mode = $%d", filter.Mode)
+	}
+	if filter.Status != "" {
+		addCondition("status = $%d", filter.Status)
+	}
+	if filter.CustomerID != "" {
+		addCondition("customer_id = $%d", filter.CustomerID)
+	}
+	if filter.PlanID != "" {
+		addCondition("plan_id = $%d", filter.PlanID)
+	}
+	if filter.Cursor != nil {
+		args = append(args, filter.Cursor.CreatedAt, filter.Cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	// Busca um item a mais para saber se existe proxima pagina.
+	args = append(args, limit+1)
+	query := fmt.Sprintf(
+		`SELECT `+subscriptionColumns+` FROM subscriptions WHERE %s ORDER BY created_at DESC, id DESC LIMIT $%d`,
+		strings.Join(conditions, " AND "),
+		len(args),
+	)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &domain.SubscriptionPage{Subscriptions: make([]*domain.Subscription, 0, limit)}
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Subscriptions = append(page.Subscriptions, subscription)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Subscriptions) > limit {
+		page.Subscriptions = page.Subscriptions[:limit]
+		last := page.Subscriptions[limit-1]
+		page.NextCursor = &domain.InvoiceCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	return page, nil
+}
+
+// ListEvents retorna o historico da assinatura em ordem cronologica.
+func (r *SubscriptionRepository) ListEvents(subscriptionID string) ([]*domain.SubscriptionEvent, error) {
+	rows, err := r.db.Query(`
+		SELECT id, subscription_id, event_type, from_status, to_status, invoice_id, metadata, created_at
+		FROM subscription_events
+		WHERE subscription_id = $1
+		ORDER BY seq ASC
+	`, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*domain.SubscriptionEvent, 0)
+	for rows.Next() {
+		var event domain.SubscriptionEvent
+		var fromStatus sql.NullString
+		var toStatus sql.NullString
+		var invoiceID sql.NullString
+		var metadata []byte
+
+		if err := rows.Scan(
+			&event.ID,
+			&event.SubscriptionID,
+			&event.EventType,
+			&fromStatus,
+			&toStatus,
+			&invoiceID,
+			&metadata,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if fromStatus.Valid {
+			value := domain.SubscriptionStatus(fromStatus.String)
+			event.FromStatus = &value
+		}
+		if toStatus.Valid {
+			value := domain.SubscriptionStatus(toStatus.String)
+			event.ToStatus = &value
+		}
+		event.InvoiceID = invoiceID.String
+		if len(metadata) > 0 {
+			event.Metadata = metadata
+		}
+
+		events = append(events, &event)
+	}
+	return events, rows.Err()
+}
+
+func insertSubscriptionEvents(tx *sql.Tx, events []*domain.SubscriptionEvent) error {
+	for _, event := range events {
+		var metadata any
+		if len(event.Metadata) > 0 {
+			metadata = []byte(event.Metadata)
+		}
+		_, err := tx.Exec(`
+			INSERT INTO subscription_events (id, subscription_id, event_type, from_status, to_status, invoice_id, metadata, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`,
+			event.ID,
+			event.SubscriptionID,
+			event.EventType,
+			event.FromStatus,
+			event.ToStatus,
+			nullableUUID(event.InvoiceID),
+			metadata,
+			event.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prefixColumns qualifica uma lista de colunas com o alias da tabela.
+func prefixColumns(alias, columns string) string {
+	fields := strings.Split(columns, ",")
+	for i, field := range fields {
+		fields[i] = alias + "." + strings.TrimSpace(field)
+	}
+	return strings.Join(fields, ", ")
+}
+
+func scanPlan(row rowScanner) (*domain.Plan, error) {
+	var plan domain.Plan
+	err := row.Scan(
+		&plan.ID,
+		&plan.AccountID,
+		&plan.Mode,
+		&plan.Name,
+		&plan.AmountCents,
+		&plan.Currency,
+		&plan.Interval,
+		&plan.IntervalCount,
+		&plan.TrialDays,
+		&plan.Active,
+		&plan.CreatedAt,
+		&plan.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func scanSubscription(row rowScanner) (*domain.Subscription, error) {
+	return scanSubscriptionWith(row)
+}
+
+// scanSubscriptionWith le as colunas de subscriptionColumns seguidas de extra.
+func scanSubscriptionWith(row rowScanner, extra ...any) (*domain.Subscription, error) {
+	var subscription domain.Subscription
+	var nextBillingAt sql.NullTime
+	var pendingInvoiceID sql.NullString
+	var latestInvoiceID sql.NullString
+	var canceledAt sql.NullTime
+	var billingInvoiceID sql.NullString
+
+	dest := []any{
+		&subscription.ID,
+		&subscription.AccountID,
+		&subscription.Mode,
+		&subscription.PlanID,
+		&subscription.CustomerID,
+		&subscription.CardToken,
+		&subscription.Status,
+		&subscription.BillingAnchor,
+		&subscription.CurrentPeriodStart,
+		&subscription.CurrentPeriodEnd,
+		&nextBillingAt,
+		&subscription.FailedAttempts,
+		&pendingInvoiceID,
+		&latestInvoiceID,
+		&subscription.CancelAtPeriodEnd,
+		&canceledAt,
+		&billingInvoiceID,
+		&subscription.Version,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if nextBillingAt.Valid {
+		subscription.NextBillingAt = &nextBillingAt.Time
+	}
+	if canceledAt.Valid {
+		subscription.CanceledAt = &canceledAt.Time
+	}
+	subscription.PendingInvoiceID = pendingInvoiceID.String
+	subscription.LatestInvoiceID = latestInvoiceID.String
+	subscription.BillingInvoiceID = billingInvoiceID.String
+	return &subscription, nil
+}
@@ -0,0 +1,41 @@
+package security
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+)
+
+// AdminKey e uma credencial da API admin. Name identifica o operador na auditoria.
+type AdminKey struct {
+	Name string
+	Key  string
+}
+
+// ParseAdminKeys le ADMIN_API_KEYS no formato "nome:chave,nome2:chave2".
+func ParseAdminKeys(raw string) ([]AdminKey, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	keys := make([]AdminKey, 0)
+	for _, pair := range strings.Split(raw, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.New("invalid ADMIN_API_KEYS format")
+		}
+		keys = append(keys, AdminKey{Name: parts[0], Key: parts[1]})
+	}
+	return keys, nil
+}
+
+// MatchAdminKey retorna o operador dono da chave, comparando em tempo constante.
+func MatchAdminKey(keys []AdminKey, candidate string) (string, bool) {
+	name, found := "", false
+	for _, key := range keys {
+		if subtle.ConstantTimeCompare([]byte(key.Key), []byte(candidate)) == 1 {
+			name, found = key.Name, true
+		}
+	}
+	return name, found
+}
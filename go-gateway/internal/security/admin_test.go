@@ -0,0 +1,21 @@
+package security
+
+import "testing"
+
+func TestParseAndMatchAdminKeys(t *testing.T) {
+	keys, err := ParseAdminKeys("alice:key-a, bob:key-b")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if name, ok := MatchAdminKey(keys, "key-b"); !ok || name != "bob" {
+		t.Fatalf("expected bob, got %q (ok %v)", name, ok)
+	}
+	if _, ok := MatchAdminKey(keys, "key-c"); ok {
+		t.Fatal("expected unknown key to be rejected")
+	}
+
+	if _, err := ParseAdminKeys("alice"); err == nil {
+		t.Fatal("expected error for entry without key")
+	}
+}
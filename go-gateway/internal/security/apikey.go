@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -56,22 +57,45 @@ func HashAPIKeyWithActiveKey(apiKey string) (string, string, error) {
 	return hashWithSecret(apiKey, secret), cfg.ActiveKeyID, nil
 }
 
+// HashAPIKeyCandidates calcula o hash da chave com cada segredo configurado.
+// O key id ativo vem primeiro; os demais seguem em ordem alfabetica.
 func HashAPIKeyCandidates(apiKey string) ([]KeyHash, error) {
 	cfg, err := loadAPIKeySecrets()
 	if err != nil {
 		return nil, err
 	}
 
-	hashes := make([]KeyHash, 0, len(cfg.Secrets))
-	for keyID, secret := range cfg.Secrets {
+	keyIDs := orderedKeyIDs(cfg)
+	hashes := make([]KeyHash, 0, len(keyIDs))
+	for _, keyID := range keyIDs {
 		hashes = append(hashes, KeyHash{
 			KeyID: keyID,
-			Hash:  hashWithSecret(apiKey, secret),
+			Hash:  hashWithSecret(apiKey, cfg.Secrets[keyID]),
 		})
 	}
 	return hashes, nil
 }
 
+// KeyIDs retorna os key ids configurados, com o ativo primeiro.
+func KeyIDs() ([]string, error) {
+	cfg, err := loadAPIKeySecrets()
+	if err != nil {
+		return nil, err
+	}
+	return orderedKeyIDs(cfg), nil
+}
+
+func orderedKeyIDs(cfg APIKeySecrets) []string {
+	keyIDs := make([]string, 0, len(cfg.Secrets))
+	for keyID := range cfg.Secrets {
+		if keyID != cfg.ActiveKeyID {
+			keyIDs = append(keyIDs, keyID)
+		}
+	}
+	sort.Strings(keyIDs)
+	return append([]string{cfg.ActiveKeyID}, keyIDs...)
+}
+
 func loadAPIKeySecrets() (APIKeySecrets, error) {
 	secretsOnce.Do(func() {
 		active := os.Getenv("API_KEY_ACTIVE_KEY_ID")
@@ -0,0 +1,28 @@
+package security
+
+import "testing"
+
+func TestHashAPIKeyCandidatesStartWithActiveKeyID(t *testing.T) {
+	t.Setenv("API_KEY_SECRETS", "v1:old,v3:newest,v2:current")
+	t.Setenv("API_KEY_ACTIVE_KEY_ID", "v2")
+
+	candidates, err := HashAPIKeyCandidates("sk_live_abc")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	want := []string{"v2", "v1", "v3"}
+	if len(candidates) != len(want) {
+		t.Fatalf("expected %d candidates, got %d", len(want), len(candidates))
+	}
+	for i, keyID := range want {
+		if candidates[i].KeyID != keyID {
+			t.Fatalf("expected key id %s at %d, got %s", keyID, i, candidates[i].KeyID)
+		}
+	}
+
+	hash, err := HashAPIKey("sk_live_abc", "v2")
+	if err != nil || candidates[0].Hash != hash {
+		t.Fatalf("expected active candidate hash %s, got %s (err %v)", hash, candidates[0].Hash, err)
+	}
+}
@@ -0,0 +1,137 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrCardVaultNotConfigured e retornado quando CARD_VAULT_KEYS falta fora de ambientes de dev.
+var ErrCardVaultNotConfigured = errors.New("CARD_VAULT_KEYS is required outside dev environments")
+
+// CardVaultKeys guarda as chaves AES-256 do cofre de cartoes por key id.
+// Dados novos sao cifrados com ActiveKeyID; os demais key ids ficam apenas para decifrar.
+type CardVaultKeys struct {
+	ActiveKeyID string
+	Keys        map[string][]byte
+}
+
+var vaultOnce sync.Once
+var vaultCache CardVaultKeys
+var vaultErr error
+
+// EncryptCardData cifra com a chave ativa (AES-256-GCM). aad amarra o texto cifrado ao
+// registro dono dele. Retorna nonce + texto cifrado e o key id usado.
+func EncryptCardData(plaintext, aad []byte) ([]byte, string, error) {
+	cfg, err := loadCardVaultKeys()
+	if err != nil {
+		return nil, "", err
+	}
+	return cfg.encrypt(plaintext, aad)
+}
+
+// DecryptCardData decifra um valor gerado por EncryptCardData com a chave keyID.
+func DecryptCardData(ciphertext []byte, keyID string, aad []byte) ([]byte, error) {
+	cfg, err := loadCardVaultKeys()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.decrypt(ciphertext, keyID, aad)
+}
+
+// CardVaultActiveKeyID retorna o key id usado para cifrar dados novos.
+func CardVaultActiveKeyID() (string, error) {
+	cfg, err := loadCardVaultKeys()
+	if err != nil {
+		return "", err
+	}
+	return cfg.ActiveKeyID, nil
+}
+
+func (k CardVaultKeys) encrypt(plaintext, aad []byte) ([]byte, string, error) {
+	gcm, err := k.aead(k.ActiveKeyID)
+	if err != nil {
+		return nil, "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, "", err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), k.ActiveKeyID, nil
+}
+
+func (k CardVaultKeys) decrypt(ciphertext []byte, keyID string, aad []byte) ([]byte, error) {
+	gcm, err := k.aead(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("card vault ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, sealed, aad)
+}
+
+func (k CardVaultKeys) aead(keyID string) (cipher.AEAD, error) {
+	key, ok := k.Keys[keyID]
+	if !ok {
+		return nil, errors.New("card vault key not found for key id")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func loadCardVaultKeys() (CardVaultKeys, error) {
+	vaultOnce.Do(func() {
+		active := os.Getenv("CARD_VAULT_ACTIVE_KEY_ID")
+		if active == "" {
+			active = "v1"
+		}
+
+		raw := os.Getenv("CARD_VAULT_KEYS")
+		if raw == "" {
+			if !isDevEnv() {
+				vaultErr = ErrCardVaultNotConfigured
+				return
+			}
+			devKey := sha256.Sum256([]byte("dev_card_vault_key"))
+			vaultCache = CardVaultKeys{ActiveKeyID: "v1", Keys: map[string][]byte{"v1": devKey[:]}}
+			return
+		}
+
+		vaultCache, vaultErr = parseCardVaultKeys(active, raw)
+	})
+
+	return vaultCache, vaultErr
+}
+
+// parseCardVaultKeys le CARD_VAULT_KEYS no formato "id:chave_base64,...", com chaves de 32 bytes.
+func parseCardVaultKeys(active, raw string) (CardVaultKeys, error) {
+	keys := map[string][]byte{}
+	for _, pair := range strings.Split(raw, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return CardVaultKeys{}, errors.New("invalid CARD_VAULT_KEYS format")
+		}
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil || len(key) != 32 {
+			return CardVaultKeys{}, errors.New("CARD_VAULT_KEYS keys must be 32 bytes in base64")
+		}
+		keys[parts[0]] = key
+	}
+
+	if _, ok := keys[active]; !ok {
+		return CardVaultKeys{}, errors.New("CARD_VAULT_ACTIVE_KEY_ID not found in CARD_VAULT_KEYS")
+	}
+	return CardVaultKeys{ActiveKeyID: active, Keys: keys}, nil
+}
@@ -0,0 +1,54 @@
+package security
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func TestCardVaultKeysDecryptAfterRotation(t *testing.T) {
+	oldKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	newKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+
+	before, err := parseCardVaultKeys("v1", "v1:"+oldKey)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	ciphertext, keyID, err := before.encrypt([]byte("4242424242424242"), []byte("tok_1"))
+	if err != nil || keyID != "v1" {
+		t.Fatalf("expected v1 ciphertext, got key id %s (err %v)", keyID, err)
+	}
+
+	after, err := parseCardVaultKeys("v2", "v1:"+oldKey+",v2:"+newKey)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	plaintext, err := after.decrypt(ciphertext, keyID, []byte("tok_1"))
+	if err != nil || string(plaintext) != "4242424242424242" {
+		t.Fatalf("expected original plaintext, got %q (err %v)", plaintext, err)
+	}
+	if _, err := after.decrypt(ciphertext, keyID, []byte("tok_2")); err == nil {
+		t.Fatalf("expected error when aad does not match")
+	}
+
+	_, keyID, err = after.encrypt([]byte("4242424242424242"), []byte("tok_1"))
+	if err != nil || keyID != "v2" {
+		t.Fatalf("expected new data on active key v2, got %s (err %v)", keyID, err)
+	}
+}
+
+func TestParseCardVaultKeysRejectsInvalidKeys(t *testing.T) {
+	short := base64.StdEncoding.EncodeToString([]byte("short"))
+	valid := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+
+	cases := map[string][2]string{
+		"short key":      {"v1", "v1:" + short},
+		"missing secret": {"v1", "v1:"},
+		"unknown active": {"v2", "v1:" + valid},
+	}
+	for name, tc := range cases {
+		if _, err := parseCardVaultKeys(tc[0], tc[1]); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
@@ -1,15 +1,21 @@
 package service
 
 import (
+	"context"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/repository"
+	"github.com/google/uuid"
 )
 
-// AccountLimitService valida politicas de limite por conta.
+// limitLockTimeout limita a espera pelo lock de limites de uma conta sob concorrencia.
+const limitLockTimeout = 5 * time.Second
+
+// AccountLimitService valida politicas de limite por conta, modo e moeda.
 type AccountLimitService struct {
 	limitsRepo  *repository.AccountLimitRepository
 	invoiceRepo domain.InvoiceRepository
@@ -21,42 +27,140 @@ func NewAccountLimitService(limitsRepo *repository.AccountLimitRepository, invoi
 		MaxAmountPerTxCents:  parseEnvInt64("ACCOUNT_LIMIT_MAX_AMOUNT_PER_TX_CENTS", 0),
 		MaxDailyVolumeCents:  parseEnvInt64("ACCOUNT_LIMIT_MAX_DAILY_VOLUME_CENTS", 0),
 		MaxDailyTransactions: parseEnvInt64("ACCOUNT_LIMIT_MAX_DAILY_TRANSACTIONS", 0),
+		Enforcement:          domain.LimitEnforcement(os.Getenv("ACCOUNT_LIMIT_ENFORCEMENT")),
+		Timezone:             os.Getenv("ACCOUNT_LIMIT_TIMEZONE"),
+	}
+	if !defaults.Enforcement.Valid() {
+		defaults.Enforcement = domain.LimitEnforcementHard
+	}
+	if _, err := time.LoadLocation(defaults.Timezone); err != nil || defaults.Timezone == "" {
+		defaults.Timezone = "UTC"
 	}
 	return &AccountLimitService{limitsRepo: limitsRepo, invoiceRepo: invoiceRepo, defaults: defaults}
 }
 
-func (s *AccountLimitService) Validate(accountID string, amountCents int64, now time.Time) error {
-	limits, err := s.limitsRepo.EnsureDefaults(accountID, domain.AccountLimit{
-		AccountID:            accountID,
-		MaxAmountPerTxCents:  s.defaults.MaxAmountPerTxCents,
-		MaxDailyVolumeCents:  s.defaults.MaxDailyVolumeCents,
-		MaxDailyTransactions: s.defaults.MaxDailyTransactions,
-	})
+// Validate aplica os limites do modo e da moeda da fatura; valores em unidades minimas da moeda.
+// Violacoes com enforcement hard voltam como LimitExceededError; com soft, a violacao e retornada
+// sem erro para que a fatura siga para revisao manual. O uso e lido em scope, o escopo de Lock em
+// que a fatura sera gravada, para que criacoes concorrentes vejam o uso atualizado.
+func (s *AccountLimitService) Validate(scope domain.LimitScope, accountID string, mode domain.Mode, currency, paymentType string, amountCents int64, now time.Time) (*domain.LimitExceededError, error) {
+	limits, err := s.limitsRepo.EnsureDefaults(accountID, mode, currency, s.defaultsFor(accountID, mode, currency))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if limits.MaxAmountPerTxCents > 0 && amountCents > limits.MaxAmountPerTxCents {
-		return domain.LimitExceededError{Reason: "max_amount_per_tx_exceeded"}
+	loc := limits.Location()
+	for _, rule := range limits.EffectiveRules(paymentType) {
+		var usage domain.LimitUsage
+		if rule.Window != domain.LimitWindowTransaction && (rule.MaxVolumeCents > 0 || rule.MaxTransactions > 0) {
+			start, end := rule.Window.Bounds(now, loc)
+			current, err := scope.GetUsage(accountID, mode, currency, rule.PaymentType, start, end)
+			if err != nil {
+				return nil, err
+			}
+			usage = *current
+		}
+
+		if violation := rule.CheckAmount(amountCents, usage); violation != nil {
+			if limits.Soft() {
+				return violation, nil
+			}
+			return nil, *violation
+		}
 	}
 
-	start := time.Date(now.UTC().Year(), now.UTC().Month(), now.UTC().Day(), 0, 0, 0, 0, time.UTC)
-	end := start.Add(24 * time.Hour)
+	return nil, nil
+}
+
+// Lock serializa as verificacoes de limite da conta, modo e moeda entre requisicoes e replicas:
+// abre o escopo de limite em que Validate le o uso e a fatura e gravada. O chamador confirma com
+// Commit depois de gravar (ou Rollback) e assim libera o lock. Sem o lock em limitLockTimeout,
+// retorna domain.ErrLimitBusy.
+func (s *AccountLimitService) Lock(ctx context.Context, accountID string, mode domain.Mode, currency string) (domain.LimitScope, error) {
+	ctx, cancel := context.WithTimeout(ctx, limitLockTimeout)
+	defer cancel()
+	return s.invoiceRepo.BeginLimitScope(ctx, accountID+":"+string(mode)+":"+currency)
+}
 
-	usage, err := s.invoiceRepo.GetDailyUsage(accountID, start, end)
+// Status retorna os limites do modo e da moeda com o uso do dia corrente no fuso da conta
+// e o uso de cada regra adicional.
+func (s *AccountLimitService) Status(accountID string, mode domain.Mode, currency string, now time.Time) (*domain.AccountLimitStatus, error) {
+	limits, err := s.limitsRepo.EnsureDefaults(accountID, mode, currency, s.defaultsFor(accountID, mode, currency))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if limits.MaxDailyVolumeCents > 0 && usage.TotalCents+amountCents > limits.MaxDailyVolumeCents {
-		return domain.LimitExceededError{Reason: "max_daily_volume_exceeded"}
+	loc := limits.Location()
+	start, end := domain.LimitWindowDay.Bounds(now, loc)
+	usage, err := s.invoiceRepo.GetUsage(accountID, mode, currency, "", start, end)
+	if err != nil {
+		return nil, err
 	}
 
-	if limits.MaxDailyTransactions > 0 && usage.Count+1 > limits.MaxDailyTransactions {
-		return domain.LimitExceededError{Reason: "max_daily_transactions_exceeded"}
+	status := &domain.AccountLimitStatus{Limit: *limits, Usage: *usage, DayStart: start, DayEnd: end}
+	for _, rule := range limits.Rules {
+		ruleStatus := domain.LimitRuleStatus{Rule: rule}
+		if rule.Window != domain.LimitWindowTransaction {
+			ruleStatus.Start, ruleStatus.End = rule.Window.Bounds(now, loc)
+			ruleUsage, err := s.invoiceRepo.GetUsage(accountID, mode, currency, rule.PaymentType, ruleStatus.Start, ruleStatus.End)
+			if err != nil {
+				return nil, err
+			}
+			ruleStatus.Usage = *ruleUsage
+		}
+		status.Rules = append(status.Rules, ruleStatus)
 	}
+	return status, nil
+}
+
+// List retorna os limites ja definidos para a conta, em todos os modos e moedas.
+func (s *AccountLimitService) List(accountID string) ([]*domain.AccountLimit, error) {
+	return s.limitsRepo.ListByAccountID(accountID)
+}
 
-	return nil
+// Set substitui os limites do modo e da moeda e registra quem alterou e por que.
+func (s *AccountLimitService) Set(limit domain.AccountLimit, changedBy, reason string) (*domain.AccountLimit, error) {
+	return s.replace(limit, domain.LimitAuditActionSet, changedBy, reason)
+}
+
+// Reset volta os limites do modo e da moeda para os padroes configurados, registrando a auditoria.
+func (s *AccountLimitService) Reset(accountID string, mode domain.Mode, currency, changedBy, reason string) (*domain.AccountLimit, error) {
+	return s.replace(s.defaultsFor(accountID, mode, currency), domain.LimitAuditActionReset, changedBy, reason)
+}
+
+// ListAudits lista as alteracoes de limites da conta, das mais recentes para as mais antigas.
+func (s *AccountLimitService) ListAudits(accountID string, limit int) ([]*domain.AccountLimitAudit, error) {
+	return s.limitsRepo.ListAudits(accountID, limit)
+}
+
+func (s *AccountLimitService) replace(limit domain.AccountLimit, action, changedBy, reason string) (*domain.AccountLimit, error) {
+	audit := &domain.AccountLimitAudit{
+		ID:        uuid.New().String(),
+		AccountID: limit.AccountID,
+		Mode:      limit.Mode,
+		Currency:  limit.Currency,
+		Action:    action,
+		ChangedBy: changedBy,
+		Reason:    strings.TrimSpace(reason),
+		CreatedAt: time.Now(),
+	}
+	return s.limitsRepo.Replace(limit, audit)
+}
+
+// defaultsFor retorna os limites iniciais da moeda. Variaveis com sufixo da moeda
+// (ex.: ACCOUNT_LIMIT_MAX_AMOUNT_PER_TX_CENTS_USD) sobrescrevem os padroes gerais.
+func (s *AccountLimitService) defaultsFor(accountID string, mode domain.Mode, currency string) domain.AccountLimit {
+	return domain.AccountLimit{
+		AccountID:            accountID,
+		Mode:                 mode,
+		Currency:             currency,
+		MaxAmountPerTxCents:  parseEnvInt64("ACCOUNT_LIMIT_MAX_AMOUNT_PER_TX_CENTS_"+currency, s.defaults.MaxAmountPerTxCents),
+		MaxDailyVolumeCents:  parseEnvInt64("ACCOUNT_LIMIT_MAX_DAILY_VOLUME_CENTS_"+currency, s.defaults.MaxDailyVolumeCents),
+		MaxDailyTransactions: parseEnvInt64("ACCOUNT_LIMIT_MAX_DAILY_TRANSACTIONS_"+currency, s.defaults.MaxDailyTransactions),
+		Rules:                []domain.LimitRule{},
+		Enforcement:          s.defaults.Enforcement,
+		Timezone:             s.defaults.Timezone,
+	}
 }
 
 func parseEnvInt64(key string, fallback int64) int64 {
@@ -103,6 +103,34 @@ func (s *AccountService) FindByAPIKey(apiKey string) (*dto.AccountOutput, error)
 	return &output, nil
 }
 
+// GetWithBalances busca a conta incluindo os saldos em cada moeda no modo informado (live ou test)
+func (s *AccountService) GetWithBalances(accountID string, mode domain.Mode) (*dto.AccountOutput, error) {
+	account, err := s.repository.FindByID(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := s.repository.ListBalances(account.ID, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	// balance reflete o saldo do modo na moeda padrao da conta.
+	account.BalanceCents, account.PendingCents = 0, 0
+	for _, balance := range balances {
+		if balance.Currency == account.Currency {
+			account.BalanceCents = balance.BalanceCents
+			account.PendingCents = balance.PendingCents
+		}
+	}
+
+	output := dto.FromAccount(account)
+	output.APIKey = ""
+	output.Mode = string(mode)
+	output.Balances = dto.FromBalances(balances)
+	return &output, nil
+}
+
 // FindByID busca uma conta pelo ID
 func (s *AccountService) FindByID(id string) (*dto.AccountOutput, error) {
 	account, err := s.repository.FindByID(id)
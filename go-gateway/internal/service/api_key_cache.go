@@ -0,0 +1,81 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+)
+
+// apiKeyCache guarda as API keys resolvidas por hash (HMAC com o key id ativo) durante ttl.
+// As chaves em cache sao tratadas como imutaveis.
+type apiKeyCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]apiKeyCacheEntry
+}
+
+type apiKeyCacheEntry struct {
+	key       *domain.APIKey
+	expiresAt time.Time
+}
+
+func newAPIKeyCache(ttl time.Duration) *apiKeyCache {
+	return &apiKeyCache{ttl: ttl, entries: make(map[string]apiKeyCacheEntry)}
+}
+
+func (c *apiKeyCache) get(hash string, now time.Time) (*domain.APIKey, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[hash]
+	if !ok {
+		return nil, false
+	}
+	if !now.Before(entry.expiresAt) {
+		delete(c.entries, hash)
+		return nil, false
+	}
+	return entry.key, true
+}
+
+func (c *apiKeyCache) set(hash string, key *domain.APIKey, now time.Time) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Remove entradas vencidas na escrita para o mapa nao crescer sem limite.
+	for cached, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, cached)
+		}
+	}
+	c.entries[hash] = apiKeyCacheEntry{key: key, expiresAt: now.Add(c.ttl)}
+}
+
+// flush descarta todas as entradas
+func (c *apiKeyCache) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]apiKeyCacheEntry)
+}
+
+// invalidate remove do cache a API key com o id informado
+func (c *apiKeyCache) invalidate(keyID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for hash, entry := range c.entries {
+		if entry.key.ID == keyID {
+			delete(c.entries, hash)
+		}
+	}
+}
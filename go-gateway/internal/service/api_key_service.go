@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/security"
+	"github.com/lib/pq"
+)
+
+// DefaultAPIKeyCacheTTL e o tempo que uma API key resolvida fica em cache.
+const DefaultAPIKeyCacheTTL = 30 * time.Second
+
+// APIKeyService gerencia as API keys das contas e autentica requisicoes
+type APIKeyService struct {
+	repository domain.APIKeyRepository
+	cache      *apiKeyCache
+}
+
+// NewAPIKeyService cria o servico; cacheTTL <= 0 desativa o cache
+func NewAPIKeyService(repository domain.APIKeyRepository, cacheTTL time.Duration) *APIKeyService {
+	return &APIKeyService{repository: repository, cache: newAPIKeyCache(cacheTTL)}
+}
+
+// Authenticate resolve a API key e retorna o principal da requisicao.
+// Retorna ErrAPIKeyNotFound, ErrAPIKeyRevoked ou ErrAPIKeyExpired.
+func (s *APIKeyService) Authenticate(secret string) (*domain.Principal, error) {
+	now := time.Now()
+
+	// O hash com o key id ativo e a chave do cache: um HMAC por requisicao, sem ida ao banco.
+	hash, _, err := security.HashAPIKeyWithActiveKey(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := s.cache.get(hash, now)
+	if !ok {
+		key, err = s.load(secret, now)
+		if err != nil {
+			return nil, err
+		}
+		s.cache.set(hash, key, now)
+	}
+
+	if key.Revoked() {
+		return nil, domain.ErrAPIKeyRevoked
+	}
+	if key.Expired(now) {
+		return nil, domain.ErrAPIKeyExpired
+	}
+	return domain.NewPrincipal(key), nil
+}
+
+// load busca a chave no banco. Rehash e registro de uso sao melhor esforco e nao
+// bloqueiam a requisicao; com o cache, acontecem no maximo uma vez por TTL.
+func (s *APIKeyService) load(secret string, now time.Time) (*domain.APIKey, error) {
+	key, err := s.repository.FindByKey(secret)
+	if err != nil {
+		return nil, err
+	}
+	if !key.Active(now) {
+		return key, nil
+	}
+
+	if err := s.repository.RehashIfStale(key, secret); err != nil {
+		slog.Error("erro ao migrar hash da api key", "api_key_id", key.ID, "error", err)
+	}
+	if err := s.repository.TouchLastUsed(key.ID, now); err != nil {
+		slog.Error("erro ao registrar uso da api key", "api_key_id", key.ID, "error", err)
+	}
+	return key, nil
+}
+
+// Create emite uma nova chave para a conta e o modo do principal. A chave so recebe escopos
+// que o principal ja tem (ErrInsufficientScope); lista vazia retorna ErrInvalidScope.
+// O segredo so e retornado nesta resposta.
+func (s *APIKeyService) Create(principal *domain.Principal, input dto.CreateAPIKeyInput) (*dto.APIKeyOutput, error) {
+	if len(input.Scopes) == 0 {
+		return nil, domain.ErrInvalidScope
+	}
+	if principal.MissingScope(input.Scopes) != "" {
+		return nil, domain.ErrInsufficientScope
+	}
+	key, err := domain.NewAPIKey(principal.AccountID, principal.Mode, input.Label, input.Scopes, input.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.Save(key); err != nil {
+		return nil, err
+	}
+	return dto.FromAPIKey(key), nil
+}
+
+// List lista as chaves da conta no modo informado, sem segredos
+func (s *APIKeyService) List(accountID string, mode domain.Mode) ([]*dto.APIKeyOutput, error) {
+	keys, err := s.repository.ListByAccountID(accountID, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]*dto.APIKeyOutput, len(keys))
+	for i, key := range keys {
+		output[i] = dto.FromAPIKey(key)
+	}
+	return output, nil
+}
+
+// Revoke revoga uma chave da conta e a remove do cache desta instancia. As demais instancias
+// recebem a revogacao por ListenRevocations; sem o listener, deixam de aceita-la em ate um TTL.
+// Rotacao = criar uma chave nova e revogar a antiga.
+func (s *APIKeyService) Revoke(accountID string, mode domain.Mode, id string) (*dto.APIKeyOutput, error) {
+	key, err := s.repository.Revoke(accountID, mode, id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	s.cache.invalidate(key.ID)
+	return dto.FromAPIKey(key), nil
+}
+
+// GrantScopes concede escopos a uma chave da conta (admin), como payouts:write as chaves emitidas
+// antes dos saques. Escopos desconhecidos ou lista vazia retornam ErrInvalidScope.
+func (s *APIKeyService) GrantScopes(accountID, id string, scopes []string) (*dto.APIKeyOutput, error) {
+	if len(scopes) == 0 {
+		return nil, domain.ErrInvalidScope
+	}
+	for _, scope := range scopes {
+		if !domain.ValidScope(scope) {
+			return nil, domain.ErrInvalidScope
+		}
+	}
+	key, err := s.repository.GrantScopes(accountID, id, scopes)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.invalidate(key.ID)
+	return dto.FromAPIKey(key), nil
+}
+
+// ListenRevocations remove do cache as chaves revogadas em qualquer replica, a partir das
+// notificacoes de domain.APIKeyRevokedChannel. Uma notificacao nil indica que a conexao foi
+// refeita e avisos podem ter sido perdidos, entao o cache inteiro e descartado.
+func (s *APIKeyService) ListenRevocations(ctx context.Context, notifications <-chan *pq.Notification) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification, ok := <-notifications:
+			if !ok {
+				return
+			}
+			if notification == nil {
+				s.cache.flush()
+				continue
+			}
+			s.cache.invalidate(notification.Extra)
+		}
+	}
+}
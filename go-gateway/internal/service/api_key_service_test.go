@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/lib/pq"
+)
+
+type stubAPIKeyRepository struct {
+	key     *domain.APIKey
+	lookups int
+}
+
+func (r *stubAPIKeyRepository) Save(key *domain.APIKey) error { return nil }
+
+func (r *stubAPIKeyRepository) FindByKey(secret string) (*domain.APIKey, error) {
+	r.lookups++
+	if r.key == nil || secret != r.key.Secret {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	copied := *r.key
+	return &copied, nil
+}
+
+func (r *stubAPIKeyRepository) RehashIfStale(key *domain.APIKey, secret string) error { return nil }
+
+func (r *stubAPIKeyRepository) ListByAccountID(accountID string, mode domain.Mode) ([]*domain.APIKey, error) {
+	return nil, nil
+}
+
+func (r *stubAPIKeyRepository) Revoke(accountID string, mode domain.Mode, id string, at time.Time) (*domain.APIKey, error) {
+	r.key.RevokedAt = &at
+	copied := *r.key
+	return &copied, nil
+}
+
+func (r *stubAPIKeyRepository) GrantScopes(accountID, id string, scopes []string) (*domain.APIKey, error) {
+	r.key.Scopes = append(r.key.Scopes, scopes...)
+	copied := *r.key
+	return &copied, nil
+}
+
+func (r *stubAPIKeyRepository) TouchLastUsed(id string, at time.Time) error { return nil }
+
+func TestAuthenticateCachesPrincipalUntilRevoked(t *testing.T) {
+	t.Setenv("API_KEY_SECRET", "test-secret")
+
+	key, err := domain.NewAPIKey("acc-1", domain.ModeTest, "ci", []string{domain.ScopeInvoicesRead}, nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	repo := &stubAPIKeyRepository{key: key}
+	svc := NewAPIKeyService(repo, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		principal, err := svc.Authenticate(key.Secret)
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if principal.AccountID != "acc-1" || principal.Mode != domain.ModeTest || principal.KeyID != key.ID {
+			t.Fatalf("unexpected principal: %+v", principal)
+		}
+	}
+	if repo.lookups != 1 {
+		t.Fatalf("expected 1 repository lookup, got %d", repo.lookups)
+	}
+
+	if _, err := svc.Revoke("acc-1", domain.ModeTest, key.ID); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if _, err := svc.Authenticate(key.Secret); err != domain.ErrAPIKeyRevoked {
+		t.Fatalf("expected ErrAPIKeyRevoked after revoke, got %v", err)
+	}
+}
+
+func TestListenRevocationsInvalidatesKeysRevokedElsewhere(t *testing.T) {
+	t.Setenv("API_KEY_SECRET", "test-secret")
+
+	key, err := domain.NewAPIKey("acc-1", domain.ModeTest, "ci", []string{domain.ScopeInvoicesRead}, nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	repo := &stubAPIKeyRepository{key: key}
+	svc := NewAPIKeyService(repo, time.Hour)
+	if _, err := svc.Authenticate(key.Secret); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	// Outra replica revoga a chave e publica o aviso
+	revokedAt := time.Now()
+	key.RevokedAt = &revokedAt
+	notifications := make(chan *pq.Notification, 1)
+	notifications <- &pq.Notification{Channel: domain.APIKeyRevokedChannel, Extra: key.ID}
+	close(notifications)
+	svc.ListenRevocations(context.Background(), notifications)
+
+	if _, err := svc.Authenticate(key.Secret); err != domain.ErrAPIKeyRevoked {
+		t.Fatalf("expected ErrAPIKeyRevoked after notification, got %v", err)
+	}
+	if repo.lookups != 2 {
+		t.Fatalf("expected 2 repository lookups, got %d", repo.lookups)
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+)
+
+// CardTokenService tokeniza cartoes no cofre e resolve tokens para o numero do cartao.
+type CardTokenService struct {
+	repository domain.CardTokenRepository
+}
+
+func NewCardTokenService(repository domain.CardTokenRepository) *CardTokenService {
+	return &CardTokenService{repository: repository}
+}
+
+// Create valida o cartao e guarda o PAN cifrado, retornando o token opaco.
+func (s *CardTokenService) Create(accountID string, mode domain.Mode, input dto.CreateCardTokenInput) (*dto.CardTokenOutput, error) {
+	token, err := domain.NewCardToken(accountID, mode, domain.CreditCard{
+		Number:         input.CardNumber,
+		CVV:            input.CVV,
+		ExpiryMonth:    input.ExpiryMonth,
+		ExpiryYear:     input.ExpiryYear,
+		CardholderName: input.CardholderName,
+	}, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.Save(token); err != nil {
+		return nil, err
+	}
+	return dto.FromCardToken(token), nil
+}
+
+// Resolve retorna o token com o PAN decifrado. Tokens de outra conta ou de outro modo
+// retornam ErrCardTokenNotFound; cartoes vencidos, ErrCardExpired.
+func (s *CardTokenService) Resolve(accountID string, mode domain.Mode, id string) (*domain.CardToken, error) {
+	token, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if token.AccountID != accountID || token.Mode != mode {
+		return nil, domain.ErrCardTokenNotFound
+	}
+	if token.Expired(time.Now()) {
+		return nil, domain.ErrCardExpired
+	}
+	if err := s.repository.Reveal(token); err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.RekeyIfStale(token); err != nil {
+		slog.Error("erro ao recifrar token de cartao", "card_token", token.ID, "error", err)
+	}
+	return token, nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+)
+
+// CustomerService gerencia os clientes da conta e os cartoes do cofre salvos neles.
+type CustomerService struct {
+	repository domain.CustomerRepository
+	cardTokens domain.CardTokenRepository
+}
+
+func NewCustomerService(repository domain.CustomerRepository, cardTokens domain.CardTokenRepository) *CustomerService {
+	return &CustomerService{repository: repository, cardTokens: cardTokens}
+}
+
+func (s *CustomerService) Create(accountID string, mode domain.Mode, input dto.CreateCustomerInput) (*dto.CustomerOutput, error) {
+	customer, err := domain.NewCustomer(accountID, mode, input.Name, input.Email, input.Document, input.ExternalReference, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.Save(customer); err != nil {
+		return nil, err
+	}
+	return dto.FromCustomer(customer), nil
+}
+
+// Find retorna o cliente da conta e do modo; clientes de outra conta, de outro modo ou
+// removidos retornam ErrCustomerNotFound.
+func (s *CustomerService) Find(accountID string, mode domain.Mode, id string) (*domain.Customer, error) {
+	customer, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if customer.AccountID != accountID || customer.Mode != mode {
+		return nil, domain.ErrCustomerNotFound
+	}
+	return customer, nil
+}
+
+func (s *CustomerService) Get(accountID string, mode domain.Mode, id string) (*dto.CustomerOutput, error) {
+	customer, err := s.Find(accountID, mode, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromCustomer(customer), nil
+}
+
+func (s *CustomerService) List(accountID string, filter domain.CustomerFilter) (*dto.CustomerListOutput, error) {
+	page, err := s.repository.ListByAccountID(accountID, filter)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromCustomerPage(page), nil
+}
+
+func (s *CustomerService) Update(accountID string, mode domain.Mode, id string, input dto.UpdateCustomerInput) (*dto.CustomerOutput, error) {
+	customer, err := s.Find(accountID, mode, id)
+	if err != nil {
+		return nil, err
+	}
+	err = customer.Apply(domain.CustomerChanges{
+		Name:              input.Name,
+		Email:             input.Email,
+		Document:          input.Document,
+		ExternalReference: input.ExternalReference,
+	}, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.Update(customer); err != nil {
+		return nil, err
+	}
+	return dto.FromCustomer(customer), nil
+}
+
+// Delete remove o cliente. As faturas continuam com o customer_id; os cartoes salvos voltam
+// a ser tokens avulsos.
+func (s *CustomerService) Delete(accountID string, mode domain.Mode, id string) error {
+	if _, err := s.Find(accountID, mode, id); err != nil {
+		return err
+	}
+	return s.repository.Delete(id, time.Now())
+}
+
+// AttachPaymentMethod salva no cliente um token da mesma conta e do mesmo modo.
+func (s *CustomerService) AttachPaymentMethod(accountID string, mode domain.Mode, customerID, cardToken string) (*dto.CardTokenOutput, error) {
+	customer, err := s.Find(accountID, mode, customerID)
+	if err != nil {
+		return nil, err
+	}
+	token, err := s.cardTokens.FindByID(cardToken)
+	if err != nil {
+		return nil, err
+	}
+	if token.AccountID != accountID || token.Mode != mode {
+		return nil, domain.ErrCardTokenNotFound
+	}
+	if token.Expired(time.Now()) {
+		return nil, domain.ErrCardExpired
+	}
+	if err := s.cardTokens.AttachToCustomer(token.ID, customer.ID); err != nil {
+		return nil, err
+	}
+	token.CustomerID = customer.ID
+	return dto.FromCardToken(token), nil
+}
+
+func (s *CustomerService) ListPaymentMethods(accountID string, mode domain.Mode, customerID string) ([]*dto.CardTokenOutput, error) {
+	customer, err := s.Find(accountID, mode, customerID)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := s.cardTokens.ListByCustomerID(customer.ID)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]*dto.CardTokenOutput, len(tokens))
+	for i, token := range tokens {
+		output[i] = dto.FromCardToken(token)
+	}
+	return output, nil
+}
+
+// DetachPaymentMethod remove o token do cliente; o token continua valido para faturas avulsas.
+func (s *CustomerService) DetachPaymentMethod(accountID string, mode domain.Mode, customerID, cardToken string) error {
+	customer, err := s.Find(accountID, mode, customerID)
+	if err != nil {
+		return err
+	}
+	return s.cardTokens.DetachFromCustomer(cardToken, customer.ID)
+}
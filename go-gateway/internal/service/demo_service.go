@@ -80,7 +80,7 @@ func (s *DemoService) seedInvoices(account *domain.Account) error {
 			CardholderName: "Demo User",
 		}
 
-		amountCents := domain.AmountToCents(seed.amount)
+		amountCents := domain.AmountToMinor(seed.amount, account.Currency)
 		invoice, err := domain.NewInvoice(
 			account.ID,
 			amountCents,
@@ -102,7 +102,7 @@ func (s *DemoService) seedInvoices(account *domain.Account) error {
 		invoice.CreatedAt = createdAt
 		invoice.UpdatedAt = createdAt.Add(2 * time.Hour)
 
-		if err := s.invoiceRepository.Save(invoice, ""); err != nil {
+		if err := s.invoiceRepository.Save(invoice, "", nil); err != nil {
 			return err
 		}
 
@@ -131,11 +131,9 @@ func (s *DemoService) seedInvoices(account *domain.Account) error {
 		}
 	}
 
+	// O saldo das faturas aprovadas ja foi lancado no ledger por Save.
 	if approvedTotalCents > 0 {
 		account.AddBalance(approvedTotalCents)
-		if err := s.accountRepository.AddBalance(account.ID, approvedTotalCents); err != nil {
-			return err
-		}
 	}
 
 	return nil
@@ -1,11 +1,15 @@
 package service
 
 import (
+	"context"
+	"log/slog"
 	"time"
 
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/cardbin"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain/events"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/fraud"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/outbox"
 )
 
@@ -14,6 +18,25 @@ type InvoiceService struct {
 	accountService    AccountService
 	kafkaProducer     KafkaProducerInterface
 	limitService      *AccountLimitService
+	cardTokens        *CardTokenService
+	customers         *CustomerService
+	fraudEngine       fraud.RuleEngine
+	decider           domain.Decider
+	bins              *cardbin.Table
+	boleto            BoletoConfig
+	pix               PixConfig
+}
+
+// BoletoConfig define o emissor dos boletos e o prazo padrao de vencimento em dias.
+type BoletoConfig struct {
+	Issuer  domain.BoletoIssuer
+	DueDays int
+}
+
+// PixConfig define o recebedor das cobrancas PIX e a expiracao padrao. Sem chave, PIX fica desativado.
+type PixConfig struct {
+	Receiver   domain.PixReceiver
+	Expiration time.Duration
 }
 
 func NewInvoiceService(
@@ -21,35 +44,135 @@ func NewInvoiceService(
 	accountService AccountService,
 	kafkaProducer KafkaProducerInterface,
 	limitService *AccountLimitService,
+	cardTokens *CardTokenService,
+	customers *CustomerService,
+	fraudEngine fraud.RuleEngine,
+	decider domain.Decider,
+	bins *cardbin.Table,
+	boleto BoletoConfig,
+	pix PixConfig,
 ) *InvoiceService {
 	return &InvoiceService{
 		invoiceRepository: invoiceRepository,
 		accountService:    accountService,
 		kafkaProducer:     kafkaProducer,
 		limitService:      limitService,
+		cardTokens:        cardTokens,
+		customers:         customers,
+		fraudEngine:       fraudEngine,
+		decider:           decider,
+		bins:              bins,
+		boleto:            boleto,
+		pix:               pix,
 	}
 }
 
 func (s *InvoiceService) Create(input dto.CreateInvoiceInput) (*dto.InvoiceOutput, error) {
-	accountOutput, err := s.accountService.FindByAPIKey(input.APIKey)
+	// Com ID reservado, uma repeticao devolve a fatura ja criada em vez de cobrar de novo.
+	if input.InvoiceID != "" {
+		existing, err := s.findOwnedInvoice(input.InvoiceID, input.AccountID, input.Mode)
+		if err == nil {
+			return dto.FromInvoice(existing), nil
+		}
+		if err != domain.ErrInvoiceNotFound {
+			return nil, err
+		}
+	}
+
+	accountOutput, err := s.accountService.FindByID(input.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sem currency no payload, a fatura usa a moeda padrao da conta.
+	currencyCode := input.Currency
+	if currencyCode == "" {
+		currencyCode = accountOutput.Currency
+	}
+	currency, err := domain.NormalizeCurrency(currencyCode)
 	if err != nil {
 		return nil, err
 	}
 
-	amountCents := domain.AmountToCents(input.Amount)
+	if !domain.HasValidPrecision(input.Amount, currency) {
+		return nil, domain.ErrInvalidAmount
+	}
+	if input.PaymentType == domain.PaymentTypeBoleto && currency != domain.BoletoCurrency {
+		return nil, domain.ErrUnsupportedCurrency
+	}
+	if input.PaymentType == domain.PaymentTypePix {
+		if !s.pix.Receiver.Enabled() {
+			return nil, domain.ErrPixDisabled
+		}
+		if currency != domain.PixCurrency {
+			return nil, domain.ErrUnsupportedCurrency
+		}
+	}
+
+	// Com card_token o cartao vem do cofre e segue o mesmo caminho dos campos de cartao.
+	if input.CardToken != "" {
+		if s.cardTokens == nil {
+			return nil, domain.ErrCardVaultDisabled
+		}
+		token, err := s.cardTokens.Resolve(accountOutput.ID, input.Mode, input.CardToken)
+		if err != nil {
+			return nil, err
+		}
+		card := token.Card()
+		input.CardNumber = card.Number
+		input.ExpiryMonth = card.ExpiryMonth
+		input.ExpiryYear = card.ExpiryYear
+		input.CardholderName = card.CardholderName
+
+		// Token salvo em um cliente leva a fatura para esse cliente e nao pode ser usado por outro.
+		if token.CustomerID != "" {
+			if input.CustomerID == "" {
+				input.CustomerID = token.CustomerID
+			} else if input.CustomerID != token.CustomerID {
+				return nil, domain.ErrCardTokenAttached
+			}
+		}
+	}
+
+	if input.CustomerID != "" {
+		if s.customers == nil {
+			return nil, domain.ErrCustomerNotFound
+		}
+		if _, err := s.customers.Find(accountOutput.ID, input.Mode, input.CustomerID); err != nil {
+			return nil, err
+		}
+	}
+
+	mode := input.Mode
+	amountCents := domain.AmountToMinor(input.Amount, currency)
+	invoices := domain.InvoiceRepository(s.invoiceRepository)
+	var limitScope domain.LimitScope
+	var limitViolation *domain.LimitExceededError
 	if s.limitService != nil {
-		if err := s.limitService.Validate(accountOutput.ID, amountCents, time.Now()); err != nil {
+		// O escopo vai ate a fatura ser gravada para que requisicoes concorrentes vejam o uso atualizado.
+		limitScope, err = s.limitService.Lock(context.Background(), accountOutput.ID, mode, currency)
+		if err != nil {
+			return nil, err
+		}
+		defer limitScope.Rollback()
+
+		limitViolation, err = s.limitService.Validate(limitScope, accountOutput.ID, mode, currency, input.PaymentType, amountCents, time.Now())
+		if err != nil {
 			return nil, err
 		}
+		invoices = limitScope
 	}
 
-	invoice, err := dto.ToInvoice(input, accountOutput.ID)
+	invoice, err := dto.ToInvoice(input, accountOutput.ID, currency)
 	if err != nil {
 		return nil, err
 	}
-
-	if err := invoice.Process(); err != nil {
-		return nil, err
+	invoice.Mode = mode
+	if input.InvoiceID != "" {
+		invoice.ID = input.InvoiceID
+	}
+	if info, ok := s.bins.Lookup(input.CardNumber); ok && invoice.PaymentType == domain.PaymentTypeCreditCard {
+		invoice.CardFunding, invoice.CardCountry = info.Funding, info.Country
 	}
 
 	requestID := ""
@@ -57,12 +180,42 @@ func (s *InvoiceService) Create(input dto.CreateInvoiceInput) (*dto.InvoiceOutpu
 		requestID = value
 	}
 
+	output, err := s.persist(invoices, invoice, input, accountOutput, limitViolation, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if limitScope != nil {
+		if err := limitScope.Commit(); err != nil {
+			return nil, err
+		}
+	}
+	return output, nil
+}
+
+// persist decide e grava a fatura conforme o tipo de pagamento. invoices e o escopo de limite
+// quando ha limites; a gravacao so e confirmada no Commit do escopo.
+func (s *InvoiceService) persist(invoices domain.InvoiceRepository, invoice *domain.Invoice, input dto.CreateInvoiceInput, accountOutput *dto.AccountOutput, limitViolation *domain.LimitExceededError, requestID string) (*dto.InvoiceOutput, error) {
+	switch invoice.PaymentType {
+	case domain.PaymentTypeBoleto:
+		return s.createBoleto(invoices, invoice, input.DueDate, limitViolation, requestID)
+	case domain.PaymentTypePix:
+		return s.createPix(invoices, invoice, input.PixExpiresIn, limitViolation, requestID)
+	}
+
+	fraudMetadata, err := s.decide(invoice, input, accountOutput, limitViolation != nil)
+	if err != nil {
+		return nil, err
+	}
+	// Limite soft excedido: a fatura pendente espera o operador e nao vai ao antifraude automatico.
+	invoice.ManualReview = limitViolation != nil && invoice.Status == domain.StatusPending
+
 	// Se o status for pending, significa que e uma transacao de alto valor.
-	if invoice.Status == domain.StatusPending {
+	if invoice.Status == domain.StatusPending && !invoice.ManualReview {
 		pendingTransaction := events.NewPendingTransaction(
 			invoice.AccountID,
 			invoice.ID,
-			domain.CentsToAmount(invoice.AmountCents),
+			invoice.Currency,
+			domain.MinorToAmount(invoice.AmountCents, invoice.Currency),
 			invoice.AmountCents,
 		)
 
@@ -76,19 +229,25 @@ func (s *InvoiceService) Create(input dto.CreateInvoiceInput) (*dto.InvoiceOutpu
 			correlationID = requestID
 		}
 
-		if err := s.invoiceRepository.SaveWithOutbox(invoice, "pending_transaction", payload, correlationID); err != nil {
+		// Faturas de teste nunca vao ao topico real; o TestModeResponder as resolve.
+		eventType := "pending_transaction"
+		if invoice.Mode == domain.ModeTest {
+			eventType = TestPendingTransactionEventType
+		}
+
+		if err := invoices.SaveWithOutbox(invoice, eventType, payload, correlationID, fraudMetadata); err != nil {
 			return nil, err
 		}
 	} else {
-		if err := s.invoiceRepository.Save(invoice, requestID); err != nil {
+		// Save credita o saldo de faturas aprovadas via ledger na mesma transacao; faturas em
+		// revisao manual sao gravadas pending, sem evento para o antifraude.
+		if err := invoices.Save(invoice, requestID, fraudMetadata); err != nil {
 			return nil, err
 		}
 	}
 
-	// Para transacoes aprovadas, atualizar o saldo
-	if invoice.Status == domain.StatusApproved {
-		_, err = s.accountService.UpdateBalanceByAccountID(invoice.AccountID, invoice.AmountCents)
-		if err != nil {
+	if limitViolation != nil {
+		if err := recordLimitReview(invoices, invoice, limitViolation, requestID); err != nil {
 			return nil, err
 		}
 	}
@@ -96,67 +255,393 @@ func (s *InvoiceService) Create(input dto.CreateInvoiceInput) (*dto.InvoiceOutpu
 	return dto.FromInvoice(invoice), nil
 }
 
-func (s *InvoiceService) GetByID(id, apiKey string) (*dto.InvoiceOutput, error) {
-	invoice, err := s.invoiceRepository.FindByID(id)
+// createBoleto emite o boleto e grava a fatura em pending ate a liquidacao. Boletos nao
+// passam pelo antifraude de cartao nem pelo decider.
+func (s *InvoiceService) createBoleto(invoices domain.InvoiceRepository, invoice *domain.Invoice, dueDateInput string, limitViolation *domain.LimitExceededError, requestID string) (*dto.InvoiceOutput, error) {
+	dueDate := domain.BoletoDueDate(time.Now(), s.boleto.DueDays)
+	if dueDateInput != "" {
+		parsed, err := time.Parse("2006-01-02", dueDateInput)
+		if err != nil {
+			return nil, err
+		}
+		dueDate = parsed
+	}
+
+	ourNumber, err := invoices.NextBoletoNumber()
+	if err != nil {
+		return nil, err
+	}
+	boleto, err := s.boleto.Issuer.Issue(invoice.ID, ourNumber, invoice.AmountCents, dueDate)
 	if err != nil {
 		return nil, err
 	}
+	if err := invoices.SaveBoleto(invoice, boleto, requestID); err != nil {
+		return nil, err
+	}
+
+	if limitViolation != nil {
+		if err := recordLimitReview(invoices, invoice, limitViolation, requestID); err != nil {
+			return nil, err
+		}
+	}
+
+	output := dto.FromInvoice(invoice)
+	output.Boleto = dto.FromBoleto(boleto)
+	return output, nil
+}
+
+// createPix gera a cobranca PIX e grava a fatura em pending ate a confirmacao do PSP.
+// Como o boleto, PIX nao passa pelo antifraude de cartao nem pelo decider.
+func (s *InvoiceService) createPix(invoices domain.InvoiceRepository, invoice *domain.Invoice, expiresIn int, limitViolation *domain.LimitExceededError, requestID string) (*dto.InvoiceOutput, error) {
+	expiration := s.pix.Expiration
+	if expiresIn > 0 {
+		expiration = time.Duration(expiresIn) * time.Second
+	}
 
-	accountOutput, err := s.accountService.FindByAPIKey(apiKey)
+	charge, err := s.pix.Receiver.NewCharge(invoice.ID, invoice.AmountCents, invoice.CreatedAt.Add(expiration))
 	if err != nil {
 		return nil, err
 	}
+	if err := invoices.SavePix(invoice, charge, requestID); err != nil {
+		return nil, err
+	}
 
-	if invoice.AccountID != accountOutput.ID {
-		return nil, domain.ErrUnauthorizedAccess
+	if limitViolation != nil {
+		if err := recordLimitReview(invoices, invoice, limitViolation, requestID); err != nil {
+			return nil, err
+		}
 	}
 
-	return dto.FromInvoice(invoice), nil
+	output := dto.FromInvoice(invoice)
+	output.Pix = dto.FromPixCharge(charge)
+	return output, nil
+}
+
+// recordLimitReview registra por que a fatura foi para revisao manual (limite soft excedido).
+// Roda no escopo de limite, entao o evento e gravado junto com a fatura.
+func recordLimitReview(invoices domain.InvoiceRepository, invoice *domain.Invoice, violation *domain.LimitExceededError, requestID string) error {
+	metadata := map[string]any{
+		"reason": violation.Reason,
+		"window": violation.Window,
+	}
+	if violation.PaymentType != "" {
+		metadata["payment_type"] = violation.PaymentType
+	}
+	return invoices.AddInvoiceEvent(invoice.ID, "limit_review", &invoice.Status, &invoice.Status, metadata, requestID, nil)
+}
+
+// decide avalia a fatura no motor antifraude: reject rejeita, review mantem pending
+// para o antifraude externo e approve segue para a decisao local do decider (Process).
+// Com limitReview (limite soft excedido) a fatura nunca e aprovada localmente: fica pending
+// para revisao manual, salvo rejeicao do motor. Retorna o metadata do evento fraud_evaluated.
+func (s *InvoiceService) decide(invoice *domain.Invoice, input dto.CreateInvoiceInput, account *dto.AccountOutput, limitReview bool) (map[string]any, error) {
+	if s.fraudEngine == nil {
+		if limitReview {
+			return nil, nil
+		}
+		return nil, invoice.Process(s.decider, input.CardNumber)
+	}
+
+	cardBIN := ""
+	if len(input.CardNumber) >= 6 {
+		cardBIN = input.CardNumber[:6]
+	}
+	assessment, err := s.fraudEngine.Evaluate(context.Background(), fraud.Input{
+		AccountID:        account.ID,
+		Mode:             invoice.Mode,
+		AccountCreatedAt: account.CreatedAt,
+		CustomerID:       invoice.CustomerID,
+		Currency:         invoice.Currency,
+		AmountCents:      invoice.AmountCents,
+		CardBIN:          cardBIN,
+		CardLastDigits:   invoice.CardLastDigits,
+		CardholderName:   input.CardholderName,
+		Now:              time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch assessment.Decision {
+	case fraud.DecisionReject:
+		err = invoice.UpdateStatus(domain.StatusRejected)
+	case fraud.DecisionReview:
+		// Permanece pending e segue para o antifraude externo via Kafka.
+	default:
+		// Limite soft excedido: fica pending para revisao manual em vez da decisao local.
+		if !limitReview {
+			err = invoice.Process(s.decider, input.CardNumber)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return assessment.Metadata(), nil
 }
 
-func (s *InvoiceService) ListByAccount(accountID string) ([]*dto.InvoiceOutput, error) {
-	invoices, err := s.invoiceRepository.FindByAccountID(accountID)
+func (s *InvoiceService) GetByID(id, accountID string, mode domain.Mode) (*dto.InvoiceOutput, error) {
+	invoice, err := s.findOwnedInvoice(id, accountID, mode)
 	if err != nil {
 		return nil, err
 	}
 
-	output := make([]*dto.InvoiceOutput, len(invoices))
-	for i, invoice := range invoices {
-		output[i] = dto.FromInvoice(invoice)
+	output := dto.FromInvoice(invoice)
+	if invoice.PaymentType == domain.PaymentTypeBoleto {
+		boleto, err := s.invoiceRepository.FindBoletoByInvoiceID(invoice.ID)
+		if err != nil && err != domain.ErrBoletoNotFound {
+			return nil, err
+		}
+		if boleto != nil {
+			output.Boleto = dto.FromBoleto(boleto)
+		}
+	}
+	if invoice.PaymentType == domain.PaymentTypePix {
+		charge, err := s.invoiceRepository.FindPixChargeByInvoiceID(invoice.ID)
+		if err != nil && err != domain.ErrPixChargeNotFound {
+			return nil, err
+		}
+		if charge != nil {
+			output.Pix = dto.FromPixCharge(charge)
+		}
 	}
 	return output, nil
 }
 
-// ListByAccountAPIKey lista as faturas de uma conta através de uma API Key
-func (s *InvoiceService) ListByAccountAPIKey(apiKey string) ([]*dto.InvoiceOutput, error) {
-	accountOutput, err := s.accountService.FindByAPIKey(apiKey)
+// ConfirmPixPayments processa as confirmacoes do webhook PIX. Cobrancas desconhecidas e valores
+// divergentes sao logados e ignorados (o PSP nao deve reenviar); erros inesperados interrompem
+// o lote para que o PSP reenvie a notificacao.
+func (s *InvoiceService) ConfirmPixPayments(input dto.PixWebhookInput, requestID string) error {
+	for _, payment := range input.Pix {
+		amountCents, err := domain.ParsePixAmount(payment.Valor)
+		if err != nil {
+			slog.Warn("invalid pix amount", "txid", payment.TxID, "end_to_end_id", payment.EndToEndID, "valor", payment.Valor)
+			continue
+		}
+		paidAt := payment.Horario
+		if paidAt.IsZero() {
+			paidAt = time.Now()
+		}
+
+		_, err = s.invoiceRepository.SettlePix(domain.PixPayment{
+			TxID:        payment.TxID,
+			EndToEndID:  payment.EndToEndID,
+			AmountCents: amountCents,
+			PaidAt:      paidAt,
+		}, requestID)
+		switch err {
+		case nil:
+		case domain.ErrPixChargeNotFound, domain.ErrPixAmountMismatch, domain.ErrPixInvoiceClosed:
+			slog.Warn("pix payment not applied", "txid", payment.TxID, "end_to_end_id", payment.EndToEndID, "error", err)
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// SettleBoletos processa um lote de pagamentos de boleto (retorno bancario). Cada pagamento
+// e liquidado em transacao propria; falhas viram o resultado do item e nao interrompem o lote.
+func (s *InvoiceService) SettleBoletos(input dto.SettleBoletosInput, requestID string) *dto.BoletoSettlementOutput {
+	output := &dto.BoletoSettlementOutput{Results: make([]dto.BoletoSettlementResult, 0, len(input.Payments))}
+	for _, payment := range input.Payments {
+		result := dto.BoletoSettlementResult{Code: payment.Code}
+
+		barcode, err := domain.ParseBoletoCode(payment.Code)
+		if err != nil {
+			result.Result = dto.BoletoResultInvalidCode
+			output.Results = append(output.Results, result)
+			continue
+		}
+
+		paidAt := time.Now()
+		if payment.PaidAt != nil {
+			paidAt = *payment.PaidAt
+		}
+		invoice, _, err := s.invoiceRepository.SettleBoleto(domain.BoletoSettlement{
+			Barcode:         barcode,
+			PaidAmountCents: domain.AmountToMinor(payment.PaidAmount, domain.BoletoCurrency),
+			PaidAt:          paidAt,
+			Source:          input.Source,
+		}, requestID)
+		if invoice != nil {
+			result.InvoiceID = invoice.ID
+			result.Status = string(invoice.Status)
+		}
+
+		switch err {
+		case nil:
+			result.Result = dto.BoletoResultSettled
+			output.Settled++
+		case domain.ErrBoletoNotFound:
+			result.Result = dto.BoletoResultNotFound
+		case domain.ErrBoletoAlreadyPaid:
+			result.Result = dto.BoletoResultAlreadyPaid
+		case domain.ErrBoletoNotPayable:
+			result.Result = dto.BoletoResultNotPayable
+		case domain.ErrBoletoAmountMismatch:
+			result.Result = dto.BoletoResultAmountMismatch
+		default:
+			slog.Error("failed to settle boleto", "barcode", barcode, "error", err)
+			result.Result = dto.BoletoResultError
+		}
+		output.Results = append(output.Results, result)
+	}
+	return output
+}
+
+// AccountCurrency retorna a moeda da conta, usada nos filtros de valor sem currency explicita
+func (s *InvoiceService) AccountCurrency(accountID string) (string, error) {
+	account, err := s.accountService.FindByID(accountID)
+	if err != nil {
+		return "", err
+	}
+	return account.Currency, nil
+}
+
+// ListByAccount lista uma pagina de faturas da conta no modo informado aplicando os filtros
+func (s *InvoiceService) ListByAccount(accountID string, mode domain.Mode, filter domain.InvoiceFilter) (*dto.InvoiceListOutput, error) {
+	filter.Mode = mode
+	page, err := s.invoiceRepository.ListByAccountID(accountID, filter)
 	if err != nil {
 		return nil, err
 	}
+	return dto.FromInvoicePage(page), nil
+}
+
+// Refund estorna total ou parcialmente uma fatura aprovada da conta.
+func (s *InvoiceService) Refund(input dto.RefundInvoiceInput) (*dto.InvoiceOutput, error) {
+	invoice, err := s.findOwnedInvoice(input.InvoiceID, input.AccountID, input.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	var amountCents int64
+	if input.Amount != nil {
+		if !domain.HasValidPrecision(*input.Amount, invoice.Currency) {
+			return nil, domain.ErrInvalidAmount
+		}
+		amountCents = domain.AmountToMinor(*input.Amount, invoice.Currency)
+		if amountCents <= 0 {
+			return nil, domain.ErrInvalidAmount
+		}
+	}
 
-	return s.ListByAccount(accountOutput.ID)
+	requestID := ""
+	if value, ok := input.Metadata["request_id"]; ok {
+		requestID = value
+	}
+
+	refunded, err := s.invoiceRepository.ApplyRefund(invoice.ID, amountCents, requestID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromInvoice(refunded), nil
 }
 
-// ProcessTransactionResult processa o resultado de uma transação após análise de fraude
-func (s *InvoiceService) ProcessTransactionResult(invoiceID string, status domain.Status, requestID string) error {
-	return s.invoiceRepository.ApplyTransactionResult(invoiceID, status, requestID)
+// Cancel cancela uma fatura pendente da conta
+func (s *InvoiceService) Cancel(invoiceID, accountID string, mode domain.Mode, requestID string) (*dto.InvoiceOutput, error) {
+	invoice, err := s.findOwnedInvoice(invoiceID, accountID, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelled, err := s.invoiceRepository.Cancel(invoice.ID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromInvoice(cancelled), nil
 }
 
-// ListEventsByInvoiceID retorna eventos de uma fatura garantindo autorizacao.
-func (s *InvoiceService) ListEventsByInvoiceID(invoiceID, apiKey string) ([]*dto.InvoiceEventOutput, error) {
-	invoice, err := s.invoiceRepository.FindByID(invoiceID)
+// Capture captura total ou parcialmente uma fatura autorizada. Sem amount, captura o valor autorizado.
+func (s *InvoiceService) Capture(input dto.CaptureInvoiceInput) (*dto.InvoiceOutput, error) {
+	invoice, err := s.findOwnedInvoice(input.InvoiceID, input.AccountID, input.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	var amountCents int64
+	if input.Amount != nil {
+		if !domain.HasValidPrecision(*input.Amount, invoice.Currency) {
+			return nil, domain.ErrInvalidAmount
+		}
+		amountCents = domain.AmountToMinor(*input.Amount, invoice.Currency)
+		if amountCents <= 0 {
+			return nil, domain.ErrInvalidAmount
+		}
+	}
+
+	requestID := ""
+	if value, ok := input.Metadata["request_id"]; ok {
+		requestID = value
+	}
+
+	captured, err := s.invoiceRepository.Capture(invoice.ID, amountCents, requestID)
 	if err != nil {
 		return nil, err
 	}
+	return dto.FromInvoice(captured), nil
+}
 
-	accountOutput, err := s.accountService.FindByAPIKey(apiKey)
+// Void libera uma autorizacao da conta sem captura
+func (s *InvoiceService) Void(invoiceID, accountID string, mode domain.Mode, requestID string) (*dto.InvoiceOutput, error) {
+	invoice, err := s.findOwnedInvoice(invoiceID, accountID, mode)
 	if err != nil {
 		return nil, err
 	}
 
-	if invoice.AccountID != accountOutput.ID {
+	voided, err := s.invoiceRepository.Void(invoice.ID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromInvoice(voided), nil
+}
+
+// findOwnedInvoice busca a fatura e garante que pertence a conta.
+// Faturas de outro modo (live/test) sao tratadas como inexistentes.
+func (s *InvoiceService) findOwnedInvoice(invoiceID, accountID string, mode domain.Mode) (*domain.Invoice, error) {
+	invoice, err := s.invoiceRepository.FindByID(invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if invoice.AccountID != accountID {
 		return nil, domain.ErrUnauthorizedAccess
 	}
+	if invoice.Mode != mode {
+		return nil, domain.ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+// ResolveReview aplica a decisao do operador a uma fatura em revisao manual: approve segue o
+// caminho normal de aprovacao (credito via ledger) e reject rejeita a fatura.
+func (s *InvoiceService) ResolveReview(invoiceID string, approve bool, operator, reason, requestID string) (*dto.InvoiceOutput, error) {
+	status := domain.StatusRejected
+	if approve {
+		status = domain.StatusApproved
+	}
+	metadata := map[string]any{
+		"decision": status,
+		"operator": operator,
+		"reason":   reason,
+	}
+	invoice, err := s.invoiceRepository.ResolveReview(invoiceID, status, metadata, requestID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromInvoice(invoice), nil
+}
+
+// ProcessTransactionResult processa o resultado de uma transação após análise de fraude
+func (s *InvoiceService) ProcessTransactionResult(invoiceID string, status domain.Status, requestID string) error {
+	return s.invoiceRepository.ApplyTransactionResult(invoiceID, status, requestID)
+}
+
+// ListEventsByInvoiceID retorna eventos de uma fatura garantindo autorizacao.
+func (s *InvoiceService) ListEventsByInvoiceID(invoiceID, accountID string, mode domain.Mode) ([]*dto.InvoiceEventOutput, error) {
+	if _, err := s.findOwnedInvoice(invoiceID, accountID, mode); err != nil {
+		return nil, err
+	}
 
 	events, err := s.invoiceRepository.ListEventsByInvoiceID(invoiceID)
 	if err != nil {
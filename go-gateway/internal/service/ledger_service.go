@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/ledger"
+)
+
+const (
+	DefaultLedgerPageSize = 50
+	MaxLedgerPageSize     = 200
+)
+
+// LedgerService expoe os lancamentos do ledger das contas
+type LedgerService struct {
+	repository *ledger.Repository
+}
+
+func NewLedgerService(repository *ledger.Repository) *LedgerService {
+	return &LedgerService{repository: repository}
+}
+
+// ListEntries retorna os lancamentos mais recentes da conta no modo informado
+func (s *LedgerService) ListEntries(ctx context.Context, accountID string, mode domain.Mode, limit int) ([]dto.LedgerEntryOutput, error) {
+	entries, err := s.repository.ListByAccountID(ctx, accountID, mode, limit)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromLedgerEntries(entries), nil
+}
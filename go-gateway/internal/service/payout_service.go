@@ -0,0 +1,179 @@
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain/events"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+)
+
+// PayoutRequestedEventType e o tipo de outbox publicado no topico de saques.
+const PayoutRequestedEventType = "payout_requested"
+
+// TestPayoutRequestedEventType e o tipo de outbox dos saques de teste; nunca chega ao
+// processador de liquidacao.
+const TestPayoutRequestedEventType = "test_payout_requested"
+
+// PayoutService gerencia as contas bancarias do merchant e os saques do saldo para elas.
+type PayoutService struct {
+	repository     domain.PayoutRepository
+	accountService AccountService
+}
+
+func NewPayoutService(repository domain.PayoutRepository, accountService AccountService) *PayoutService {
+	return &PayoutService{repository: repository, accountService: accountService}
+}
+
+func (s *PayoutService) CreateBankAccount(accountID string, mode domain.Mode, input dto.CreateBankAccountInput) (*dto.BankAccountOutput, error) {
+	bankAccount, err := domain.NewBankAccount(
+		accountID,
+		mode,
+		input.HolderName,
+		input.HolderDocument,
+		input.BankCode,
+		input.Branch,
+		input.AccountNumber,
+		input.AccountType,
+		time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.SaveBankAccount(bankAccount); err != nil {
+		return nil, err
+	}
+	return dto.FromBankAccount(bankAccount), nil
+}
+
+// findBankAccount retorna a conta bancaria da conta e do modo; as de outra conta, de outro
+// modo ou removidas retornam ErrBankAccountNotFound.
+func (s *PayoutService) findBankAccount(accountID string, mode domain.Mode, id string) (*domain.BankAccount, error) {
+	bankAccount, err := s.repository.FindBankAccountByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if bankAccount.AccountID != accountID || bankAccount.Mode != mode {
+		return nil, domain.ErrBankAccountNotFound
+	}
+	return bankAccount, nil
+}
+
+func (s *PayoutService) ListBankAccounts(accountID string, mode domain.Mode) ([]*dto.BankAccountOutput, error) {
+	bankAccounts, err := s.repository.ListBankAccounts(accountID, mode)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]*dto.BankAccountOutput, 0, len(bankAccounts))
+	for _, bankAccount := range bankAccounts {
+		output = append(output, dto.FromBankAccount(bankAccount))
+	}
+	return output, nil
+}
+
+// DeleteBankAccount remove a conta bancaria. Saques ja solicitados seguem normalmente.
+func (s *PayoutService) DeleteBankAccount(accountID string, mode domain.Mode, id string) error {
+	if _, err := s.findBankAccount(accountID, mode, id); err != nil {
+		return err
+	}
+	return s.repository.DeleteBankAccount(id, time.Now())
+}
+
+// Create solicita um saque: reserva o valor no saldo e publica o pedido pelo outbox. Sem
+// currency, o saque usa a moeda padrao da conta.
+func (s *PayoutService) Create(accountID string, mode domain.Mode, input dto.CreatePayoutInput, requestID string) (*dto.PayoutOutput, error) {
+	currencyCode := input.Currency
+	if currencyCode == "" {
+		account, err := s.accountService.FindByID(accountID)
+		if err != nil {
+			return nil, err
+		}
+		currencyCode = account.Currency
+	}
+	currency, err := domain.NormalizeCurrency(currencyCode)
+	if err != nil {
+		return nil, err
+	}
+	if !domain.HasValidPrecision(input.Amount, currency) {
+		return nil, domain.ErrInvalidAmount
+	}
+
+	bankAccount, err := s.findBankAccount(accountID, mode, input.BankAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	payout, err := domain.NewPayout(accountID, mode, bankAccount.ID, domain.AmountToMinor(input.Amount, currency), currency, input.Description, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(events.NewPayoutRequested(payout, bankAccount))
+	if err != nil {
+		return nil, err
+	}
+	eventType := PayoutRequestedEventType
+	if mode == domain.ModeTest {
+		eventType = TestPayoutRequestedEventType
+	}
+	if err := s.repository.Create(payout, eventType, payload, requestID); err != nil {
+		return nil, err
+	}
+	return dto.FromPayout(payout), nil
+}
+
+func (s *PayoutService) Get(accountID string, mode domain.Mode, id string) (*dto.PayoutOutput, error) {
+	payout, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if payout.AccountID != accountID || payout.Mode != mode {
+		return nil, domain.ErrPayoutNotFound
+	}
+	return dto.FromPayout(payout), nil
+}
+
+func (s *PayoutService) List(accountID string, filter domain.PayoutFilter) (*dto.PayoutListOutput, error) {
+	page, err := s.repository.ListByAccountID(accountID, filter)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromPayoutPage(page), nil
+}
+
+// ListDeadLettered lista os saques cujo pedido nao chegou ao processador de liquidacao depois
+// de todas as tentativas (admin).
+func (s *PayoutService) ListDeadLettered(limit int) ([]*dto.DeadLetteredPayoutOutput, error) {
+	payouts, err := s.repository.ListDeadLettered(limit)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]*dto.DeadLetteredPayoutOutput, len(payouts))
+	for i, payout := range payouts {
+		output[i] = dto.FromDeadLetteredPayout(payout)
+	}
+	return output, nil
+}
+
+// Redeliver reenvia ao processador o pedido de um saque que ficou dead no outbox (admin).
+func (s *PayoutService) Redeliver(id string) (*dto.PayoutOutput, error) {
+	payout, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.Redeliver(payout.ID); err != nil {
+		return nil, err
+	}
+	return dto.FromPayout(payout), nil
+}
+
+// UpdateStatus aplica o status informado pelo processador de liquidacao. failed devolve o
+// valor ao saldo; repetir o status atual nao altera o saque.
+func (s *PayoutService) UpdateStatus(id string, status domain.PayoutStatus, failureReason string) (*dto.PayoutOutput, error) {
+	payout, err := s.repository.Transition(id, status, failureReason, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromPayout(payout), nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+)
+
+// PricingService gerencia os planos de tarifas das contas. As tarifas sao aplicadas pelo
+// InvoiceRepository quando o saldo da fatura e creditado.
+type PricingService struct {
+	repository     domain.PricingPlanRepository
+	accountService AccountService
+}
+
+func NewPricingService(repository domain.PricingPlanRepository, accountService AccountService) *PricingService {
+	return &PricingService{repository: repository, accountService: accountService}
+}
+
+// Get retorna o plano do modo e da moeda; sem moeda usa a da conta. Sem plano, retorna um
+// plano vazio (sem tarifas).
+func (s *PricingService) Get(accountID string, mode domain.Mode, currency string) (*dto.PricingPlanOutput, error) {
+	if currency == "" {
+		account, err := s.accountService.FindByID(accountID)
+		if err != nil {
+			return nil, err
+		}
+		currency = account.Currency
+	}
+	currency, err := domain.NormalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := s.repository.Get(accountID, mode, currency)
+	if err == domain.ErrPricingPlanNotFound {
+		plan = &domain.PricingPlan{AccountID: accountID, Mode: mode, Currency: currency}
+	} else if err != nil {
+		return nil, err
+	}
+	return dto.FromPricingPlan(plan), nil
+}
+
+// List retorna os planos da conta em todos os modos e moedas. Retorna ErrAccountNotFound.
+func (s *PricingService) List(accountID string) ([]*dto.PricingPlanOutput, error) {
+	if _, err := s.accountService.FindByID(accountID); err != nil {
+		return nil, err
+	}
+	plans, err := s.repository.ListByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromPricingPlans(plans), nil
+}
+
+// Set cria ou substitui o plano do modo e da moeda; vale para as faturas creditadas a partir de agora.
+func (s *PricingService) Set(accountID string, input dto.SetPricingPlanInput) (*dto.PricingPlanOutput, error) {
+	currency, err := domain.NormalizeCurrency(input.Currency)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.repository.Replace(domain.PricingPlan{
+		AccountID: accountID,
+		Mode:      domain.Mode(input.Mode),
+		Currency:  currency,
+		Rates:     input.Rates,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromPricingPlan(plan), nil
+}
+
+// Delete remove o plano do modo e da moeda. Retorna ErrPricingPlanNotFound.
+func (s *PricingService) Delete(accountID string, mode domain.Mode, currency string) error {
+	currency, err := domain.NormalizeCurrency(currency)
+	if err != nil {
+		return err
+	}
+	return s.repository.Delete(accountID, mode, currency)
+}
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+)
+
+// subscriptionRequestID identifica as faturas criadas pelo scheduler de assinaturas.
+const subscriptionRequestID = "subscription-scheduler"
+
+// subscriptionClaimLease e o tempo que uma assinatura reservada fica fora da fila. Se a
+// instancia cair antes de gravar o resultado, a cobranca volta a ser tentada depois dele.
+const subscriptionClaimLease = 5 * time.Minute
+
+// SubscriptionInvoicer cria as faturas da cobranca recorrente. Implementado por InvoiceService.
+type SubscriptionInvoicer interface {
+	Create(input dto.CreateInvoiceInput) (*dto.InvoiceOutput, error)
+}
+
+// SubscriptionService gerencia planos e assinaturas e executa a cobranca recorrente pelo
+// InvoiceService, com retentativas (dunning) conforme o DunningSchedule.
+type SubscriptionService struct {
+	repository     domain.SubscriptionRepository
+	accountService AccountService
+	customers      *CustomerService
+	cardTokens     domain.CardTokenRepository
+	invoices       SubscriptionInvoicer
+	dunning        domain.DunningSchedule
+}
+
+func NewSubscriptionService(
+	repository domain.SubscriptionRepository,
+	accountService AccountService,
+	customers *CustomerService,
+	cardTokens domain.CardTokenRepository,
+	invoices SubscriptionInvoicer,
+	dunning domain.DunningSchedule,
+) *SubscriptionService {
+	return &SubscriptionService{
+		repository:     repository,
+		accountService: accountService,
+		customers:      customers,
+		cardTokens:     cardTokens,
+		invoices:       invoices,
+		dunning:        dunning,
+	}
+}
+
+// CreatePlan cria um plano. Sem currency, o plano usa a moeda padrao da conta.
+func (s *SubscriptionService) CreatePlan(accountID string, mode domain.Mode, input dto.CreatePlanInput) (*dto.PlanOutput, error) {
+	currencyCode := input.Currency
+	if currencyCode == "" {
+		account, err := s.accountService.FindByID(accountID)
+		if err != nil {
+			return nil, err
+		}
+		currencyCode = account.Currency
+	}
+	currency, err := domain.NormalizeCurrency(currencyCode)
+	if err != nil {
+		return nil, err
+	}
+	if !domain.HasValidPrecision(input.Amount, currency) {
+		return nil, domain.ErrInvalidAmount
+	}
+
+	intervalCount := input.IntervalCount
+	if intervalCount == 0 {
+		intervalCount = 1
+	}
+	plan, err := domain.NewPlan(
+		accountID,
+		mode,
+		strings.TrimSpace(input.Name),
+		domain.AmountToMinor(input.Amount, currency),
+		currency,
+		domain.PlanInterval(input.Interval),
+		intervalCount,
+		input.TrialDays,
+		time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.SavePlan(plan); err != nil {
+		return nil, err
+	}
+	return dto.FromPlan(plan), nil
+}
+
+// findPlan retorna o plano da conta e do modo; planos de outra conta ou modo retornam ErrPlanNotFound.
+func (s *SubscriptionService) findPlan(accountID string, mode domain.Mode, id string) (*domain.Plan, error) {
+	plan, err := s.repository.FindPlanByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if plan.AccountID != accountID || plan.Mode != mode {
+		return nil, domain.ErrPlanNotFound
+	}
+	return plan, nil
+}
+
+func (s *SubscriptionService) GetPlan(accountID string, mode domain.Mode, id string) (*dto.PlanOutput, error) {
+	plan, err := s.findPlan(accountID, mode, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromPlan(plan), nil
+}
+
+func (s *SubscriptionService) ListPlans(accountID string, mode domain.Mode) ([]*dto.PlanOutput, error) {
+	plans, err := s.repository.ListPlans(accountID, mode)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]*dto.PlanOutput, len(plans))
+	for i, plan := range plans {
+		output[i] = dto.FromPlan(plan)
+	}
+	return output, nil
+}
+
+// ArchivePlan impede novas assinaturas do plano; as assinaturas existentes continuam cobrando.
+func (s *SubscriptionService) ArchivePlan(accountID string, mode domain.Mode, id string) (*dto.PlanOutput, error) {
+	plan, err := s.findPlan(accountID, mode, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := s.repository.ArchivePlan(plan.ID, now); err != nil {
+		return nil, err
+	}
+	plan.Active, plan.UpdatedAt = false, now
+	return dto.FromPlan(plan), nil
+}
+
+// Create assina o plano para o cliente. O card_token precisa estar salvo no cliente.
+func (s *SubscriptionService) Create(accountID string, mode domain.Mode, input dto.CreateSubscriptionInput) (*dto.SubscriptionOutput, error) {
+	plan, err := s.findPlan(accountID, mode, input.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.Active {
+		return nil, domain.ErrPlanInactive
+	}
+	customer, err := s.customers.Find(accountID, mode, input.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	token, err := s.cardTokens.FindByID(input.CardToken)
+	if err != nil {
+		return nil, err
+	}
+	if token.AccountID != accountID || token.Mode != mode || token.CustomerID != customer.ID {
+		return nil, domain.ErrCardTokenNotFound
+	}
+	now := time.Now()
+	if token.Expired(now) {
+		return nil, domain.ErrCardExpired
+	}
+
+	subscription, event := domain.NewSubscription(plan, customer.ID, token.ID, now)
+	if err := s.repository.Save(subscription, event); err != nil {
+		return nil, err
+	}
+	return dto.FromSubscription(subscription), nil
+}
+
+// find retorna a assinatura da conta e do modo; de outra conta ou modo retorna ErrSubscriptionNotFound.
+func (s *SubscriptionService) find(accountID string, mode domain.Mode, id string) (*domain.Subscription, error) {
+	subscription, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.AccountID != accountID || subscription.Mode != mode {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+	return subscription, nil
+}
+
+func (s *SubscriptionService) Get(accountID string, mode domain.Mode, id string) (*dto.SubscriptionOutput, error) {
+	subscription, err := s.find(accountID, mode, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromSubscription(subscription), nil
+}
+
+func (s *SubscriptionService) List(accountID string, filter domain.SubscriptionFilter) (*dto.SubscriptionListOutput, error) {
+	page, err := s.repository.ListByAccountID(accountID, filter)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromSubscriptionPage(page), nil
+}
+
+// Cancel cancela a assinatura agora ou, com atPeriodEnd, no fim do periodo ja pago.
+func (s *SubscriptionService) Cancel(accountID string, mode domain.Mode, id string, atPeriodEnd bool) (*dto.SubscriptionOutput, error) {
+	subscription, err := s.find(accountID, mode, id)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.Status == domain.SubscriptionCanceled {
+		return nil, domain.ErrSubscriptionCanceled
+	}
+
+	var event *domain.SubscriptionEvent
+	if atPeriodEnd {
+		event = subscription.ScheduleCancel(time.Now())
+	} else {
+		event = subscription.Cancel(domain.CancelReasonRequested, time.Now())
+	}
+	if err := s.repository.Update(subscription, []*domain.SubscriptionEvent{event}); err != nil {
+		return nil, err
+	}
+	return dto.FromSubscription(subscription), nil
+}
+
+func (s *SubscriptionService) ListEvents(accountID string, mode domain.Mode, id string) ([]*dto.SubscriptionEventOutput, error) {
+	subscription, err := s.find(accountID, mode, id)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.repository.ListEvents(subscription.ID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromSubscriptionEvents(events), nil
+}
+
+// ResolveInvoices aplica o resultado das faturas pendentes que ja tem status final: aprovada
+// avanca o periodo, rejeitada, cancelada ou expirada entra no dunning. Retorna quantas
+// assinaturas foram atualizadas.
+func (s *SubscriptionService) ResolveInvoices(ctx context.Context, limit int) (int, error) {
+	settled, err := s.repository.ListSettledInvoices(limit)
+	if err != nil {
+		return 0, err
+	}
+
+	resolved := 0
+	for _, item := range settled {
+		if ctx.Err() != nil {
+			break
+		}
+		subscription := item.Subscription
+		now := time.Now()
+
+		var events []*domain.SubscriptionEvent
+		switch item.InvoiceStatus {
+		case domain.StatusApproved, domain.StatusAuthorized, domain.StatusRefunded, domain.StatusPartiallyRefunded:
+			plan, err := s.repository.FindPlanByID(subscription.PlanID)
+			if err != nil {
+				slog.Error("subscription plan lookup failed", "subscription_id", subscription.ID, "error", err)
+				continue
+			}
+			events = []*domain.SubscriptionEvent{subscription.PaymentSucceeded(plan, subscription.PendingInvoiceID, now)}
+		default:
+			events = subscription.PaymentFailed(subscription.PendingInvoiceID, "invoice_"+string(item.InvoiceStatus), s.dunning, now)
+		}
+
+		if err := s.repository.Update(subscription, events); err != nil {
+			slog.Error("subscription invoice resolution failed", "subscription_id", subscription.ID, "error", err)
+			continue
+		}
+		resolved++
+	}
+	return resolved, nil
+}
+
+// BillDue cria as faturas das assinaturas com cobranca vencida. Retorna quantas assinaturas
+// foram cobradas ou canceladas no fim do periodo.
+func (s *SubscriptionService) BillDue(ctx context.Context, limit int) (int, error) {
+	subscriptions, err := s.repository.ClaimDue(time.Now(), subscriptionClaimLease, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	billed := 0
+	for _, subscription := range subscriptions {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := s.bill(subscription); err != nil {
+			slog.Error("subscription billing failed", "subscription_id", subscription.ID, "error", err)
+			continue
+		}
+		billed++
+	}
+	return billed, nil
+}
+
+// bill cobra o proximo periodo da assinatura com o ID de fatura reservado em ClaimDue. Recusas
+// de pagamento (cartao expirado, limite excedido, fatura rejeitada) contam como falha do
+// dunning; cliente removido cancela a assinatura; erros de infraestrutura deixam a assinatura
+// para depois do lease, sem consumir retentativas. Se o resultado nao puder ser gravado, a
+// proxima tentativa reencontra a mesma fatura pelo ID reservado.
+func (s *SubscriptionService) bill(subscription *domain.Subscription) error {
+	if subscription.CancelAtPeriodEnd {
+		event := subscription.Cancel(domain.CancelReasonPeriodEnd, time.Now())
+		return s.repository.Update(subscription, []*domain.SubscriptionEvent{event})
+	}
+
+	plan, err := s.repository.FindPlanByID(subscription.PlanID)
+	if err != nil {
+		return err
+	}
+
+	output, err := s.invoices.Create(dto.CreateInvoiceInput{
+		AccountID:   subscription.AccountID,
+		Mode:        subscription.Mode,
+		Amount:      domain.MinorToAmount(plan.AmountCents, plan.Currency),
+		Currency:    plan.Currency,
+		Description: plan.Name,
+		PaymentType: domain.PaymentTypeCreditCard,
+		CardToken:   subscription.CardToken,
+		CustomerID:  subscription.CustomerID,
+		Metadata:    map[string]string{"request_id": subscriptionRequestID},
+		InvoiceID:   subscription.BillingInvoiceID,
+	})
+	now := time.Now()
+	if err != nil {
+		if errors.Is(err, domain.ErrCustomerNotFound) {
+			event := subscription.Cancel(domain.CancelReasonCustomerDeleted, now)
+			return s.repository.Update(subscription, []*domain.SubscriptionEvent{event})
+		}
+		if !paymentDeclined(err) {
+			return err
+		}
+		return s.repository.Update(subscription, subscription.PaymentFailed("", err.Error(), s.dunning, now))
+	}
+
+	events := []*domain.SubscriptionEvent{subscription.InvoiceCreated(output.ID, now)}
+	switch domain.Status(output.Status) {
+	case domain.StatusApproved, domain.StatusAuthorized:
+		events = append(events, subscription.PaymentSucceeded(plan, output.ID, now))
+	case domain.StatusPending:
+		// ResolveInvoices aplica o resultado quando a fatura sair de pending.
+	default:
+		events = append(events, subscription.PaymentFailed(output.ID, "invoice_"+output.Status, s.dunning, now)...)
+	}
+
+	if err := s.repository.Update(subscription, events); err != nil {
+		// A fatura ja existe; a proxima tentativa a reencontra pelo ID reservado.
+		slog.Error("subscription invoice not recorded", "subscription_id", subscription.ID, "invoice_id", output.ID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// paymentDeclined informa se o erro de InvoiceService.Create e uma recusa do pagamento e nao
+// uma falha de infraestrutura.
+func paymentDeclined(err error) bool {
+	var limitErr domain.LimitExceededError
+	if errors.As(err, &limitErr) {
+		return true
+	}
+	switch {
+	case errors.Is(err, domain.ErrCardExpired),
+		errors.Is(err, domain.ErrCardTokenNotFound),
+		errors.Is(err, domain.ErrCardTokenAttached),
+		errors.Is(err, domain.ErrInvalidCardNumber),
+		errors.Is(err, domain.ErrUnsupportedCardBrand),
+		errors.Is(err, domain.ErrUnsupportedCurrency),
+		errors.Is(err, domain.ErrInvalidAmount):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+	"github.com/google/uuid"
+)
+
+// stubSubscriptionRepository guarda uma copia da assinatura, como o banco: mudancas feitas
+// pelo servico so valem depois de um Update bem-sucedido.
+type stubSubscriptionRepository struct {
+	domain.SubscriptionRepository
+	plan         *domain.Plan
+	subscription domain.Subscription
+	failUpdates  int
+	updates      int
+}
+
+func (r *stubSubscriptionRepository) FindPlanByID(id string) (*domain.Plan, error) {
+	return r.plan, nil
+}
+
+func (r *stubSubscriptionRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]*domain.Subscription, error) {
+	if !r.subscription.Billable(now) {
+		return nil, nil
+	}
+	r.subscription.ReserveInvoice()
+	claimed := r.subscription
+	return []*domain.Subscription{&claimed}, nil
+}
+
+func (r *stubSubscriptionRepository) Update(subscription *domain.Subscription, events []*domain.SubscriptionEvent) error {
+	if r.failUpdates > 0 {
+		r.failUpdates--
+		return errors.New("connection reset")
+	}
+	r.updates++
+	r.subscription = *subscription
+	return nil
+}
+
+// stubInvoicer cria faturas pendentes e, como InvoiceService, devolve a existente quando o ID
+// reservado ja foi usado.
+type stubInvoicer struct {
+	invoices map[string]*dto.InvoiceOutput
+	err      error
+}
+
+func (i *stubInvoicer) Create(input dto.CreateInvoiceInput) (*dto.InvoiceOutput, error) {
+	if i.err != nil {
+		return nil, i.err
+	}
+	if existing, ok := i.invoices[input.InvoiceID]; ok {
+		return existing, nil
+	}
+	id := input.InvoiceID
+	if id == "" {
+		id = uuid.New().String()
+	}
+	output := &dto.InvoiceOutput{ID: id, Status: string(domain.StatusPending)}
+	i.invoices[id] = output
+	return output, nil
+}
+
+func newBillingFixture(now time.Time) (*stubSubscriptionRepository, *stubInvoicer, *SubscriptionService) {
+	plan := &domain.Plan{ID: "plan", AccountID: "acc-1", AmountCents: 2990, Currency: "BRL", Interval: domain.PlanIntervalMonth, IntervalCount: 1}
+	subscription, _ := domain.NewSubscription(plan, "customer", "tok_1", now.Add(-time.Minute))
+	repo := &stubSubscriptionRepository{plan: plan, subscription: *subscription}
+	invoicer := &stubInvoicer{invoices: map[string]*dto.InvoiceOutput{}}
+	return repo, invoicer, NewSubscriptionService(repo, AccountService{}, nil, nil, invoicer, domain.DefaultDunningSchedule)
+}
+
+func TestBillDueReusesReservedInvoiceAfterUpdateFailure(t *testing.T) {
+	repo, invoicer, svc := newBillingFixture(time.Now())
+	repo.failUpdates = 1
+
+	if billed, err := svc.BillDue(context.Background(), 10); err != nil || billed != 0 {
+		t.Fatalf("expected failed billing, got %d (%v)", billed, err)
+	}
+	// A reserva expira e a assinatura volta para a fila sem a fatura vinculada.
+	if billed, err := svc.BillDue(context.Background(), 10); err != nil || billed != 1 {
+		t.Fatalf("expected retry to bill, got %d (%v)", billed, err)
+	}
+
+	if len(invoicer.invoices) != 1 {
+		t.Fatalf("expected a single invoice, got %d", len(invoicer.invoices))
+	}
+	if _, ok := invoicer.invoices[repo.subscription.PendingInvoiceID]; !ok || repo.subscription.BillingInvoiceID != "" {
+		t.Fatalf("expected pending invoice recorded and reservation cleared, got %+v", repo.subscription)
+	}
+}
+
+func TestBillDueCancelsSubscriptionOfDeletedCustomer(t *testing.T) {
+	repo, invoicer, svc := newBillingFixture(time.Now())
+	invoicer.err = domain.ErrCustomerNotFound
+
+	if billed, err := svc.BillDue(context.Background(), 10); err != nil || billed != 1 {
+		t.Fatalf("expected subscription handled, got %d (%v)", billed, err)
+	}
+	if repo.subscription.Status != domain.SubscriptionCanceled || repo.subscription.FailedAttempts != 0 {
+		t.Fatalf("expected canceled without dunning, got %s after %d attempts", repo.subscription.Status, repo.subscription.FailedAttempts)
+	}
+}
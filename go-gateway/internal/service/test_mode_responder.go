@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain/events"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/outbox"
+)
+
+// TestPendingTransactionEventType e o tipo de outbox usado por faturas de teste
+// que iriam ao antifraude; nunca e publicado no topico pending_transactions.
+const TestPendingTransactionEventType = "test_pending_transaction"
+
+// testModeRejectAbove e o valor (em unidades da moeda) acima do qual o
+// antifraude simulado rejeita a fatura de teste.
+const testModeRejectAbove int64 = 50000
+
+// TestModeResponder simula o antifraude para faturas de teste: e registrado como
+// publisher do outbox e aplica o resultado como se viesse de transactions_result.
+type TestModeResponder struct {
+	invoiceService *InvoiceService
+}
+
+func NewTestModeResponder(invoiceService *InvoiceService) *TestModeResponder {
+	return &TestModeResponder{invoiceService: invoiceService}
+}
+
+func (r *TestModeResponder) Publish(ctx context.Context, ev outbox.Event) error {
+	var pending events.PendingTransaction
+	if err := json.Unmarshal(ev.Payload, &pending); err != nil {
+		return err
+	}
+
+	status := domain.StatusApproved
+	if pending.AmountCents > testModeRejectAbove*domain.MinorFactor(pending.Currency) {
+		status = domain.StatusRejected
+	}
+
+	slog.Info("resultado simulado para fatura de teste", "invoice_id", pending.InvoiceID, "status", status)
+	return r.invoiceService.ProcessTransactionResult(pending.InvoiceID, status, ev.CorrelationID.String)
+}
+
+// TestPayoutResponder simula o processador de liquidacao para saques de teste: e registrado
+// como publisher do outbox e leva o saque a in_transit e depois a paid.
+type TestPayoutResponder struct {
+	payoutService *PayoutService
+}
+
+func NewTestPayoutResponder(payoutService *PayoutService) *TestPayoutResponder {
+	return &TestPayoutResponder{payoutService: payoutService}
+}
+
+func (r *TestPayoutResponder) Publish(ctx context.Context, ev outbox.Event) error {
+	var requested events.PayoutRequested
+	if err := json.Unmarshal(ev.Payload, &requested); err != nil {
+		return err
+	}
+
+	slog.Info("saque de teste pago", "payout_id", requested.PayoutID)
+	// Na reentrega de um saque ja pago, in_transit e recusado e paid e idempotente.
+	if _, err := r.payoutService.UpdateStatus(requested.PayoutID, domain.PayoutInTransit, ""); err != nil && err != domain.ErrInvalidPayoutTransition {
+		return err
+	}
+	_, err := r.payoutService.UpdateStatus(requested.PayoutID, domain.PayoutPaid, "")
+	return err
+}
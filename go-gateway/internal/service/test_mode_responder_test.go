@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/outbox"
+)
+
+// stubPayoutRepository aplica as transicoes do dominio e guarda a sequencia de status.
+type stubPayoutRepository struct {
+	domain.PayoutRepository
+	payout  *domain.Payout
+	history []domain.PayoutStatus
+}
+
+func (r *stubPayoutRepository) Transition(id string, status domain.PayoutStatus, failureReason string, at time.Time) (*domain.Payout, error) {
+	if r.payout.Status == status {
+		return r.payout, nil
+	}
+	if err := r.payout.Transition(status, failureReason, at); err != nil {
+		return nil, err
+	}
+	r.history = append(r.history, status)
+	return r.payout, nil
+}
+
+func TestPayoutResponderPaysThroughInTransit(t *testing.T) {
+	repo := &stubPayoutRepository{payout: &domain.Payout{ID: "po-1", Status: domain.PayoutPending}}
+	responder := NewTestPayoutResponder(NewPayoutService(repo, AccountService{}))
+	event := outbox.Event{Payload: []byte(`{"payout_id":"po-1"}`)}
+
+	// A reentrega do evento nao muda um saque ja pago.
+	for i := 0; i < 2; i++ {
+		if err := responder.Publish(context.Background(), event); err != nil {
+			t.Fatalf("publish failed: %v", err)
+		}
+	}
+
+	if len(repo.history) != 2 || repo.history[0] != domain.PayoutInTransit || repo.history[1] != domain.PayoutPaid {
+		t.Fatalf("expected pending -> in_transit -> paid, got %v", repo.history)
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/webhook"
+)
+
+const defaultWebhookDeliveriesLimit = 50
+
+// WebhookService gerencia endpoints de webhook das contas
+type WebhookService struct {
+	repository *webhook.Repository
+	targets    webhook.TargetPolicy
+}
+
+func NewWebhookService(repository *webhook.Repository, targets webhook.TargetPolicy) *WebhookService {
+	return &WebhookService{repository: repository, targets: targets}
+}
+
+// CreateEndpoint cadastra um endpoint e retorna o secret de assinatura uma unica vez.
+// Retorna webhook.ErrBlockedTarget se a URL apontar para um endereco interno.
+func (s *WebhookService) CreateEndpoint(ctx context.Context, accountID string, mode domain.Mode, input dto.CreateWebhookEndpointInput) (*dto.WebhookEndpointOutput, error) {
+	if err := s.targets.CheckURL(ctx, input.URL); err != nil {
+		return nil, err
+	}
+	endpoint, err := webhook.NewEndpoint(accountID, mode, input.URL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	return dto.FromWebhookEndpoint(endpoint), nil
+}
+
+// ListEndpoints lista os endpoints da conta no modo sem expor os secrets
+func (s *WebhookService) ListEndpoints(ctx context.Context, accountID string, mode domain.Mode) ([]*dto.WebhookEndpointOutput, error) {
+	endpoints, err := s.repository.ListEndpoints(ctx, accountID, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]*dto.WebhookEndpointOutput, len(endpoints))
+	for i, endpoint := range endpoints {
+		output[i] = dto.FromWebhookEndpoint(endpoint)
+		output[i].Secret = ""
+	}
+	return output, nil
+}
+
+// DeleteEndpoint desativa um endpoint da conta no modo
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, accountID string, mode domain.Mode, endpointID string) error {
+	return s.repository.DeactivateEndpoint(ctx, accountID, mode, endpointID)
+}
+
+// ListDeliveries lista as tentativas de entrega de um endpoint da conta no modo
+func (s *WebhookService) ListDeliveries(ctx context.Context, accountID string, mode domain.Mode, endpointID string) ([]*dto.WebhookDeliveryOutput, error) {
+	endpoint, err := s.repository.FindEndpoint(ctx, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.AccountID != accountID || endpoint.Mode != mode {
+		return nil, domain.ErrWebhookEndpointNotFound
+	}
+
+	deliveries, err := s.repository.ListDeliveries(ctx, endpoint.ID, defaultWebhookDeliveriesLimit)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromWebhookDeliveries(deliveries), nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/service"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/middleware"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/response"
 )
 
@@ -52,6 +53,9 @@ func (h *AccountHandler) Create(w http.ResponseWriter, r *http.Request) {
 		case domain.ErrEmailAlreadyExists:
 			response.Error(w, http.StatusConflict, "email_already_exists", "email already exists", nil)
 			return
+		case domain.ErrUnsupportedCurrency:
+			response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid account data", map[string]string{"currency": "unsupported currency"})
+			return
 		case domain.ErrDuplicatedAPIKey:
 			response.Error(w, http.StatusConflict, "api_key_conflict", "api key conflict", nil)
 			return
@@ -74,13 +78,13 @@ func (h *AccountHandler) Create(w http.ResponseWriter, r *http.Request) {
 // @Failure 500 {object} response.ErrorResponse
 // @Router /accounts [get]
 func (h *AccountHandler) Get(w http.ResponseWriter, r *http.Request) {
-	apiKey := r.Header.Get("X-API-KEY")
-	if apiKey == "" {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
 		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
 		return
 	}
 
-	output, err := h.accountService.FindByAPIKey(apiKey)
+	output, err := h.accountService.GetWithBalances(principal.AccountID, principal.Mode)
 	if err != nil {
 		switch err {
 		case domain.ErrAccountNotFound:
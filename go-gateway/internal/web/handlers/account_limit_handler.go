@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/service"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/middleware"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultLimitAuditPageSize = 50
+	maxLimitAuditPageSize     = 200
+)
+
+// AccountLimitHandler processa requisições HTTP de limites da conta (merchant e admin)
+type AccountLimitHandler struct {
+	limitService   *service.AccountLimitService
+	accountService *service.AccountService
+}
+
+// NewAccountLimitHandler cria um novo handler de limites
+func NewAccountLimitHandler(limitService *service.AccountLimitService, accountService *service.AccountService) *AccountLimitHandler {
+	return &AccountLimitHandler{limitService: limitService, accountService: accountService}
+}
+
+// Get retorna os limites da conta com o uso do dia.
+// @Summary Consultar limites da conta
+// @Description Retorna os limites do modo da chave, o uso do dia corrente (no fuso da conta) e o uso de cada regra adicional, para saber quanto falta antes de limit_exceeded.
+// @Tags accounts
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param currency query string false "Moeda ISO 4217 (padrao: moeda da conta)"
+// @Success 200 {object} dto.AccountLimitStatusOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /accounts/limits [get]
+func (h *AccountLimitHandler) Get(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	currency := r.URL.Query().Get("currency")
+	if currency == "" {
+		account, err := h.accountService.FindByID(principal.AccountID)
+		if err != nil {
+			writeMerchantLimitError(w, err)
+			return
+		}
+		currency = account.Currency
+	}
+	currency, err := domain.NormalizeCurrency(currency)
+	if err != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid limit parameters", map[string]string{"currency": "unsupported currency"})
+		return
+	}
+
+	status, err := h.limitService.Status(principal.AccountID, principal.Mode, currency, time.Now())
+	if err != nil {
+		writeMerchantLimitError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, dto.FromAccountLimitStatus(status))
+}
+
+// AdminList lista os limites de uma conta em todos os modos e moedas.
+// @Summary Listar limites da conta (admin)
+// @Tags admin
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Admin key"
+// @Param id path string true "Account ID"
+// @Success 200 {array} dto.AccountLimitOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/accounts/{id}/limits [get]
+func (h *AccountLimitHandler) AdminList(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := h.adminAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	limits, err := h.limitService.List(accountID)
+	if err != nil {
+		writeAccountLimitError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, dto.FromAccountLimits(limits))
+}
+
+// AdminSet substitui os limites de um modo e moeda da conta.
+// @Summary Definir limites da conta (admin)
+// @Description Substitui os limites base (0 = sem limite), as regras adicionais, o enforcement e o fuso, e registra o operador e o motivo na auditoria.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Admin key"
+// @Param id path string true "Account ID"
+// @Param request body dto.SetAccountLimitInput true "Limits payload"
+// @Success 200 {object} dto.AccountLimitOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/accounts/{id}/limits [put]
+func (h *AccountLimitHandler) AdminSet(w http.ResponseWriter, r *http.Request) {
+	admin, _ := middleware.AdminFromContext(r.Context())
+	accountID, ok := h.adminAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.SetAccountLimitInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	if validationErrors := validateSetAccountLimitInput(input); validationErrors != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid limit data", validationErrors)
+		return
+	}
+
+	currency, _ := domain.NormalizeCurrency(input.Currency)
+	enforcement := domain.LimitEnforcement(input.Enforcement)
+	if enforcement == "" {
+		enforcement = domain.LimitEnforcementHard
+	}
+	timezone := input.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	limit, err := h.limitService.Set(domain.AccountLimit{
+		AccountID:            accountID,
+		Mode:                 domain.Mode(input.Mode),
+		Currency:             currency,
+		MaxAmountPerTxCents:  *input.MaxAmountPerTxCents,
+		MaxDailyVolumeCents:  *input.MaxDailyVolumeCents,
+		MaxDailyTransactions: *input.MaxDailyTransactions,
+		Rules:                input.Rules,
+		Enforcement:          enforcement,
+		Timezone:             timezone,
+	}, admin, input.Reason)
+	if err != nil {
+		writeAccountLimitError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, dto.FromAccountLimit(limit))
+}
+
+// AdminReset volta os limites de um modo e moeda para os padroes configurados.
+// @Summary Restaurar limites padrao da conta (admin)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Admin key"
+// @Param id path string true "Account ID"
+// @Param request body dto.ResetAccountLimitInput true "Reset payload"
+// @Success 200 {object} dto.AccountLimitOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/accounts/{id}/limits/reset [post]
+func (h *AccountLimitHandler) AdminReset(w http.ResponseWriter, r *http.Request) {
+	admin, _ := middleware.AdminFromContext(r.Context())
+	accountID, ok := h.adminAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.ResetAccountLimitInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	if validationErrors := validateResetAccountLimitInput(input); validationErrors != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid limit data", validationErrors)
+		return
+	}
+
+	currency, _ := domain.NormalizeCurrency(input.Currency)
+	limit, err := h.limitService.Reset(accountID, domain.Mode(input.Mode), currency, admin, input.Reason)
+	if err != nil {
+		writeAccountLimitError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, dto.FromAccountLimit(limit))
+}
+
+// AdminListAudits lista a trilha de auditoria dos limites da conta.
+// @Summary Auditoria de limites da conta (admin)
+// @Tags admin
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Admin key"
+// @Param id path string true "Account ID"
+// @Param limit query int false "Quantidade de registros (1-200, padrao 50)"
+// @Success 200 {array} dto.AccountLimitAuditOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/accounts/{id}/limits/audit [get]
+func (h *AccountLimitHandler) AdminListAudits(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := h.adminAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultLimitAuditPageSize
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxLimitAuditPageSize {
+			response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid list parameters", map[string]string{
+				"limit": "limit must be between 1 and " + strconv.Itoa(maxLimitAuditPageSize),
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	audits, err := h.limitService.ListAudits(accountID, limit)
+	if err != nil {
+		writeAccountLimitError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, dto.FromAccountLimitAudits(audits))
+}
+
+// adminAccountID le o id da rota e confirma que a conta existe (404 account_not_found).
+func (h *AccountLimitHandler) adminAccountID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	accountID := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(accountID); err != nil {
+		response.Error(w, http.StatusNotFound, "account_not_found", "account not found", nil)
+		return "", false
+	}
+
+	if _, err := h.accountService.FindByID(accountID); err != nil {
+		writeAccountLimitError(w, err)
+		return "", false
+	}
+	return accountID, true
+}
+
+// writeMerchantLimitError traduz erros da rota do merchant; conta ausente indica chave invalida.
+func writeMerchantLimitError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrAccountNotFound:
+		response.Error(w, http.StatusUnauthorized, "invalid_api_key", "invalid api key", nil)
+	default:
+		response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
+	}
+}
+
+func writeAccountLimitError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrAccountNotFound:
+		response.Error(w, http.StatusNotFound, "account_not_found", "account not found", nil)
+	default:
+		response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
+	}
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/service"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/middleware"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// APIKeyHandler processa requisições HTTP de gestão de API keys
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+}
+
+// NewAPIKeyHandler cria um novo handler de API keys
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// Create emite uma nova API key para a conta.
+// @Summary Criar API key
+// @Description Emite uma chave no mesmo modo (live/test) da chave usada, apenas com escopos que ela ja tem. O segredo so e retornado nesta resposta.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param request body dto.CreateAPIKeyInput true "API key payload"
+// @Success 201 {object} dto.APIKeyOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api-keys [post]
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	var input dto.CreateAPIKeyInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	if validationErrors := validateCreateAPIKeyInput(input, time.Now()); validationErrors != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid api key data", validationErrors)
+		return
+	}
+
+	output, err := h.apiKeyService.Create(principal, input)
+	if err == domain.ErrInsufficientScope {
+		response.Error(w, http.StatusForbidden, "insufficient_scope", "api key cannot grant scopes it does not hold", map[string]string{"scope": principal.MissingScope(input.Scopes)})
+		return
+	}
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, output)
+}
+
+// List lista as API keys da conta no modo da chave usada.
+// @Summary Listar API keys
+// @Tags api-keys
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Success 200 {array} dto.APIKeyOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api-keys [get]
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	output, err := h.apiKeyService.List(principal.AccountID, principal.Mode)
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// Revoke revoga uma API key da conta.
+// @Summary Revogar API key
+// @Description A chave deixa de autenticar imediatamente. Para rotacionar, crie uma nova chave e revogue a antiga.
+// @Tags api-keys
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param id path string true "API key ID"
+// @Success 200 {object} dto.APIKeyOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		response.Error(w, http.StatusNotFound, "api_key_not_found", "api key not found", nil)
+		return
+	}
+
+	output, err := h.apiKeyService.Revoke(principal.AccountID, principal.Mode, id)
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// AdminGrantScopes concede escopos a uma API key da conta.
+// @Summary Conceder escopos a uma API key (admin)
+// @Description Acrescenta os escopos a chave, mantendo os atuais. Usado para liberar payouts:write as chaves emitidas antes dos saques, depois de confirmar o merchant.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Admin key"
+// @Param id path string true "Account ID"
+// @Param key_id path string true "API key ID"
+// @Param request body dto.GrantAPIKeyScopesInput true "Scopes payload"
+// @Success 200 {object} dto.APIKeyOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/accounts/{id}/api-keys/{key_id}/scopes [post]
+func (h *APIKeyHandler) AdminGrantScopes(w http.ResponseWriter, r *http.Request) {
+	accountID, id := chi.URLParam(r, "id"), chi.URLParam(r, "key_id")
+	if _, err := uuid.Parse(accountID); err != nil {
+		writeAPIKeyError(w, domain.ErrAPIKeyNotFound)
+		return
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		writeAPIKeyError(w, domain.ErrAPIKeyNotFound)
+		return
+	}
+
+	var input dto.GrantAPIKeyScopesInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	output, err := h.apiKeyService.GrantScopes(accountID, id, input.Scopes)
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+func writeAPIKeyError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrAPIKeyNotFound:
+		response.Error(w, http.StatusNotFound, "api_key_not_found", "api key not found", nil)
+	case domain.ErrInvalidScope:
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid api key data", map[string]string{"scopes": "invalid scope"})
+	default:
+		response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
+	}
+}
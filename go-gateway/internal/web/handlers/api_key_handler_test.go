@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/service"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/middleware"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/response"
+	"github.com/go-chi/chi/v5"
+)
+
+type stubAPIKeyRepository struct {
+	saved []*domain.APIKey
+}
+
+func (r *stubAPIKeyRepository) Save(key *domain.APIKey) error {
+	r.saved = append(r.saved, key)
+	return nil
+}
+
+func (r *stubAPIKeyRepository) FindByKey(secret string) (*domain.APIKey, error) {
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+func (r *stubAPIKeyRepository) RehashIfStale(key *domain.APIKey, secret string) error { return nil }
+
+func (r *stubAPIKeyRepository) ListByAccountID(accountID string, mode domain.Mode) ([]*domain.APIKey, error) {
+	return nil, nil
+}
+
+func (r *stubAPIKeyRepository) Revoke(accountID string, mode domain.Mode, id string, at time.Time) (*domain.APIKey, error) {
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+func (r *stubAPIKeyRepository) GrantScopes(accountID, id string, scopes []string) (*domain.APIKey, error) {
+	for _, key := range r.saved {
+		if key.ID == id && key.AccountID == accountID {
+			key.Scopes = append(key.Scopes, scopes...)
+			return key, nil
+		}
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+func (r *stubAPIKeyRepository) TouchLastUsed(id string, at time.Time) error { return nil }
+
+func createAPIKey(t *testing.T, repo *stubAPIKeyRepository, scopes []string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	t.Setenv("API_KEY_SECRET", "test-secret")
+
+	handler := NewAPIKeyHandler(service.NewAPIKeyService(repo, 0))
+	principal := &domain.Principal{AccountID: "acc-1", KeyID: "key-1", Scopes: scopes, Mode: domain.ModeLive}
+	req := httptest.NewRequest(http.MethodPost, "/api-keys", strings.NewReader(body))
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+	rec := httptest.NewRecorder()
+	handler.Create(rec, req)
+	return rec
+}
+
+func TestAPIKeyCreateRejectsScopesTheCallerDoesNotHold(t *testing.T) {
+	repo := &stubAPIKeyRepository{}
+	rec := createAPIKey(t, repo, []string{domain.ScopeAPIKeysWrite, domain.ScopeInvoicesRead},
+		`{"label":"backend","scopes":["invoices:read","payouts:write"]}`)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body response.ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Code != "insufficient_scope" || body.Details["scope"] != domain.ScopePayoutsWrite {
+		t.Fatalf("unexpected response: %+v", body)
+	}
+	if len(repo.saved) != 0 {
+		t.Fatalf("expected no key to be saved, got %d", len(repo.saved))
+	}
+}
+
+func TestAPIKeyCreateRejectsEmptyScopes(t *testing.T) {
+	repo := &stubAPIKeyRepository{}
+	rec := createAPIKey(t, repo, []string{domain.ScopeAPIKeysWrite}, `{"label":"backend","scopes":[]}`)
+
+	if rec.Code != http.StatusUnprocessableEntity || len(repo.saved) != 0 {
+		t.Fatalf("expected 422 without saving, got %d (%d saved)", rec.Code, len(repo.saved))
+	}
+}
+
+func TestAPIKeyCreateGrantsSubsetOfCallerScopes(t *testing.T) {
+	repo := &stubAPIKeyRepository{}
+	rec := createAPIKey(t, repo, []string{domain.ScopeAPIKeysWrite, domain.ScopeInvoicesRead},
+		`{"label":"reader","scopes":["invoices:read"]}`)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(repo.saved) != 1 || repo.saved[0].Mode != domain.ModeLive || repo.saved[0].AccountID != "acc-1" {
+		t.Fatalf("unexpected saved keys: %+v", repo.saved)
+	}
+}
+
+func TestAPIKeyAdminGrantScopes(t *testing.T) {
+	accountID := "9b2f4c1d-3e5a-4b7c-8d9e-0f1a2b3c4d5e"
+	tests := []struct {
+		name   string
+		keyID  func(key *domain.APIKey) string
+		body   string
+		status int
+		code   string
+	}{
+		{"grants payouts", func(key *domain.APIKey) string { return key.ID }, `{"scopes":["payouts:write"]}`, http.StatusOK, ""},
+		{"unknown scope", func(key *domain.APIKey) string { return key.ID }, `{"scopes":["payouts:admin"]}`, http.StatusUnprocessableEntity, "validation_error"},
+		{"empty scopes", func(key *domain.APIKey) string { return key.ID }, `{"scopes":[]}`, http.StatusUnprocessableEntity, "validation_error"},
+		{"unknown key", func(*domain.APIKey) string { return "1c2d3e4f-5a6b-4c7d-8e9f-0a1b2c3d4e5f" }, `{"scopes":["payouts:write"]}`, http.StatusNotFound, "api_key_not_found"},
+		{"invalid key id", func(*domain.APIKey) string { return "key-1" }, `{"scopes":["payouts:write"]}`, http.StatusNotFound, "api_key_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := domain.NewAPIKey(accountID, domain.ModeLive, "legacy", []string{domain.ScopeInvoicesRead}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			repo := &stubAPIKeyRepository{saved: []*domain.APIKey{key}}
+			handler := NewAPIKeyHandler(service.NewAPIKeyService(repo, 0))
+
+			keyID := tt.keyID(key)
+			req := httptest.NewRequest(http.MethodPost, "/admin/accounts/"+accountID+"/api-keys/"+keyID+"/scopes", strings.NewReader(tt.body))
+			routeContext := chi.NewRouteContext()
+			routeContext.URLParams.Add("id", accountID)
+			routeContext.URLParams.Add("key_id", keyID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeContext))
+			rec := httptest.NewRecorder()
+			handler.AdminGrantScopes(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if tt.code != "" {
+				var body response.ErrorResponse
+				if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body.Code != tt.code {
+					t.Fatalf("expected code %s, got %+v (err %v)", tt.code, body, err)
+				}
+				if len(key.Scopes) != 1 {
+					t.Fatalf("expected scopes untouched, got %v", key.Scopes)
+				}
+				return
+			}
+			if !key.HasScope(domain.ScopePayoutsWrite) || !key.HasScope(domain.ScopeInvoicesRead) {
+				t.Fatalf("expected payouts:write added to the existing scopes, got %v", key.Scopes)
+			}
+		})
+	}
+}
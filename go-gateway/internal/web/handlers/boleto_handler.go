@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/service"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/telemetry"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/middleware"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/response"
+)
+
+// BoletoHandler processa requisições HTTP de liquidação de boletos (admin)
+type BoletoHandler struct {
+	invoiceService *service.InvoiceService
+}
+
+// NewBoletoHandler cria um novo handler de boletos
+func NewBoletoHandler(invoiceService *service.InvoiceService) *BoletoHandler {
+	return &BoletoHandler{invoiceService: invoiceService}
+}
+
+// AdminSettle importa um lote de pagamentos de boleto (retorno bancario).
+// @Summary Liquidar boletos (admin)
+// @Description Cada pagamento aprova a fatura pendente do boleto e credita o saldo. Pagamentos repetidos retornam already_paid; pagamentos de faturas canceladas ou expiradas sao registrados como not_payable para conciliacao.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Admin key"
+// @Param request body dto.SettleBoletosInput true "Settlement payload"
+// @Success 200 {object} dto.BoletoSettlementOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Router /admin/boletos/settlements [post]
+func (h *BoletoHandler) AdminSettle(w http.ResponseWriter, r *http.Request) {
+	admin, _ := middleware.AdminFromContext(r.Context())
+
+	var input dto.SettleBoletosInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	if validationErrors := validateSettleBoletosInput(input); validationErrors != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid settlement data", validationErrors)
+		return
+	}
+
+	if input.Source == "" {
+		input.Source = "admin:" + admin
+	}
+
+	output := h.invoiceService.SettleBoletos(input, telemetry.RequestIDFromContext(r.Context()))
+	response.JSON(w, http.StatusOK, output)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/service"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/middleware"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/response"
+)
+
+// CardTokenHandler processa requisições HTTP do cofre de cartões
+type CardTokenHandler struct {
+	cardTokenService *service.CardTokenService
+}
+
+// NewCardTokenHandler cria um novo handler de tokens de cartão
+func NewCardTokenHandler(cardTokenService *service.CardTokenService) *CardTokenHandler {
+	return &CardTokenHandler{cardTokenService: cardTokenService}
+}
+
+// Create tokeniza um cartão.
+// @Summary Tokenizar cartao
+// @Description Valida o cartao (Luhn, validade e bandeira), guarda o numero cifrado e retorna um token para usar em card_token no POST /invoice. O CVV nao e guardado.
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param request body dto.CreateCardTokenInput true "Card payload"
+// @Success 201 {object} dto.CardTokenOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 503 {object} response.ErrorResponse
+// @Router /tokens [post]
+func (h *CardTokenHandler) Create(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	var input dto.CreateCardTokenInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	if validationErrors := validateCreateCardTokenInput(input); validationErrors != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid card data", validationErrors)
+		return
+	}
+
+	output, err := h.cardTokenService.Create(principal.AccountID, principal.Mode, input)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidCardNumber:
+			response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid card data", map[string]string{"card_number": "invalid card_number"})
+		case domain.ErrUnsupportedCardBrand:
+			response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid card data", map[string]string{"card_number": "unsupported card brand"})
+		case domain.ErrCardExpired:
+			response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid card data", map[string]string{"expiry_year": "card expired"})
+		case domain.ErrCardVaultDisabled:
+			response.Error(w, http.StatusServiceUnavailable, "card_vault_disabled", "card vault is not enabled", nil)
+		default:
+			response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, output)
+}
@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/service"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/middleware"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// CustomerHandler processa requisições HTTP de clientes e meios de pagamento salvos
+type CustomerHandler struct {
+	customerService *service.CustomerService
+}
+
+// NewCustomerHandler cria um novo handler de clientes
+func NewCustomerHandler(customerService *service.CustomerService) *CustomerHandler {
+	return &CustomerHandler{customerService: customerService}
+}
+
+// Create cria um cliente.
+// @Summary Criar cliente
+// @Description Cria um cliente no modo da API key. Document aceita CPF ou CNPJ, com ou sem pontuacao; external_reference e unico por conta e modo.
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param request body dto.CreateCustomerInput true "Customer payload"
+// @Success 201 {object} dto.CustomerOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /customers [post]
+func (h *CustomerHandler) Create(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	var input dto.CreateCustomerInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	if validationErrors := validateCreateCustomerInput(input); validationErrors != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid customer data", validationErrors)
+		return
+	}
+
+	output, err := h.customerService.Create(principal.AccountID, principal.Mode, input)
+	if err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, output)
+}
+
+// List lista os clientes da conta no modo da API key.
+// @Summary Listar clientes
+// @Tags customers
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "Cursor from next_cursor"
+// @Param email query string false "Exact email"
+// @Param external_reference query string false "Exact external reference"
+// @Success 200 {object} dto.CustomerListOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /customers [get]
+func (h *CustomerHandler) List(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	filter, validationErrors := parseListCustomersQuery(r.URL.Query())
+	if validationErrors != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid query parameters", validationErrors)
+		return
+	}
+	filter.Mode = principal.Mode
+
+	output, err := h.customerService.List(principal.AccountID, filter)
+	if err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// Get retorna um cliente pelo ID.
+// @Summary Buscar cliente por ID
+// @Tags customers
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param id path string true "Customer ID"
+// @Success 200 {object} dto.CustomerOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /customers/{id} [get]
+func (h *CustomerHandler) Get(w http.ResponseWriter, r *http.Request) {
+	principal, id, ok := customerRequest(w, r)
+	if !ok {
+		return
+	}
+
+	output, err := h.customerService.Get(principal.AccountID, principal.Mode, id)
+	if err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// Update altera os campos informados de um cliente.
+// @Summary Atualizar cliente
+// @Description Campos ausentes nao sao alterados; string vazia limpa email, document e external_reference.
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param id path string true "Customer ID"
+// @Param request body dto.UpdateCustomerInput true "Customer changes"
+// @Success 200 {object} dto.CustomerOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /customers/{id} [patch]
+func (h *CustomerHandler) Update(w http.ResponseWriter, r *http.Request) {
+	principal, id, ok := customerRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.UpdateCustomerInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	if validationErrors := validateUpdateCustomerInput(input); validationErrors != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid customer data", validationErrors)
+		return
+	}
+
+	output, err := h.customerService.Update(principal.AccountID, principal.Mode, id, input)
+	if err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// Delete remove um cliente.
+// @Summary Remover cliente
+// @Description Remove o cliente da listagem e desvincula os cartoes salvos. As faturas mantem o customer_id.
+// @Tags customers
+// @Param X-API-KEY header string true "API key"
+// @Param id path string true "Customer ID"
+// @Success 204
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /customers/{id} [delete]
+func (h *CustomerHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	principal, id, ok := customerRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.customerService.Delete(principal.AccountID, principal.Mode, id); err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AttachPaymentMethod salva um token do cofre no cliente.
+// @Summary Salvar meio de pagamento
+// @Description Salva no cliente um token de POST /tokens. Faturas com esse card_token passam a ser do cliente.
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param id path string true "Customer ID"
+// @Param request body dto.AttachPaymentMethodInput true "Card token"
+// @Success 201 {object} dto.CardTokenOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /customers/{id}/payment-methods [post]
+func (h *CustomerHandler) AttachPaymentMethod(w http.ResponseWriter, r *http.Request) {
+	principal, id, ok := customerRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.AttachPaymentMethodInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	if !domain.IsCardToken(input.CardToken) {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid payment method", map[string]string{"card_token": "invalid card_token"})
+		return
+	}
+
+	output, err := h.customerService.AttachPaymentMethod(principal.AccountID, principal.Mode, id, input.CardToken)
+	if err != nil {
+		switch err {
+		case domain.ErrCardTokenNotFound, domain.ErrCardExpired:
+			response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid payment method", map[string]string{"card_token": err.Error()})
+		default:
+			writeCustomerError(w, err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, output)
+}
+
+// ListPaymentMethods lista os tokens salvos no cliente.
+// @Summary Listar meios de pagamento do cliente
+// @Tags customers
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param id path string true "Customer ID"
+// @Success 200 {array} dto.CardTokenOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /customers/{id}/payment-methods [get]
+func (h *CustomerHandler) ListPaymentMethods(w http.ResponseWriter, r *http.Request) {
+	principal, id, ok := customerRequest(w, r)
+	if !ok {
+		return
+	}
+
+	output, err := h.customerService.ListPaymentMethods(principal.AccountID, principal.Mode, id)
+	if err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// DetachPaymentMethod remove um token dos meios de pagamento do cliente.
+// @Summary Remover meio de pagamento
+// @Description O token deixa de ser do cliente mas continua valido em card_token.
+// @Tags customers
+// @Param X-API-KEY header string true "API key"
+// @Param id path string true "Customer ID"
+// @Param token path string true "Card token"
+// @Success 204
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /customers/{id}/payment-methods/{token} [delete]
+func (h *CustomerHandler) DetachPaymentMethod(w http.ResponseWriter, r *http.Request) {
+	principal, id, ok := customerRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.customerService.DetachPaymentMethod(principal.AccountID, principal.Mode, id, chi.URLParam(r, "token")); err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// customerRequest le o principal e o ID do cliente da rota, respondendo 401 ou 404 quando invalidos.
+func customerRequest(w http.ResponseWriter, r *http.Request) (*domain.Principal, string, bool) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return nil, "", false
+	}
+
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		response.Error(w, http.StatusNotFound, "customer_not_found", "customer not found", nil)
+		return nil, "", false
+	}
+	return principal, id, true
+}
+
+func writeCustomerError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrCustomerNotFound:
+		response.Error(w, http.StatusNotFound, "customer_not_found", "customer not found", nil)
+	case domain.ErrCardTokenNotFound:
+		response.Error(w, http.StatusNotFound, "payment_method_not_found", "payment method not found", nil)
+	case domain.ErrCustomerReferenceConflict:
+		response.Error(w, http.StatusConflict, "customer_reference_conflict", "external_reference already in use", nil)
+	case domain.ErrCardTokenAttached:
+		response.Error(w, http.StatusConflict, "card_token_attached", "card token is saved on another customer", nil)
+	case domain.ErrInvalidDocument:
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid customer data", map[string]string{"document": "document must be a valid CPF or CNPJ"})
+	case domain.ErrInvalidCustomerName:
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid customer data", map[string]string{"name": "name is required"})
+	default:
+		response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
+	}
+}
@@ -15,8 +15,10 @@ import (
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/repository"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/service"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/telemetry"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/middleware"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/response"
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 type InvoiceHandler struct {
@@ -45,6 +47,7 @@ func NewInvoiceHandler(service *service.InvoiceService, idempotencyStore *reposi
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 409 {object} response.ErrorResponse
 // @Failure 422 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /invoice [post]
 func (h *InvoiceHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -69,13 +72,14 @@ func (h *InvoiceHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	apiKey := r.Header.Get("X-API-KEY")
-	if apiKey == "" {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
 		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
 		return
 	}
 
-	input.APIKey = apiKey
+	input.AccountID = principal.AccountID
+	input.Mode = principal.Mode
 	input.Metadata = map[string]string{
 		"request_id": telemetry.RequestIDFromContext(r.Context()),
 	}
@@ -87,48 +91,7 @@ func (h *InvoiceHandler) Create(w http.ResponseWriter, r *http.Request) {
 				bodyBytes = reencoded
 			}
 		}
-		endpoint := r.Method + ":" + r.URL.Path
-		requestHash := hashIdempotency(bodyBytes, apiKey)
-
-		_ = h.idempotencyStore.DeleteExpired(r.Context())
-		existing, err := h.idempotencyStore.Get(r.Context(), idempotencyKey, endpoint)
-		if err != nil {
-			response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
-			return
-		}
-		if existing != nil {
-			if time.Now().After(existing.ExpiresAt) {
-				_ = h.idempotencyStore.Delete(r.Context(), idempotencyKey, endpoint)
-			} else {
-				if existing.RequestHash != requestHash {
-					response.Error(w, http.StatusConflict, "idempotency_conflict", "idempotency key payload mismatch", nil)
-					return
-				}
-				if existing.Status != "completed" {
-					response.Error(w, http.StatusConflict, "idempotency_in_progress", "request with this idempotency key is still processing", nil)
-					return
-				}
-				writeCachedResponse(w, existing.StatusCode, existing.ResponseBody)
-				return
-			}
-		}
-
-		created, err := h.idempotencyStore.CreateProcessing(r.Context(), idempotencyKey, endpoint, requestHash, time.Now().Add(24*time.Hour))
-		if err != nil {
-			response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
-			return
-		}
-		if !created {
-			existing, err := h.idempotencyStore.Get(r.Context(), idempotencyKey, endpoint)
-			if err != nil {
-				response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
-				return
-			}
-			if existing != nil && existing.Status == "completed" && existing.RequestHash == requestHash {
-				writeCachedResponse(w, existing.StatusCode, existing.ResponseBody)
-				return
-			}
-			response.Error(w, http.StatusConflict, "idempotency_in_progress", "request with this idempotency key is still processing", nil)
+		if !beginIdempotentRequest(w, r, h.idempotencyStore, idempotencyKey, bodyBytes, principal) {
 			return
 		}
 	}
@@ -157,15 +120,61 @@ func (h *InvoiceHandler) Create(w http.ResponseWriter, r *http.Request) {
 				Message: err.Error(),
 			})
 			return
+		case domain.ErrUnsupportedCurrency:
+			writeWithIdempotency(w, h.idempotencyStore, r, idempotencyKey, http.StatusUnprocessableEntity, response.ErrorResponse{
+				Code:    "validation_error",
+				Message: "invalid invoice data",
+				Details: map[string]string{"currency": "unsupported currency"},
+			})
+			return
+		case domain.ErrCardTokenNotFound, domain.ErrCardExpired:
+			writeWithIdempotency(w, h.idempotencyStore, r, idempotencyKey, http.StatusUnprocessableEntity, response.ErrorResponse{
+				Code:    "validation_error",
+				Message: "invalid invoice data",
+				Details: map[string]string{"card_token": err.Error()},
+			})
+			return
+		case domain.ErrCardTokenAttached:
+			writeWithIdempotency(w, h.idempotencyStore, r, idempotencyKey, http.StatusUnprocessableEntity, response.ErrorResponse{
+				Code:    "validation_error",
+				Message: "invalid invoice data",
+				Details: map[string]string{"card_token": "card_token is saved on another customer"},
+			})
+			return
+		case domain.ErrCustomerNotFound:
+			writeWithIdempotency(w, h.idempotencyStore, r, idempotencyKey, http.StatusUnprocessableEntity, response.ErrorResponse{
+				Code:    "validation_error",
+				Message: "invalid invoice data",
+				Details: map[string]string{"customer_id": "customer not found"},
+			})
+			return
+		case domain.ErrCardVaultDisabled:
+			writeWithIdempotency(w, h.idempotencyStore, r, idempotencyKey, http.StatusServiceUnavailable, response.ErrorResponse{
+				Code:    "card_vault_disabled",
+				Message: "card vault is not enabled",
+			})
+			return
+		case domain.ErrPixDisabled:
+			writeWithIdempotency(w, h.idempotencyStore, r, idempotencyKey, http.StatusUnprocessableEntity, response.ErrorResponse{
+				Code:    "validation_error",
+				Message: "invalid invoice data",
+				Details: map[string]string{"payment_type": "pix is not enabled"},
+			})
+			return
+		case domain.ErrLimitBusy:
+			w.Header().Set("Retry-After", "1")
+			writeWithIdempotency(w, h.idempotencyStore, r, idempotencyKey, http.StatusTooManyRequests, response.ErrorResponse{
+				Code:    "limit_busy",
+				Message: "account limits are busy, retry shortly",
+			})
+			return
 		default:
 			var limitErr domain.LimitExceededError
 			if errors.As(err, &limitErr) {
 				writeWithIdempotency(w, h.idempotencyStore, r, idempotencyKey, http.StatusUnprocessableEntity, response.ErrorResponse{
 					Code:    "limit_exceeded",
 					Message: "account limit exceeded",
-					Details: map[string]string{
-						"reason": limitErr.Reason,
-					},
+					Details: limitErrorDetails(limitErr),
 				})
 				return
 			}
@@ -200,13 +209,13 @@ func (h *InvoiceHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	apiKey := r.Header.Get("X-API-KEY")
-	if apiKey == "" {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
 		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
 		return
 	}
 
-	output, err := h.service.GetByID(id, apiKey)
+	output, err := h.service.GetByID(id, principal.AccountID, principal.Mode)
 	if err != nil {
 		switch err {
 		case domain.ErrInvoiceNotFound:
@@ -247,13 +256,13 @@ func (h *InvoiceHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	apiKey := r.Header.Get("X-API-KEY")
-	if apiKey == "" {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
 		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
 		return
 	}
 
-	events, err := h.service.ListEventsByInvoiceID(id, apiKey)
+	events, err := h.service.ListEventsByInvoiceID(id, principal.AccountID, principal.Mode)
 	if err != nil {
 		switch err {
 		case domain.ErrInvoiceNotFound:
@@ -274,6 +283,408 @@ func (h *InvoiceHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
 	response.JSON(w, http.StatusOK, events)
 }
 
+// Refund estorna total ou parcialmente uma fatura aprovada.
+// @Summary Estornar fatura
+// @Description Estorna o valor informado ou, sem amount, todo o valor restante da fatura aprovada.
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param Idempotency-Key header string false "Idempotency key"
+// @Param id path string true "Invoice ID"
+// @Param request body RefundInvoiceRequest false "Refund payload"
+// @Success 200 {object} dto.InvoiceOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /invoice/{id}/refund [post]
+func (h *InvoiceHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		response.Error(w, http.StatusBadRequest, "invoice_id_required", "invoice id is required", nil)
+		return
+	}
+
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	var input dto.RefundInvoiceInput
+	if len(bytes.TrimSpace(bodyBytes)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(bodyBytes))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&input); err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+			return
+		}
+	}
+
+	input.AccountID = principal.AccountID
+	input.Mode = principal.Mode
+	input.InvoiceID = id
+	input.Metadata = map[string]string{
+		"request_id": telemetry.RequestIDFromContext(r.Context()),
+	}
+
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if idempotencyKey != "" && !beginIdempotentRequest(w, r, h.idempotencyStore, idempotencyKey, bodyBytes, principal) {
+		return
+	}
+
+	if input.Amount != nil && *input.Amount <= 0 {
+		writeWithIdempotency(w, h.idempotencyStore, r, idempotencyKey, http.StatusUnprocessableEntity, response.ErrorResponse{
+			Code:    "validation_error",
+			Message: "invalid refund data",
+			Details: map[string]string{"amount": "amount must be greater than zero"},
+		})
+		return
+	}
+
+	output, err := h.service.Refund(input)
+	if err != nil {
+		status, body := refundErrorResponse(err)
+		writeWithIdempotency(w, h.idempotencyStore, r, idempotencyKey, status, body)
+		return
+	}
+
+	writeWithIdempotency(w, h.idempotencyStore, r, idempotencyKey, http.StatusOK, output)
+}
+
+// Cancel cancela uma fatura pendente.
+// @Summary Cancelar fatura
+// @Description Cancela uma fatura ainda pendente de analise antifraude.
+// @Tags invoices
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} dto.InvoiceOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /invoice/{id}/cancel [post]
+func (h *InvoiceHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		response.Error(w, http.StatusBadRequest, "invoice_id_required", "invoice id is required", nil)
+		return
+	}
+
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	output, err := h.service.Cancel(id, principal.AccountID, principal.Mode, telemetry.RequestIDFromContext(r.Context()))
+	if err != nil {
+		switch err {
+		case domain.ErrInvoiceNotFound:
+			response.Error(w, http.StatusNotFound, "invoice_not_found", "invoice not found", nil)
+			return
+		case domain.ErrAccountNotFound:
+			response.Error(w, http.StatusUnauthorized, "invalid_api_key", "invalid api key", nil)
+			return
+		case domain.ErrUnauthorizedAccess:
+			response.Error(w, http.StatusForbidden, "forbidden", "forbidden", nil)
+			return
+		case domain.ErrInvoiceNotCancellable:
+			response.Error(w, http.StatusConflict, "invoice_not_cancellable", "invoice not cancellable", nil)
+			return
+		default:
+			response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
+			return
+		}
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// Capture captura total ou parcialmente uma fatura autorizada.
+// @Summary Capturar fatura
+// @Description Captura o valor informado ou, sem amount, todo o valor autorizado. O restante da autorizacao e liberado.
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param Idempotency-Key header string false "Idempotency key"
+// @Param id path string true "Invoice ID"
+// @Param request body CaptureInvoiceRequest false "Capture payload"
+// @Success 200 {object} dto.InvoiceOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /invoice/{id}/capture [post]
+func (h *InvoiceHandler) Capture(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		response.Error(w, http.StatusBadRequest, "invoice_id_required", "invoice id is required", nil)
+		return
+	}
+
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	var input dto.CaptureInvoiceInput
+	if len(bytes.TrimSpace(bodyBytes)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(bodyBytes))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&input); err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+			return
+		}
+	}
+
+	input.AccountID = principal.AccountID
+	input.Mode = principal.Mode
+	input.InvoiceID = id
+	input.Metadata = map[string]string{
+		"request_id": telemetry.RequestIDFromContext(r.Context()),
+	}
+
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if idempotencyKey != "" && !beginIdempotentRequest(w, r, h.idempotencyStore, idempotencyKey, bodyBytes, principal) {
+		return
+	}
+
+	if input.Amount != nil && *input.Amount <= 0 {
+		writeWithIdempotency(w, h.idempotencyStore, r, idempotencyKey, http.StatusUnprocessableEntity, response.ErrorResponse{
+			Code:    "validation_error",
+			Message: "invalid capture data",
+			Details: map[string]string{"amount": "amount must be greater than zero"},
+		})
+		return
+	}
+
+	output, err := h.service.Capture(input)
+	if err != nil {
+		status, body := captureErrorResponse(err)
+		writeWithIdempotency(w, h.idempotencyStore, r, idempotencyKey, status, body)
+		return
+	}
+
+	writeWithIdempotency(w, h.idempotencyStore, r, idempotencyKey, http.StatusOK, output)
+}
+
+// Void libera uma autorizacao sem captura.
+// @Summary Liberar autorizacao
+// @Description Libera uma fatura authorized sem capturar nenhum valor.
+// @Tags invoices
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} dto.InvoiceOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /invoice/{id}/void [post]
+func (h *InvoiceHandler) Void(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		response.Error(w, http.StatusBadRequest, "invoice_id_required", "invoice id is required", nil)
+		return
+	}
+
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	output, err := h.service.Void(id, principal.AccountID, principal.Mode, telemetry.RequestIDFromContext(r.Context()))
+	if err != nil {
+		switch err {
+		case domain.ErrInvoiceNotFound:
+			response.Error(w, http.StatusNotFound, "invoice_not_found", "invoice not found", nil)
+			return
+		case domain.ErrAccountNotFound:
+			response.Error(w, http.StatusUnauthorized, "invalid_api_key", "invalid api key", nil)
+			return
+		case domain.ErrUnauthorizedAccess:
+			response.Error(w, http.StatusForbidden, "forbidden", "forbidden", nil)
+			return
+		case domain.ErrInvoiceNotVoidable:
+			response.Error(w, http.StatusConflict, "invoice_not_voidable", "invoice not voidable", nil)
+			return
+		default:
+			response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
+			return
+		}
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// AdminReview aplica a decisao do operador a uma fatura retida por limite soft.
+// @Summary Decidir revisao manual da fatura (admin)
+// @Description approve credita a fatura pelo caminho normal de aprovacao; reject a rejeita. O operador e o motivo vao para o evento limit_review_resolved.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Admin key"
+// @Param id path string true "Invoice ID"
+// @Param request body dto.ReviewInvoiceInput true "Review decision"
+// @Success 200 {object} dto.InvoiceOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/invoices/{id}/review [post]
+func (h *InvoiceHandler) AdminReview(w http.ResponseWriter, r *http.Request) {
+	admin, _ := middleware.AdminFromContext(r.Context())
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		response.Error(w, http.StatusNotFound, "invoice_not_found", "invoice not found", nil)
+		return
+	}
+
+	var input dto.ReviewInvoiceInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+	if validationErrors := validateReviewInvoiceInput(input); validationErrors != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid review decision", validationErrors)
+		return
+	}
+
+	output, err := h.service.ResolveReview(id, input.Decision == "approve", admin, input.Reason, telemetry.RequestIDFromContext(r.Context()))
+	if err != nil {
+		switch err {
+		case domain.ErrInvoiceNotFound:
+			response.Error(w, http.StatusNotFound, "invoice_not_found", "invoice not found", nil)
+		case domain.ErrInvoiceNotInReview:
+			response.Error(w, http.StatusConflict, "invoice_not_in_review", "invoice is not awaiting manual review", nil)
+		default:
+			response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+func captureErrorResponse(err error) (int, response.ErrorResponse) {
+	switch err {
+	case domain.ErrInvoiceNotCapturable:
+		return http.StatusConflict, response.ErrorResponse{Code: "invoice_not_capturable", Message: err.Error()}
+	case domain.ErrCaptureExceedsAmount:
+		return http.StatusUnprocessableEntity, response.ErrorResponse{Code: "capture_amount_exceeded", Message: err.Error()}
+	default:
+		return refundErrorResponse(err)
+	}
+}
+
+func refundErrorResponse(err error) (int, response.ErrorResponse) {
+	switch err {
+	case domain.ErrInvoiceNotFound:
+		return http.StatusNotFound, response.ErrorResponse{Code: "invoice_not_found", Message: "invoice not found"}
+	case domain.ErrAccountNotFound:
+		return http.StatusUnauthorized, response.ErrorResponse{Code: "invalid_api_key", Message: "invalid api key"}
+	case domain.ErrUnauthorizedAccess:
+		return http.StatusForbidden, response.ErrorResponse{Code: "forbidden", Message: "forbidden"}
+	case domain.ErrInvoiceNotRefundable:
+		return http.StatusConflict, response.ErrorResponse{Code: "invoice_not_refundable", Message: err.Error()}
+	case domain.ErrRefundExceedsAmount:
+		return http.StatusUnprocessableEntity, response.ErrorResponse{Code: "refund_amount_exceeded", Message: err.Error()}
+	case domain.ErrInsufficientBalance:
+		return http.StatusUnprocessableEntity, response.ErrorResponse{Code: "insufficient_balance", Message: "available balance is not enough for this refund"}
+	case domain.ErrInvalidAmount:
+		return http.StatusUnprocessableEntity, response.ErrorResponse{Code: "validation_error", Message: err.Error()}
+	default:
+		return http.StatusInternalServerError, response.ErrorResponse{Code: "internal_error", Message: "internal server error"}
+	}
+}
+
+// beginIdempotentRequest reserva a Idempotency-Key para a requisicao atual.
+// Retorna false quando a resposta ja foi escrita (replay, conflito ou erro).
+func beginIdempotentRequest(w http.ResponseWriter, r *http.Request, store *repository.IdempotencyRepository, idempotencyKey string, bodyBytes []byte, principal *domain.Principal) bool {
+	if store == nil {
+		return true
+	}
+
+	endpoint := r.Method + ":" + r.URL.Path
+	requestHash := hashIdempotency(bodyBytes, principal)
+
+	_ = store.DeleteExpired(r.Context())
+	existing, err := store.Get(r.Context(), idempotencyKey, endpoint)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
+		return false
+	}
+	if existing != nil {
+		if time.Now().After(existing.ExpiresAt) {
+			_ = store.Delete(r.Context(), idempotencyKey, endpoint)
+		} else {
+			if existing.RequestHash != requestHash {
+				response.Error(w, http.StatusConflict, "idempotency_conflict", "idempotency key payload mismatch", nil)
+				return false
+			}
+			if existing.Status != "completed" {
+				response.Error(w, http.StatusConflict, "idempotency_in_progress", "request with this idempotency key is still processing", nil)
+				return false
+			}
+			writeCachedResponse(w, existing.StatusCode, existing.ResponseBody)
+			return false
+		}
+	}
+
+	created, err := store.CreateProcessing(r.Context(), idempotencyKey, endpoint, requestHash, time.Now().Add(24*time.Hour))
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
+		return false
+	}
+	if !created {
+		existing, err := store.Get(r.Context(), idempotencyKey, endpoint)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
+			return false
+		}
+		if existing != nil && existing.Status == "completed" && existing.RequestHash == requestHash {
+			writeCachedResponse(w, existing.StatusCode, existing.ResponseBody)
+			return false
+		}
+		response.Error(w, http.StatusConflict, "idempotency_in_progress", "request with this idempotency key is still processing", nil)
+		return false
+	}
+
+	return true
+}
+
 func writeCachedResponse(w http.ResponseWriter, status int, body []byte) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -284,7 +695,8 @@ func writeWithIdempotency(w http.ResponseWriter, store *repository.IdempotencyRe
 	body, _ := json.Marshal(payload)
 	if key != "" && store != nil {
 		endpoint := r.Method + ":" + r.URL.Path
-		if status >= 500 {
+		// Respostas transitorias liberam a key para o retry.
+		if status >= 500 || status == http.StatusTooManyRequests {
 			_ = store.Delete(r.Context(), key, endpoint)
 		} else {
 			_ = store.UpdateResponse(r.Context(), key, endpoint, status, body)
@@ -296,30 +708,65 @@ func writeWithIdempotency(w http.ResponseWriter, store *repository.IdempotencyRe
 	_, _ = w.Write(body)
 }
 
-func hashIdempotency(body []byte, apiKey string) string {
+// hashIdempotency inclui conta e modo: qualquer API key da conta no mesmo modo reaproveita a resposta.
+func hashIdempotency(body []byte, principal *domain.Principal) string {
 	hash := sha256.New()
 	_, _ = hash.Write(body)
-	_, _ = hash.Write([]byte(apiKey))
+	_, _ = hash.Write([]byte(principal.AccountID + ":" + string(principal.Mode)))
 	return hex.EncodeToString(hash.Sum(nil))
 }
 
-// ListByAccount lista as faturas da conta.
+// ListByAccount lista as faturas da conta com paginacao por cursor.
 // @Summary Listar faturas
+// @Description Lista faturas da mais recente para a mais antiga. Use next_cursor como cursor para a proxima pagina.
 // @Tags invoices
 // @Produce json
 // @Param X-API-KEY header string true "API key"
-// @Success 200 {array} dto.InvoiceOutput
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "Cursor returned as next_cursor"
+// @Param status query string false "Invoice status"
+// @Param payment_type query string false "Payment type"
+// @Param card_brand query string false "Card brand (visa, mastercard, amex, elo, hipercard)"
+// @Param customer_id query string false "Customer ID"
+// @Param created_from query string false "Created at lower bound (RFC3339, inclusive)"
+// @Param created_to query string false "Created at upper bound (RFC3339, exclusive)"
+// @Param currency query string false "Currency (ISO 4217)"
+// @Param min_amount query number false "Minimum amount (in currency or, without it, the account currency)"
+// @Param max_amount query number false "Maximum amount (in currency or, without it, the account currency)"
+// @Success 200 {object} dto.InvoiceListOutput
 // @Failure 401 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /invoice [get]
 func (h *InvoiceHandler) ListByAccount(w http.ResponseWriter, r *http.Request) {
-	apiKey := r.Header.Get("X-API-KEY")
-	if apiKey == "" {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
 		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
 		return
 	}
 
-	output, err := h.service.ListByAccountAPIKey(apiKey)
+	query := r.URL.Query()
+	accountCurrency := ""
+	if query.Get("currency") == "" && (query.Get("min_amount") != "" || query.Get("max_amount") != "") {
+		currency, err := h.service.AccountCurrency(principal.AccountID)
+		if err != nil {
+			if err == domain.ErrAccountNotFound {
+				response.Error(w, http.StatusUnauthorized, "invalid_api_key", "invalid api key", nil)
+				return
+			}
+			response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
+			return
+		}
+		accountCurrency = currency
+	}
+
+	filter, validationErrors := parseListInvoicesQuery(query, accountCurrency)
+	if validationErrors != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid list parameters", validationErrors)
+		return
+	}
+
+	output, err := h.service.ListByAccount(principal.AccountID, principal.Mode, filter)
 	if err != nil {
 		switch err {
 		case domain.ErrAccountNotFound:
@@ -333,3 +780,15 @@ func (h *InvoiceHandler) ListByAccount(w http.ResponseWriter, r *http.Request) {
 
 	response.JSON(w, http.StatusOK, output)
 }
+
+// limitErrorDetails descreve a regra violada; window e payment_type so aparecem quando se aplicam.
+func limitErrorDetails(err domain.LimitExceededError) map[string]string {
+	details := map[string]string{"reason": err.Reason}
+	if err.Window != "" {
+		details["window"] = string(err.Window)
+	}
+	if err.PaymentType != "" {
+		details["payment_type"] = err.PaymentType
+	}
+	return details
+}
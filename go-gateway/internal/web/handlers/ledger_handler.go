@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/service"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/middleware"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/response"
+)
+
+// LedgerHandler processa requisições HTTP do ledger das contas
+type LedgerHandler struct {
+	ledgerService *service.LedgerService
+}
+
+// NewLedgerHandler cria um novo handler do ledger
+func NewLedgerHandler(ledgerService *service.LedgerService) *LedgerHandler {
+	return &LedgerHandler{ledgerService: ledgerService}
+}
+
+// List lista os lancamentos do ledger da conta.
+// @Summary Listar lancamentos do ledger
+// @Description Retorna os lancamentos mais recentes que compoem o saldo da conta. Cada lancamento tem partidas que somam zero.
+// @Tags accounts
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param limit query int false "Quantidade de lancamentos (1-200, padrao 50)"
+// @Success 200 {array} dto.LedgerEntryOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /accounts/ledger [get]
+func (h *LedgerHandler) List(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	limit := service.DefaultLedgerPageSize
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > service.MaxLedgerPageSize {
+			response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid list parameters", map[string]string{
+				"limit": "limit must be between 1 and " + strconv.Itoa(service.MaxLedgerPageSize),
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	output, err := h.ledgerService.ListEntries(r.Context(), principal.AccountID, principal.Mode, limit)
+	if err != nil {
+		switch err {
+		case domain.ErrAccountNotFound:
+			response.Error(w, http.StatusUnauthorized, "invalid_api_key", "invalid api key", nil)
+		default:
+			response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/repository"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/service"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/telemetry"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/middleware"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultDeadLetterPageSize = 50
+	maxDeadLetterPageSize     = 200
+)
+
+// PayoutHandler processa requisições HTTP de contas bancárias e saques
+type PayoutHandler struct {
+	payoutService    *service.PayoutService
+	idempotencyStore *repository.IdempotencyRepository
+}
+
+// NewPayoutHandler cria um novo handler de saques
+func NewPayoutHandler(payoutService *service.PayoutService, idempotencyStore *repository.IdempotencyRepository) *PayoutHandler {
+	return &PayoutHandler{payoutService: payoutService, idempotencyStore: idempotencyStore}
+}
+
+// CreateBankAccount cadastra uma conta bancaria para saques.
+// @Summary Cadastrar conta bancaria
+// @Description Cadastra uma conta bancaria no modo da API key. holder_document aceita CPF ou CNPJ; bank_code e o codigo COMPE de 3 digitos.
+// @Tags payouts
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param request body dto.CreateBankAccountInput true "Bank account payload"
+// @Success 201 {object} dto.BankAccountOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /bank-accounts [post]
+func (h *PayoutHandler) CreateBankAccount(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	var input dto.CreateBankAccountInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	if validationErrors := validateCreateBankAccountInput(input); validationErrors != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid bank account data", validationErrors)
+		return
+	}
+
+	output, err := h.payoutService.CreateBankAccount(principal.AccountID, principal.Mode, input)
+	if err != nil {
+		writePayoutError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, output)
+}
+
+// ListBankAccounts lista as contas bancarias da conta no modo da API key.
+// @Summary Listar contas bancarias
+// @Tags payouts
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Success 200 {array} dto.BankAccountOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /bank-accounts [get]
+func (h *PayoutHandler) ListBankAccounts(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	output, err := h.payoutService.ListBankAccounts(principal.AccountID, principal.Mode)
+	if err != nil {
+		writePayoutError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// DeleteBankAccount remove uma conta bancaria.
+// @Summary Remover conta bancaria
+// @Description Remove a conta bancaria da listagem; saques ja solicitados para ela seguem normalmente.
+// @Tags payouts
+// @Param X-API-KEY header string true "API key"
+// @Param id path string true "Bank account ID"
+// @Success 204
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /bank-accounts/{id} [delete]
+func (h *PayoutHandler) DeleteBankAccount(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		writePayoutError(w, domain.ErrBankAccountNotFound)
+		return
+	}
+
+	if err := h.payoutService.DeleteBankAccount(principal.AccountID, principal.Mode, id); err != nil {
+		writePayoutError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Create solicita um saque do saldo para uma conta bancaria.
+// @Summary Solicitar saque
+// @Description Reserva o valor no saldo do modo da API key e envia o saque ao processador de liquidacao. Sem currency vale a moeda da conta. Saldo insuficiente retorna insufficient_balance.
+// @Tags payouts
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param Idempotency-Key header string false "Idempotency key"
+// @Param request body dto.CreatePayoutInput true "Payout payload"
+// @Success 201 {object} dto.PayoutOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /payouts [post]
+func (h *PayoutHandler) Create(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	var input dto.CreatePayoutInput
+	decoder := json.NewDecoder(bytes.NewReader(bodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if idempotencyKey != "" && !beginIdempotentRequest(w, r, h.idempotencyStore, idempotencyKey, bodyBytes, principal) {
+		return
+	}
+
+	if validationErrors := validateCreatePayoutInput(input); validationErrors != nil {
+		writeWithIdempotency(w, h.idempotencyStore, r, idempotencyKey, http.StatusUnprocessableEntity, response.ErrorResponse{
+			Code:    "validation_error",
+			Message: "invalid payout data",
+			Details: validationErrors,
+		})
+		return
+	}
+
+	output, err := h.payoutService.Create(principal.AccountID, principal.Mode, input, telemetry.RequestIDFromContext(r.Context()))
+	if err != nil {
+		status, body := payoutErrorResponse(err)
+		writeWithIdempotency(w, h.idempotencyStore, r, idempotencyKey, status, body)
+		return
+	}
+
+	writeWithIdempotency(w, h.idempotencyStore, r, idempotencyKey, http.StatusCreated, output)
+}
+
+// List lista o historico de saques da conta no modo da API key.
+// @Summary Listar saques
+// @Tags payouts
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "Cursor from next_cursor"
+// @Param status query string false "Payout status (pending, in_transit, paid, failed)"
+// @Success 200 {object} dto.PayoutListOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /payouts [get]
+func (h *PayoutHandler) List(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	filter, validationErrors := parseListPayoutsQuery(r.URL.Query())
+	if validationErrors != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid query parameters", validationErrors)
+		return
+	}
+	filter.Mode = principal.Mode
+
+	output, err := h.payoutService.List(principal.AccountID, filter)
+	if err != nil {
+		writePayoutError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// Get retorna um saque pelo ID.
+// @Summary Buscar saque por ID
+// @Tags payouts
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param id path string true "Payout ID"
+// @Success 200 {object} dto.PayoutOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /payouts/{id} [get]
+func (h *PayoutHandler) Get(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		writePayoutError(w, domain.ErrPayoutNotFound)
+		return
+	}
+
+	output, err := h.payoutService.Get(principal.AccountID, principal.Mode, id)
+	if err != nil {
+		writePayoutError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// AdminUpdateStatus registra o andamento de um saque informado pelo processador de liquidacao.
+// @Summary Atualizar status do saque (admin)
+// @Description Aceita in_transit, paid ou failed; failed exige failure_reason e devolve o valor ao saldo. Repetir o status atual nao altera o saque.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Admin key"
+// @Param id path string true "Payout ID"
+// @Param request body dto.UpdatePayoutStatusInput true "Status payload"
+// @Success 200 {object} dto.PayoutOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Router /admin/payouts/{id}/status [post]
+func (h *PayoutHandler) AdminUpdateStatus(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		writePayoutError(w, domain.ErrPayoutNotFound)
+		return
+	}
+
+	var input dto.UpdatePayoutStatusInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	if validationErrors := validateUpdatePayoutStatusInput(input); validationErrors != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid payout status", validationErrors)
+		return
+	}
+
+	output, err := h.payoutService.UpdateStatus(id, domain.PayoutStatus(input.Status), input.FailureReason)
+	if err != nil {
+		writePayoutError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// AdminListDeadLetters lista os saques cujo pedido esgotou as tentativas de entrega.
+// @Summary Listar saques nao entregues (admin)
+// @Description Saques pending cujo evento do outbox ficou dead apos PAYOUT_MAX_ATTEMPTS tentativas. O valor segue reservado ate o reenvio ou a falha do saque.
+// @Tags admin
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Admin key"
+// @Param limit query int false "Max items (1-200, default 50)"
+// @Success 200 {array} dto.DeadLetteredPayoutOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/payouts/dead-letters [get]
+func (h *PayoutHandler) AdminListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	limit := defaultDeadLetterPageSize
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxDeadLetterPageSize {
+			response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid list parameters", map[string]string{
+				"limit": "limit must be between 1 and " + strconv.Itoa(maxDeadLetterPageSize),
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	output, err := h.payoutService.ListDeadLettered(limit)
+	if err != nil {
+		writePayoutError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// AdminRedeliver reenvia ao processador o pedido de um saque que ficou dead no outbox.
+// @Summary Reenviar saque nao entregue (admin)
+// @Description Volta o evento dead do saque para a fila do outbox com as tentativas zeradas. Apenas saques pending.
+// @Tags admin
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Admin key"
+// @Param id path string true "Payout ID"
+// @Success 202 {object} dto.PayoutOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /admin/payouts/{id}/redeliver [post]
+func (h *PayoutHandler) AdminRedeliver(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		writePayoutError(w, domain.ErrPayoutNotFound)
+		return
+	}
+
+	output, err := h.payoutService.Redeliver(id)
+	if err != nil {
+		writePayoutError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, output)
+}
+
+func writePayoutError(w http.ResponseWriter, err error) {
+	status, body := payoutErrorResponse(err)
+	response.Error(w, status, body.Code, body.Message, body.Details)
+}
+
+func payoutErrorResponse(err error) (int, response.ErrorResponse) {
+	switch err {
+	case domain.ErrBankAccountNotFound:
+		return http.StatusNotFound, response.ErrorResponse{Code: "bank_account_not_found", Message: "bank account not found"}
+	case domain.ErrPayoutNotFound:
+		return http.StatusNotFound, response.ErrorResponse{Code: "payout_not_found", Message: "payout not found"}
+	case domain.ErrInsufficientBalance:
+		return http.StatusUnprocessableEntity, response.ErrorResponse{Code: "insufficient_balance", Message: "balance is not enough for this payout"}
+	case domain.ErrInvalidPayoutTransition:
+		return http.StatusConflict, response.ErrorResponse{Code: "invalid_payout_transition", Message: "payout cannot move to this status"}
+	case domain.ErrPayoutRequestInFlight:
+		return http.StatusConflict, response.ErrorResponse{Code: "payout_request_in_flight", Message: "payout request is being published, retry shortly"}
+	case domain.ErrPayoutNotDeadLettered:
+		return http.StatusConflict, response.ErrorResponse{Code: "payout_not_dead_lettered", Message: "payout has no dead-lettered delivery to retry"}
+	case domain.ErrUnsupportedCurrency:
+		return http.StatusUnprocessableEntity, response.ErrorResponse{Code: "validation_error", Message: "invalid payout data", Details: map[string]string{"currency": "currency must be a supported ISO 4217 code"}}
+	case domain.ErrInvalidAmount:
+		return http.StatusUnprocessableEntity, response.ErrorResponse{Code: "validation_error", Message: "invalid payout data", Details: map[string]string{"amount": "amount must be greater than zero with the currency precision"}}
+	case domain.ErrInvalidDocument:
+		return http.StatusUnprocessableEntity, response.ErrorResponse{Code: "validation_error", Message: "invalid bank account data", Details: map[string]string{"holder_document": "holder_document must be a valid CPF or CNPJ"}}
+	case domain.ErrInvalidBankAccount:
+		return http.StatusUnprocessableEntity, response.ErrorResponse{Code: "validation_error", Message: "invalid bank account data", Details: map[string]string{"account_number": "account_number must have up to 12 digits and an optional check digit"}}
+	default:
+		return http.StatusInternalServerError, response.ErrorResponse{Code: "internal_error", Message: "internal server error"}
+	}
+}
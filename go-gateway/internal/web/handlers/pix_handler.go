@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/service"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/telemetry"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/response"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/webhook"
+)
+
+// pixSignatureTolerance limita a idade da assinatura do webhook PIX.
+const pixSignatureTolerance = 5 * time.Minute
+
+// maxPixWebhookBody limita o corpo aceito no webhook PIX.
+const maxPixWebhookBody = 1 << 20
+
+// PixWebhookHandler recebe as confirmacoes de pagamento PIX enviadas pelo PSP
+type PixWebhookHandler struct {
+	invoiceService *service.InvoiceService
+	secret         string
+}
+
+// NewPixWebhookHandler cria um novo handler do webhook PIX
+func NewPixWebhookHandler(invoiceService *service.InvoiceService, secret string) *PixWebhookHandler {
+	return &PixWebhookHandler{invoiceService: invoiceService, secret: secret}
+}
+
+// Receive confirma pagamentos PIX e aprova as faturas correspondentes.
+// @Summary Webhook de pagamentos PIX
+// @Description Recebe do PSP o corpo {"pix": [...]} do padrao do Banco Central, assinado em X-Webhook-Signature (t=<unix>,v1=<hmac>) com PIX_WEBHOOK_SECRET. Responde 200 quando o lote foi processado; 5xx indica que o PSP deve reenviar.
+// @Tags pix
+// @Accept json
+// @Produce json
+// @Param request body dto.PixWebhookInput true "PIX payments"
+// @Success 200
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /webhooks/pix [post]
+func (h *PixWebhookHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPixWebhookBody))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	if err := webhook.Verify(h.secret, r.Header.Get(webhook.SignatureHeader), body, pixSignatureTolerance, time.Now()); err != nil {
+		response.Error(w, http.StatusUnauthorized, "invalid_signature", "invalid webhook signature", nil)
+		return
+	}
+
+	var input dto.PixWebhookInput
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if err := decoder.Decode(&input); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	if err := h.invoiceService.ConfirmPixPayments(input, telemetry.RequestIDFromContext(r.Context())); err != nil {
+		slog.Error("failed to confirm pix payments", "error", err)
+		response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/service"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/middleware"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// PricingHandler processa requisições HTTP dos planos de tarifas (merchant e admin)
+type PricingHandler struct {
+	pricingService *service.PricingService
+}
+
+// NewPricingHandler cria um novo handler de planos de tarifas
+func NewPricingHandler(pricingService *service.PricingService) *PricingHandler {
+	return &PricingHandler{pricingService: pricingService}
+}
+
+// Get retorna as tarifas da conta no modo da chave.
+// @Summary Consultar tarifas da conta
+// @Description Retorna o percentual (pontos-base), o valor fixo e os acrescimos por parcela de cada tipo de pagamento. Sem plano, rates vem vazio e nenhuma tarifa e cobrada.
+// @Tags accounts
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param currency query string false "Moeda ISO 4217 (padrao: moeda da conta)"
+// @Success 200 {object} dto.PricingPlanOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /accounts/pricing [get]
+func (h *PricingHandler) Get(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	output, err := h.pricingService.Get(principal.AccountID, principal.Mode, r.URL.Query().Get("currency"))
+	if err != nil {
+		writePricingMerchantError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// AdminList lista os planos de tarifas de uma conta em todos os modos e moedas.
+// @Summary Listar tarifas da conta (admin)
+// @Tags admin
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Admin key"
+// @Param id path string true "Account ID"
+// @Success 200 {array} dto.PricingPlanOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/accounts/{id}/pricing [get]
+func (h *PricingHandler) AdminList(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := adminPricingAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	plans, err := h.pricingService.List(accountID)
+	if err != nil {
+		writePricingError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, plans)
+}
+
+// AdminSet substitui as tarifas de um modo e moeda da conta.
+// @Summary Definir tarifas da conta (admin)
+// @Description Substitui as tarifas por tipo de pagamento: percent_bps (1% = 100), fixed_cents e, para credit_card, installment_surcharges_bps por numero de parcelas. Vale para as faturas creditadas depois da alteracao.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param X-ADMIN-KEY header string true "Admin key"
+// @Param id path string true "Account ID"
+// @Param request body dto.SetPricingPlanInput true "Pricing payload"
+// @Success 200 {object} dto.PricingPlanOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/accounts/{id}/pricing [put]
+func (h *PricingHandler) AdminSet(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := adminPricingAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.SetPricingPlanInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	if validationErrors := validateSetPricingPlanInput(input); validationErrors != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid pricing data", validationErrors)
+		return
+	}
+
+	plan, err := h.pricingService.Set(accountID, input)
+	if err != nil {
+		writePricingError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, plan)
+}
+
+// AdminDelete remove as tarifas de um modo e moeda da conta.
+// @Summary Remover tarifas da conta (admin)
+// @Description As proximas faturas do modo e da moeda nao pagam tarifa; faturas ja creditadas mantem a tarifa aplicada.
+// @Tags admin
+// @Param X-ADMIN-KEY header string true "Admin key"
+// @Param id path string true "Account ID"
+// @Param mode query string true "live ou test"
+// @Param currency query string true "Moeda ISO 4217"
+// @Success 204
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/accounts/{id}/pricing [delete]
+func (h *PricingHandler) AdminDelete(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := adminPricingAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	mode, currency := r.URL.Query().Get("mode"), r.URL.Query().Get("currency")
+	if validationErrors := validatePricingTarget(mode, currency); len(validationErrors) > 0 {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid pricing parameters", validationErrors)
+		return
+	}
+
+	if err := h.pricingService.Delete(accountID, domain.Mode(mode), currency); err != nil {
+		writePricingError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// adminPricingAccountID le o id da rota; ids invalidos respondem 404 account_not_found.
+func adminPricingAccountID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	accountID := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(accountID); err != nil {
+		writePricingError(w, domain.ErrAccountNotFound)
+		return "", false
+	}
+	return accountID, true
+}
+
+// writePricingMerchantError responde os erros da consulta de tarifas pela chave do merchant.
+func writePricingMerchantError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrAccountNotFound:
+		response.Error(w, http.StatusUnauthorized, "invalid_api_key", "invalid api key", nil)
+	case domain.ErrUnsupportedCurrency:
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid pricing parameters", map[string]string{"currency": "unsupported currency"})
+	default:
+		response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
+	}
+}
+
+func writePricingError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrAccountNotFound:
+		response.Error(w, http.StatusNotFound, "account_not_found", "account not found", nil)
+	case domain.ErrPricingPlanNotFound:
+		response.Error(w, http.StatusNotFound, "pricing_plan_not_found", "pricing plan not found", nil)
+	default:
+		response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
+	}
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/service"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/middleware"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/response"
+	"github.com/go-chi/chi/v5"
+)
+
+const pricingAccountID = "6f1c2a4e-8d3b-4f7a-9c1e-2b5d7a9e0f13"
+
+type stubPricingPlanRepository struct {
+	domain.PricingPlanRepository
+	replaced   []domain.PricingPlan
+	deleted    []string
+	replaceErr error
+	deleteErr  error
+}
+
+func (r *stubPricingPlanRepository) Replace(plan domain.PricingPlan) (*domain.PricingPlan, error) {
+	if r.replaceErr != nil {
+		return nil, r.replaceErr
+	}
+	r.replaced = append(r.replaced, plan)
+	return &plan, nil
+}
+
+func (r *stubPricingPlanRepository) Delete(accountID string, mode domain.Mode, currency string) error {
+	if r.deleteErr != nil {
+		return r.deleteErr
+	}
+	r.deleted = append(r.deleted, accountID+"/"+string(mode)+"/"+currency)
+	return nil
+}
+
+type stubPricingAccountRepository struct {
+	domain.AccountRepository
+}
+
+func (r *stubPricingAccountRepository) FindByID(id string) (*domain.Account, error) {
+	return nil, domain.ErrAccountNotFound
+}
+
+func newPricingHandler(repo *stubPricingPlanRepository) *PricingHandler {
+	accounts := service.NewAccountService(&stubPricingAccountRepository{})
+	return NewPricingHandler(service.NewPricingService(repo, *accounts))
+}
+
+func adminPricingRequest(method, accountID, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	routeContext := chi.NewRouteContext()
+	routeContext.URLParams.Add("id", accountID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeContext))
+}
+
+func decodeErrorResponse(t *testing.T, rec *httptest.ResponseRecorder) response.ErrorResponse {
+	t.Helper()
+	var body response.ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return body
+}
+
+func TestPricingGetAnswersInvalidAPIKeyWhenAccountIsGone(t *testing.T) {
+	handler := newPricingHandler(&stubPricingPlanRepository{})
+	principal := &domain.Principal{AccountID: pricingAccountID, Mode: domain.ModeLive}
+	req := httptest.NewRequest(http.MethodGet, "/accounts/pricing", nil)
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+	rec := httptest.NewRecorder()
+	handler.Get(rec, req)
+
+	if rec.Code != http.StatusUnauthorized || decodeErrorResponse(t, rec).Code != "invalid_api_key" {
+		t.Fatalf("expected 401 invalid_api_key, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPricingAdminSet(t *testing.T) {
+	validBody := `{"mode":"live","currency":"BRL","rates":{"credit_card":{"percent_bps":299,"fixed_cents":39,"installment_surcharges_bps":{"2":150}}}}`
+	tests := []struct {
+		name       string
+		accountID  string
+		body       string
+		replaceErr error
+		status     int
+		code       string
+	}{
+		{"saved", pricingAccountID, validBody, nil, http.StatusOK, ""},
+		{"invalid account id", "not-a-uuid", validBody, nil, http.StatusNotFound, "account_not_found"},
+		{"unknown account", pricingAccountID, validBody, domain.ErrAccountNotFound, http.StatusNotFound, "account_not_found"},
+		{"unknown field", pricingAccountID, `{"mode":"live","currency":"BRL","rates":{},"plan":"gold"}`, nil, http.StatusBadRequest, "invalid_payload"},
+		{"invalid rates", pricingAccountID, `{"mode":"live","currency":"BRL","rates":{"boleto":{"percent_bps":-1,"fixed_cents":0}}}`, nil, http.StatusUnprocessableEntity, "validation_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &stubPricingPlanRepository{replaceErr: tt.replaceErr}
+			rec := httptest.NewRecorder()
+			newPricingHandler(repo).AdminSet(rec, adminPricingRequest(http.MethodPut, tt.accountID, "/admin/accounts/"+tt.accountID+"/pricing", tt.body))
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if tt.code != "" {
+				if code := decodeErrorResponse(t, rec).Code; code != tt.code {
+					t.Fatalf("expected code %s, got %s", tt.code, code)
+				}
+				return
+			}
+			if len(repo.replaced) != 1 || repo.replaced[0].AccountID != tt.accountID ||
+				repo.replaced[0].Rates[domain.PaymentTypeCreditCard].InstallmentSurchargesBps[2] != 150 {
+				t.Fatalf("unexpected saved plans: %+v", repo.replaced)
+			}
+		})
+	}
+}
+
+func TestPricingAdminDelete(t *testing.T) {
+	tests := []struct {
+		name      string
+		accountID string
+		query     string
+		deleteErr error
+		status    int
+		code      string
+	}{
+		{"deleted", pricingAccountID, "mode=test&currency=brl", nil, http.StatusNoContent, ""},
+		{"invalid account id", "not-a-uuid", "mode=test&currency=BRL", nil, http.StatusNotFound, "account_not_found"},
+		{"missing mode", pricingAccountID, "currency=BRL", nil, http.StatusUnprocessableEntity, "validation_error"},
+		{"unsupported currency", pricingAccountID, "mode=live&currency=XXX", nil, http.StatusUnprocessableEntity, "validation_error"},
+		{"no plan", pricingAccountID, "mode=live&currency=BRL", domain.ErrPricingPlanNotFound, http.StatusNotFound, "pricing_plan_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &stubPricingPlanRepository{deleteErr: tt.deleteErr}
+			rec := httptest.NewRecorder()
+			newPricingHandler(repo).AdminDelete(rec, adminPricingRequest(http.MethodDelete, tt.accountID, "/admin/accounts/"+tt.accountID+"/pricing?"+tt.query, ""))
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if tt.code != "" {
+				if code := decodeErrorResponse(t, rec).Code; code != tt.code {
+					t.Fatalf("expected code %s, got %s", tt.code, code)
+				}
+				return
+			}
+			if len(repo.deleted) != 1 || repo.deleted[0] != pricingAccountID+"/test/BRL" {
+				t.Fatalf("unexpected deletes: %v", repo.deleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,388 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/service"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/middleware"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// SubscriptionHandler processa requisições HTTP de planos e assinaturas
+type SubscriptionHandler struct {
+	subscriptionService *service.SubscriptionService
+}
+
+// NewSubscriptionHandler cria um novo handler de assinaturas
+func NewSubscriptionHandler(subscriptionService *service.SubscriptionService) *SubscriptionHandler {
+	return &SubscriptionHandler{subscriptionService: subscriptionService}
+}
+
+// CreatePlan cria um plano de assinatura.
+// @Summary Criar plano
+// @Description Cria um plano no modo da API key. Interval aceita day, week, month ou year; sem currency vale a moeda da conta.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param request body dto.CreatePlanInput true "Plan payload"
+// @Success 201 {object} dto.PlanOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /plans [post]
+func (h *SubscriptionHandler) CreatePlan(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	var input dto.CreatePlanInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	if validationErrors := validateCreatePlanInput(input); validationErrors != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid plan data", validationErrors)
+		return
+	}
+
+	output, err := h.subscriptionService.CreatePlan(principal.AccountID, principal.Mode, input)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, output)
+}
+
+// ListPlans lista os planos da conta no modo da API key.
+// @Summary Listar planos
+// @Tags subscriptions
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Success 200 {array} dto.PlanOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /plans [get]
+func (h *SubscriptionHandler) ListPlans(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	output, err := h.subscriptionService.ListPlans(principal.AccountID, principal.Mode)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// GetPlan retorna um plano pelo ID.
+// @Summary Buscar plano por ID
+// @Tags subscriptions
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param id path string true "Plan ID"
+// @Success 200 {object} dto.PlanOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /plans/{id} [get]
+func (h *SubscriptionHandler) GetPlan(w http.ResponseWriter, r *http.Request) {
+	principal, id, ok := subscriptionRequest(w, r, domain.ErrPlanNotFound)
+	if !ok {
+		return
+	}
+
+	output, err := h.subscriptionService.GetPlan(principal.AccountID, principal.Mode, id)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// ArchivePlan arquiva um plano.
+// @Summary Arquivar plano
+// @Description O plano deixa de aceitar novas assinaturas; as assinaturas existentes continuam cobrando.
+// @Tags subscriptions
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param id path string true "Plan ID"
+// @Success 200 {object} dto.PlanOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /plans/{id} [delete]
+func (h *SubscriptionHandler) ArchivePlan(w http.ResponseWriter, r *http.Request) {
+	principal, id, ok := subscriptionRequest(w, r, domain.ErrPlanNotFound)
+	if !ok {
+		return
+	}
+
+	output, err := h.subscriptionService.ArchivePlan(principal.AccountID, principal.Mode, id)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// Create assina um plano para um cliente.
+// @Summary Criar assinatura
+// @Description Assina o plano para o cliente com um card_token salvo nele. Com trial_days a primeira cobranca ocorre no fim do trial; sem trial, no proximo ciclo do scheduler.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param request body dto.CreateSubscriptionInput true "Subscription payload"
+// @Success 201 {object} dto.SubscriptionOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /subscriptions [post]
+func (h *SubscriptionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	var input dto.CreateSubscriptionInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	if validationErrors := validateCreateSubscriptionInput(input); validationErrors != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid subscription data", validationErrors)
+		return
+	}
+
+	output, err := h.subscriptionService.Create(principal.AccountID, principal.Mode, input)
+	if err != nil {
+		// Referencias invalidas no payload sao erros de validacao, nao 404 da rota.
+		switch err {
+		case domain.ErrPlanNotFound:
+			response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid subscription data", map[string]string{"plan_id": err.Error()})
+		case domain.ErrCustomerNotFound:
+			response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid subscription data", map[string]string{"customer_id": err.Error()})
+		case domain.ErrCardTokenNotFound:
+			response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid subscription data", map[string]string{"card_token": "card token is not saved on the customer"})
+		case domain.ErrCardExpired:
+			response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid subscription data", map[string]string{"card_token": err.Error()})
+		default:
+			writeSubscriptionError(w, err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, output)
+}
+
+// List lista as assinaturas da conta no modo da API key.
+// @Summary Listar assinaturas
+// @Tags subscriptions
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "Cursor from next_cursor"
+// @Param status query string false "trialing, active, past_due or canceled"
+// @Param customer_id query string false "Customer ID"
+// @Param plan_id query string false "Plan ID"
+// @Success 200 {object} dto.SubscriptionListOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /subscriptions [get]
+func (h *SubscriptionHandler) List(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	filter, validationErrors := parseListSubscriptionsQuery(r.URL.Query())
+	if validationErrors != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid query parameters", validationErrors)
+		return
+	}
+	filter.Mode = principal.Mode
+
+	output, err := h.subscriptionService.List(principal.AccountID, filter)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// Get retorna uma assinatura pelo ID.
+// @Summary Buscar assinatura por ID
+// @Tags subscriptions
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} dto.SubscriptionOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /subscriptions/{id} [get]
+func (h *SubscriptionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	principal, id, ok := subscriptionRequest(w, r, domain.ErrSubscriptionNotFound)
+	if !ok {
+		return
+	}
+
+	output, err := h.subscriptionService.Get(principal.AccountID, principal.Mode, id)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// Cancel cancela uma assinatura.
+// @Summary Cancelar assinatura
+// @Description Sem corpo cancela agora. Com at_period_end=true a assinatura segue ate o fim do periodo pago e e cancelada em vez de cobrada.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param id path string true "Subscription ID"
+// @Param request body dto.CancelSubscriptionInput false "Cancel options"
+// @Success 200 {object} dto.SubscriptionOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /subscriptions/{id}/cancel [post]
+func (h *SubscriptionHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	principal, id, ok := subscriptionRequest(w, r, domain.ErrSubscriptionNotFound)
+	if !ok {
+		return
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	var input dto.CancelSubscriptionInput
+	if len(bytes.TrimSpace(bodyBytes)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(bodyBytes))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&input); err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+			return
+		}
+	}
+
+	output, err := h.subscriptionService.Cancel(principal.AccountID, principal.Mode, id, input.AtPeriodEnd)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// ListEvents retorna o historico de uma assinatura.
+// @Summary Listar eventos da assinatura
+// @Tags subscriptions
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param id path string true "Subscription ID"
+// @Success 200 {array} dto.SubscriptionEventOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /subscriptions/{id}/events [get]
+func (h *SubscriptionHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	principal, id, ok := subscriptionRequest(w, r, domain.ErrSubscriptionNotFound)
+	if !ok {
+		return
+	}
+
+	output, err := h.subscriptionService.ListEvents(principal.AccountID, principal.Mode, id)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// subscriptionRequest le o principal e o ID da rota, respondendo 401 ou 404 (notFound) quando invalidos.
+func subscriptionRequest(w http.ResponseWriter, r *http.Request, notFound error) (*domain.Principal, string, bool) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return nil, "", false
+	}
+
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeSubscriptionError(w, notFound)
+		return nil, "", false
+	}
+	return principal, id, true
+}
+
+func writeSubscriptionError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrPlanNotFound:
+		response.Error(w, http.StatusNotFound, "plan_not_found", "plan not found", nil)
+	case domain.ErrSubscriptionNotFound:
+		response.Error(w, http.StatusNotFound, "subscription_not_found", "subscription not found", nil)
+	case domain.ErrPlanInactive:
+		response.Error(w, http.StatusConflict, "plan_archived", "plan is archived", nil)
+	case domain.ErrSubscriptionCanceled:
+		response.Error(w, http.StatusConflict, "subscription_canceled", "subscription already canceled", nil)
+	case domain.ErrSubscriptionConflict:
+		response.Error(w, http.StatusConflict, "subscription_conflict", "subscription changed, retry the request", nil)
+	case domain.ErrUnsupportedCurrency:
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid plan data", map[string]string{"currency": "currency must be a supported ISO 4217 code"})
+	case domain.ErrInvalidAmount:
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid plan data", map[string]string{"amount": "amount must be greater than zero with the currency precision"})
+	case domain.ErrInvalidPlan:
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid plan data", map[string]string{"interval": "invalid interval, interval_count or trial_days"})
+	default:
+		response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
+	}
+}
@@ -10,4 +10,18 @@ type CreateInvoiceRequest struct {
 	ExpiryMonth    int     `json:"expiry_month" example:"12"`
 	ExpiryYear     int     `json:"expiry_year" example:"2030"`
 	CardholderName string  `json:"cardholder_name" example:"Demo User"`
+	CardToken      string  `json:"card_token,omitempty" example:"tok_4f1c2b8e9d0a4b7c8e6f5a3b2c1d0e9f"`
+	CustomerID     string  `json:"customer_id,omitempty" example:"0f8fad5b-d9cb-469f-a165-70867728950e"`
+	CaptureMethod  string  `json:"capture_method,omitempty" example:"automatic"`
+	Currency       string  `json:"currency,omitempty" example:"BRL"`
+}
+
+// RefundInvoiceRequest representa o payload do POST /invoice/{id}/refund (swagger).
+type RefundInvoiceRequest struct {
+	Amount float64 `json:"amount,omitempty" example:"50"`
+}
+
+// CaptureInvoiceRequest representa o payload do POST /invoice/{id}/capture (swagger).
+type CaptureInvoiceRequest struct {
+	Amount float64 `json:"amount,omitempty" example:"80"`
 }
@@ -2,11 +2,15 @@ package handlers
 
 import (
 	"net/mail"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
 
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+	"github.com/google/uuid"
 )
 
 func validateCreateAccountInput(input dto.CreateAccountInput) map[string]string {
@@ -22,6 +26,12 @@ func validateCreateAccountInput(input dto.CreateAccountInput) map[string]string
 		errors["email"] = "invalid email"
 	}
 
+	if input.Currency != "" {
+		if _, err := domain.NormalizeCurrency(input.Currency); err != nil {
+			errors["currency"] = "unsupported currency"
+		}
+	}
+
 	if len(errors) == 0 {
 		return nil
 	}
@@ -29,6 +39,15 @@ func validateCreateAccountInput(input dto.CreateAccountInput) map[string]string
 	return errors
 }
 
+// maxBoletoDueDays limita o vencimento informado na criacao de boletos.
+const maxBoletoDueDays = 180
+
+// Limites (em segundos) da expiracao informada na criacao de cobrancas PIX.
+const (
+	minPixExpiresIn = 60
+	maxPixExpiresIn = 86400
+)
+
 func validateCreateInvoiceInput(input dto.CreateInvoiceInput) map[string]string {
 	errors := make(map[string]string)
 
@@ -42,36 +61,81 @@ func validateCreateInvoiceInput(input dto.CreateInvoiceInput) map[string]string
 
 	if strings.TrimSpace(input.PaymentType) == "" {
 		errors["payment_type"] = "payment_type is required"
-	} else if input.PaymentType != "credit_card" && input.PaymentType != "boleto" {
-		errors["payment_type"] = "payment_type must be credit_card or boleto"
+	} else if !domain.ValidPaymentType(input.PaymentType) {
+		errors["payment_type"] = "payment_type must be credit_card, boleto or pix"
 	}
 
 	if input.PaymentType == "credit_card" {
-		if len(input.CardNumber) < 12 || len(input.CardNumber) > 19 {
-			errors["card_number"] = "card_number must have 12 to 19 digits"
-		} else if !isDigits(input.CardNumber) {
-			errors["card_number"] = "card_number must contain only digits"
+		if input.CardToken != "" {
+			if !domain.IsCardToken(input.CardToken) {
+				errors["card_token"] = "invalid card_token"
+			} else if input.CardNumber != "" || input.CVV != "" || input.ExpiryMonth != 0 || input.ExpiryYear != 0 || input.CardholderName != "" {
+				errors["card_token"] = "card_token cannot be combined with card fields"
+			}
+		} else {
+			validateCardFields(errors, input.CardNumber, input.CVV, input.ExpiryMonth, input.ExpiryYear, input.CardholderName)
+		}
+	} else if input.CardToken != "" {
+		errors["card_token"] = "card_token is only available for credit_card"
+	}
+
+	if input.Installments != 0 {
+		if input.PaymentType != domain.PaymentTypeCreditCard {
+			errors["installments"] = "installments is only available for credit_card"
+		} else if input.Installments < 1 || input.Installments > domain.MaxInstallments {
+			errors["installments"] = "installments must be between 1 and 12"
+		}
+	}
+
+	if input.CustomerID != "" {
+		if _, err := uuid.Parse(input.CustomerID); err != nil {
+			errors["customer_id"] = "invalid customer_id"
 		}
+	}
 
-		if len(input.CVV) < 3 || len(input.CVV) > 4 {
-			errors["cvv"] = "cvv must have 3 to 4 digits"
-		} else if !isDigits(input.CVV) {
-			errors["cvv"] = "cvv must contain only digits"
+	// sem currency a precisao e validada no service, com a moeda da conta.
+	if input.Currency != "" {
+		if currency, err := domain.NormalizeCurrency(input.Currency); err != nil {
+			errors["currency"] = "unsupported currency"
+		} else if input.Amount > 0 && !domain.HasValidPrecision(input.Amount, currency) {
+			errors["amount"] = "amount has more decimal places than currency allows"
 		}
+	}
 
-		if input.ExpiryMonth < 1 || input.ExpiryMonth > 12 {
-			errors["expiry_month"] = "expiry_month must be between 1 and 12"
+	if input.PaymentType == domain.PaymentTypeBoleto {
+		if _, ok := errors["currency"]; !ok && input.Currency != "" && strings.ToUpper(input.Currency) != domain.BoletoCurrency {
+			errors["currency"] = "boleto is only available in BRL"
+		}
+		if input.DueDate != "" {
+			today := domain.BoletoToday(time.Now())
+			if dueDate, err := time.Parse("2006-01-02", input.DueDate); err != nil {
+				errors["due_date"] = "due_date must be YYYY-MM-DD"
+			} else if dueDate.Before(today) {
+				errors["due_date"] = "due_date must not be in the past"
+			} else if dueDate.After(today.AddDate(0, 0, maxBoletoDueDays)) {
+				errors["due_date"] = "due_date must be within 180 days"
+			}
 		}
+	} else if input.DueDate != "" {
+		errors["due_date"] = "due_date is only available for boleto"
+	}
 
-		currentTime := time.Now()
-		if input.ExpiryYear < currentTime.Year() {
-			errors["expiry_year"] = "card expired"
-		} else if input.ExpiryYear == currentTime.Year() && input.ExpiryMonth < int(currentTime.Month()) {
-			errors["expiry_year"] = "card expired"
+	if input.PaymentType == domain.PaymentTypePix {
+		if _, ok := errors["currency"]; !ok && input.Currency != "" && strings.ToUpper(input.Currency) != domain.PixCurrency {
+			errors["currency"] = "pix is only available in BRL"
+		}
+		if input.PixExpiresIn != 0 && (input.PixExpiresIn < minPixExpiresIn || input.PixExpiresIn > maxPixExpiresIn) {
+			errors["pix_expires_in"] = "pix_expires_in must be between 60 and 86400 seconds"
 		}
+	} else if input.PixExpiresIn != 0 {
+		errors["pix_expires_in"] = "pix_expires_in is only available for pix"
+	}
 
-		if strings.TrimSpace(input.CardholderName) == "" {
-			errors["cardholder_name"] = "cardholder_name is required"
+	if input.CaptureMethod != "" {
+		if !domain.CaptureMethod(input.CaptureMethod).Valid() {
+			errors["capture_method"] = "capture_method must be automatic or manual"
+		} else if input.CaptureMethod == string(domain.CaptureManual) && input.PaymentType != "credit_card" {
+			errors["capture_method"] = "manual capture is only available for credit_card"
 		}
 	}
 
@@ -82,6 +146,696 @@ func validateCreateInvoiceInput(input dto.CreateInvoiceInput) map[string]string
 	return errors
 }
 
+func validateCreateCardTokenInput(input dto.CreateCardTokenInput) map[string]string {
+	errors := make(map[string]string)
+
+	validateCardFields(errors, input.CardNumber, input.CVV, input.ExpiryMonth, input.ExpiryYear, input.CardholderName)
+
+	if len(errors) == 0 {
+		return nil
+	}
+
+	return errors
+}
+
+// validateCardFields valida os campos de cartao comuns a faturas e a tokenizacao: bandeira,
+// comprimento e CVV conforme a bandeira, Luhn, validade e portador.
+func validateCardFields(errors map[string]string, number, cvv string, expiryMonth, expiryYear int, cardholderName string) {
+	brand := domain.DetectCardBrand(number)
+	rule, knownBrand := domain.CardRule(brand)
+	if len(number) < 12 || len(number) > 19 {
+		errors["card_number"] = "card_number must have 12 to 19 digits"
+	} else if !isDigits(number) {
+		errors["card_number"] = "card_number must contain only digits"
+	} else if !knownBrand {
+		errors["card_number"] = "card brand must be visa, mastercard, amex, elo or hipercard"
+	} else if !rule.ValidLength(len(number)) {
+		errors["card_number"] = "card_number length is invalid for " + brand
+	} else if !domain.LuhnValid(number) {
+		errors["card_number"] = "invalid card_number"
+	}
+
+	if knownBrand && len(cvv) != rule.CVVLength {
+		errors["cvv"] = "cvv must have " + strconv.Itoa(rule.CVVLength) + " digits for " + brand
+	} else if len(cvv) < 3 || len(cvv) > 4 {
+		errors["cvv"] = "cvv must have 3 to 4 digits"
+	} else if !isDigits(cvv) {
+		errors["cvv"] = "cvv must contain only digits"
+	}
+
+	if expiryMonth < 1 || expiryMonth > 12 {
+		errors["expiry_month"] = "expiry_month must be between 1 and 12"
+	}
+
+	if domain.CardExpired(expiryMonth, expiryYear, time.Now()) {
+		errors["expiry_year"] = "card expired"
+	}
+
+	if strings.TrimSpace(cardholderName) == "" {
+		errors["cardholder_name"] = "cardholder_name is required"
+	}
+}
+
+// maxCustomerFieldLength limita nome, email e referencia externa do cliente.
+const maxCustomerFieldLength = 255
+
+func validateCreateCustomerInput(input dto.CreateCustomerInput) map[string]string {
+	errors := make(map[string]string)
+
+	if strings.TrimSpace(input.Name) == "" {
+		errors["name"] = "name is required"
+	}
+	validateCustomerFields(errors, &input.Name, &input.Email, &input.Document, &input.ExternalReference)
+
+	if len(errors) == 0 {
+		return nil
+	}
+
+	return errors
+}
+
+func validateUpdateCustomerInput(input dto.UpdateCustomerInput) map[string]string {
+	errors := make(map[string]string)
+
+	if input.Name != nil && strings.TrimSpace(*input.Name) == "" {
+		errors["name"] = "name cannot be empty"
+	}
+	validateCustomerFields(errors, input.Name, input.Email, input.Document, input.ExternalReference)
+
+	if len(errors) == 0 {
+		return nil
+	}
+
+	return errors
+}
+
+// validateCustomerFields valida os campos informados (nao nil) de criacao e atualizacao de cliente.
+// Email, document e external_reference vazios sao aceitos.
+func validateCustomerFields(errors map[string]string, name, email, document, externalReference *string) {
+	if name != nil && len(*name) > maxCustomerFieldLength {
+		errors["name"] = "name must have at most 255 characters"
+	}
+
+	if email != nil && strings.TrimSpace(*email) != "" {
+		if len(*email) > maxCustomerFieldLength {
+			errors["email"] = "email must have at most 255 characters"
+		} else if _, err := mail.ParseAddress(*email); err != nil {
+			errors["email"] = "invalid email"
+		}
+	}
+
+	if document != nil {
+		if _, _, err := domain.NormalizeDocument(*document); err != nil {
+			errors["document"] = "document must be a valid CPF or CNPJ"
+		}
+	}
+
+	if externalReference != nil && len(*externalReference) > maxCustomerFieldLength {
+		errors["external_reference"] = "external_reference must have at most 255 characters"
+	}
+}
+
+func parseListCustomersQuery(query url.Values) (domain.CustomerFilter, map[string]string) {
+	errors := make(map[string]string)
+	filter := domain.CustomerFilter{Limit: domain.DefaultCustomerPageSize}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > domain.MaxCustomerPageSize {
+			errors["limit"] = "limit must be between 1 and " + strconv.Itoa(domain.MaxCustomerPageSize)
+		} else {
+			filter.Limit = limit
+		}
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := dto.DecodeInvoiceCursor(value)
+		if err != nil {
+			errors["cursor"] = "invalid cursor"
+		} else {
+			filter.Cursor = cursor
+		}
+	}
+
+	// Os filtros comparam com os valores normalizados na gravacao.
+	filter.Email = strings.ToLower(strings.TrimSpace(query.Get("email")))
+	filter.ExternalReference = strings.TrimSpace(query.Get("external_reference"))
+
+	if len(errors) == 0 {
+		return filter, nil
+	}
+
+	return filter, errors
+}
+
+func validateCreatePlanInput(input dto.CreatePlanInput) map[string]string {
+	errors := make(map[string]string)
+
+	if strings.TrimSpace(input.Name) == "" {
+		errors["name"] = "name is required"
+	} else if len(input.Name) > maxCustomerFieldLength {
+		errors["name"] = "name must have at most 255 characters"
+	}
+
+	if input.Amount <= 0 {
+		errors["amount"] = "amount must be greater than zero"
+	}
+
+	if !domain.PlanInterval(input.Interval).Valid() {
+		errors["interval"] = "interval must be day, week, month or year"
+	}
+
+	if input.IntervalCount < 0 || input.IntervalCount > domain.MaxPlanIntervalCount {
+		errors["interval_count"] = "interval_count must be between 1 and " + strconv.Itoa(domain.MaxPlanIntervalCount)
+	}
+
+	if input.TrialDays < 0 || input.TrialDays > domain.MaxPlanTrialDays {
+		errors["trial_days"] = "trial_days must be between 0 and " + strconv.Itoa(domain.MaxPlanTrialDays)
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+
+	return errors
+}
+
+func validateCreateSubscriptionInput(input dto.CreateSubscriptionInput) map[string]string {
+	errors := make(map[string]string)
+
+	if _, err := uuid.Parse(input.PlanID); err != nil {
+		errors["plan_id"] = "invalid plan_id"
+	}
+
+	if _, err := uuid.Parse(input.CustomerID); err != nil {
+		errors["customer_id"] = "invalid customer_id"
+	}
+
+	if !domain.IsCardToken(input.CardToken) {
+		errors["card_token"] = "invalid card_token"
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+
+	return errors
+}
+
+func parseListSubscriptionsQuery(query url.Values) (domain.SubscriptionFilter, map[string]string) {
+	errors := make(map[string]string)
+	filter := domain.SubscriptionFilter{Limit: domain.DefaultSubscriptionPageSize}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > domain.MaxSubscriptionPageSize {
+			errors["limit"] = "limit must be between 1 and " + strconv.Itoa(domain.MaxSubscriptionPageSize)
+		} else {
+			filter.Limit = limit
+		}
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := dto.DecodeInvoiceCursor(value)
+		if err != nil {
+			errors["cursor"] = "invalid cursor"
+		} else {
+			filter.Cursor = cursor
+		}
+	}
+
+	if value := query.Get("status"); value != "" {
+		if !domain.SubscriptionStatus(value).Valid() {
+			errors["status"] = "status must be trialing, active, past_due or canceled"
+		} else {
+			filter.Status = domain.SubscriptionStatus(value)
+		}
+	}
+
+	if value := query.Get("customer_id"); value != "" {
+		if _, err := uuid.Parse(value); err != nil {
+			errors["customer_id"] = "invalid customer_id"
+		} else {
+			filter.CustomerID = value
+		}
+	}
+
+	if value := query.Get("plan_id"); value != "" {
+		if _, err := uuid.Parse(value); err != nil {
+			errors["plan_id"] = "invalid plan_id"
+		} else {
+			filter.PlanID = value
+		}
+	}
+
+	if len(errors) == 0 {
+		return filter, nil
+	}
+
+	return filter, errors
+}
+
+func validateCreateBankAccountInput(input dto.CreateBankAccountInput) map[string]string {
+	errors := make(map[string]string)
+
+	if strings.TrimSpace(input.HolderName) == "" {
+		errors["holder_name"] = "holder_name is required"
+	} else if len(input.HolderName) > maxCustomerFieldLength {
+		errors["holder_name"] = "holder_name must have at most 255 characters"
+	}
+
+	if document, _, err := domain.NormalizeDocument(input.HolderDocument); err != nil || document == "" {
+		errors["holder_document"] = "holder_document must be a valid CPF or CNPJ"
+	}
+
+	if len(input.BankCode) != 3 || !isDigits(input.BankCode) {
+		errors["bank_code"] = "bank_code must have 3 digits"
+	}
+
+	if input.Branch == "" || len(input.Branch) > 5 || !isDigits(input.Branch) {
+		errors["branch"] = "branch must have 1 to 5 digits"
+	}
+
+	if number := strings.TrimSpace(input.AccountNumber); number == "" || len(number) > 14 {
+		errors["account_number"] = "account_number must have up to 12 digits and an optional check digit"
+	}
+
+	if input.AccountType != domain.BankAccountChecking && input.AccountType != domain.BankAccountSavings {
+		errors["account_type"] = "account_type must be checking or savings"
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+
+	return errors
+}
+
+func validateCreatePayoutInput(input dto.CreatePayoutInput) map[string]string {
+	errors := make(map[string]string)
+
+	if _, err := uuid.Parse(input.BankAccountID); err != nil {
+		errors["bank_account_id"] = "invalid bank_account_id"
+	}
+
+	if input.Amount <= 0 {
+		errors["amount"] = "amount must be greater than zero"
+	}
+
+	if len(input.Description) > maxCustomerFieldLength {
+		errors["description"] = "description must have at most 255 characters"
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+
+	return errors
+}
+
+func validateUpdatePayoutStatusInput(input dto.UpdatePayoutStatusInput) map[string]string {
+	errors := make(map[string]string)
+
+	switch domain.PayoutStatus(input.Status) {
+	case domain.PayoutInTransit, domain.PayoutPaid:
+	case domain.PayoutFailed:
+		if strings.TrimSpace(input.FailureReason) == "" {
+			errors["failure_reason"] = "failure_reason is required when status is failed"
+		}
+	default:
+		errors["status"] = "status must be in_transit, paid or failed"
+	}
+
+	if len(input.FailureReason) > maxCustomerFieldLength {
+		errors["failure_reason"] = "failure_reason must have at most 255 characters"
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+
+	return errors
+}
+
+func parseListPayoutsQuery(query url.Values) (domain.PayoutFilter, map[string]string) {
+	errors := make(map[string]string)
+	filter := domain.PayoutFilter{Limit: domain.DefaultPayoutPageSize}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > domain.MaxPayoutPageSize {
+			errors["limit"] = "limit must be between 1 and " + strconv.Itoa(domain.MaxPayoutPageSize)
+		} else {
+			filter.Limit = limit
+		}
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := dto.DecodeInvoiceCursor(value)
+		if err != nil {
+			errors["cursor"] = "invalid cursor"
+		} else {
+			filter.Cursor = cursor
+		}
+	}
+
+	if value := query.Get("status"); value != "" {
+		if !domain.PayoutStatus(value).Valid() {
+			errors["status"] = "status must be pending, in_transit, paid or failed"
+		} else {
+			filter.Status = domain.PayoutStatus(value)
+		}
+	}
+
+	if len(errors) == 0 {
+		return filter, nil
+	}
+
+	return filter, errors
+}
+
+// validateCreateWebhookEndpointInput exige https no modo live; http so e aceito com chave de teste.
+func validateCreateWebhookEndpointInput(input dto.CreateWebhookEndpointInput, mode domain.Mode) map[string]string {
+	errors := make(map[string]string)
+
+	if strings.TrimSpace(input.URL) == "" {
+		errors["url"] = "url is required"
+	} else if parsed, err := url.Parse(input.URL); err != nil || parsed.Host == "" || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		errors["url"] = "url must be an absolute http or https url"
+	} else if parsed.Scheme != "https" && mode != domain.ModeTest {
+		errors["url"] = "url must use https"
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+
+	return errors
+}
+
+func validateCreateAPIKeyInput(input dto.CreateAPIKeyInput, now time.Time) map[string]string {
+	errors := make(map[string]string)
+
+	if len(strings.TrimSpace(input.Label)) > 100 {
+		errors["label"] = "label must have at most 100 characters"
+	}
+
+	if len(input.Scopes) == 0 {
+		errors["scopes"] = "at least one scope is required"
+	} else {
+		for _, scope := range input.Scopes {
+			if !domain.ValidScope(scope) {
+				errors["scopes"] = "unknown scope: " + scope
+				break
+			}
+		}
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(now) {
+		errors["expires_at"] = "expires_at must be in the future"
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+
+	return errors
+}
+
+func validateSetAccountLimitInput(input dto.SetAccountLimitInput) map[string]string {
+	errors := validateLimitTarget(input.Mode, input.Currency, input.Reason)
+
+	limits := map[string]*int64{
+		"max_amount_per_tx_cents": input.MaxAmountPerTxCents,
+		"max_daily_volume_cents":  input.MaxDailyVolumeCents,
+		"max_daily_transactions":  input.MaxDailyTransactions,
+	}
+	for field, value := range limits {
+		if value == nil {
+			errors[field] = field + " is required"
+		} else if *value < 0 {
+			errors[field] = field + " must be zero (no limit) or greater"
+		}
+	}
+
+	for i, rule := range input.Rules {
+		field := "rules[" + strconv.Itoa(i) + "]"
+		switch {
+		case !rule.Window.Valid():
+			errors[field] = "window must be transaction, day, month, rolling_1h, rolling_24h or rolling_7d"
+		case rule.PaymentType != "" && !domain.ValidLimitPaymentType(rule.PaymentType):
+			errors[field] = "payment_type must be credit_card, boleto or pix"
+		case rule.MaxVolumeCents < 0 || rule.MaxTransactions < 0:
+			errors[field] = "limits must be zero (no limit) or greater"
+		case rule.Window == domain.LimitWindowTransaction && rule.MaxTransactions > 0:
+			errors[field] = "transaction window only accepts max_volume_cents"
+		case rule.MaxVolumeCents == 0 && rule.MaxTransactions == 0:
+			errors[field] = "max_volume_cents or max_transactions is required"
+		}
+	}
+
+	if input.Enforcement != "" && !domain.LimitEnforcement(input.Enforcement).Valid() {
+		errors["enforcement"] = "enforcement must be hard or soft"
+	}
+
+	if input.Timezone != "" {
+		if _, err := time.LoadLocation(input.Timezone); err != nil {
+			errors["timezone"] = "unknown timezone"
+		}
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+
+	return errors
+}
+
+func validateResetAccountLimitInput(input dto.ResetAccountLimitInput) map[string]string {
+	errors := validateLimitTarget(input.Mode, input.Currency, input.Reason)
+	if len(errors) == 0 {
+		return nil
+	}
+
+	return errors
+}
+
+// maxFeeBps limita percentuais de tarifa a 100%.
+const maxFeeBps = 10000
+
+func validateSetPricingPlanInput(input dto.SetPricingPlanInput) map[string]string {
+	errors := validatePricingTarget(input.Mode, input.Currency)
+
+	if input.Rates == nil {
+		errors["rates"] = "rates is required"
+	}
+	for paymentType, rate := range input.Rates {
+		field := "rates." + paymentType
+		switch {
+		case !domain.ValidPaymentType(paymentType):
+			errors[field] = "payment type must be credit_card, boleto or pix"
+		case rate.PercentBps < 0 || rate.PercentBps > maxFeeBps:
+			errors[field] = "percent_bps must be between 0 and 10000"
+		case rate.FixedCents < 0:
+			errors[field] = "fixed_cents must be zero or greater"
+		case len(rate.InstallmentSurchargesBps) > 0 && paymentType != domain.PaymentTypeCreditCard:
+			errors[field] = "installment_surcharges_bps is only available for credit_card"
+		}
+		for installments, bps := range rate.InstallmentSurchargesBps {
+			if _, invalid := errors[field]; invalid {
+				break
+			}
+			if installments < 2 || installments > domain.MaxInstallments || bps < 0 || bps > maxFeeBps {
+				errors[field] = "installment_surcharges_bps needs installments between 2 and 12 and bps between 0 and 10000"
+			}
+		}
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+
+	return errors
+}
+
+// validatePricingTarget valida o modo e a moeda de um plano de tarifas.
+func validatePricingTarget(mode, currency string) map[string]string {
+	errors := make(map[string]string)
+
+	if !domain.Mode(mode).Valid() {
+		errors["mode"] = "mode must be live or test"
+	}
+
+	if strings.TrimSpace(currency) == "" {
+		errors["currency"] = "currency is required"
+	} else if _, err := domain.NormalizeCurrency(currency); err != nil {
+		errors["currency"] = "unsupported currency"
+	}
+
+	return errors
+}
+
+// validateLimitTarget valida o modo, a moeda e o motivo comuns as alteracoes de limites.
+func validateLimitTarget(mode, currency, reason string) map[string]string {
+	errors := make(map[string]string)
+
+	if !domain.Mode(mode).Valid() {
+		errors["mode"] = "mode must be live or test"
+	}
+
+	if strings.TrimSpace(currency) == "" {
+		errors["currency"] = "currency is required"
+	} else if _, err := domain.NormalizeCurrency(currency); err != nil {
+		errors["currency"] = "unsupported currency"
+	}
+
+	if strings.TrimSpace(reason) == "" {
+		errors["reason"] = "reason is required"
+	} else if len(reason) > 500 {
+		errors["reason"] = "reason must have at most 500 characters"
+	}
+
+	return errors
+}
+
+// validateReviewInvoiceInput exige a decisao do operador e o motivo, gravado no evento da fatura.
+func validateReviewInvoiceInput(input dto.ReviewInvoiceInput) map[string]string {
+	errors := make(map[string]string)
+
+	if input.Decision != "approve" && input.Decision != "reject" {
+		errors["decision"] = "decision must be approve or reject"
+	}
+	if strings.TrimSpace(input.Reason) == "" {
+		errors["reason"] = "reason is required"
+	} else if len(input.Reason) > 500 {
+		errors["reason"] = "reason must have at most 500 characters"
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
+
+func parseListInvoicesQuery(query url.Values, accountCurrency string) (domain.InvoiceFilter, map[string]string) {
+	errors := make(map[string]string)
+	filter := domain.InvoiceFilter{Limit: domain.DefaultInvoicePageSize}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > domain.MaxInvoicePageSize {
+			errors["limit"] = "limit must be between 1 and " + strconv.Itoa(domain.MaxInvoicePageSize)
+		} else {
+			filter.Limit = limit
+		}
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := dto.DecodeInvoiceCursor(value)
+		if err != nil {
+			errors["cursor"] = "invalid cursor"
+		} else {
+			filter.Cursor = cursor
+		}
+	}
+
+	if value := query.Get("status"); value != "" {
+		if !domain.Status(value).Valid() {
+			errors["status"] = "invalid status"
+		} else {
+			filter.Status = domain.Status(value)
+		}
+	}
+
+	if value := query.Get("payment_type"); value != "" {
+		if !domain.ValidPaymentType(value) {
+			errors["payment_type"] = "payment_type must be credit_card, boleto or pix"
+		} else {
+			filter.PaymentType = value
+		}
+	}
+
+	if value := query.Get("card_brand"); value != "" {
+		if _, ok := domain.CardRule(value); !ok {
+			errors["card_brand"] = "card_brand must be visa, mastercard, amex, elo or hipercard"
+		} else {
+			filter.CardBrand = value
+		}
+	}
+
+	if value := query.Get("customer_id"); value != "" {
+		if _, err := uuid.Parse(value); err != nil {
+			errors["customer_id"] = "invalid customer_id"
+		} else {
+			filter.CustomerID = value
+		}
+	}
+
+	if value := query.Get("created_from"); value != "" {
+		createdFrom, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			errors["created_from"] = "created_from must be RFC3339"
+		} else {
+			filter.CreatedFrom = &createdFrom
+		}
+	}
+
+	if value := query.Get("created_to"); value != "" {
+		createdTo, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			errors["created_to"] = "created_to must be RFC3339"
+		} else {
+			filter.CreatedTo = &createdTo
+		}
+	}
+
+	if value := query.Get("currency"); value != "" {
+		currency, err := domain.NormalizeCurrency(value)
+		if err != nil {
+			errors["currency"] = "unsupported currency"
+		} else {
+			filter.Currency = currency
+		}
+	}
+	// min_amount/max_amount usam as casas decimais de currency ou, sem ela, da moeda da conta.
+	amountCurrency := filter.Currency
+	if amountCurrency == "" {
+		amountCurrency = accountCurrency
+	}
+	if amountCurrency == "" {
+		amountCurrency = domain.DefaultCurrency
+	}
+
+	for field, bound := range map[string]**int64{"min_amount": &filter.MinAmountCents, "max_amount": &filter.MaxAmountCents} {
+		value := query.Get(field)
+		if value == "" {
+			continue
+		}
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil || amount < 0 {
+			errors[field] = field + " must be a non-negative number"
+		} else if !domain.HasValidPrecision(amount, amountCurrency) {
+			errors[field] = field + " has more decimal places than currency allows"
+		} else {
+			cents := domain.AmountToMinor(amount, amountCurrency)
+			*bound = &cents
+		}
+	}
+
+	if filter.MinAmountCents != nil && filter.MaxAmountCents != nil && *filter.MinAmountCents > *filter.MaxAmountCents {
+		errors["max_amount"] = "max_amount must be greater than or equal to min_amount"
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		errors["created_to"] = "created_to must be after created_from"
+	}
+
+	if len(errors) == 0 {
+		return filter, nil
+	}
+
+	return filter, errors
+}
+
 func isDigits(value string) bool {
 	for _, r := range value {
 		if !unicode.IsDigit(r) {
@@ -90,3 +844,38 @@ func isDigits(value string) bool {
 	}
 	return true
 }
+
+// maxBoletoSettlementBatch limita os pagamentos de um lote de liquidacao.
+const maxBoletoSettlementBatch = 1000
+
+func validateSettleBoletosInput(input dto.SettleBoletosInput) map[string]string {
+	errors := make(map[string]string)
+
+	if len(input.Payments) == 0 {
+		errors["payments"] = "payments is required"
+	} else if len(input.Payments) > maxBoletoSettlementBatch {
+		errors["payments"] = "payments must have at most 1000 items"
+	}
+
+	for i, payment := range input.Payments {
+		field := "payments[" + strconv.Itoa(i) + "]"
+		if strings.TrimSpace(payment.Code) == "" {
+			errors[field+".code"] = "code is required"
+		}
+		if payment.PaidAmount <= 0 {
+			errors[field+".paid_amount"] = "paid_amount must be greater than zero"
+		} else if !domain.HasValidPrecision(payment.PaidAmount, domain.BoletoCurrency) {
+			errors[field+".paid_amount"] = "paid_amount has more decimal places than currency allows"
+		}
+	}
+
+	if len(input.Source) > 50 {
+		errors["source"] = "source must have at most 50 characters"
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+
+	return errors
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+)
+
+func TestValidateCreateWebhookEndpointInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		url   string
+		mode  domain.Mode
+		valid bool
+	}{
+		{"https live", "https://merchant.example/webhooks", domain.ModeLive, true},
+		{"http live", "http://merchant.example/webhooks", domain.ModeLive, false},
+		{"http test", "http://merchant.example/webhooks", domain.ModeTest, true},
+		{"relative", "/webhooks", domain.ModeTest, false},
+		{"other scheme", "ftp://merchant.example", domain.ModeTest, false},
+		{"empty", " ", domain.ModeLive, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors := validateCreateWebhookEndpointInput(dto.CreateWebhookEndpointInput{URL: tt.url}, tt.mode)
+			if valid := errors == nil; valid != tt.valid {
+				t.Fatalf("expected valid %v, got errors %v", tt.valid, errors)
+			}
+		})
+	}
+}
+
+func TestParseListInvoicesQueryUsesCurrencyDecimals(t *testing.T) {
+	tests := []struct {
+		name            string
+		query           string
+		accountCurrency string
+		minCents        int64
+		invalid         bool
+	}{
+		{"account currency", "min_amount=1500", "JPY", 1500, false},
+		{"explicit currency", "currency=BRL&min_amount=15", "JPY", 1500, false},
+		{"three decimals", "currency=BHD&min_amount=1.5", "", 1500, false},
+		{"default BRL", "min_amount=15", "", 1500, false},
+		{"too many decimals", "min_amount=15.5", "JPY", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filter, errors := parseListInvoicesQuery(query, tt.accountCurrency)
+			if tt.invalid {
+				if errors["min_amount"] == "" {
+					t.Fatalf("expected min_amount error, got %v", errors)
+				}
+				return
+			}
+			if errors != nil {
+				t.Fatalf("unexpected errors: %v", errors)
+			}
+			if filter.MinAmountCents == nil || *filter.MinAmountCents != tt.minCents {
+				t.Fatalf("expected min %d, got %v", tt.minCents, filter.MinAmountCents)
+			}
+		})
+	}
+}
+
+func TestValidateReviewInvoiceInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  dto.ReviewInvoiceInput
+		fields []string
+	}{
+		{"approve", dto.ReviewInvoiceInput{Decision: "approve", Reason: "customer confirmed"}, nil},
+		{"reject", dto.ReviewInvoiceInput{Decision: "reject", Reason: "volume spike"}, nil},
+		{"unknown decision", dto.ReviewInvoiceInput{Decision: "approved", Reason: "ok"}, []string{"decision"}},
+		{"missing reason", dto.ReviewInvoiceInput{Decision: "approve", Reason: " "}, []string{"reason"}},
+		{"long reason", dto.ReviewInvoiceInput{Decision: "reject", Reason: strings.Repeat("x", 501)}, []string{"reason"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors := validateReviewInvoiceInput(tt.input)
+			if len(errors) != len(tt.fields) {
+				t.Fatalf("expected errors on %v, got %v", tt.fields, errors)
+			}
+			for _, field := range tt.fields {
+				if errors[field] == "" {
+					t.Fatalf("expected error on %s, got %v", field, errors)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSetPricingPlanInput(t *testing.T) {
+	card := func(rate domain.FeeRate) map[string]domain.FeeRate {
+		return map[string]domain.FeeRate{domain.PaymentTypeCreditCard: rate}
+	}
+	tests := []struct {
+		name   string
+		input  dto.SetPricingPlanInput
+		fields []string
+	}{
+		{"valid", dto.SetPricingPlanInput{Mode: "live", Currency: "BRL", Rates: card(domain.FeeRate{PercentBps: 299, FixedCents: 39})}, nil},
+		{"empty rates", dto.SetPricingPlanInput{Mode: "test", Currency: "usd", Rates: map[string]domain.FeeRate{}}, nil},
+		{"missing rates", dto.SetPricingPlanInput{Mode: "live", Currency: "BRL"}, []string{"rates"}},
+		{"invalid target", dto.SetPricingPlanInput{Mode: "sandbox", Currency: "XXX", Rates: map[string]domain.FeeRate{}}, []string{"mode", "currency"}},
+		{"missing currency", dto.SetPricingPlanInput{Mode: "live", Currency: " ", Rates: map[string]domain.FeeRate{}}, []string{"currency"}},
+		{"unknown payment type", dto.SetPricingPlanInput{Mode: "live", Currency: "BRL", Rates: map[string]domain.FeeRate{"ted": {}}}, []string{"rates.ted"}},
+		{"percent above 100%", dto.SetPricingPlanInput{Mode: "live", Currency: "BRL", Rates: card(domain.FeeRate{PercentBps: 10001})}, []string{"rates.credit_card"}},
+		{"negative fixed", dto.SetPricingPlanInput{Mode: "live", Currency: "BRL", Rates: card(domain.FeeRate{FixedCents: -1})}, []string{"rates.credit_card"}},
+		{"surcharges", dto.SetPricingPlanInput{Mode: "live", Currency: "BRL", Rates: card(domain.FeeRate{InstallmentSurchargesBps: map[int]int64{2: 150, 12: 10000}})}, nil},
+		{"surcharge outside card", dto.SetPricingPlanInput{Mode: "live", Currency: "BRL", Rates: map[string]domain.FeeRate{
+			domain.PaymentTypeBoleto: {InstallmentSurchargesBps: map[int]int64{2: 150}},
+		}}, []string{"rates.boleto"}},
+		{"surcharge on single installment", dto.SetPricingPlanInput{Mode: "live", Currency: "BRL", Rates: card(domain.FeeRate{InstallmentSurchargesBps: map[int]int64{1: 150}})}, []string{"rates.credit_card"}},
+		{"surcharge above max installments", dto.SetPricingPlanInput{Mode: "live", Currency: "BRL", Rates: card(domain.FeeRate{InstallmentSurchargesBps: map[int]int64{13: 150}})}, []string{"rates.credit_card"}},
+		{"negative surcharge", dto.SetPricingPlanInput{Mode: "live", Currency: "BRL", Rates: card(domain.FeeRate{InstallmentSurchargesBps: map[int]int64{3: -1}})}, []string{"rates.credit_card"}},
+		{"surcharge above 100%", dto.SetPricingPlanInput{Mode: "live", Currency: "BRL", Rates: card(domain.FeeRate{InstallmentSurchargesBps: map[int]int64{3: 10001}})}, []string{"rates.credit_card"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors := validateSetPricingPlanInput(tt.input)
+			if len(errors) != len(tt.fields) {
+				t.Fatalf("expected errors on %v, got %v", tt.fields, errors)
+			}
+			for _, field := range tt.fields {
+				if errors[field] == "" {
+					t.Fatalf("expected error on %s, got %v", field, errors)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/dto"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/service"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/middleware"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/response"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/webhook"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// WebhookHandler processa requisições HTTP de endpoints de webhook
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+// NewWebhookHandler cria um novo handler de webhooks
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// Create cadastra um endpoint de webhook.
+// @Summary Cadastrar webhook
+// @Description Cadastra uma URL para receber eventos de mudanca de status das faturas do modo da chave. O secret de assinatura so e retornado nesta resposta. Exige https no modo live; enderecos de loopback, redes privadas e link-local sao recusados.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param request body dto.CreateWebhookEndpointInput true "Webhook payload"
+// @Success 201 {object} dto.WebhookEndpointOutput
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /webhooks [post]
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	var input dto.CreateWebhookEndpointInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid_payload", "invalid request payload", nil)
+		return
+	}
+
+	if validationErrors := validateCreateWebhookEndpointInput(input, principal.Mode); validationErrors != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid webhook data", validationErrors)
+		return
+	}
+
+	output, err := h.webhookService.CreateEndpoint(r.Context(), principal.AccountID, principal.Mode, input)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, output)
+}
+
+// List lista os endpoints de webhook da conta.
+// @Summary Listar webhooks
+// @Tags webhooks
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Success 200 {array} dto.WebhookEndpointOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /webhooks [get]
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	output, err := h.webhookService.ListEndpoints(r.Context(), principal.AccountID, principal.Mode)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+// Delete desativa um endpoint de webhook.
+// @Summary Remover webhook
+// @Tags webhooks
+// @Param X-API-KEY header string true "API key"
+// @Param id path string true "Webhook endpoint ID"
+// @Success 204
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		response.Error(w, http.StatusNotFound, "webhook_not_found", "webhook endpoint not found", nil)
+		return
+	}
+
+	if err := h.webhookService.DeleteEndpoint(r.Context(), principal.AccountID, principal.Mode, id); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries lista as tentativas de entrega de um endpoint.
+// @Summary Listar entregas do webhook
+// @Tags webhooks
+// @Produce json
+// @Param X-API-KEY header string true "API key"
+// @Param id path string true "Webhook endpoint ID"
+// @Success 200 {array} dto.WebhookDeliveryOutput
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		response.Error(w, http.StatusNotFound, "webhook_not_found", "webhook endpoint not found", nil)
+		return
+	}
+
+	output, err := h.webhookService.ListDeliveries(r.Context(), principal.AccountID, principal.Mode, id)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, output)
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrAccountNotFound:
+		response.Error(w, http.StatusUnauthorized, "invalid_api_key", "invalid api key", nil)
+	case domain.ErrWebhookEndpointNotFound:
+		response.Error(w, http.StatusNotFound, "webhook_not_found", "webhook endpoint not found", nil)
+	case webhook.ErrBlockedTarget:
+		response.Error(w, http.StatusUnprocessableEntity, "validation_error", "invalid webhook data", map[string]string{"url": "url must point to a public address"})
+	default:
+		response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/security"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/response"
+)
+
+type adminContextKey struct{}
+
+type AdminAuthMiddleware struct {
+	keys []security.AdminKey
+}
+
+func NewAdminAuthMiddleware(keys []security.AdminKey) *AdminAuthMiddleware {
+	return &AdminAuthMiddleware{keys: keys}
+}
+
+// Enabled indica se ha alguma chave admin configurada; sem chaves as rotas admin nao sao expostas.
+func (m *AdminAuthMiddleware) Enabled() bool {
+	return len(m.keys) > 0
+}
+
+// Authenticate valida o header X-ADMIN-KEY e guarda o nome do operador no contexto.
+func (m *AdminAuthMiddleware) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adminKey := r.Header.Get("X-ADMIN-KEY")
+		if adminKey == "" {
+			response.Error(w, http.StatusUnauthorized, "admin_key_required", "admin key is required", nil)
+			return
+		}
+
+		name, ok := security.MatchAdminKey(m.keys, adminKey)
+		if !ok {
+			response.Error(w, http.StatusUnauthorized, "invalid_admin_key", "invalid admin key", nil)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), adminContextKey{}, name)))
+	})
+}
+
+// AdminFromContext retorna o operador autenticado por AdminAuthMiddleware.Authenticate
+func AdminFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(adminContextKey{}).(string)
+	return name, ok && name != ""
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/ratelimit"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/security"
+)
+
+func TestAdminAuthRejectsMissingAndInvalidKeys(t *testing.T) {
+	m := NewAdminAuthMiddleware([]security.AdminKey{{Name: "ops", Key: "secret"}})
+	var operator string
+	handler := m.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operator, _ = AdminFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name   string
+		key    string
+		status int
+	}{
+		{"missing", "", http.StatusUnauthorized},
+		{"invalid", "guess", http.StatusUnauthorized},
+		{"valid", "secret", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/accounts/acc-1/limits", nil)
+			if tt.key != "" {
+				req.Header.Set("X-ADMIN-KEY", tt.key)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+	if operator != "ops" {
+		t.Fatalf("expected operator ops in context, got %q", operator)
+	}
+}
+
+func TestAdminAuthFailuresAreRateLimitedPerIP(t *testing.T) {
+	plans := ratelimit.NewPlans(nil, ratelimit.Limit{PerMinute: 60, Burst: 3})
+	limit := NewRateLimitMiddleware(ratelimit.NewMemoryLimiter(), plans, nil)
+	auth := NewAdminAuthMiddleware([]security.AdminKey{{Name: "ops", Key: "secret"}})
+	handler := limit.Limit(auth.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	codes := make([]int, 0, 4)
+	for i := 0; i < 4; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/admin/accounts/acc-1/limits", nil)
+		req.RemoteAddr = "203.0.113.7:4000"
+		req.Header.Set("X-ADMIN-KEY", "guess")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+
+	want := []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, codes)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
@@ -8,16 +9,20 @@ import (
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/response"
 )
 
+type principalContextKey struct{}
+
 type AuthMiddleware struct {
-	accountService *service.AccountService
+	apiKeyService *service.APIKeyService
 }
 
-func NewAuthMiddleware(accountService *service.AccountService) *AuthMiddleware {
+func NewAuthMiddleware(apiKeyService *service.APIKeyService) *AuthMiddleware {
 	return &AuthMiddleware{
-		accountService: accountService,
+		apiKeyService: apiKeyService,
 	}
 }
 
+// Authenticate valida a API key (ativa, nao revogada nem expirada) e guarda o principal no contexto.
+// Handlers e services usam o principal; nenhuma outra camada resolve a chave de novo.
 func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		apiKey := r.Header.Get("X-API-KEY")
@@ -26,17 +31,50 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		_, err := m.accountService.FindByAPIKey(apiKey)
+		principal, err := m.apiKeyService.Authenticate(apiKey)
 		if err != nil {
-			if err == domain.ErrAccountNotFound {
+			switch err {
+			case domain.ErrAPIKeyNotFound:
 				response.Error(w, http.StatusUnauthorized, "invalid_api_key", "invalid api key", nil)
-				return
+			case domain.ErrAPIKeyRevoked:
+				response.Error(w, http.StatusUnauthorized, "api_key_revoked", "api key revoked", nil)
+			case domain.ErrAPIKeyExpired:
+				response.Error(w, http.StatusUnauthorized, "api_key_expired", "api key expired", nil)
+			default:
+				response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
 			}
-
-			response.Error(w, http.StatusInternalServerError, "internal_error", "internal server error", nil)
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
 	})
 }
+
+// RequireScope exige que a API key autenticada conceda o escopo. Deve vir depois de Authenticate.
+func (m *AuthMiddleware) RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				response.Error(w, http.StatusUnauthorized, "api_key_required", "api key is required", nil)
+				return
+			}
+			if !principal.HasScope(scope) {
+				response.Error(w, http.StatusForbidden, "insufficient_scope", "api key lacks required scope", map[string]string{"scope": scope})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WithPrincipal guarda o principal autenticado no contexto
+func WithPrincipal(ctx context.Context, principal *domain.Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext retorna o principal autenticado por Authenticate
+func PrincipalFromContext(ctx context.Context) (*domain.Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*domain.Principal)
+	return principal, ok && principal != nil
+}
@@ -1,58 +1,88 @@
 package middleware
 
 import (
+	"fmt"
+	"log/slog"
+	"math"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/ratelimit"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/response"
 )
 
 type RateLimitMiddleware struct {
-	limiter *rateLimiter
+	limiter        ratelimit.RateLimiter
+	plans          *ratelimit.Plans
+	trustedProxies []*net.IPNet
 }
 
-type rateLimiter struct {
-	ratePerSecond float64
-	burst         float64
-	mu            sync.Mutex
-	clients       map[string]*clientLimiter
+// NewRateLimitMiddleware cria o middleware. X-Forwarded-For e X-Real-IP so sao lidos quando a
+// conexao vem de um dos trustedProxies; sem a lista, o IP do cliente e o RemoteAddr.
+func NewRateLimitMiddleware(limiter ratelimit.RateLimiter, plans *ratelimit.Plans, trustedProxies []*net.IPNet) *RateLimitMiddleware {
+	return &RateLimitMiddleware{limiter: limiter, plans: plans, trustedProxies: trustedProxies}
 }
 
-type clientLimiter struct {
-	tokens   float64
-	lastSeen time.Time
-	lastTime time.Time
-}
-
-func NewRateLimitMiddleware(ratePerMinute int, burst int) *RateLimitMiddleware {
-	if ratePerMinute <= 0 {
-		ratePerMinute = 60
-	}
-	if burst <= 0 {
-		burst = 10
+// ParseTrustedProxies le TRUSTED_PROXIES no formato "10.0.0.0/8,192.168.1.10" (CIDRs ou IPs).
+func ParseTrustedProxies(raw string) ([]*net.IPNet, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
 	}
 
-	limiter := &rateLimiter{
-		ratePerSecond: float64(ratePerMinute) / 60.0,
-		burst:         float64(burst),
-		clients:       make(map[string]*clientLimiter),
+	proxies := make([]*net.IPNet, 0)
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", item)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", item)
+		}
+		proxies = append(proxies, network)
 	}
-
-	return &RateLimitMiddleware{limiter: limiter}
+	return proxies, nil
 }
 
+// Limit aplica o limite da conta autenticada (por conta e modo) ou, sem principal, o limite
+// global por IP. Falhas do limiter liberam a requisicao para nao derrubar a API.
 func (m *RateLimitMiddleware) Limit(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		key := clientKeyFromRequest(r)
+		key, limit, err := m.clientLimit(r)
+		if err != nil {
+			slog.Error("rate limit plan lookup failed", "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
 		if key == "" {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		if !m.limiter.allow(key) {
+		result, err := m.limiter.Allow(r.Context(), key, limit)
+		if err != nil {
+			slog.Error("rate limiter failed", "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		if !result.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
 			response.Error(w, http.StatusTooManyRequests, "rate_limited", "too many requests", nil)
 			return
 		}
@@ -61,55 +91,61 @@ func (m *RateLimitMiddleware) Limit(next http.Handler) http.Handler {
 	})
 }
 
-func clientKeyFromRequest(r *http.Request) string {
-	if apiKey := r.Header.Get("X-API-KEY"); apiKey != "" {
-		return apiKey
+func (m *RateLimitMiddleware) clientLimit(r *http.Request) (string, ratelimit.Limit, error) {
+	if principal, ok := PrincipalFromContext(r.Context()); ok {
+		limit, err := m.plans.ForAccount(r.Context(), principal.AccountID)
+		return "account:" + principal.AccountID + ":" + string(principal.Mode), limit, err
 	}
-	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
-		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
-	}
-	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
-		return realIP
+
+	ip := m.clientIP(r)
+	if ip == "" {
+		return "", ratelimit.Limit{}, nil
 	}
+	return "ip:" + ip, m.plans.Default(), nil
+}
+
+// clientIP retorna o RemoteAddr ou, se ele for um proxy confiavel, o ultimo endereco de
+// X-Forwarded-For que nao e proxy confiavel (os anteriores podem ter sido forjados pelo cliente).
+func (m *RateLimitMiddleware) clientIP(r *http.Request) string {
 	host, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
-		return r.RemoteAddr
+		host = r.RemoteAddr
+	}
+	if !m.trusted(host) {
+		return host
 	}
-	return host
-}
-
-func (l *rateLimiter) allow(key string) bool {
-	now := time.Now()
-
-	l.mu.Lock()
-	defer l.mu.Unlock()
 
-	client, ok := l.clients[key]
-	if !ok {
-		l.clients[key] = &clientLimiter{
-			tokens:   l.burst - 1,
-			lastSeen: now,
-			lastTime: now,
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop != "" && !m.trusted(hop) {
+				return hop
+			}
 		}
-		return true
 	}
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
+		return realIP
+	}
+	return host
+}
 
-	elapsed := now.Sub(client.lastTime).Seconds()
-	client.tokens = min(l.burst, client.tokens+(elapsed*l.ratePerSecond))
-	client.lastTime = now
-	client.lastSeen = now
-
-	if client.tokens < 1 {
+func (m *RateLimitMiddleware) trusted(host string) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
 		return false
 	}
-
-	client.tokens -= 1
-	return true
+	for _, network := range m.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
 }
 
-func min(a, b float64) float64 {
-	if a < b {
-		return a
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
 	}
-	return b
+	return int(math.Ceil(d.Seconds()))
 }
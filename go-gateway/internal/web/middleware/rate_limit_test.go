@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/ratelimit"
+)
+
+func serveLimited(t *testing.T, m *RateLimitMiddleware, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+	t.Helper()
+	handler := m.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/accounts", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimitSetsHeadersAndRetryAfter(t *testing.T) {
+	plans := ratelimit.NewPlans(nil, ratelimit.Limit{PerMinute: 60, Burst: 2})
+	m := NewRateLimitMiddleware(ratelimit.NewMemoryLimiter(), plans, nil)
+
+	first := serveLimited(t, m, "203.0.113.7:4000", "")
+	if first.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", first.Code)
+	}
+	if got := first.Header().Get("RateLimit-Limit"); got != "2" {
+		t.Fatalf("expected RateLimit-Limit 2, got %q", got)
+	}
+	if got := first.Header().Get("RateLimit-Remaining"); got != "1" {
+		t.Fatalf("expected RateLimit-Remaining 1, got %q", got)
+	}
+	if got := first.Header().Get("RateLimit-Reset"); got != "1" {
+		t.Fatalf("expected RateLimit-Reset 1, got %q", got)
+	}
+	if first.Header().Get("Retry-After") != "" {
+		t.Fatal("expected no Retry-After on an allowed request")
+	}
+
+	serveLimited(t, m, "203.0.113.7:4000", "")
+	limited := serveLimited(t, m, "203.0.113.7:4000", "")
+	if limited.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", limited.Code)
+	}
+	if got := limited.Header().Get("Retry-After"); got != "1" {
+		t.Fatalf("expected Retry-After 1, got %q", got)
+	}
+	if got := limited.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Fatalf("expected RateLimit-Remaining 0, got %q", got)
+	}
+	if got := limited.Header().Get("RateLimit-Reset"); got != "2" {
+		t.Fatalf("expected RateLimit-Reset 2, got %q", got)
+	}
+}
+
+func TestRateLimitOnlyTrustsForwardedForFromTrustedProxies(t *testing.T) {
+	plans := ratelimit.NewPlans(nil, ratelimit.Limit{PerMinute: 60, Burst: 1})
+	proxies, err := ParseTrustedProxies("10.0.0.0/8")
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	m := NewRateLimitMiddleware(ratelimit.NewMemoryLimiter(), plans, proxies)
+
+	// Fora da lista, um X-Forwarded-For diferente por requisicao nao escapa do limite.
+	serveLimited(t, m, "203.0.113.7:4000", "198.51.100.1")
+	if rec := serveLimited(t, m, "203.0.113.7:4000", "198.51.100.2"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected spoofed X-Forwarded-For to be ignored, got %d", rec.Code)
+	}
+
+	// Atras do proxy confiavel vale o ultimo hop nao confiavel, nao o primeiro (forjavel).
+	if rec := serveLimited(t, m, "10.0.0.5:4000", "1.1.1.1, 198.51.100.9"); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected first request from client to pass, got %d", rec.Code)
+	}
+	if rec := serveLimited(t, m, "10.0.0.5:4000", "2.2.2.2, 198.51.100.9"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected client behind proxy to be limited, got %d", rec.Code)
+	}
+}
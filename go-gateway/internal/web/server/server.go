@@ -4,6 +4,7 @@ import (
 	"expvar"
 	"net/http"
 
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/repository"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/service"
 	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/web/handlers"
@@ -17,31 +18,64 @@ type Server struct {
 	router         *chi.Mux
 	server         *http.Server
 	accountService *service.AccountService
+	apiKeyService  *service.APIKeyService
 	invoiceService *service.InvoiceService
+	webhookService *service.WebhookService
+	ledgerService  *service.LedgerService
+	limitService   *service.AccountLimitService
+	cardTokens     *service.CardTokenService
+	customers      *service.CustomerService
+	subscriptions  *service.SubscriptionService
+	payouts        *service.PayoutService
+	pricing        *service.PricingService
 	idempotency    *repository.IdempotencyRepository
 	demoService    *service.DemoService
 	healthHandler  *handlers.HealthHandler
 	rateLimit      *middleware.RateLimitMiddleware
+	adminAuth      *middleware.AdminAuthMiddleware
+	pixSecret      string
 	port           string
 }
 
 func NewServer(
 	accountService *service.AccountService,
+	apiKeyService *service.APIKeyService,
 	invoiceService *service.InvoiceService,
+	webhookService *service.WebhookService,
+	ledgerService *service.LedgerService,
+	limitService *service.AccountLimitService,
+	cardTokenService *service.CardTokenService,
+	customerService *service.CustomerService,
+	subscriptionService *service.SubscriptionService,
+	payoutService *service.PayoutService,
+	pricingService *service.PricingService,
 	idempotencyStore *repository.IdempotencyRepository,
 	demoService *service.DemoService,
 	healthHandler *handlers.HealthHandler,
 	rateLimit *middleware.RateLimitMiddleware,
+	adminAuth *middleware.AdminAuthMiddleware,
+	pixSecret string,
 	port string,
 ) *Server {
 	return &Server{
 		router:         chi.NewRouter(),
 		accountService: accountService,
+		apiKeyService:  apiKeyService,
 		invoiceService: invoiceService,
+		webhookService: webhookService,
+		ledgerService:  ledgerService,
+		limitService:   limitService,
+		cardTokens:     cardTokenService,
+		customers:      customerService,
+		subscriptions:  subscriptionService,
+		payouts:        payoutService,
+		pricing:        pricingService,
 		idempotency:    idempotencyStore,
 		demoService:    demoService,
 		healthHandler:  healthHandler,
 		rateLimit:      rateLimit,
+		adminAuth:      adminAuth,
+		pixSecret:      pixSecret,
 		port:           port,
 	}
 }
@@ -49,7 +83,17 @@ func NewServer(
 func (s *Server) ConfigureRoutes() {
 	accountHandler := handlers.NewAccountHandler(s.accountService)
 	invoiceHandler := handlers.NewInvoiceHandler(s.invoiceService, s.idempotency)
-	authMiddleware := middleware.NewAuthMiddleware(s.accountService)
+	webhookHandler := handlers.NewWebhookHandler(s.webhookService)
+	ledgerHandler := handlers.NewLedgerHandler(s.ledgerService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(s.apiKeyService)
+	limitHandler := handlers.NewAccountLimitHandler(s.limitService, s.accountService)
+	boletoHandler := handlers.NewBoletoHandler(s.invoiceService)
+	cardTokenHandler := handlers.NewCardTokenHandler(s.cardTokens)
+	customerHandler := handlers.NewCustomerHandler(s.customers)
+	subscriptionHandler := handlers.NewSubscriptionHandler(s.subscriptions)
+	payoutHandler := handlers.NewPayoutHandler(s.payouts, s.idempotency)
+	pricingHandler := handlers.NewPricingHandler(s.pricing)
+	authMiddleware := middleware.NewAuthMiddleware(s.apiKeyService)
 	demoHandler := handlers.NewDemoHandler(s.demoService)
 
 	s.router.Use(middleware.RequestID)
@@ -59,7 +103,6 @@ func (s *Server) ConfigureRoutes() {
 	s.router.Use(middleware.CORS)
 
 	s.router.With(s.rateLimit.Limit).Post("/accounts", accountHandler.Create)
-	s.router.With(s.rateLimit.Limit).Get("/accounts", accountHandler.Get)
 	s.router.With(s.rateLimit.Limit).Post("/demo", demoHandler.Create)
 	s.router.Get("/swagger/*", httpSwagger.WrapHandler)
 	s.router.Get("/health", s.healthHandler.Liveness)
@@ -67,14 +110,86 @@ func (s *Server) ConfigureRoutes() {
 	s.router.Handle("/metrics", expvar.Handler())
 	s.router.Handle("/metrics/prom", promhttp.Handler())
 
+	// O webhook PIX e autenticado pela assinatura do PSP e so existe com PIX_WEBHOOK_SECRET configurado.
+	if s.pixSecret != "" {
+		pixHandler := handlers.NewPixWebhookHandler(s.invoiceService, s.pixSecret)
+		s.router.Post("/webhooks/pix", pixHandler.Receive)
+	}
+
 	s.router.Group(func(r chi.Router) {
 		r.Use(authMiddleware.Authenticate)
 		r.Use(s.rateLimit.Limit)
-		r.Post("/invoice", invoiceHandler.Create)
-		r.Get("/invoice/{id}", invoiceHandler.GetByID)
-		r.Get("/invoice/{id}/events", invoiceHandler.ListEvents)
-		r.Get("/invoice", invoiceHandler.ListByAccount)
+		// Cada rota exige um escopo da API key (403 insufficient_scope).
+		scope := authMiddleware.RequireScope
+		r.With(scope(domain.ScopeAccountRead)).Get("/accounts", accountHandler.Get)
+		r.With(scope(domain.ScopeAccountRead)).Get("/accounts/ledger", ledgerHandler.List)
+		r.With(scope(domain.ScopeAccountRead)).Get("/accounts/limits", limitHandler.Get)
+		r.With(scope(domain.ScopeAccountRead)).Get("/accounts/pricing", pricingHandler.Get)
+		r.With(scope(domain.ScopeInvoicesWrite)).Post("/tokens", cardTokenHandler.Create)
+		// Clientes e meios de pagamento salvos usam os escopos de faturas.
+		r.With(scope(domain.ScopeInvoicesWrite)).Post("/customers", customerHandler.Create)
+		r.With(scope(domain.ScopeInvoicesRead)).Get("/customers", customerHandler.List)
+		r.With(scope(domain.ScopeInvoicesRead)).Get("/customers/{id}", customerHandler.Get)
+		r.With(scope(domain.ScopeInvoicesWrite)).Patch("/customers/{id}", customerHandler.Update)
+		r.With(scope(domain.ScopeInvoicesWrite)).Delete("/customers/{id}", customerHandler.Delete)
+		r.With(scope(domain.ScopeInvoicesWrite)).Post("/customers/{id}/payment-methods", customerHandler.AttachPaymentMethod)
+		r.With(scope(domain.ScopeInvoicesRead)).Get("/customers/{id}/payment-methods", customerHandler.ListPaymentMethods)
+		r.With(scope(domain.ScopeInvoicesWrite)).Delete("/customers/{id}/payment-methods/{token}", customerHandler.DetachPaymentMethod)
+		// Planos e assinaturas tambem usam os escopos de faturas.
+		r.With(scope(domain.ScopeInvoicesWrite)).Post("/plans", subscriptionHandler.CreatePlan)
+		r.With(scope(domain.ScopeInvoicesRead)).Get("/plans", subscriptionHandler.ListPlans)
+		r.With(scope(domain.ScopeInvoicesRead)).Get("/plans/{id}", subscriptionHandler.GetPlan)
+		r.With(scope(domain.ScopeInvoicesWrite)).Delete("/plans/{id}", subscriptionHandler.ArchivePlan)
+		r.With(scope(domain.ScopeInvoicesWrite)).Post("/subscriptions", subscriptionHandler.Create)
+		r.With(scope(domain.ScopeInvoicesRead)).Get("/subscriptions", subscriptionHandler.List)
+		r.With(scope(domain.ScopeInvoicesRead)).Get("/subscriptions/{id}", subscriptionHandler.Get)
+		r.With(scope(domain.ScopeInvoicesWrite)).Post("/subscriptions/{id}/cancel", subscriptionHandler.Cancel)
+		r.With(scope(domain.ScopeInvoicesRead)).Get("/subscriptions/{id}/events", subscriptionHandler.ListEvents)
+		// Saques tiram dinheiro da conta e exigem payouts:write; consultas usam account:read.
+		r.With(scope(domain.ScopePayoutsWrite)).Post("/bank-accounts", payoutHandler.CreateBankAccount)
+		r.With(scope(domain.ScopeAccountRead)).Get("/bank-accounts", payoutHandler.ListBankAccounts)
+		r.With(scope(domain.ScopePayoutsWrite)).Delete("/bank-accounts/{id}", payoutHandler.DeleteBankAccount)
+		r.With(scope(domain.ScopePayoutsWrite)).Post("/payouts", payoutHandler.Create)
+		r.With(scope(domain.ScopeAccountRead)).Get("/payouts", payoutHandler.List)
+		r.With(scope(domain.ScopeAccountRead)).Get("/payouts/{id}", payoutHandler.Get)
+		r.With(scope(domain.ScopeInvoicesWrite)).Post("/invoice", invoiceHandler.Create)
+		r.With(scope(domain.ScopeInvoicesRead)).Get("/invoice/{id}", invoiceHandler.GetByID)
+		r.With(scope(domain.ScopeInvoicesRead)).Get("/invoice/{id}/events", invoiceHandler.ListEvents)
+		r.With(scope(domain.ScopeRefundsWrite)).Post("/invoice/{id}/refund", invoiceHandler.Refund)
+		r.With(scope(domain.ScopeInvoicesWrite)).Post("/invoice/{id}/cancel", invoiceHandler.Cancel)
+		r.With(scope(domain.ScopeInvoicesWrite)).Post("/invoice/{id}/capture", invoiceHandler.Capture)
+		r.With(scope(domain.ScopeInvoicesWrite)).Post("/invoice/{id}/void", invoiceHandler.Void)
+		r.With(scope(domain.ScopeInvoicesRead)).Get("/invoice", invoiceHandler.ListByAccount)
+		r.With(scope(domain.ScopeWebhooksWrite)).Post("/webhooks", webhookHandler.Create)
+		r.With(scope(domain.ScopeWebhooksRead)).Get("/webhooks", webhookHandler.List)
+		r.With(scope(domain.ScopeWebhooksWrite)).Delete("/webhooks/{id}", webhookHandler.Delete)
+		r.With(scope(domain.ScopeWebhooksRead)).Get("/webhooks/{id}/deliveries", webhookHandler.ListDeliveries)
+		r.With(scope(domain.ScopeAPIKeysWrite)).Post("/api-keys", apiKeyHandler.Create)
+		r.With(scope(domain.ScopeAPIKeysRead)).Get("/api-keys", apiKeyHandler.List)
+		r.With(scope(domain.ScopeAPIKeysWrite)).Delete("/api-keys/{id}", apiKeyHandler.Revoke)
 	})
+
+	// Rotas admin so existem com ADMIN_API_KEYS configurado. O limite por IP vem antes da
+	// autenticacao para que tentativas de X-ADMIN-KEY tambem consumam a cota.
+	if s.adminAuth.Enabled() {
+		s.router.Route("/admin", func(r chi.Router) {
+			r.Use(s.rateLimit.Limit)
+			r.Use(s.adminAuth.Authenticate)
+			r.Get("/accounts/{id}/limits", limitHandler.AdminList)
+			r.Put("/accounts/{id}/limits", limitHandler.AdminSet)
+			r.Post("/accounts/{id}/limits/reset", limitHandler.AdminReset)
+			r.Get("/accounts/{id}/limits/audit", limitHandler.AdminListAudits)
+			r.Get("/accounts/{id}/pricing", pricingHandler.AdminList)
+			r.Put("/accounts/{id}/pricing", pricingHandler.AdminSet)
+			r.Delete("/accounts/{id}/pricing", pricingHandler.AdminDelete)
+			r.Post("/accounts/{id}/api-keys/{key_id}/scopes", apiKeyHandler.AdminGrantScopes)
+			r.Post("/invoices/{id}/review", invoiceHandler.AdminReview)
+			r.Post("/boletos/settlements", boletoHandler.AdminSettle)
+			r.Get("/payouts/dead-letters", payoutHandler.AdminListDeadLetters)
+			r.Post("/payouts/{id}/status", payoutHandler.AdminUpdateStatus)
+			r.Post("/payouts/{id}/redeliver", payoutHandler.AdminRedeliver)
+		})
+	}
 }
 
 func (s *Server) Start() error {
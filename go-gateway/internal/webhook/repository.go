@@ -0,0 +1,144 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+)
+
+// Repository persiste endpoints e tentativas de entrega de webhooks.
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) CreateEndpoint(ctx context.Context, endpoint *Endpoint) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhook_endpoints (id, account_id, mode, url, secret, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, endpoint.ID, endpoint.AccountID, endpoint.Mode, endpoint.URL, endpoint.Secret, endpoint.Active, endpoint.CreatedAt, endpoint.UpdatedAt)
+	return err
+}
+
+// ListEndpoints lista os endpoints da conta no modo informado.
+func (r *Repository) ListEndpoints(ctx context.Context, accountID string, mode domain.Mode) ([]*Endpoint, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, account_id, mode, url, secret, active, created_at, updated_at
+		FROM webhook_endpoints
+		WHERE account_id = $1 AND mode = $2
+		ORDER BY created_at DESC
+	`, accountID, mode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	endpoints := make([]*Endpoint, 0)
+	for rows.Next() {
+		var endpoint Endpoint
+		if err := rows.Scan(&endpoint.ID, &endpoint.AccountID, &endpoint.Mode, &endpoint.URL, &endpoint.Secret, &endpoint.Active, &endpoint.CreatedAt, &endpoint.UpdatedAt); err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, &endpoint)
+	}
+	return endpoints, rows.Err()
+}
+
+// FindEndpoint busca um endpoint pelo ID, ativo ou nao.
+func (r *Repository) FindEndpoint(ctx context.Context, id string) (*Endpoint, error) {
+	var endpoint Endpoint
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, account_id, mode, url, secret, active, created_at, updated_at
+		FROM webhook_endpoints
+		WHERE id = $1
+	`, id).Scan(&endpoint.ID, &endpoint.AccountID, &endpoint.Mode, &endpoint.URL, &endpoint.Secret, &endpoint.Active, &endpoint.CreatedAt, &endpoint.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrWebhookEndpointNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// DeactivateEndpoint desativa o endpoint da conta no modo; entregas pendentes sao descartadas.
+func (r *Repository) DeactivateEndpoint(ctx context.Context, accountID string, mode domain.Mode, id string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_endpoints
+		SET active = false, updated_at = NOW()
+		WHERE id = $1 AND account_id = $2 AND mode = $3
+	`, id, accountID, mode)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrWebhookEndpointNotFound
+	}
+	return nil
+}
+
+func (r *Repository) SaveDelivery(ctx context.Context, delivery Delivery) error {
+	var responseStatus sql.NullInt64
+	if delivery.ResponseStatus > 0 {
+		responseStatus = sql.NullInt64{Int64: int64(delivery.ResponseStatus), Valid: true}
+	}
+	var deliveryError sql.NullString
+	if delivery.Error != "" {
+		deliveryError = sql.NullString{String: delivery.Error, Valid: true}
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (id, endpoint_id, outbox_event_id, event_id, event_type, attempt, success, response_status, error, duration_ms, created_at)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, delivery.EndpointID, delivery.OutboxEventID, delivery.EventID, delivery.EventType, delivery.Attempt, delivery.Success, responseStatus, deliveryError, delivery.DurationMs, delivery.CreatedAt)
+	return err
+}
+
+// ListDeliveries retorna as tentativas mais recentes de um endpoint.
+func (r *Repository) ListDeliveries(ctx context.Context, endpointID string, limit int) ([]*Delivery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, endpoint_id, outbox_event_id, event_id, event_type, attempt, success, response_status, error, duration_ms, created_at
+		FROM webhook_deliveries
+		WHERE endpoint_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, endpointID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]*Delivery, 0)
+	for rows.Next() {
+		var delivery Delivery
+		var responseStatus sql.NullInt64
+		var deliveryError sql.NullString
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.EndpointID,
+			&delivery.OutboxEventID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.Attempt,
+			&delivery.Success,
+			&responseStatus,
+			&deliveryError,
+			&delivery.DurationMs,
+			&delivery.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		delivery.ResponseStatus = int(responseStatus.Int64)
+		delivery.Error = deliveryError.String
+		deliveries = append(deliveries, &delivery)
+	}
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/outbox"
+)
+
+// Store e o subconjunto do repositorio usado pelo Sender.
+type Store interface {
+	FindEndpoint(ctx context.Context, id string) (*Endpoint, error)
+	SaveDelivery(ctx context.Context, delivery Delivery) error
+}
+
+// Sender implementa outbox.Publisher entregando eventos via HTTP POST assinado.
+type Sender struct {
+	store  Store
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewSender cria o Sender. Conexoes a destinos bloqueados pela policy falham no dial,
+// inclusive depois de redirects; proxies de ambiente sao ignorados pelo mesmo motivo.
+func NewSender(store Store, timeout time.Duration, policy TargetPolicy) *Sender {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	transport := &http.Transport{
+		Proxy:               nil,
+		DialContext:         policy.dialer(timeout).DialContext,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	return &Sender{
+		store:  store,
+		client: &http.Client{Timeout: timeout, Transport: transport},
+		now:    time.Now,
+	}
+}
+
+// Publish entrega o evento e registra a tentativa. Respostas fora de 2xx
+// retornam erro para que o worker reagende com backoff.
+func (s *Sender) Publish(ctx context.Context, ev outbox.Event) error {
+	var payload outboxPayload
+	if err := json.Unmarshal(ev.Payload, &payload); err != nil {
+		return err
+	}
+
+	endpoint, err := s.store.FindEndpoint(ctx, payload.EndpointID)
+	if errors.Is(err, domain.ErrWebhookEndpointNotFound) {
+		slog.Warn("webhook endpoint removido, descartando entrega", "endpoint_id", payload.EndpointID, "outbox_event_id", ev.ID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !endpoint.Active {
+		slog.Info("webhook endpoint inativo, descartando entrega", "endpoint_id", endpoint.ID, "outbox_event_id", ev.ID)
+		return nil
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload.Event, &event); err != nil {
+		return err
+	}
+
+	body := []byte(payload.Event)
+	start := s.now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "payment-gateway-webhooks/1.0")
+	req.Header.Set(EventIDHeader, event.ID)
+	req.Header.Set(SignatureHeader, SignatureHeaderValue(endpoint.Secret, start.Unix(), body))
+
+	delivery := Delivery{
+		EndpointID:    endpoint.ID,
+		OutboxEventID: ev.ID,
+		EventID:       event.ID,
+		EventType:     event.Type,
+		Attempt:       ev.Attempts + 1,
+		CreatedAt:     start,
+	}
+
+	resp, err := s.client.Do(req)
+	delivery.DurationMs = s.now().Sub(start).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+		s.saveDelivery(ctx, delivery)
+		return err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	_ = resp.Body.Close()
+
+	delivery.ResponseStatus = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		delivery.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+		s.saveDelivery(ctx, delivery)
+		return errors.New(delivery.Error)
+	}
+
+	delivery.Success = true
+	s.saveDelivery(ctx, delivery)
+	return nil
+}
+
+func (s *Sender) saveDelivery(ctx context.Context, delivery Delivery) {
+	if err := s.store.SaveDelivery(ctx, delivery); err != nil {
+		slog.Error("erro ao registrar entrega de webhook", "error", err, "endpoint_id", delivery.EndpointID)
+	}
+}
@@ -0,0 +1,196 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/outbox"
+)
+
+type fakeStore struct {
+	mu         sync.Mutex
+	endpoints  map[string]*Endpoint
+	deliveries []Delivery
+}
+
+func (s *fakeStore) FindEndpoint(_ context.Context, id string) (*Endpoint, error) {
+	endpoint, ok := s.endpoints[id]
+	if !ok {
+		return nil, domain.ErrWebhookEndpointNotFound
+	}
+	return endpoint, nil
+}
+
+func (s *fakeStore) SaveDelivery(_ context.Context, delivery Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+func newOutboxEvent(t *testing.T, endpointID string, event Event, attempts int) outbox.Event {
+	t.Helper()
+	body, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to marshal event: %v", err)
+	}
+	payload, err := json.Marshal(outboxPayload{EndpointID: endpointID, Event: body})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+	return outbox.Event{ID: "outbox-1", Type: OutboxEventType, Payload: payload, Attempts: attempts}
+}
+
+func TestSenderDeliversSignedEvent(t *testing.T) {
+	const secret = "whsec_test"
+	var receivedSignature, receivedEventID string
+	var receivedBody []byte
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedSignature = r.Header.Get(SignatureHeader)
+		receivedEventID = r.Header.Get(EventIDHeader)
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	store := &fakeStore{endpoints: map[string]*Endpoint{
+		"endpoint-1": {ID: "endpoint-1", URL: receiver.URL, Secret: secret, Active: true},
+	}}
+	event := NewEvent("invoice.approved", map[string]any{"invoice_id": "invoice-1"})
+
+	sender := NewSender(store, time.Second, TargetPolicy{AllowPrivate: true})
+	if err := sender.Publish(context.Background(), newOutboxEvent(t, "endpoint-1", event, 0)); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if receivedEventID != event.ID {
+		t.Fatalf("expected event id %s, got %s", event.ID, receivedEventID)
+	}
+	if err := Verify(secret, receivedSignature, receivedBody, time.Minute, time.Now()); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+	if err := Verify("other-secret", receivedSignature, receivedBody, time.Minute, time.Now()); err != ErrSignatureMismatch {
+		t.Fatalf("expected ErrSignatureMismatch, got %v", err)
+	}
+
+	if len(store.deliveries) != 1 || !store.deliveries[0].Success || store.deliveries[0].Attempt != 1 {
+		t.Fatalf("expected one successful delivery on attempt 1, got %+v", store.deliveries)
+	}
+}
+
+func TestSenderRecordsFailedAttempt(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	store := &fakeStore{endpoints: map[string]*Endpoint{
+		"endpoint-1": {ID: "endpoint-1", URL: receiver.URL, Secret: "whsec_test", Active: true},
+	}}
+
+	sender := NewSender(store, time.Second, TargetPolicy{AllowPrivate: true})
+	err := sender.Publish(context.Background(), newOutboxEvent(t, "endpoint-1", NewEvent("invoice.rejected", nil), 2))
+	if err == nil {
+		t.Fatal("expected error for non-2xx response")
+	}
+
+	if len(store.deliveries) != 1 {
+		t.Fatalf("expected one delivery, got %d", len(store.deliveries))
+	}
+	delivery := store.deliveries[0]
+	if delivery.Success || delivery.ResponseStatus != http.StatusInternalServerError || delivery.Attempt != 3 {
+		t.Fatalf("unexpected delivery record: %+v", delivery)
+	}
+}
+
+func TestSenderSkipsInactiveEndpoint(t *testing.T) {
+	store := &fakeStore{endpoints: map[string]*Endpoint{
+		"endpoint-1": {ID: "endpoint-1", URL: "http://127.0.0.1:0", Secret: "whsec_test", Active: false},
+	}}
+
+	sender := NewSender(store, time.Second, TargetPolicy{AllowPrivate: true})
+	if err := sender.Publish(context.Background(), newOutboxEvent(t, "endpoint-1", NewEvent("invoice.approved", nil), 0)); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(store.deliveries) != 0 {
+		t.Fatalf("expected no deliveries, got %d", len(store.deliveries))
+	}
+}
+
+func TestSenderRefusesPrivateTargetAtDial(t *testing.T) {
+	called := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	// Endpoint cadastrado com um nome publico que passou a resolver para loopback.
+	store := &fakeStore{endpoints: map[string]*Endpoint{
+		"endpoint-1": {ID: "endpoint-1", URL: receiver.URL, Secret: "whsec_test", Active: true},
+	}}
+
+	sender := NewSender(store, time.Second, TargetPolicy{})
+	err := sender.Publish(context.Background(), newOutboxEvent(t, "endpoint-1", NewEvent("invoice.approved", nil), 0))
+	if !errors.Is(err, ErrBlockedTarget) {
+		t.Fatalf("expected ErrBlockedTarget, got %v", err)
+	}
+	if called {
+		t.Fatal("expected no request to reach the private target")
+	}
+	if len(store.deliveries) != 1 || store.deliveries[0].Success {
+		t.Fatalf("expected one failed delivery, got %+v", store.deliveries)
+	}
+}
+
+func TestTargetPolicyCheckURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		blocked bool
+	}{
+		{"https://93.184.216.34/webhooks", false},
+		{"https://localhost/webhooks", true},
+		{"https://api.localhost/webhooks", true},
+		{"http://127.0.0.1:8080/webhooks", true},
+		{"https://10.0.0.5/webhooks", true},
+		{"https://172.16.3.1/webhooks", true},
+		{"https://192.168.0.10/webhooks", true},
+		{"http://169.254.169.254/latest/meta-data", true},
+		{"https://100.64.0.1/webhooks", true},
+		{"https://0.0.0.0/webhooks", true},
+		{"https://[::1]/webhooks", true},
+		{"https://[fd00::1]/webhooks", true},
+		{"https://[fe80::1]/webhooks", true},
+		{"https://[::ffff:127.0.0.1]/webhooks", true},
+	}
+
+	for _, tt := range tests {
+		err := TargetPolicy{}.CheckURL(context.Background(), tt.url)
+		if blocked := errors.Is(err, ErrBlockedTarget); blocked != tt.blocked {
+			t.Errorf("CheckURL(%q) = %v, want blocked %v", tt.url, err, tt.blocked)
+		}
+	}
+
+	if err := (TargetPolicy{AllowPrivate: true}).CheckURL(context.Background(), "http://127.0.0.1:8080"); err != nil {
+		t.Fatalf("expected AllowPrivate to accept loopback, got %v", err)
+	}
+}
+
+func TestVerifyRejectsStaleTimestamp(t *testing.T) {
+	body := []byte(`{"id":"evt"}`)
+	signedAt := time.Now().Add(-10 * time.Minute)
+	header := SignatureHeaderValue("whsec_test", signedAt.Unix(), body)
+
+	if err := Verify("whsec_test", header, body, 5*time.Minute, time.Now()); err != ErrSignatureExpired {
+		t.Fatalf("expected ErrSignatureExpired, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader carrega timestamp e assinatura no formato "t=<unix>,v1=<hex>".
+	SignatureHeader = "X-Webhook-Signature"
+	// EventIDHeader permite ao merchant deduplicar entregas repetidas.
+	EventIDHeader = "X-Webhook-Event-Id"
+)
+
+var (
+	ErrInvalidSignatureHeader = errors.New("invalid signature header")
+	ErrSignatureMismatch      = errors.New("signature mismatch")
+	ErrSignatureExpired       = errors.New("signature timestamp outside tolerance")
+)
+
+// Sign calcula HMAC-SHA256 de "<timestamp>.<body>" com o secret do endpoint.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureHeaderValue monta o valor do header de assinatura.
+func SignatureHeaderValue(secret string, timestamp int64, body []byte) string {
+	return "t=" + strconv.FormatInt(timestamp, 10) + ",v1=" + Sign(secret, timestamp, body)
+}
+
+// Verify valida o header de assinatura recebido, rejeitando timestamps fora da tolerancia.
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var timestamp int64
+	var signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ErrInvalidSignatureHeader
+		}
+		switch key {
+		case "t":
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return ErrInvalidSignatureHeader
+			}
+			timestamp = parsed
+		case "v1":
+			signature = value
+		}
+	}
+	if timestamp == 0 || signature == "" {
+		return ErrInvalidSignatureHeader
+	}
+
+	if tolerance > 0 {
+		age := now.Sub(time.Unix(timestamp, 0))
+		if age < -tolerance || age > tolerance {
+			return ErrSignatureExpired
+		}
+	}
+
+	expected := Sign(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
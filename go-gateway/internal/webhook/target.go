@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedTarget indica um destino de webhook em loopback, rede privada ou link-local.
+var ErrBlockedTarget = errors.New("webhook target is not a public address")
+
+// blockedPrefixes complementa os ranges da biblioteca padrao (loopback, privados, link-local)
+// com redes compartilhadas e reservadas que tambem nao devem ser alcancadas pelo gateway.
+var blockedPrefixes = mustParseCIDRs(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"198.18.0.0/15",
+	"240.0.0.0/4",
+)
+
+// TargetPolicy define quais destinos podem receber webhooks. A checagem e feita no cadastro
+// do endpoint e repetida a cada conexao, para que DNS rebinding nao a contorne.
+type TargetPolicy struct {
+	// AllowPrivate libera loopback e redes privadas (apenas desenvolvimento local).
+	AllowPrivate bool
+}
+
+// CheckURL rejeita URLs cujo host e localhost, um IP bloqueado ou um nome que resolve para um.
+// Falhas de DNS nao bloqueiam o cadastro; o endereco e verificado de novo na entrega.
+func (p TargetPolicy) CheckURL(ctx context.Context, rawURL string) error {
+	if p.AllowPrivate {
+		return nil
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrBlockedTarget
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return p.CheckIP(ip)
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if err := p.CheckIP(addr.IP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckIP rejeita enderecos que nao sao unicast publicos.
+func (p TargetPolicy) CheckIP(ip net.IP) error {
+	if p.AllowPrivate {
+		return nil
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsMulticast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return ErrBlockedTarget
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return ErrBlockedTarget
+		}
+	}
+	return nil
+}
+
+// dialer retorna um dialer que verifica o IP de cada conexao depois da resolucao de DNS.
+func (p TargetPolicy) dialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil {
+				return fmt.Errorf("%w: %s", ErrBlockedTarget, address)
+			}
+			if err := p.CheckIP(ip); err != nil {
+				return fmt.Errorf("%w: %s", err, address)
+			}
+			return nil
+		},
+	}
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	prefixes := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, prefix, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		prefixes[i] = prefix
+	}
+	return prefixes
+}
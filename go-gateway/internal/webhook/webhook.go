@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/GuiCintra27/payment-gateway/go-gateway/internal/domain"
+	"github.com/google/uuid"
+)
+
+// OutboxEventType identifica entregas de webhook na tabela outbox_events.
+const OutboxEventType = "webhook_delivery"
+
+// Endpoint representa uma URL cadastrada por uma conta para receber eventos do seu modo.
+type Endpoint struct {
+	ID        string
+	AccountID string
+	Mode      domain.Mode
+	URL       string
+	Secret    string
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Delivery registra uma tentativa de entrega para um endpoint.
+type Delivery struct {
+	ID             string
+	EndpointID     string
+	OutboxEventID  string
+	EventID        string
+	EventType      string
+	Attempt        int
+	Success        bool
+	ResponseStatus int
+	Error          string
+	DurationMs     int64
+	CreatedAt      time.Time
+}
+
+// Event e o corpo JSON enviado ao merchant.
+type Event struct {
+	ID        string         `json:"id"`
+	Type      string         `json:"type"`
+	CreatedAt time.Time      `json:"created_at"`
+	Data      map[string]any `json:"data"`
+}
+
+// outboxPayload e o payload gravado em outbox_events para cada endpoint.
+type outboxPayload struct {
+	EndpointID string          `json:"endpoint_id"`
+	Event      json.RawMessage `json:"event"`
+}
+
+// NewEndpoint cria um endpoint ativo com secret de assinatura aleatorio.
+func NewEndpoint(accountID string, mode domain.Mode, url string) (*Endpoint, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Endpoint{
+		ID:        uuid.NewString(),
+		AccountID: accountID,
+		Mode:      mode,
+		URL:       url,
+		Secret:    secret,
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// NewEvent cria um evento com ID unico.
+func NewEvent(eventType string, data map[string]any) Event {
+	return Event{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+}
+
+// Enqueue cria uma entrega na outbox para cada endpoint ativo da conta no modo do evento,
+// usando a transacao do chamador para manter atomicidade com a mudanca de estado.
+func Enqueue(tx *sql.Tx, accountID string, mode domain.Mode, aggregateID string, event Event, correlationID string) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO outbox_events (id, aggregate_id, type, payload, status, attempts, next_attempt_at, correlation_id, created_at, updated_at)
+		SELECT gen_random_uuid(), $1, $2, jsonb_build_object('endpoint_id', id, 'event', $3::jsonb), 'pending', 0, NOW(), $4, NOW(), NOW()
+		FROM webhook_endpoints
+		WHERE account_id = $5 AND mode = $6 AND active
+	`, aggregateID, OutboxEventType, string(body), correlationID, accountID, mode)
+	return err
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,5 @@
+ALTER TABLE invoices
+    DROP CONSTRAINT IF EXISTS invoices_refunded_cents_check;
+
+ALTER TABLE invoices
+    DROP COLUMN IF EXISTS refunded_cents;
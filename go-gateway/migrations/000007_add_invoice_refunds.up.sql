@@ -0,0 +1,5 @@
+ALTER TABLE invoices
+    ADD COLUMN IF NOT EXISTS refunded_cents BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE invoices
+    ADD CONSTRAINT invoices_refunded_cents_check CHECK (refunded_cents >= 0 AND refunded_cents <= amount_cents);
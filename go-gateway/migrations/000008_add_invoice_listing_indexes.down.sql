@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS idx_invoices_account_id ON invoices (account_id);
+
+DROP INDEX IF EXISTS idx_invoices_account_payment_type_created_id;
+DROP INDEX IF EXISTS idx_invoices_account_status_created_id;
+DROP INDEX IF EXISTS idx_invoices_account_created_id;
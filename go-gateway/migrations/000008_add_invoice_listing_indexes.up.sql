@@ -0,0 +1,11 @@
+CREATE INDEX IF NOT EXISTS idx_invoices_account_created_id
+    ON invoices (account_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_invoices_account_status_created_id
+    ON invoices (account_id, status, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_invoices_account_payment_type_created_id
+    ON invoices (account_id, payment_type, created_at DESC, id DESC);
+
+-- Coberto pelo prefixo de idx_invoices_account_created_id.
+DROP INDEX IF EXISTS idx_invoices_account_id;
@@ -0,0 +1,4 @@
+DELETE FROM outbox_events WHERE type = 'webhook_delivery';
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_endpoints;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS ledger_postings_balanced ON ledger_postings;
+DROP FUNCTION IF EXISTS ledger_check_entry_balanced();
+DROP TABLE IF EXISTS ledger_postings;
+DROP TABLE IF EXISTS ledger_entries;
@@ -0,0 +1,59 @@
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    invoice_id UUID REFERENCES invoices(id) ON DELETE SET NULL,
+    entry_type VARCHAR(50) NOT NULL,
+    description TEXT,
+    metadata JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account_created
+    ON ledger_entries(account_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_invoice_id ON ledger_entries(invoice_id);
+
+CREATE TABLE IF NOT EXISTS ledger_postings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    entry_id UUID NOT NULL REFERENCES ledger_entries(id) ON DELETE CASCADE,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    ledger_account VARCHAR(50) NOT NULL,
+    amount_cents BIGINT NOT NULL CHECK (amount_cents <> 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_entry_id ON ledger_postings(entry_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_account_ledger
+    ON ledger_postings(account_id, ledger_account);
+
+-- Saldo de abertura: contas existentes passam a ter historico coerente com balance_cents.
+INSERT INTO ledger_entries (id, account_id, entry_type, description)
+SELECT gen_random_uuid(), id, 'opening_balance', 'saldo migrado de accounts.balance_cents'
+FROM accounts
+WHERE balance_cents <> 0;
+
+INSERT INTO ledger_postings (entry_id, account_id, ledger_account, amount_cents)
+SELECT e.id, e.account_id, 'merchant_balance', a.balance_cents
+FROM ledger_entries e
+JOIN accounts a ON a.id = e.account_id
+WHERE e.entry_type = 'opening_balance';
+
+INSERT INTO ledger_postings (entry_id, account_id, ledger_account, amount_cents)
+SELECT e.id, e.account_id, 'opening_balance', -a.balance_cents
+FROM ledger_entries e
+JOIN accounts a ON a.id = e.account_id
+WHERE e.entry_type = 'opening_balance';
+
+-- Garante no banco que cada lancamento soma zero ao final da transacao.
+CREATE OR REPLACE FUNCTION ledger_check_entry_balanced() RETURNS TRIGGER AS $$
+BEGIN
+    IF (SELECT COALESCE(SUM(amount_cents), 0) FROM ledger_postings WHERE entry_id = NEW.entry_id) <> 0 THEN
+        RAISE EXCEPTION 'ledger entry % is not balanced', NEW.entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_postings_balanced
+    AFTER INSERT ON ledger_postings
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_check_entry_balanced();
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_invoices_authorized_updated_at;
+
+ALTER TABLE invoices DROP CONSTRAINT IF EXISTS invoices_refunded_cents_check;
+ALTER TABLE invoices
+    ADD CONSTRAINT invoices_refunded_cents_check CHECK (refunded_cents >= 0 AND refunded_cents <= amount_cents);
+
+ALTER TABLE invoices DROP CONSTRAINT IF EXISTS invoices_captured_cents_check;
+
+ALTER TABLE invoices
+    DROP COLUMN IF EXISTS captured_cents,
+    DROP COLUMN IF EXISTS capture_method;
@@ -0,0 +1,20 @@
+ALTER TABLE invoices
+    ADD COLUMN IF NOT EXISTS capture_method VARCHAR(20) NOT NULL DEFAULT 'automatic',
+    ADD COLUMN IF NOT EXISTS captured_cents BIGINT NOT NULL DEFAULT 0;
+
+-- Faturas ja aprovadas foram capturadas integralmente na aprovacao.
+UPDATE invoices
+SET captured_cents = amount_cents
+WHERE status IN ('approved', 'partially_refunded', 'refunded');
+
+ALTER TABLE invoices
+    ADD CONSTRAINT invoices_captured_cents_check CHECK (captured_cents >= 0 AND captured_cents <= amount_cents);
+
+ALTER TABLE invoices DROP CONSTRAINT IF EXISTS invoices_refunded_cents_check;
+ALTER TABLE invoices
+    ADD CONSTRAINT invoices_refunded_cents_check CHECK (refunded_cents >= 0 AND refunded_cents <= captured_cents);
+
+-- Usado pelo void automatico de autorizacoes antigas.
+CREATE INDEX IF NOT EXISTS idx_invoices_authorized_updated_at
+    ON invoices (updated_at)
+    WHERE status = 'authorized';
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_invoices_account_currency_created_id;
+
+DELETE FROM account_limits WHERE currency <> 'BRL';
+ALTER TABLE account_limits DROP CONSTRAINT IF EXISTS account_limits_pkey;
+ALTER TABLE account_limits ADD CONSTRAINT account_limits_pkey PRIMARY KEY (account_id);
+ALTER TABLE account_limits DROP COLUMN IF EXISTS currency;
+
+DROP TABLE IF EXISTS account_balances;
+
+ALTER TABLE ledger_postings DROP COLUMN IF EXISTS currency;
+ALTER TABLE ledger_entries DROP COLUMN IF EXISTS currency;
+ALTER TABLE invoices DROP COLUMN IF EXISTS currency;
+ALTER TABLE accounts DROP COLUMN IF EXISTS currency;
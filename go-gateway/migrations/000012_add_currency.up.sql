@@ -0,0 +1,27 @@
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'BRL';
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'BRL';
+ALTER TABLE ledger_entries ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'BRL';
+ALTER TABLE ledger_postings ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'BRL';
+
+-- Saldo por moeda; accounts.balance_cents segue como projecao da moeda padrao da conta.
+CREATE TABLE IF NOT EXISTS account_balances (
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    currency CHAR(3) NOT NULL,
+    balance_cents BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (account_id, currency)
+);
+
+INSERT INTO account_balances (account_id, currency, balance_cents, updated_at)
+SELECT id, currency, balance_cents, updated_at
+FROM accounts
+ON CONFLICT (account_id, currency) DO NOTHING;
+
+-- Limites passam a ser definidos por conta e moeda.
+ALTER TABLE account_limits ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'BRL';
+ALTER TABLE account_limits DROP CONSTRAINT IF EXISTS account_limits_pkey;
+ALTER TABLE account_limits ADD CONSTRAINT account_limits_pkey PRIMARY KEY (account_id, currency);
+
+-- Usado pelo uso diario de limites e pelo filtro de currency na listagem.
+CREATE INDEX IF NOT EXISTS idx_invoices_account_currency_created_id
+    ON invoices (account_id, currency, created_at DESC, id DESC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_invoices_account_card_created;
+DROP TABLE IF EXISTS account_fraud_settings;
@@ -0,0 +1,10 @@
+-- Ajustes do motor antifraude por conta; contas sem linha usam a configuracao padrao.
+CREATE TABLE IF NOT EXISTS account_fraud_settings (
+    account_id UUID PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+    settings JSONB NOT NULL DEFAULT '{}'::jsonb,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Usado pela regra de velocidade por cartao.
+CREATE INDEX IF NOT EXISTS idx_invoices_account_card_created
+    ON invoices (account_id, card_last_digits, created_at);
@@ -0,0 +1,28 @@
+DROP INDEX IF EXISTS idx_invoices_account_mode_created_id;
+
+-- Dados de teste nao tem representacao sem a coluna mode.
+DELETE FROM account_limits WHERE mode = 'test';
+ALTER TABLE account_limits DROP CONSTRAINT IF EXISTS account_limits_pkey;
+ALTER TABLE account_limits ADD CONSTRAINT account_limits_pkey PRIMARY KEY (account_id, currency);
+ALTER TABLE account_limits DROP COLUMN IF EXISTS mode;
+
+DELETE FROM account_balances WHERE mode = 'test';
+ALTER TABLE account_balances DROP CONSTRAINT IF EXISTS account_balances_pkey;
+ALTER TABLE account_balances ADD CONSTRAINT account_balances_pkey PRIMARY KEY (account_id, currency);
+ALTER TABLE account_balances DROP COLUMN IF EXISTS mode;
+
+DELETE FROM webhook_endpoints WHERE mode = 'test';
+DROP INDEX IF EXISTS idx_webhook_endpoints_account_mode;
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_account_id ON webhook_endpoints (account_id);
+ALTER TABLE webhook_endpoints DROP COLUMN IF EXISTS mode;
+
+DELETE FROM ledger_entries WHERE mode = 'test';
+ALTER TABLE ledger_postings DROP COLUMN IF EXISTS mode;
+ALTER TABLE ledger_entries DROP COLUMN IF EXISTS mode;
+
+DELETE FROM invoices WHERE mode = 'test';
+ALTER TABLE invoices DROP COLUMN IF EXISTS mode;
+
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS test_api_key_key_id,
+    DROP COLUMN IF EXISTS test_api_key;
@@ -0,0 +1,28 @@
+-- Chave de teste emitida junto da chave live; contas antigas ficam sem chave de teste.
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS test_api_key VARCHAR(255) UNIQUE,
+    ADD COLUMN IF NOT EXISTS test_api_key_key_id VARCHAR(20);
+
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS mode VARCHAR(4) NOT NULL DEFAULT 'live'
+    CHECK (mode IN ('live', 'test'));
+ALTER TABLE ledger_entries ADD COLUMN IF NOT EXISTS mode VARCHAR(4) NOT NULL DEFAULT 'live';
+ALTER TABLE ledger_postings ADD COLUMN IF NOT EXISTS mode VARCHAR(4) NOT NULL DEFAULT 'live';
+
+-- Saldos e limites passam a ser separados por modo.
+ALTER TABLE account_balances ADD COLUMN IF NOT EXISTS mode VARCHAR(4) NOT NULL DEFAULT 'live';
+ALTER TABLE account_balances DROP CONSTRAINT IF EXISTS account_balances_pkey;
+ALTER TABLE account_balances ADD CONSTRAINT account_balances_pkey PRIMARY KEY (account_id, mode, currency);
+
+ALTER TABLE account_limits ADD COLUMN IF NOT EXISTS mode VARCHAR(4) NOT NULL DEFAULT 'live';
+ALTER TABLE account_limits DROP CONSTRAINT IF EXISTS account_limits_pkey;
+ALTER TABLE account_limits ADD CONSTRAINT account_limits_pkey PRIMARY KEY (account_id, mode, currency);
+
+-- Endpoints de webhook pertencem a um modo; eventos de teste nao chegam a endpoints live e vice-versa.
+ALTER TABLE webhook_endpoints ADD COLUMN IF NOT EXISTS mode VARCHAR(4) NOT NULL DEFAULT 'live'
+    CHECK (mode IN ('live', 'test'));
+DROP INDEX IF EXISTS idx_webhook_endpoints_account_id;
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_account_mode ON webhook_endpoints (account_id, mode);
+
+-- Listagem sempre filtra pelo modo da API key.
+CREATE INDEX IF NOT EXISTS idx_invoices_account_mode_created_id
+    ON invoices (account_id, mode, created_at DESC, id DESC);
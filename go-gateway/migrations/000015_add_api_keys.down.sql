@@ -0,0 +1,24 @@
+-- Restaura a chave live mais antiga nas contas criadas depois de api_keys.
+UPDATE accounts a
+SET api_key = k.key_hash, api_key_key_id = k.key_id
+FROM (
+    SELECT DISTINCT ON (account_id) account_id, key_hash, key_id
+    FROM api_keys
+    WHERE mode = 'live'
+    ORDER BY account_id, created_at
+) k
+WHERE a.id = k.account_id AND a.api_key IS NULL;
+
+UPDATE accounts a
+SET test_api_key = k.key_hash, test_api_key_key_id = k.key_id
+FROM (
+    SELECT DISTINCT ON (account_id) account_id, key_hash, key_id
+    FROM api_keys
+    WHERE mode = 'test'
+    ORDER BY account_id, created_at
+) k
+WHERE a.id = k.account_id AND a.test_api_key IS NULL;
+
+ALTER TABLE accounts ALTER COLUMN api_key SET NOT NULL;
+
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,40 @@
+-- Varias chaves por conta, com escopos, expiracao e revogacao.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    mode VARCHAR(4) NOT NULL CHECK (mode IN ('live', 'test')),
+    label VARCHAR(100) NOT NULL DEFAULT '',
+    key_hash VARCHAR(255) NOT NULL UNIQUE,
+    key_id VARCHAR(20) NOT NULL,
+    last4 VARCHAR(4) NOT NULL DEFAULT '',
+    scopes TEXT[] NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_account_mode_created
+    ON api_keys (account_id, mode, created_at DESC);
+
+-- Chaves existentes viram chaves "default" com todos os escopos (last4 desconhecido).
+INSERT INTO api_keys (account_id, mode, label, key_hash, key_id, scopes, created_at)
+SELECT id, 'live', 'default', api_key, api_key_key_id,
+       ARRAY['account:read', 'invoices:read', 'invoices:write', 'refunds:write',
+             'webhooks:read', 'webhooks:write', 'api_keys:read', 'api_keys:write'],
+       created_at
+FROM accounts
+WHERE api_key IS NOT NULL
+ON CONFLICT (key_hash) DO NOTHING;
+
+INSERT INTO api_keys (account_id, mode, label, key_hash, key_id, scopes, created_at)
+SELECT id, 'test', 'default', test_api_key, test_api_key_key_id,
+       ARRAY['account:read', 'invoices:read', 'invoices:write', 'refunds:write',
+             'webhooks:read', 'webhooks:write', 'api_keys:read', 'api_keys:write'],
+       created_at
+FROM accounts
+WHERE test_api_key IS NOT NULL AND test_api_key_key_id IS NOT NULL
+ON CONFLICT (key_hash) DO NOTHING;
+
+-- accounts.api_key deixa de ser a fonte da autenticacao; contas novas nao o preenchem.
+ALTER TABLE accounts ALTER COLUMN api_key DROP NOT NULL;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS rate_limit_windows;
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS rate_limit_burst,
+    DROP COLUMN IF EXISTS rate_limit_per_minute,
+    DROP COLUMN IF EXISTS rate_limit_plan;
+DROP TABLE IF EXISTS rate_limit_plans;
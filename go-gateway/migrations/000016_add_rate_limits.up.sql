@@ -0,0 +1,30 @@
+-- Planos de rate limit; contas sem plano nem override usam o limite global (API_RATE_LIMIT_*).
+CREATE TABLE IF NOT EXISTS rate_limit_plans (
+    name VARCHAR(50) PRIMARY KEY,
+    requests_per_minute INTEGER NOT NULL CHECK (requests_per_minute > 0),
+    burst INTEGER NOT NULL CHECK (burst > 0)
+);
+
+INSERT INTO rate_limit_plans (name, requests_per_minute, burst) VALUES
+    ('starter', 60, 10),
+    ('growth', 600, 100),
+    ('enterprise', 6000, 1000)
+ON CONFLICT (name) DO NOTHING;
+
+-- Override por conta tem precedencia sobre o plano.
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS rate_limit_plan VARCHAR(50) REFERENCES rate_limit_plans(name),
+    ADD COLUMN IF NOT EXISTS rate_limit_per_minute INTEGER CHECK (rate_limit_per_minute > 0),
+    ADD COLUMN IF NOT EXISTS rate_limit_burst INTEGER CHECK (rate_limit_burst > 0);
+
+-- Contadores da janela deslizante compartilhada (RATE_LIMIT_BACKEND=postgres). A janela varia
+-- com o limite da chave; expires_at marca quando ela deixa de entrar no calculo.
+CREATE TABLE IF NOT EXISTS rate_limit_windows (
+    key VARCHAR(255) NOT NULL,
+    window_start TIMESTAMP NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (key, window_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_windows_expires_at ON rate_limit_windows (expires_at);
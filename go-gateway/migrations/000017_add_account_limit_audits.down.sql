@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_account_limit_audits_account_created;
+DROP TABLE IF EXISTS account_limit_audits;
@@ -0,0 +1,16 @@
+-- Trilha de auditoria das alteracoes de limites feitas pela API admin.
+CREATE TABLE IF NOT EXISTS account_limit_audits (
+    id UUID PRIMARY KEY,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    mode VARCHAR(4) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    changed_by VARCHAR(255) NOT NULL,
+    reason TEXT,
+    previous_limits JSONB,
+    new_limits JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_limit_audits_account_created
+    ON account_limit_audits (account_id, created_at DESC);
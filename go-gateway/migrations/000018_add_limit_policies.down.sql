@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_invoices_account_mode_type_created;
+DROP INDEX IF EXISTS idx_invoices_manual_review;
+ALTER TABLE invoices DROP COLUMN IF EXISTS manual_review;
+ALTER TABLE account_limits
+    DROP COLUMN IF EXISTS timezone,
+    DROP COLUMN IF EXISTS enforcement,
+    DROP COLUMN IF EXISTS rules;
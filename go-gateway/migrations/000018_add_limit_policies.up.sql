@@ -0,0 +1,16 @@
+-- Regras adicionais (janelas moveis, mensais e por tipo de pagamento), modo de aplicacao e fuso
+-- usado nas janelas de dia e mes.
+ALTER TABLE account_limits
+    ADD COLUMN IF NOT EXISTS rules JSONB NOT NULL DEFAULT '[]'::jsonb,
+    ADD COLUMN IF NOT EXISTS enforcement VARCHAR(10) NOT NULL DEFAULT 'hard',
+    ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- Faturas de cartao retidas por limite soft aguardam a decisao de um operador; o antifraude
+-- automatico nao as aprova.
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS manual_review BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_invoices_manual_review ON invoices (created_at) WHERE manual_review;
+
+-- Usado pelas regras por tipo de pagamento.
+CREATE INDEX IF NOT EXISTS idx_invoices_account_mode_type_created
+    ON invoices (account_id, mode, currency, payment_type, created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_boletos_unpaid_due_date;
+DROP TABLE IF EXISTS boletos;
+DROP SEQUENCE IF EXISTS boleto_our_number_seq;
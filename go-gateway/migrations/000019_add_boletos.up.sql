@@ -0,0 +1,20 @@
+-- Nosso numero sequencial usado no campo livre do codigo de barras.
+CREATE SEQUENCE IF NOT EXISTS boleto_our_number_seq;
+
+-- Dados do boleto emitido para faturas com payment_type boleto.
+CREATE TABLE IF NOT EXISTS boletos (
+    invoice_id UUID PRIMARY KEY REFERENCES invoices(id) ON DELETE CASCADE,
+    bank_code CHAR(3) NOT NULL,
+    our_number BIGINT NOT NULL UNIQUE,
+    barcode CHAR(44) NOT NULL UNIQUE,
+    digitable_line CHAR(47) NOT NULL UNIQUE,
+    due_date DATE NOT NULL,
+    paid_at TIMESTAMP,
+    paid_amount_cents BIGINT,
+    settlement_source VARCHAR(50),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Usado pelo job que expira boletos vencidos nao pagos.
+CREATE INDEX IF NOT EXISTS idx_boletos_unpaid_due_date
+    ON boletos (due_date) WHERE paid_at IS NULL;
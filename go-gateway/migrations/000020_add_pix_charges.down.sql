@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS pix_unapplied_payments;
+DROP INDEX IF EXISTS idx_pix_charges_unpaid_expires_at;
+DROP TABLE IF EXISTS pix_charges;
@@ -0,0 +1,31 @@
+-- Cobrancas PIX (BR Code copia e cola) de faturas com payment_type pix.
+CREATE TABLE IF NOT EXISTS pix_charges (
+    invoice_id UUID PRIMARY KEY REFERENCES invoices(id) ON DELETE CASCADE,
+    txid VARCHAR(35) NOT NULL UNIQUE,
+    payload TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    end_to_end_id VARCHAR(32) UNIQUE,
+    paid_at TIMESTAMP,
+    paid_amount_cents BIGINT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Usado pelo job que expira cobrancas nao pagas.
+CREATE INDEX IF NOT EXISTS idx_pix_charges_unpaid_expires_at
+    ON pix_charges (expires_at) WHERE paid_at IS NULL;
+
+-- Pagamentos PIX recebidos que nao creditam a fatura (fatura expirada/cancelada ou cobranca ja
+-- paga). Ficam registrados para devolucao ao pagador; refunded_at e preenchido na devolucao.
+CREATE TABLE IF NOT EXISTS pix_unapplied_payments (
+    end_to_end_id VARCHAR(32) PRIMARY KEY,
+    invoice_id UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    txid VARCHAR(35) NOT NULL,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('invoice_closed', 'duplicate')),
+    amount_cents BIGINT NOT NULL,
+    paid_at TIMESTAMP NOT NULL,
+    refunded_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pix_unapplied_payments_pending_refund
+    ON pix_unapplied_payments (created_at) WHERE refunded_at IS NULL;
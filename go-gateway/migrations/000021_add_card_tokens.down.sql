@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_card_tokens_key_id;
+DROP TABLE IF EXISTS card_tokens;
@@ -0,0 +1,18 @@
+-- Cofre de cartoes: PAN cifrado (AES-256-GCM) com o key id da chave usada e dados exibiveis.
+CREATE TABLE IF NOT EXISTS card_tokens (
+    id VARCHAR(40) PRIMARY KEY,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    mode VARCHAR(4) NOT NULL CHECK (mode IN ('live', 'test')),
+    brand VARCHAR(20) NOT NULL,
+    bin VARCHAR(6) NOT NULL,
+    last4 VARCHAR(4) NOT NULL,
+    expiry_month INTEGER NOT NULL,
+    expiry_year INTEGER NOT NULL,
+    cardholder_name VARCHAR(255) NOT NULL,
+    encrypted_pan BYTEA NOT NULL,
+    key_id VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Usado na recifragem dos tokens ao rotacionar CARD_VAULT_ACTIVE_KEY_ID.
+CREATE INDEX IF NOT EXISTS idx_card_tokens_key_id ON card_tokens (key_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_invoices_account_card_brand_created_id;
+
+ALTER TABLE invoices
+    DROP COLUMN IF EXISTS card_brand,
+    DROP COLUMN IF EXISTS card_bin,
+    DROP COLUMN IF EXISTS card_funding,
+    DROP COLUMN IF EXISTS card_country;
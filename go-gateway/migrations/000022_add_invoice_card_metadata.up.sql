@@ -0,0 +1,10 @@
+-- Bandeira e BIN vem do numero do cartao; tipo (credit/debit/prepaid) e pais emissor da tabela de BINs.
+ALTER TABLE invoices
+    ADD COLUMN IF NOT EXISTS card_brand VARCHAR(20) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS card_bin VARCHAR(8) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS card_funding VARCHAR(10) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS card_country VARCHAR(2) NOT NULL DEFAULT '';
+
+-- Relatorios por bandeira (GET /invoice?card_brand=).
+CREATE INDEX IF NOT EXISTS idx_invoices_account_card_brand_created_id
+    ON invoices (account_id, card_brand, created_at DESC, id DESC);
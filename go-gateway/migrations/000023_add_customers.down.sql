@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_invoices_customer_created_id;
+ALTER TABLE invoices DROP COLUMN IF EXISTS customer_id;
+
+DROP INDEX IF EXISTS idx_card_tokens_customer_id;
+ALTER TABLE card_tokens DROP COLUMN IF EXISTS customer_id;
+
+DROP INDEX IF EXISTS idx_customers_account_mode_external_reference;
+DROP INDEX IF EXISTS idx_customers_account_mode_created_id;
+DROP TABLE IF EXISTS customers;
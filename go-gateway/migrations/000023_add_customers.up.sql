@@ -0,0 +1,39 @@
+-- Clientes do merchant por conta e modo. Clientes removidos ficam com deleted_at para manter as faturas.
+CREATE TABLE IF NOT EXISTS customers (
+    id UUID PRIMARY KEY,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    mode VARCHAR(4) NOT NULL CHECK (mode IN ('live', 'test')),
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    document VARCHAR(14) NOT NULL DEFAULT '',
+    document_type VARCHAR(4) NOT NULL DEFAULT '',
+    external_reference VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_customers_account_mode_created_id
+    ON customers (account_id, mode, created_at DESC, id DESC)
+    WHERE deleted_at IS NULL;
+
+-- A referencia externa identifica o cliente no sistema do merchant e nao pode se repetir.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_account_mode_external_reference
+    ON customers (account_id, mode, external_reference)
+    WHERE external_reference <> '' AND deleted_at IS NULL;
+
+-- Meios de pagamento salvos: tokens do cofre vinculados ao cliente.
+ALTER TABLE card_tokens
+    ADD COLUMN IF NOT EXISTS customer_id UUID NULL REFERENCES customers(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_card_tokens_customer_id
+    ON card_tokens (customer_id)
+    WHERE customer_id IS NOT NULL;
+
+ALTER TABLE invoices
+    ADD COLUMN IF NOT EXISTS customer_id UUID NULL REFERENCES customers(id);
+
+-- Faturas por cliente (GET /invoice?customer_id=) e velocidade por cliente no antifraude.
+CREATE INDEX IF NOT EXISTS idx_invoices_customer_created_id
+    ON invoices (customer_id, created_at DESC, id DESC)
+    WHERE customer_id IS NOT NULL;
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_subscription_events_subscription_seq;
+DROP TABLE IF EXISTS subscription_events;
+
+DROP INDEX IF EXISTS idx_subscriptions_customer_id;
+DROP INDEX IF EXISTS idx_subscriptions_pending_invoice;
+DROP INDEX IF EXISTS idx_subscriptions_next_billing;
+DROP INDEX IF EXISTS idx_subscriptions_account_mode_created_id;
+DROP TABLE IF EXISTS subscriptions;
+
+DROP INDEX IF EXISTS idx_subscription_plans_account_mode_created;
+DROP TABLE IF EXISTS subscription_plans;
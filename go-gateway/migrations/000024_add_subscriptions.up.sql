@@ -0,0 +1,75 @@
+-- Planos de assinatura: valor e ciclo de cobranca. Planos arquivados (active = false) nao aceitam novas assinaturas.
+CREATE TABLE IF NOT EXISTS subscription_plans (
+    id UUID PRIMARY KEY,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    mode VARCHAR(4) NOT NULL CHECK (mode IN ('live', 'test')),
+    name VARCHAR(255) NOT NULL,
+    amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+    currency VARCHAR(3) NOT NULL,
+    billing_interval VARCHAR(5) NOT NULL CHECK (billing_interval IN ('day', 'week', 'month', 'year')),
+    interval_count INTEGER NOT NULL CHECK (interval_count >= 1),
+    trial_days INTEGER NOT NULL DEFAULT 0 CHECK (trial_days >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_plans_account_mode_created
+    ON subscription_plans (account_id, mode, created_at DESC);
+
+-- Assinaturas: cliente + cartao salvo cobrados pelo scheduler em next_billing_at.
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id UUID PRIMARY KEY,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    mode VARCHAR(4) NOT NULL CHECK (mode IN ('live', 'test')),
+    plan_id UUID NOT NULL REFERENCES subscription_plans(id),
+    customer_id UUID NOT NULL REFERENCES customers(id),
+    card_token VARCHAR(40) NOT NULL REFERENCES card_tokens(id),
+    status VARCHAR(10) NOT NULL CHECK (status IN ('trialing', 'active', 'past_due', 'canceled')),
+    billing_anchor TIMESTAMP NOT NULL,
+    current_period_start TIMESTAMP NOT NULL,
+    current_period_end TIMESTAMP NOT NULL,
+    next_billing_at TIMESTAMP NULL,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    pending_invoice_id UUID NULL REFERENCES invoices(id),
+    latest_invoice_id UUID NULL REFERENCES invoices(id),
+    -- Fatura reservada pelo scheduler ao reservar a assinatura. Derivada de (assinatura, periodo,
+    -- tentativa), faz a repeticao da cobranca reencontrar a mesma fatura. Sem FK: a fatura ainda
+    -- nao existe quando a reserva e gravada.
+    billing_invoice_id UUID NULL,
+    cancel_at_period_end BOOLEAN NOT NULL DEFAULT FALSE,
+    canceled_at TIMESTAMP NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_account_mode_created_id
+    ON subscriptions (account_id, mode, created_at DESC, id DESC);
+
+-- Fila do scheduler: assinaturas sem fatura em aberto, pela data da proxima cobranca.
+CREATE INDEX IF NOT EXISTS idx_subscriptions_next_billing
+    ON subscriptions (next_billing_at)
+    WHERE status <> 'canceled' AND pending_invoice_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_pending_invoice
+    ON subscriptions (pending_invoice_id)
+    WHERE pending_invoice_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_customer_id ON subscriptions (customer_id);
+
+-- Historico de cobrancas e transicoes da assinatura. seq ordena eventos gravados no mesmo instante.
+CREATE TABLE IF NOT EXISTS subscription_events (
+    id UUID PRIMARY KEY,
+    seq BIGSERIAL NOT NULL,
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    from_status VARCHAR(10) NULL,
+    to_status VARCHAR(10) NULL,
+    invoice_id UUID NULL REFERENCES invoices(id),
+    metadata JSONB NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_events_subscription_seq
+    ON subscription_events (subscription_id, seq);
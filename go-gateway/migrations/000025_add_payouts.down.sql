@@ -0,0 +1,7 @@
+UPDATE api_keys SET scopes = array_remove(scopes, 'payouts:write');
+
+DROP INDEX IF EXISTS idx_payouts_account_mode_created_id;
+DROP TABLE IF EXISTS payouts;
+
+DROP INDEX IF EXISTS idx_bank_accounts_account_mode_created;
+DROP TABLE IF EXISTS bank_accounts;
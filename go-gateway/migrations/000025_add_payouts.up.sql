@@ -0,0 +1,43 @@
+-- Contas bancarias de destino dos saques. Contas removidas ficam com deleted_at para manter os saques.
+CREATE TABLE IF NOT EXISTS bank_accounts (
+    id UUID PRIMARY KEY,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    mode VARCHAR(4) NOT NULL CHECK (mode IN ('live', 'test')),
+    holder_name VARCHAR(255) NOT NULL,
+    holder_document VARCHAR(14) NOT NULL,
+    holder_document_type VARCHAR(4) NOT NULL,
+    bank_code VARCHAR(3) NOT NULL,
+    branch VARCHAR(5) NOT NULL,
+    account_number VARCHAR(14) NOT NULL,
+    account_type VARCHAR(8) NOT NULL CHECK (account_type IN ('checking', 'savings')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_accounts_account_mode_created
+    ON bank_accounts (account_id, mode, created_at DESC)
+    WHERE deleted_at IS NULL;
+
+-- Saques: o valor sai do saldo (merchant_balance -> payout_clearing) na criacao.
+CREATE TABLE IF NOT EXISTS payouts (
+    id UUID PRIMARY KEY,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    mode VARCHAR(4) NOT NULL CHECK (mode IN ('live', 'test')),
+    bank_account_id UUID NOT NULL REFERENCES bank_accounts(id),
+    amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+    currency VARCHAR(3) NOT NULL,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('pending', 'in_transit', 'paid', 'failed')),
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    failure_reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    paid_at TIMESTAMP NULL,
+    failed_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_payouts_account_mode_created_id
+    ON payouts (account_id, mode, created_at DESC, id DESC);
+
+-- payouts:write movimenta dinheiro para fora da conta e nao e concedido as chaves ja existentes.
+-- Chaves emitidas em POST /accounts depois desta migration ja o recebem; nas chaves antigas o
+-- operador concede o escopo pelo admin (POST /admin/accounts/{id}/api-keys/{key_id}/scopes).
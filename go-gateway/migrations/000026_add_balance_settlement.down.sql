@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_balance_releases_invoice;
+DROP INDEX IF EXISTS idx_balance_releases_due;
+DROP TABLE IF EXISTS balance_releases;
+
+ALTER TABLE account_balances DROP COLUMN IF EXISTS pending_cents;
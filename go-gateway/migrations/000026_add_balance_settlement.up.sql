@@ -0,0 +1,23 @@
+-- Parte do saldo aguardando liquidacao (merchant_pending). balance_cents continua sendo o total.
+ALTER TABLE account_balances ADD COLUMN IF NOT EXISTS pending_cents BIGINT NOT NULL DEFAULT 0;
+
+-- Valores aprovados que ficam disponiveis para saque em available_at (D+N por tipo de pagamento).
+-- amount_cents diminui quando um estorno consome a parte ainda pendente.
+CREATE TABLE IF NOT EXISTS balance_releases (
+    id UUID PRIMARY KEY,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    invoice_id UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    mode VARCHAR(4) NOT NULL CHECK (mode IN ('live', 'test')),
+    currency VARCHAR(3) NOT NULL,
+    amount_cents BIGINT NOT NULL CHECK (amount_cents >= 0),
+    available_at TIMESTAMP NOT NULL,
+    released_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_releases_due
+    ON balance_releases (available_at)
+    WHERE released_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_balance_releases_invoice
+    ON balance_releases (invoice_id);
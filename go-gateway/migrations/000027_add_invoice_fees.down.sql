@@ -0,0 +1,6 @@
+ALTER TABLE invoices DROP COLUMN IF EXISTS fee_breakdown;
+ALTER TABLE invoices DROP COLUMN IF EXISTS net_cents;
+ALTER TABLE invoices DROP COLUMN IF EXISTS fee_cents;
+ALTER TABLE invoices DROP COLUMN IF EXISTS installments;
+
+DROP TABLE IF EXISTS pricing_plans;
@@ -0,0 +1,21 @@
+-- Tarifas por conta, modo e moeda: percentual (pontos-base) e valor fixo por tipo de pagamento,
+-- com acrescimos opcionais por numero de parcelas.
+CREATE TABLE IF NOT EXISTS pricing_plans (
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    mode VARCHAR(4) NOT NULL CHECK (mode IN ('live', 'test')),
+    currency VARCHAR(3) NOT NULL,
+    rates JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (account_id, mode, currency)
+);
+
+-- Tarifa e valor liquido definidos no credito do saldo; fee_breakdown guarda o detalhamento.
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS installments SMALLINT NOT NULL DEFAULT 1 CHECK (installments BETWEEN 1 AND 12);
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS fee_cents BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS net_cents BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS fee_breakdown JSONB NULL;
+
+-- Faturas creditadas antes das tarifas receberam o valor bruto.
+UPDATE invoices SET net_cents = captured_cents
+WHERE status IN ('approved', 'refunded', 'partially_refunded');
@@ -12,6 +12,8 @@ export type PendingInvoicesMessage = {
   account_id: string;
   amount: number;
   amount_cents?: number;
+  currency?: string;
+  minor_units?: number;
   invoice_id: string;
 };
 
@@ -49,11 +51,12 @@ export class InvoicesConsumer {
       return;
     }
 
+    const minorFactor = 10 ** (message.minor_units ?? 2);
     const amountCents =
       typeof message.amount_cents === 'number'
         ? message.amount_cents
-        : Math.round(message.amount * 100);
-    const amountDecimal = new Prisma.Decimal(amountCents).div(100);
+        : Math.round(message.amount * minorFactor);
+    const amountDecimal = new Prisma.Decimal(amountCents).div(minorFactor);
     try {
       const result = await this.fraudService.processInvoice({
         event_id: message.event_id,
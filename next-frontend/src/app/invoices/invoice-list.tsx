@@ -2,7 +2,7 @@ import { Button } from "@/components/ui/button"
 import { Card, CardContent } from "@/components/ui/card"
 import { Plus, Eye, ChevronLeft, ChevronRight, Receipt, TrendingUp, Clock } from "lucide-react"
 import Link from "next/link"
-import { StatusBadge } from "@/components/StatusBadge"
+import { StatusBadge, type InvoiceStatus } from "@/components/StatusBadge"
 import { InvoiceDownloadButton } from "@/components/invoice-download-button"
 import { PendingAutoRefresh } from "@/app/invoices/pending-auto-refresh"
 import { cookies } from "next/headers"
@@ -15,13 +15,14 @@ export async function getInvoices() {
   if (!apiKey) {
     return []
   }
-  const response = await fetch(`${apiBaseUrl}/invoice`, {
+  const response = await fetch(`${apiBaseUrl}/invoice?limit=100`, {
     headers: {
       "X-API-KEY": apiKey as string,
     },
     cache: "no-store",
   })
-  return response.json()
+  const payload = await response.json()
+  return payload?.data ?? []
 }
 
 type InvoiceListProps = {
@@ -219,7 +220,7 @@ export async function InvoiceList({ page, size }: InvoiceListProps) {
                     created_at: string
                     description: string
                     amount: number
-                    status: "approved" | "pending" | "rejected"
+                    status: InvoiceStatus
                   }) => (
                     <tr
                       key={invoice.id}
@@ -1,7 +1,16 @@
 import { Badge } from "@/components/ui/badge"
-import { CheckCircle2, Clock, XCircle } from "lucide-react"
+import { Ban, CheckCircle2, Clock, RotateCcw, ShieldCheck, XCircle } from "lucide-react"
 
-export type InvoiceStatus = "approved" | "pending" | "rejected"
+export type InvoiceStatus =
+  | "approved"
+  | "pending"
+  | "rejected"
+  | "authorized"
+  | "voided"
+  | "refunded"
+  | "partially_refunded"
+  | "cancelled"
+  | "expired"
 
 interface StatusBadgeProps {
   status: InvoiceStatus
@@ -36,10 +45,64 @@ const statusConfig = {
       color: "var(--danger-text)",
     },
   },
+  authorized: {
+    text: "Autorizado",
+    icon: ShieldCheck,
+    style: {
+      borderColor: "var(--warning-border)",
+      backgroundColor: "var(--warning-bg)",
+      color: "var(--warning-text)",
+    },
+  },
+  voided: {
+    text: "Liberado",
+    icon: Ban,
+    style: {
+      borderColor: "var(--border)",
+      backgroundColor: "transparent",
+      color: "var(--muted-foreground)",
+    },
+  },
+  refunded: {
+    text: "Estornado",
+    icon: RotateCcw,
+    style: {
+      borderColor: "var(--border)",
+      backgroundColor: "transparent",
+      color: "var(--muted-foreground)",
+    },
+  },
+  partially_refunded: {
+    text: "Estorno parcial",
+    icon: RotateCcw,
+    style: {
+      borderColor: "var(--border)",
+      backgroundColor: "transparent",
+      color: "var(--muted-foreground)",
+    },
+  },
+  cancelled: {
+    text: "Cancelado",
+    icon: Ban,
+    style: {
+      borderColor: "var(--border)",
+      backgroundColor: "transparent",
+      color: "var(--muted-foreground)",
+    },
+  },
+  expired: {
+    text: "Expirado",
+    icon: Clock,
+    style: {
+      borderColor: "var(--border)",
+      backgroundColor: "transparent",
+      color: "var(--muted-foreground)",
+    },
+  },
 }
 
 export function StatusBadge({ status, showIcon = true }: StatusBadgeProps) {
-  const config = statusConfig[status]
+  const config = statusConfig[status] ?? statusConfig.pending
   const Icon = config.icon
 
   return (